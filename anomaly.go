@@ -0,0 +1,72 @@
+package anymind
+
+import (
+	"context"
+	"github.com/cockroachdb/apd"
+	"time"
+)
+
+// Anomaly status. Flagged deposit is credited, held deposit is only credited once it is released.
+const (
+	AnomalyFlagged  = "flagged"
+	AnomalyHeld     = "held"
+	AnomalyReleased = "released"
+	AnomalyRejected = "rejected"
+)
+
+// Reason deposit is out of pattern.
+const (
+	AnomalyReasonAmount = "amount"
+	AnomalyReasonHour   = "hour"
+)
+
+// Anomaly is a deposit out of the pattern of its wallet, every asset balance is a wallet.
+type Anomaly struct {
+	ID       int64
+	DateTime time.Time
+	Amount   apd.Decimal
+	Asset    string
+	Reason   string
+	// Score is robust z-score of amount for AnomalyReasonAmount, share of recent deposits around the hour
+	// for AnomalyReasonHour.
+	Score  float64
+	Status string
+	// DepositStatus is status deposit is credited with once released, pending deposit stay pending.
+	DepositStatus string
+	// DepositID is id of credited deposit, it is 0 while deposit is held or when it was rejected.
+	DepositID int64
+	// KeyID and Subject identify principal who made the deposit, held deposit is credited on its behalf.
+	KeyID      int64
+	Subject    string
+	CreatedAt  time.Time
+	ReviewedAt *time.Time
+}
+
+// DepositInput return deposit the anomaly was raised for.
+func (a *Anomaly) DepositInput() *DepositInput {
+	return &DepositInput{DateTime: a.DateTime, Amount: a.Amount, Asset: a.Asset, Status: a.DepositStatus}
+}
+
+// AnomalyDetector score deposit against rolling stats of its wallet.
+//
+//go:generate moq -out src/mock/mock_anomaly_detector.go -pkg mock . AnomalyDetector
+type AnomalyDetector interface {
+	// Inspect return anomaly when deposit is out of pattern and nil otherwise, rolling stats are left as they are.
+	// Returned anomaly is not stored and has no status.
+	Inspect(ctx context.Context, input *DepositInput) (*Anomaly, error)
+	// Observe add credited deposit to rolling stats. Held and rejected deposits are never observed, so outliers
+	// do not widen the pattern they are judged against.
+	Observe(ctx context.Context, input *DepositInput) error
+}
+
+// AnomalyService store anomalies and their review.
+//
+//go:generate moq -out src/mock/mock_anomaly_service.go -pkg mock . AnomalyService
+type AnomalyService interface {
+	RecordAnomaly(ctx context.Context, anomaly *Anomaly) error
+	// ListAnomalies return latest anomalies first, every status when status is empty.
+	ListAnomalies(ctx context.Context, status string, limit int) ([]*Anomaly, error)
+	// ReviewAnomaly set held anomaly to AnomalyReleased, crediting its deposit, or AnomalyRejected.
+	// ErrNotFound is returned when there is no held anomaly with id.
+	ReviewAnomaly(ctx context.Context, id int64, status string) (*Anomaly, error)
+}
@@ -9,16 +9,42 @@ import (
 type DepositInput struct {
 	DateTime time.Time
 	Amount   apd.Decimal
+	// Asset is asset code, DefaultAsset when empty.
+	Asset string
+	// Status is DepositConfirmed when empty, pending deposit only count toward balance once confirmed.
+	Status string
+	// ID is set once deposit is stored.
+	ID int64
+	// AnomalyID is set instead of ID when deposit is held for review, it is the reference deposit is released by.
+	AnomalyID int64
 }
 
 type HistoricalData struct {
 	DateTime time.Time
 	Amount   apd.Decimal
+	// Value is Amount in HistoricalDataReq.Quote, nil when quote is not requested or no rate is known for the hour.
+	Value *apd.Decimal
 }
 
 type HistoricalDataReq struct {
 	Start time.Time
 	End   time.Time
+	// Asset is asset code, DefaultAsset when empty.
+	Asset string
+	// Quote is asset code balance is valued in, see ValueHourly.
+	Quote string
+	// IncludePending add pending deposits to confirmed balance, see AddPending.
+	IncludePending bool
+}
+
+// DepositEvent is a committed deposit as seen by the change feed.
+type DepositEvent struct {
+	ID       int64
+	DateTime time.Time
+	Amount   apd.Decimal
+	Asset    string
+	// Seq is position of deposit in confirmation order, pending deposit get it once confirmed.
+	Seq int64
 }
 
 // PersistenceService is data persistence service interface.
@@ -37,6 +63,14 @@ type APIService interface {
 	Historical(ctx context.Context, req *HistoricalDataReq) ([]*HistoricalData, error)
 }
 
+// DepositFeed provide committed deposits from every service replica.
+//
+//go:generate moq -out src/mock/mock_deposit_feed.go -pkg mock . DepositFeed
+type DepositFeed interface {
+	// Subscribe return channel receiving committed deposits, channel is closed when ctx is done.
+	Subscribe(ctx context.Context) <-chan *DepositEvent
+}
+
 // HTTPService provide API to listen and serve http services.
 type HTTPService interface {
 	// Start service, this will block and only return when service stopped / errored.
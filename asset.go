@@ -0,0 +1,72 @@
+package anymind
+
+import (
+	"context"
+	"fmt"
+	"github.com/cockroachdb/apd"
+	"regexp"
+	"time"
+)
+
+// DefaultAsset is asset of deposits made before assets were introduced, and of request that does not name one.
+const DefaultAsset = "BTC"
+
+// MaxAssetScale bound decimal places of asset amount.
+const MaxAssetScale = 36
+
+var assetCodePattern = regexp.MustCompile(`^[A-Z0-9]{2,12}$`)
+
+// Asset is currency held in wallets, e.g. BTC, ETH or a stablecoin.
+type Asset struct {
+	Code string
+	// Scale is number of decimal places amount can have, e.g. 8 for BTC and 18 for ETH.
+	Scale int
+	// DisplayPrecision is number of decimal places shown to users, stored amounts are never rounded to it.
+	DisplayPrecision int
+	CreatedAt        time.Time
+}
+
+// AssetCode return code, or DefaultAsset when code is empty.
+func AssetCode(code string) string {
+	if code == "" {
+		return DefaultAsset
+	}
+
+	return code
+}
+
+// Validate check asset code and precision of asset to be registered.
+func (a *Asset) Validate() error {
+	switch {
+	case !assetCodePattern.MatchString(a.Code):
+		return fmt.Errorf("asset code %q must be 2 to 12 upper case letters or digits", a.Code)
+	case a.Scale < 0 || a.Scale > MaxAssetScale:
+		return fmt.Errorf("asset scale must be between 0 and %d", MaxAssetScale)
+	case a.DisplayPrecision < 0 || a.DisplayPrecision > a.Scale:
+		return fmt.Errorf("asset display precision must be between 0 and scale %d", a.Scale)
+	}
+
+	return nil
+}
+
+// CheckAmount return error when amount has more decimal places than asset scale, trailing zeros are not counted.
+func (a *Asset) CheckAmount(amount *apd.Decimal) error {
+	var reduced apd.Decimal
+	reduced.Reduce(amount)
+
+	if -reduced.Exponent > int32(a.Scale) {
+		return fmt.Errorf("%s amount can have at most %d decimal places", a.Code, a.Scale)
+	}
+
+	return nil
+}
+
+// AssetService manage asset registry.
+//
+//go:generate moq -out src/mock/mock_asset_service.go -pkg mock . AssetService
+type AssetService interface {
+	// Asset return registered asset, error is ErrNotFound when code is unknown.
+	Asset(ctx context.Context, code string) (*Asset, error)
+	ListAssets(ctx context.Context) ([]*Asset, error)
+	CreateAsset(ctx context.Context, asset *Asset) error
+}
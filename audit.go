@@ -0,0 +1,168 @@
+package anymind
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/cockroachdb/apd"
+	"strconv"
+	"time"
+)
+
+// Audited actions.
+const (
+	AuditDeposit          = "deposit"
+	AuditWebhookCreate    = "webhook.create"
+	AuditWebhookDelete    = "webhook.delete"
+	AuditWebhookRedeliver = "webhook.redeliver"
+	AuditAPIKeyCreate     = "apikey.create"
+	AuditAPIKeyRevoke     = "apikey.revoke"
+	AuditAssetCreate      = "asset.create"
+	AuditRatesImport      = "rates.import"
+	AuditAnomalyReview    = "anomaly.review"
+	AuditDepositStatus    = "deposit.status"
+	AuditDepositExpire    = "deposit.expire"
+)
+
+// Outcome of audited action. Success is written in the transaction of the change,
+// rejected and failed are written on their own since nothing was changed.
+const (
+	AuditSuccess  = "success"
+	AuditRejected = "rejected"
+	AuditFailed   = "failed"
+)
+
+// AuditEntry record who did what, entries are chained by hash so tampering with any of them is detectable.
+type AuditEntry struct {
+	ID        int64
+	At        time.Time
+	Actor     string
+	ClientIP  string
+	RequestID string
+	Action    string
+	// PayloadHash is hex encoded sha256 of normalized payload, see AuditPayloadHash.
+	PayloadHash string
+	Outcome     string
+	// PrevHash is hash of previous entry, empty for the first one.
+	PrevHash string
+	Hash     string
+}
+
+// ChainHash return hash of entry linked to PrevHash, every field except Hash is covered.
+func (e *AuditEntry) ChainHash() string {
+	return chainHash(
+		e.PrevHash,
+		strconv.FormatInt(e.ID, 10),
+		e.At.UTC().Format(time.RFC3339Nano),
+		e.Actor,
+		e.ClientIP,
+		e.RequestID,
+		e.Action,
+		e.PayloadHash,
+		e.Outcome)
+}
+
+// NewAuditEntry describe action done in ctx, actor, client ip and request id are taken from ctx.
+func NewAuditEntry(ctx context.Context, action string, payload any, outcome string, at time.Time) (*AuditEntry, error) {
+	hash, err := AuditPayloadHash(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	requestID, _ := RequestIDFromContext(ctx)
+	clientIP, _ := ClientIPFromContext(ctx)
+
+	return &AuditEntry{
+		// database keep microsecond, hash must be computed on what is stored.
+		At:          at.UTC().Truncate(time.Microsecond),
+		Actor:       AuditActor(ctx),
+		ClientIP:    clientIP,
+		RequestID:   requestID,
+		Action:      action,
+		PayloadHash: hash,
+		Outcome:     outcome,
+	}, nil
+}
+
+// AuditActor identify principal of ctx, e.g. key:12 or sub:alice, it is anonymous when request was not authenticated.
+func AuditActor(ctx context.Context) string {
+	p, ok := PrincipalFromContext(ctx)
+	switch {
+	case !ok:
+		return "anonymous"
+	case p.KeyID != 0:
+		return "key:" + strconv.FormatInt(p.KeyID, 10)
+	case p.Subject != "":
+		return "sub:" + p.Subject
+	default:
+		return "anonymous"
+	}
+}
+
+// AuditPayloadHash hash payload encoded as json, map key are sorted by encoding/json so equal payload give equal hash.
+func AuditPayloadHash(payload any) (string, error) {
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(encoded)
+
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// DepositAuditPayload normalize deposit the way it is stored, so payload hash does not depend on input formatting.
+func DepositAuditPayload(input *DepositInput) map[string]string {
+	// trailing zeros are removed, 1.50 and 1.5 are the same amount.
+	var amount apd.Decimal
+	amount.Reduce(&input.Amount)
+
+	payload := map[string]string{
+		"datetime": input.DateTime.UTC().Truncate(time.Second).Format(time.RFC3339),
+		"amount":   amount.Text('f'),
+		"asset":    AssetCode(input.Asset),
+	}
+	// confirmed deposit payload is unchanged from before deposit had a status.
+	if status := DepositStatus(input.Status); status != DepositConfirmed {
+		payload["status"] = status
+	}
+
+	return payload
+}
+
+// RatesAuditPayload normalize imported rates like DepositAuditPayload.
+func RatesAuditPayload(rates []*Rate) map[string]any {
+	entries := make([]map[string]string, 0, len(rates))
+	for _, rate := range rates {
+		var value apd.Decimal
+		value.Reduce(&rate.Rate)
+
+		entries = append(entries, map[string]string{
+			"base":  rate.Base,
+			"quote": rate.Quote,
+			"hour":  rate.Hour.UTC().Format(time.RFC3339),
+			"rate":  value.Text('f'),
+		})
+	}
+
+	return map[string]any{"rates": entries}
+}
+
+// AuditFilter select audit entries, zero value field does not filter.
+type AuditFilter struct {
+	Actor   string
+	Action  string
+	AfterID int64
+	Limit   int
+}
+
+// AuditService write and query audit log.
+//
+//go:generate moq -out src/mock/mock_audit_service.go -pkg mock . AuditService
+type AuditService interface {
+	// RecordAudit append entry in its own transaction, for action that was rejected or failed before changing anything.
+	RecordAudit(ctx context.Context, action string, payload any, outcome string) error
+	// ListAudit return entries ordered by id.
+	ListAudit(ctx context.Context, filter *AuditFilter) ([]*AuditEntry, error)
+}
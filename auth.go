@@ -0,0 +1,114 @@
+package anymind
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Scopes granted to credentials.
+const (
+	ScopeDepositWrite = "deposit:write"
+	ScopeHistoryRead  = "history:read"
+	// ScopeAdmin grant every other scope.
+	ScopeAdmin = "admin"
+)
+
+// Principal is authenticated caller of a request.
+type Principal struct {
+	Subject string
+	// KeyID is id of api key used to authenticate, 0 when other credential is used.
+	KeyID  int64
+	Scopes []string
+}
+
+func (p *Principal) HasScope(scope string) bool {
+	for _, s := range p.Scopes {
+		if s == scope || s == ScopeAdmin {
+			return true
+		}
+	}
+
+	return false
+}
+
+type principalKey struct{}
+
+// WithPrincipal return context carrying authenticated principal.
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext return principal of the request, if it was authenticated.
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+
+	return p, ok
+}
+
+type credentialKey struct{}
+
+// WithCredential return context carrying credential sent by caller, it is authenticated by transport middleware.
+func WithCredential(ctx context.Context, credential string) context.Context {
+	return context.WithValue(ctx, credentialKey{}, credential)
+}
+
+// CredentialFromContext return credential sent by caller, it is empty when none was sent.
+func CredentialFromContext(ctx context.Context) string {
+	credential, _ := ctx.Value(credentialKey{}).(string)
+
+	return credential
+}
+
+// Authenticator resolve credential sent by caller into principal.
+// It return UnauthorizedErr when credential is not valid.
+//
+//go:generate moq -out src/mock/mock_authenticator.go -pkg mock . Authenticator
+type Authenticator interface {
+	Authenticate(ctx context.Context, credential string) (*Principal, error)
+}
+
+// Authenticators try each authenticator in order until one accept the credential.
+type Authenticators []Authenticator
+
+func (a Authenticators) Authenticate(ctx context.Context, credential string) (*Principal, error) {
+	var err error = UnauthorizedError(errors.New("no authenticator configured"))
+
+	for _, auth := range a {
+		var principal *Principal
+
+		principal, err = auth.Authenticate(ctx, credential)
+		if err == nil {
+			return principal, nil
+		}
+
+		var anyErr *Error
+		if !errors.As(err, &anyErr) || anyErr.Type != UnauthorizedErr {
+			return nil, err
+		}
+	}
+
+	return nil, err
+}
+
+type APIKey struct {
+	ID     int64
+	Name   string
+	Prefix string
+	// Hash is hex encoded sha256 of the key, plain key is never stored.
+	Hash      string
+	Scopes    []string
+	CreatedAt time.Time
+	RevokedAt *time.Time
+}
+
+// APIKeyPersistenceService store api keys.
+//
+//go:generate moq -out src/mock/mock_api_key_persistence_service.go -pkg mock . APIKeyPersistenceService
+type APIKeyPersistenceService interface {
+	CreateAPIKey(ctx context.Context, key *APIKey) error
+	// FindAPIKey return key by its hash, it return ErrNotFound when there is none.
+	FindAPIKey(ctx context.Context, hash string) (*APIKey, error)
+	ListAPIKeys(ctx context.Context) ([]*APIKey, error)
+	RevokeAPIKey(ctx context.Context, id int64, at time.Time) error
+}
@@ -0,0 +1,101 @@
+package anymind
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/cockroachdb/apd"
+	"time"
+)
+
+// DefaultGranularity is candle granularity when none is requested.
+const DefaultGranularity = time.Hour
+
+// MaxCandles bound number of candles a request can span.
+const MaxCandles = 2000
+
+// CandleReq request OHLC balance of every Granularity bucket between Start and End.
+type CandleReq struct {
+	Start time.Time
+	End   time.Time
+	// Asset is asset code, DefaultAsset when empty.
+	Asset string
+	// Granularity is a whole number of hours dividing a day, so buckets are aligned to UTC midnight.
+	Granularity time.Duration
+}
+
+// Validate check range and granularity of candle request.
+func (r *CandleReq) Validate() error {
+	if r.Start.After(r.End) {
+		return errors.New("invalid start and end date")
+	}
+
+	if r.Granularity < time.Hour || r.Granularity > 24*time.Hour ||
+		r.Granularity%time.Hour != 0 || (24*time.Hour)%r.Granularity != 0 {
+		return fmt.Errorf("granularity %s must be a whole number of hours dividing a day", r.Granularity)
+	}
+
+	if r.End.Sub(r.Start)/r.Granularity >= MaxCandles {
+		return fmt.Errorf("range must not span more than %d candles", MaxCandles)
+	}
+
+	return nil
+}
+
+// Candle is open, high, low and close balance of the bucket ending at DateTime, bucket cover
+// (DateTime - granularity, DateTime] so hourly candle match HistoricalData of the same DateTime.
+// High and Low include every balance reached within the bucket, not only at its ends.
+type Candle struct {
+	DateTime time.Time
+	Open     apd.Decimal
+	High     apd.Decimal
+	Low      apd.Decimal
+	Close    apd.Decimal
+}
+
+// CandleOf return datetime of the bucket of given granularity t belongs to, see Candle.
+func CandleOf(t time.Time, granularity time.Duration) time.Time {
+	return t.Truncate(time.Second).Add(-time.Second).Truncate(granularity).Add(granularity)
+}
+
+// FillCandles expand sparse candles into one candle per bucket between req.Start and req.End, like FillHourly.
+// Open is balance before the first bucket, candle open is previous candle close and high and low are widened to
+// include it. Bucket without candle keep the balance flat.
+func FillCandles(req *CandleReq, open apd.Decimal, candles []*Candle) []*Candle {
+	var res []*Candle
+
+	pos := 0
+	var balance apd.Decimal
+	balance.Set(&open)
+	for cur := CandleOf(req.Start, req.Granularity); !cur.After(req.End); cur = cur.Add(req.Granularity) {
+		entry := &Candle{DateTime: cur}
+		entry.Open.Set(&balance)
+		entry.High.Set(&balance)
+		entry.Low.Set(&balance)
+		entry.Close.Set(&balance)
+
+		if pos < len(candles) && candles[pos].DateTime.Equal(cur) {
+			if candles[pos].High.Cmp(&entry.High) > 0 {
+				entry.High.Set(&candles[pos].High)
+			}
+			if candles[pos].Low.Cmp(&entry.Low) < 0 {
+				entry.Low.Set(&candles[pos].Low)
+			}
+			entry.Close.Set(&candles[pos].Close)
+			pos++
+		}
+
+		balance.Set(&entry.Close)
+		res = append(res, entry)
+	}
+
+	return res
+}
+
+// CandleService build OHLC balance candles from individual deposits.
+//
+//go:generate moq -out src/mock/mock_candle_service.go -pkg mock . CandleService
+type CandleService interface {
+	// Candles return candle of every bucket between req.Start and req.End.
+	Candles(ctx context.Context, req *CandleReq) ([]*Candle, error)
+}
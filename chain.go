@@ -0,0 +1,143 @@
+package anymind
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/cockroachdb/apd"
+	"strconv"
+	"time"
+)
+
+// DepositRecord is a link of deposit chain, deposit as stored or settlement of pending deposit, it is what hash cover.
+type DepositRecord struct {
+	// Seq is position of link in chain. Link is chained in the transaction of its change, so chain follow commit order
+	// rather than ID, Seq is not covered by hash as PrevHash already fix the order.
+	Seq int64
+	// StatusChange is set on link recording that pending deposit ID was set to Status at DateTime by KeyID and
+	// Subject, such link has no Amount nor Asset.
+	StatusChange bool
+	ID           int64
+	DateTime     time.Time
+	Amount       apd.Decimal
+	Asset        string
+	// KeyID and Subject identify principal that made deposit, they are zero when request was not authenticated.
+	KeyID   int64
+	Subject string
+	// Status is status deposit was stored with, pending deposit stay pending in its link once settled.
+	// Status of settlement for StatusChange link.
+	Status   string
+	PrevHash string
+	Hash     string
+}
+
+// ChainHash return hash of deposit linked to PrevHash, every field except Seq and Hash is covered.
+func (r *DepositRecord) ChainHash() string {
+	if r.StatusChange {
+		// marker can not be mistaken for deposit id, so settlement can not pass for a deposit.
+		return chainHash(
+			r.PrevHash,
+			"status",
+			strconv.FormatInt(r.ID, 10),
+			r.DateTime.UTC().Format(time.RFC3339Nano),
+			r.Status,
+			strconv.FormatInt(r.KeyID, 10),
+			r.Subject)
+	}
+
+	// trailing zeros are removed, amount stored before column was unscaled NUMERIC is padded to 8 decimal places.
+	var amount apd.Decimal
+	amount.Reduce(&r.Amount)
+
+	fields := []string{
+		r.PrevHash,
+		strconv.FormatInt(r.ID, 10),
+		r.DateTime.UTC().Format(time.RFC3339Nano),
+		amount.Text('f'),
+		strconv.FormatInt(r.KeyID, 10),
+		r.Subject,
+	}
+
+	// default asset is left out, so hash of deposit made before assets were introduced stay valid.
+	if AssetCode(r.Asset) != DefaultAsset {
+		fields = append(fields, r.Asset)
+	}
+
+	// confirmed status is left out for the same reason, so pending deposit can not pass for a confirmed one.
+	if status := DepositStatus(r.Status); status != DepositConfirmed {
+		fields = append(fields, "status="+status)
+	}
+
+	return chainHash(fields...)
+}
+
+// chainHash return hex encoded sha256 of fields, length prefix keep field boundary unambiguous.
+func chainHash(fields ...string) string {
+	sum := sha256.New()
+	for _, field := range fields {
+		fmt.Fprintf(sum, "%d:%s\n", len(field), field)
+	}
+
+	return hex.EncodeToString(sum.Sum(nil))
+}
+
+// ChainCheckpoint is signed head of deposit chain, it detect chain rewritten from scratch after it was taken.
+type ChainCheckpoint struct {
+	ID       int64
+	At       time.Time
+	LastID   int64
+	HeadHash string
+	// Signature is ed25519 signature of SignedMessage.
+	Signature []byte
+}
+
+// SignedMessage return what is signed by checkpoint signature.
+func (c *ChainCheckpoint) SignedMessage() []byte {
+	return []byte(chainHash("anymind deposit checkpoint", strconv.FormatInt(c.LastID, 10), c.HeadHash, c.At.UTC().Format(time.RFC3339Nano)))
+}
+
+// Sign set checkpoint signature.
+func (c *ChainCheckpoint) Sign(key ed25519.PrivateKey) {
+	c.Signature = ed25519.Sign(key, c.SignedMessage())
+}
+
+// ChainBreak locate first inconsistency found in deposit chain.
+type ChainBreak struct {
+	// ID is deposit id, or checkpoint id when Checkpoint is set.
+	ID         int64
+	Checkpoint bool
+	Reason     string
+}
+
+// ChainReport is result of deposit chain verification.
+type ChainReport struct {
+	// Verified is number of links checked before Break, or every link when chain is intact.
+	Verified    int
+	HeadID      int64
+	HeadHash    string
+	Checkpoints int
+	Break       *ChainBreak
+}
+
+// ChainPersistenceService store deposit chain checkpoints and walk the chain.
+//
+//go:generate moq -out src/mock/mock_chain_persistence_service.go -pkg mock . ChainPersistenceService
+type ChainPersistenceService interface {
+	// DepositChainHead return id and hash of last chained deposit, they are zero when no deposit is chained.
+	DepositChainHead(ctx context.Context) (int64, string, error)
+	SaveChainCheckpoint(ctx context.Context, checkpoint *ChainCheckpoint) error
+	// ListChainCheckpoints return checkpoints ordered by LastID.
+	ListChainCheckpoints(ctx context.Context) ([]*ChainCheckpoint, error)
+	// VerifyDepositChain recompute every chained deposit hash in chain order and compare deposit at LastID of each
+	// checkpoint with its head.
+	VerifyDepositChain(ctx context.Context, checkpoints []*ChainCheckpoint) (*ChainReport, error)
+}
+
+// ChainService verify deposit chain and its checkpoints.
+//
+//go:generate moq -out src/mock/mock_chain_service.go -pkg mock . ChainService
+type ChainService interface {
+	VerifyChain(ctx context.Context) (*ChainReport, error)
+}
@@ -0,0 +1,412 @@
+package main
+
+import (
+	"anymind"
+	"anymind/src/client"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/cockroachdb/apd"
+	"os"
+	"time"
+)
+
+type command func(ctx context.Context, gf *globalFlags, args []string) error
+
+var commands = map[string]command{
+	"deposit": depositCmd,
+	"history": historyCmd,
+	"balance": balanceCmd,
+	"export":  exportCmd,
+	"health":  healthCmd,
+	"profile": profileCmd,
+}
+
+// newClient build api client from selected profile and global flags.
+func newClient(gf *globalFlags) (*client.Client, error) {
+	cfg, err := loadProfiles(gf.cfgPath)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := cfg.resolve(gf.profile)
+	if err != nil {
+		return nil, err
+	}
+
+	url := p.URL
+	if gf.url != "" {
+		url = gf.url
+	}
+
+	if url == "" {
+		return nil, errors.New("no service url, use -url or configure a profile")
+	}
+
+	apiKey := p.APIKey
+	if env := os.Getenv("ANYMINDCTL_API_KEY"); env != "" {
+		apiKey = env
+	}
+
+	var opts []client.Option
+	if apiKey != "" {
+		opts = append(opts, client.WithAPIKey(apiKey))
+	}
+	if p.Timeout != 0 {
+		opts = append(opts, client.WithTimeout(p.Timeout))
+	}
+	if p.Retries != 0 {
+		opts = append(opts, client.WithRetry(p.Retries, 200*time.Millisecond))
+	}
+
+	return client.NewClient(url, opts...)
+}
+
+// rangeFlags register -from and -to flags, range default to the last 24 hours.
+func rangeFlags(fs *flag.FlagSet) (from *string, to *string) {
+	from = fs.String("from", "", "start datetime in RFC3339, default 24 hours before -to")
+	to = fs.String("to", "", "end datetime in RFC3339, default now")
+
+	return from, to
+}
+
+// assetFlag register -asset flag, empty asset is anymind.DefaultAsset.
+func assetFlag(fs *flag.FlagSet) *string {
+	return fs.String("asset", "", "asset code, default "+anymind.DefaultAsset)
+}
+
+// quoteFlag register -quote flag, history is valued in quote asset when it is set.
+func quoteFlag(fs *flag.FlagSet) *string {
+	return fs.String("quote", "", "asset code to value balance in, e.g. USD")
+}
+
+func parseRange(from string, to string) (*anymind.HistoricalDataReq, error) {
+	req := &anymind.HistoricalDataReq{End: time.Now().UTC()}
+
+	var err error
+	if to != "" {
+		req.End, err = time.Parse(time.RFC3339, to)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -to: %w", err)
+		}
+	}
+
+	req.Start = req.End.Add(-24 * time.Hour)
+	if from != "" {
+		req.Start, err = time.Parse(time.RFC3339, from)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -from: %w", err)
+		}
+	}
+
+	return req, nil
+}
+
+func fetchHistory(ctx context.Context, c *client.Client, req *anymind.HistoricalDataReq) ([]*historyRow, error) {
+	res, err := c.Historical(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]*historyRow, 0, len(res))
+	for _, entry := range res {
+		row := &historyRow{
+			DateTime:    entry.DateTime,
+			Amount:      fmt.Sprintf("%f", &entry.Amount),
+			RateMissing: req.Quote != "" && entry.Value == nil,
+		}
+		if entry.Value != nil {
+			row.Value = fmt.Sprintf("%f", entry.Value)
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+func depositCmd(ctx context.Context, gf *globalFlags, args []string) error {
+	fs := flag.NewFlagSet("deposit", flag.ContinueOnError)
+	amount := fs.String("amount", "", "deposit amount, e.g. 1.5")
+	at := fs.String("at", "", "deposit datetime in RFC3339, default now")
+	asset := assetFlag(fs)
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	value, _, err := apd.NewFromString(*amount)
+	if err != nil {
+		return fmt.Errorf("invalid -amount: %w", err)
+	}
+
+	dt := time.Now().UTC()
+	if *at != "" {
+		dt, err = time.Parse(time.RFC3339, *at)
+		if err != nil {
+			return fmt.Errorf("invalid -at: %w", err)
+		}
+	}
+
+	c, err := newClient(gf)
+	if err != nil {
+		return err
+	}
+
+	err = c.Deposit(ctx, &anymind.DepositInput{DateTime: dt, Amount: *value, Asset: *asset})
+	if err != nil {
+		return err
+	}
+
+	if gf.output == "json" {
+		return renderJSON(stdout, &historyRow{DateTime: dt, Amount: fmt.Sprintf("%f", value)})
+	}
+
+	fmt.Fprintf(stdout, "deposited %f %s at %s\n", value, anymind.AssetCode(*asset), dt.Format(time.RFC3339))
+
+	return nil
+}
+
+func historyCmd(ctx context.Context, gf *globalFlags, args []string) error {
+	fs := flag.NewFlagSet("history", flag.ContinueOnError)
+	from, to := rangeFlags(fs)
+	format := fs.String("format", "table", "table, csv or sparkline")
+	asset := assetFlag(fs)
+	quote := quoteFlag(fs)
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	req, err := parseRange(*from, *to)
+	if err != nil {
+		return err
+	}
+	req.Asset = *asset
+	req.Quote = *quote
+
+	c, err := newClient(gf)
+	if err != nil {
+		return err
+	}
+
+	rows, err := fetchHistory(ctx, c, req)
+	if err != nil {
+		return err
+	}
+
+	if gf.output == "json" {
+		return renderJSON(stdout, rows)
+	}
+
+	switch *format {
+	case "table":
+		return renderTable(stdout, rows)
+	case "csv":
+		return renderCSV(stdout, rows)
+	case "sparkline":
+		return renderSparkline(stdout, rows)
+	default:
+		return fmt.Errorf("unknown -format %q", *format)
+	}
+}
+
+func balanceCmd(ctx context.Context, gf *globalFlags, args []string) error {
+	fs := flag.NewFlagSet("balance", flag.ContinueOnError)
+	asset := assetFlag(fs)
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	c, err := newClient(gf)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	rows, err := fetchHistory(ctx, c, &anymind.HistoricalDataReq{Start: now, End: now, Asset: *asset})
+	if err != nil {
+		return err
+	}
+
+	balance := &historyRow{DateTime: now, Amount: "0"}
+	if len(rows) > 0 {
+		balance = rows[len(rows)-1]
+	}
+
+	if gf.output == "json" {
+		return renderJSON(stdout, balance)
+	}
+
+	fmt.Fprintln(stdout, balance.Amount)
+
+	return nil
+}
+
+func exportCmd(ctx context.Context, gf *globalFlags, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	from, to := rangeFlags(fs)
+	format := fs.String("format", "csv", "csv or json")
+	out := fs.String("out", "-", "output file, - for stdout")
+	asset := assetFlag(fs)
+	quote := quoteFlag(fs)
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	if *format != "csv" && *format != "json" {
+		return fmt.Errorf("unknown -format %q", *format)
+	}
+
+	req, err := parseRange(*from, *to)
+	if err != nil {
+		return err
+	}
+	req.Asset = *asset
+	req.Quote = *quote
+
+	c, err := newClient(gf)
+	if err != nil {
+		return err
+	}
+
+	rows, err := fetchHistory(ctx, c, req)
+	if err != nil {
+		return err
+	}
+
+	var w = stdout
+	if *out != "-" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		w = f
+	}
+
+	if *format == "json" {
+		return renderJSON(w, rows)
+	}
+
+	return renderCSV(w, rows)
+}
+
+type healthResult struct {
+	Status  string `json:"status"`
+	Latency string `json:"latency"`
+	Error   string `json:"error,omitempty"`
+}
+
+// healthCmd run a cheap historical query, so both the service and its database are exercised.
+func healthCmd(ctx context.Context, gf *globalFlags, args []string) error {
+	fs := flag.NewFlagSet("health", flag.ContinueOnError)
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	c, err := newClient(gf)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	now := start.UTC()
+	_, err = c.Historical(ctx, &anymind.HistoricalDataReq{Start: now, End: now})
+
+	res := &healthResult{Status: "ok", Latency: time.Since(start).String()}
+	if err != nil {
+		res.Status = "unhealthy"
+		res.Error = err.Error()
+	}
+
+	if gf.output == "json" {
+		renderErr := renderJSON(stdout, res)
+		if renderErr != nil {
+			return renderErr
+		}
+	} else {
+		fmt.Fprintf(stdout, "%s (%s)\n", res.Status, res.Latency)
+	}
+
+	return err
+}
+
+func profileCmd(_ context.Context, gf *globalFlags, args []string) error {
+	cfg, err := loadProfiles(gf.cfgPath)
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		args = []string{"list"}
+	}
+
+	switch args[0] {
+	case "list":
+		if gf.output == "json" {
+			urls := map[string]string{}
+			for name, p := range cfg.Profiles {
+				urls[name] = p.URL
+			}
+
+			return renderJSON(stdout, map[string]any{"current": cfg.Current, "profiles": urls})
+		}
+
+		for _, name := range cfg.names() {
+			marker := " "
+			if name == cfg.Current {
+				marker = "*"
+			}
+
+			fmt.Fprintf(stdout, "%s %s\t%s\n", marker, name, cfg.Profiles[name].URL)
+		}
+
+		return nil
+	case "use":
+		if len(args) != 2 {
+			return errors.New("usage: profile use <name>")
+		}
+
+		_, ok := cfg.Profiles[args[1]]
+		if !ok {
+			return fmt.Errorf("unknown profile %q", args[1])
+		}
+
+		cfg.Current = args[1]
+
+		return cfg.save()
+	case "set":
+		fs := flag.NewFlagSet("profile set", flag.ContinueOnError)
+		url := fs.String("url", "", "service base url")
+		apiKey := fs.String("apikey", "", "api key sent as bearer credential")
+		timeout := fs.Duration("timeout", 0, "request timeout")
+		retries := fs.Int("retries", 0, "retries of idempotent request")
+		if len(args) < 2 {
+			return errors.New("usage: profile set <name> -url <url> [-apikey key] [-timeout 5s] [-retries 2]")
+		}
+
+		err = fs.Parse(args[2:])
+		if err != nil {
+			return err
+		}
+
+		if *url == "" {
+			return errors.New("-url is required")
+		}
+
+		cfg.Profiles[args[1]] = &profile{URL: *url, APIKey: *apiKey, Timeout: *timeout, Retries: *retries}
+		if cfg.Current == "" {
+			cfg.Current = args[1]
+		}
+
+		return cfg.save()
+	default:
+		return fmt.Errorf("unknown profile command %q", args[0])
+	}
+}
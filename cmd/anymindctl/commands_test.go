@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+// captureStdout redirect command output to the returned buffer until test end.
+func captureStdout(t *testing.T) *bytes.Buffer {
+	var out bytes.Buffer
+	orig := stdout
+	stdout = &out
+	t.Cleanup(func() { stdout = orig })
+
+	return &out
+}
+
+func TestHistoryCommand(t *testing.T) {
+	var received map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/historical", r.URL.Path)
+		require.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[
+			{"datetime": "2020-01-01T01:00:00Z", "amount": "1.5"},
+			{"datetime": "2020-01-01T02:00:00Z", "amount": "4"}
+		]`))
+	}))
+	defer srv.Close()
+
+	t.Setenv("ANYMINDCTL_API_KEY", "secret")
+	cfgPath := filepath.Join(t.TempDir(), "anymindctl.yaml")
+	out := captureStdout(t)
+
+	code := run([]string{"-config", cfgPath, "-url", srv.URL, "history",
+		"-from", "2020-01-01T00:00:00Z", "-to", "2020-01-01T02:00:00Z", "-asset", "ETH", "-format", "csv"})
+
+	require.Equal(t, 0, code)
+	require.Equal(t, "datetime,amount\n2020-01-01T01:00:00Z,1.5\n2020-01-01T02:00:00Z,4\n", out.String())
+	require.Equal(t, "2020-01-01T00:00:00Z", received["startDatetime"])
+	require.Equal(t, "2020-01-01T02:00:00Z", received["endDateTime"])
+	require.Equal(t, "ETH", received["asset"])
+}
+
+func TestCommandWithoutURL(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "anymindctl.yaml")
+	captureStdout(t)
+
+	require.Equal(t, 1, run([]string{"-config", cfgPath, "balance"}))
+	require.Equal(t, 2, run([]string{"-config", cfgPath, "-o", "yaml", "balance"}))
+	require.Equal(t, 2, run([]string{"-config", cfgPath, "unknown"}))
+}
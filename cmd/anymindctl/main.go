@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+const usage = `anymindctl is command line client of the wallet http api.
+
+Usage:
+  anymindctl [global flags] <command> [flags]
+
+Commands:
+  deposit   submit a deposit
+  history   show hourly balance as table, csv or sparkline
+  balance   show current balance
+  export    write hourly balance of a range to csv or json
+  health    check that the service answer requests
+  profile   list, show or change profiles
+
+Global flags:
+`
+
+// stdout receive command output, tests replace it.
+var stdout io.Writer = os.Stdout
+
+type globalFlags struct {
+	cfgPath string
+	profile string
+	url     string
+	output  string
+}
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	gf := &globalFlags{}
+
+	fs := flag.NewFlagSet("anymindctl", flag.ContinueOnError)
+	fs.StringVar(&gf.cfgPath, "config", defaultCfgPath(), "profile config file")
+	fs.StringVar(&gf.profile, "profile", os.Getenv("ANYMINDCTL_PROFILE"), "profile name, default to current profile")
+	fs.StringVar(&gf.url, "url", "", "service base url, override profile url")
+	fs.StringVar(&gf.output, "o", "text", "output format: text or json")
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), usage)
+		fs.PrintDefaults()
+	}
+
+	err := fs.Parse(args)
+	if err != nil {
+		return 2
+	}
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+
+		return 2
+	}
+
+	if gf.output != "text" && gf.output != "json" {
+		fmt.Fprintf(os.Stderr, "unknown output format %q\n", gf.output)
+
+		return 2
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	cmd, ok := commands[fs.Arg(0)]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", fs.Arg(0))
+		fs.Usage()
+
+		return 2
+	}
+
+	err = cmd(ctx, gf, fs.Args()[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/spf13/viper"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// profile hold connection setting of a single environment.
+type profile struct {
+	URL     string        `mapstructure:"url"`
+	APIKey  string        `mapstructure:"apikey"`
+	Timeout time.Duration `mapstructure:"timeout"`
+	Retries int           `mapstructure:"retries"`
+}
+
+// profileCfg is content of the config file, e.g.
+//
+//	current: staging
+//	profiles:
+//	  local:
+//	    url: http://localhost:8080
+//	  staging:
+//	    url: https://wallet.staging.example.com
+//	    timeout: 5s
+type profileCfg struct {
+	Current  string              `mapstructure:"current"`
+	Profiles map[string]*profile `mapstructure:"profiles"`
+
+	v *viper.Viper
+}
+
+func defaultCfgPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ".anymindctl.yaml"
+	}
+
+	return filepath.Join(home, ".anymindctl.yaml")
+}
+
+// loadProfiles read config file, missing file is treated as empty config.
+func loadProfiles(path string) (*profileCfg, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	v.SetConfigType("yaml")
+
+	err := v.ReadInConfig()
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("unable to read %s: %w", path, err)
+	}
+
+	cfg := &profileCfg{v: v}
+	err = v.Unmarshal(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %w", path, err)
+	}
+
+	if cfg.Profiles == nil {
+		cfg.Profiles = map[string]*profile{}
+	}
+
+	return cfg, nil
+}
+
+// resolve pick profile by name, falling back to the current profile.
+func (c *profileCfg) resolve(name string) (*profile, error) {
+	if name == "" {
+		name = c.Current
+	}
+
+	if name == "" {
+		return &profile{}, nil
+	}
+
+	p, ok := c.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown profile %q", name)
+	}
+
+	return p, nil
+}
+
+func (c *profileCfg) names() []string {
+	var res []string
+	for name := range c.Profiles {
+		res = append(res, name)
+	}
+	sort.Strings(res)
+
+	return res
+}
+
+func (c *profileCfg) save() error {
+	c.v.Set("current", c.Current)
+
+	profiles := map[string]any{}
+	for name, p := range c.Profiles {
+		entry := map[string]any{"url": p.URL}
+		if p.APIKey != "" {
+			entry["apikey"] = p.APIKey
+		}
+		if p.Timeout != 0 {
+			entry["timeout"] = p.Timeout.String()
+		}
+		if p.Retries != 0 {
+			entry["retries"] = p.Retries
+		}
+
+		profiles[name] = entry
+	}
+	c.v.Set("profiles", profiles)
+
+	return c.v.WriteConfigAs(c.v.ConfigFileUsed())
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"github.com/stretchr/testify/require"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestResolveProfile(t *testing.T) {
+	cfg := &profileCfg{
+		Current: "staging",
+		Profiles: map[string]*profile{
+			"local":   {URL: "http://localhost:8080"},
+			"staging": {URL: "https://wallet.staging.example.com", Timeout: 5 * time.Second},
+		},
+	}
+
+	testCases := []struct {
+		name    string
+		current string
+		profile string
+		url     string
+		err     string
+	}{
+		{name: "current", current: "staging", url: "https://wallet.staging.example.com"},
+		{name: "explicit", current: "staging", profile: "local", url: "http://localhost:8080"},
+		{name: "no current", url: ""},
+		{name: "unknown", current: "staging", profile: "prod", err: `unknown profile "prod"`},
+		{name: "unknown current", current: "gone", err: `unknown profile "gone"`},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			cfg.Current = tc.current
+			p, err := cfg.resolve(tc.profile)
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.url, p.URL)
+		})
+	}
+}
+
+func TestProfileCommands(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "anymindctl.yaml")
+	gf := &globalFlags{cfgPath: path, output: "text"}
+
+	// missing file is an empty config.
+	cfg, err := loadProfiles(path)
+	require.NoError(t, err)
+	require.Empty(t, cfg.names())
+
+	require.NoError(t, profileCmd(nil, gf, []string{"set", "local", "-url", "http://localhost:8080"}))
+	require.NoError(t, profileCmd(nil, gf, []string{"set", "staging", "-url", "https://staging", "-timeout", "5s", "-retries", "3"}))
+	require.EqualError(t, profileCmd(nil, gf, []string{"use", "prod"}), `unknown profile "prod"`)
+	require.NoError(t, profileCmd(nil, gf, []string{"use", "staging"}))
+
+	cfg, err = loadProfiles(path)
+	require.NoError(t, err)
+	require.Equal(t, "staging", cfg.Current)
+	require.Equal(t, []string{"local", "staging"}, cfg.names())
+	require.Equal(t, &profile{URL: "https://staging", Timeout: 5 * time.Second, Retries: 3}, cfg.Profiles["staging"])
+
+	out := captureStdout(t)
+	require.NoError(t, profileCmd(nil, gf, []string{"list"}))
+	require.Equal(t, "  local\thttp://localhost:8080\n* staging\thttps://staging\n", out.String())
+}
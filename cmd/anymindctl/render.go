@@ -0,0 +1,153 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/cockroachdb/apd"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+var sparkTicks = []rune("▁▂▃▄▅▆▇█")
+
+const missingRate = "missing rate"
+
+type historyRow struct {
+	DateTime time.Time `json:"datetime"`
+	Amount   string    `json:"amount"`
+	// Value and RateMissing are only set when history is valued in quote asset.
+	Value       string `json:"value,omitempty"`
+	RateMissing bool   `json:"rateMissing,omitempty"`
+}
+
+// quoted report whether rows are valued in quote asset, value column is then rendered.
+func quoted(rows []*historyRow) bool {
+	for _, row := range rows {
+		if row.Value != "" || row.RateMissing {
+			return true
+		}
+	}
+
+	return false
+}
+
+// valueCell render value of row, missing rate is shown as missing.
+func valueCell(row *historyRow) string {
+	if row.RateMissing {
+		return missingRate
+	}
+
+	return row.Value
+}
+func renderJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(v)
+}
+
+func renderTable(w io.Writer, rows []*historyRow) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	if quoted(rows) {
+		fmt.Fprintln(tw, "DATETIME\tAMOUNT\tVALUE\t")
+		for _, row := range rows {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t\n", row.DateTime.Format(time.RFC3339), row.Amount, valueCell(row))
+		}
+
+		return tw.Flush()
+	}
+
+	fmt.Fprintln(tw, "DATETIME\tAMOUNT\t")
+	for _, row := range rows {
+		fmt.Fprintf(tw, "%s\t%s\t\n", row.DateTime.Format(time.RFC3339), row.Amount)
+	}
+
+	return tw.Flush()
+}
+
+func renderCSV(w io.Writer, rows []*historyRow) error {
+	withValue := quoted(rows)
+	header := []string{"datetime", "amount"}
+	if withValue {
+		header = append(header, "value")
+	}
+
+	cw := csv.NewWriter(w)
+	err := cw.Write(header)
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		record := []string{row.DateTime.Format(time.RFC3339), row.Amount}
+		if withValue {
+			// missing rate is left empty, so the column stays numeric.
+			record = append(record, row.Value)
+		}
+
+		err = cw.Write(record)
+		if err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+
+	return cw.Error()
+}
+
+// renderSparkline draw balance movement on a single line, scaled between minimum and maximum.
+func renderSparkline(w io.Writer, rows []*historyRow) error {
+	if len(rows) == 0 {
+		_, err := fmt.Fprintln(w)
+
+		return err
+	}
+
+	values := make([]*apd.Decimal, len(rows))
+	for i, row := range rows {
+		val, _, err := apd.NewFromString(row.Amount)
+		if err != nil {
+			return err
+		}
+
+		values[i] = val
+	}
+
+	low, high := values[0], values[0]
+	for _, val := range values {
+		if val.Cmp(low) < 0 {
+			low = val
+		}
+		if val.Cmp(high) > 0 {
+			high = val
+		}
+	}
+
+	// float precision is good enough to pick one of eight ticks.
+	lowf, _ := low.Float64()
+	highf, _ := high.Float64()
+
+	var sb strings.Builder
+	for _, val := range values {
+		idx := 0
+		if highf > lowf {
+			f, _ := val.Float64()
+			idx = int((f - lowf) / (highf - lowf) * float64(len(sparkTicks)-1))
+		}
+
+		sb.WriteRune(sparkTicks[idx])
+	}
+
+	_, err := fmt.Fprintf(w, "%s  %s .. %s (min %s, max %s)\n",
+		sb.String(),
+		rows[0].DateTime.Format(time.RFC3339),
+		rows[len(rows)-1].DateTime.Format(time.RFC3339),
+		low.Text('f'),
+		high.Text('f'))
+
+	return err
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestRender(t *testing.T) {
+	at := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	plain := []*historyRow{
+		{DateTime: at, Amount: "1.5"},
+		{DateTime: at.Add(time.Hour), Amount: "10"},
+		{DateTime: at.Add(2 * time.Hour), Amount: "5"},
+	}
+	valued := []*historyRow{
+		{DateTime: at, Amount: "1.5", Value: "30"},
+		{DateTime: at.Add(time.Hour), Amount: "10", RateMissing: true},
+	}
+
+	testCases := []struct {
+		name   string
+		render func(w *bytes.Buffer, rows []*historyRow) error
+		rows   []*historyRow
+		out    string
+	}{
+		{
+			name:   "table",
+			render: func(w *bytes.Buffer, rows []*historyRow) error { return renderTable(w, rows) },
+			rows:   plain,
+			out: "              DATETIME  AMOUNT\n" +
+				"  2020-01-01T00:00:00Z     1.5\n" +
+				"  2020-01-01T01:00:00Z      10\n" +
+				"  2020-01-01T02:00:00Z       5\n",
+		},
+		{
+			name:   "table valued",
+			render: func(w *bytes.Buffer, rows []*historyRow) error { return renderTable(w, rows) },
+			rows:   valued,
+			out: "              DATETIME  AMOUNT         VALUE\n" +
+				"  2020-01-01T00:00:00Z     1.5            30\n" +
+				"  2020-01-01T01:00:00Z      10  missing rate\n",
+		},
+		{
+			name:   "csv",
+			render: func(w *bytes.Buffer, rows []*historyRow) error { return renderCSV(w, rows) },
+			rows:   plain,
+			out:    "datetime,amount\n2020-01-01T00:00:00Z,1.5\n2020-01-01T01:00:00Z,10\n2020-01-01T02:00:00Z,5\n",
+		},
+		{
+			name:   "csv valued",
+			render: func(w *bytes.Buffer, rows []*historyRow) error { return renderCSV(w, rows) },
+			rows:   valued,
+			out:    "datetime,amount,value\n2020-01-01T00:00:00Z,1.5,30\n2020-01-01T01:00:00Z,10,\n",
+		},
+		{
+			name:   "sparkline",
+			render: func(w *bytes.Buffer, rows []*historyRow) error { return renderSparkline(w, rows) },
+			rows:   plain,
+			out:    "▁█▃  2020-01-01T00:00:00Z .. 2020-01-01T02:00:00Z (min 1.5, max 10)\n",
+		},
+		{
+			name:   "sparkline empty",
+			render: func(w *bytes.Buffer, rows []*historyRow) error { return renderSparkline(w, rows) },
+			out:    "\n",
+		},
+		{
+			name:   "json",
+			render: func(w *bytes.Buffer, rows []*historyRow) error { return renderJSON(w, rows) },
+			rows:   valued[1:],
+			out:    "[\n  {\n    \"datetime\": \"2020-01-01T01:00:00Z\",\n    \"amount\": \"10\",\n    \"rateMissing\": true\n  }\n]\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			var out bytes.Buffer
+			require.NoError(t, tc.render(&out, tc.rows))
+			require.Equal(t, tc.out, out.String())
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"anymind/src/anomaly"
+	"anymind/src/persistence"
+	"context"
+	"go.uber.org/zap"
+	"time"
+)
+
+// detectorSeedInterval is how often rolling stats of anomaly detector are reset from stored deposits.
+const detectorSeedInterval = time.Minute
+
+// seedDetector reset rolling stats to latest stored deposits, so they survive a restart.
+func seedDetector(ctx context.Context, detector *anomaly.Service, db *persistence.Service, window int) error {
+	deposits, err := db.RecentDeposits(ctx, window)
+	if err != nil {
+		return err
+	}
+
+	return detector.Reset(deposits)
+}
+
+// seedDetectorEvery return service reseeding rolling stats, so deposits credited by any instance are reflected and
+// instances judge deposits alike.
+func seedDetectorEvery(detector *anomaly.Service, db *persistence.Service, window int, logger *zap.Logger) func(context.Context) error {
+	return func(ctx context.Context) error {
+		ticker := time.NewTicker(detectorSeedInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+			}
+
+			err := seedDetector(ctx, detector, db, window)
+			if err != nil {
+				logger.Error("failed to reseed anomaly detector", zap.Error(err))
+			}
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"anymind/src/apikey"
+	"anymind/src/config"
+	"anymind/src/persistence"
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const apiKeyUsage = `usage:
+  websvc apikey create -name <name> -scopes deposit:write,history:read,admin
+  websvc apikey revoke <id>
+  websvc apikey list`
+
+// apiKeyCmd manage api keys stored in database.
+func apiKeyCmd(ctx context.Context, _ *config.Config, db *sql.DB, args []string) error {
+	keys := apikey.NewService(persistence.NewService(db))
+	ctx = cliPrincipal(ctx)
+
+	if len(args) == 0 {
+		return errors.New(apiKeyUsage)
+	}
+
+	switch args[0] {
+	case "create":
+		fs := flag.NewFlagSet("apikey create", flag.ContinueOnError)
+		name := fs.String("name", "", "key name, e.g. the integrating partner")
+		scopes := fs.String("scopes", "", "comma separated scopes")
+		err := fs.Parse(args[1:])
+		if err != nil {
+			return err
+		}
+
+		plain, key, err := keys.Create(ctx, *name, strings.Split(*scopes, ","))
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("id:     %d\n", key.ID)
+		fmt.Printf("scopes: %s\n", strings.Join(key.Scopes, ","))
+		fmt.Printf("key:    %s\n", plain)
+		fmt.Fprintln(os.Stderr, "store the key now, it can not be shown again")
+
+		return nil
+	case "revoke":
+		if len(args) != 2 {
+			return errors.New(apiKeyUsage)
+		}
+
+		id, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid key id %q", args[1])
+		}
+
+		return keys.Revoke(ctx, id)
+	case "list":
+		list, err := keys.List(ctx)
+		if err != nil {
+			return err
+		}
+
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "ID\tNAME\tPREFIX\tSCOPES\tCREATED\tREVOKED")
+		for _, key := range list {
+			revoked := "-"
+			if key.RevokedAt != nil {
+				revoked = key.RevokedAt.Format(time.RFC3339)
+			}
+
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\n",
+				key.ID,
+				key.Name,
+				key.Prefix,
+				strings.Join(key.Scopes, ","),
+				key.CreatedAt.Format(time.RFC3339),
+				revoked)
+		}
+
+		return tw.Flush()
+	default:
+		return errors.New(apiKeyUsage)
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"anymind"
+	"anymind/src/config"
+	"anymind/src/persistence"
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/user"
+	"text/tabwriter"
+	"time"
+)
+
+const auditUsage = `usage:
+  websvc audit verify
+  websvc audit list [-actor key:12] [-action deposit] [-after <id>] [-limit 100]`
+
+// auditCmd check integrity of audit log hash chain, or print its entries.
+func auditCmd(ctx context.Context, _ *config.Config, db *sql.DB, args []string) error {
+	svc := persistence.NewService(db)
+
+	if len(args) == 0 {
+		return errors.New(auditUsage)
+	}
+
+	switch args[0] {
+	case "verify":
+		verified, err := svc.VerifyAudit(ctx)
+		if err != nil {
+			return fmt.Errorf("audit log verification failed after %d entries: %w", verified, err)
+		}
+
+		fmt.Printf("audit log intact, %d entries verified\n", verified)
+
+		return nil
+	case "list":
+		filter := &anymind.AuditFilter{}
+		fs := flag.NewFlagSet("audit list", flag.ContinueOnError)
+		fs.StringVar(&filter.Actor, "actor", "", "only entries of actor")
+		fs.StringVar(&filter.Action, "action", "", "only entries of action")
+		fs.Int64Var(&filter.AfterID, "after", 0, "only entries after id")
+		fs.IntVar(&filter.Limit, "limit", 100, "entries to print, 0 print every entry")
+		err := fs.Parse(args[1:])
+		if err != nil {
+			return err
+		}
+
+		entries, err := svc.ListAudit(ctx, filter)
+		if err != nil {
+			return err
+		}
+
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "ID\tAT\tACTOR\tCLIENT IP\tREQUEST ID\tACTION\tOUTCOME")
+		for _, e := range entries {
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				e.ID,
+				e.At.Format(time.RFC3339),
+				e.Actor,
+				e.ClientIP,
+				e.RequestID,
+				e.Action,
+				e.Outcome)
+		}
+
+		return tw.Flush()
+	default:
+		return errors.New(auditUsage)
+	}
+}
+
+// cliPrincipal identify operator running command in audit log, e.g. sub:cli:alice.
+func cliPrincipal(ctx context.Context) context.Context {
+	name := "unknown"
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+
+	return anymind.WithPrincipal(ctx, &anymind.Principal{Subject: "cli:" + name})
+}
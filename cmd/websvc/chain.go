@@ -0,0 +1,78 @@
+package main
+
+import (
+	"anymind/src/chain"
+	"anymind/src/config"
+	"anymind/src/persistence"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+const chainUsage = `usage:
+  websvc chain verify
+  websvc chain checkpoint`
+
+// chainCmd verify deposit hash chain, or checkpoint its head right away.
+func chainCmd(ctx context.Context, cfg *config.Config, db *sql.DB, args []string) error {
+	opts := []chain.Option{chain.WithAnchorFile(cfg.Chain.CheckpointFile)}
+	if cfg.Chain.SigningKey != "" {
+		key, err := chain.ParseSigningKey(cfg.Chain.SigningKey)
+		if err != nil {
+			return err
+		}
+
+		opts = append(opts, chain.WithSigningKey(key))
+	}
+
+	svc := chain.NewService(persistence.NewService(db), opts...)
+
+	if len(args) != 1 {
+		return errors.New(chainUsage)
+	}
+
+	switch args[0] {
+	case "verify":
+		report, err := svc.VerifyChain(ctx)
+		if err != nil {
+			return err
+		}
+
+		if cfg.Chain.SigningKey == "" {
+			fmt.Println("chain.signing_key is not set, checkpoint signatures are not checked")
+		}
+
+		if report.Break != nil {
+			kind := "deposit"
+			if report.Break.Checkpoint {
+				kind = "checkpoint"
+			}
+
+			return fmt.Errorf("deposit chain broken at %s %d: %s, %d links verified before",
+				kind, report.Break.ID, report.Break.Reason, report.Verified)
+		}
+
+		fmt.Printf("deposit chain intact, %d links and %d checkpoints verified, head %d %s\n",
+			report.Verified, report.Checkpoints, report.HeadID, report.HeadHash)
+
+		return nil
+	case "checkpoint":
+		checkpoint, err := svc.Checkpoint(ctx)
+		if err != nil {
+			return err
+		}
+
+		if checkpoint.LastID == 0 {
+			fmt.Println("no deposit to checkpoint")
+
+			return nil
+		}
+
+		fmt.Printf("checkpoint %d of deposit %d %s\n", checkpoint.ID, checkpoint.LastID, checkpoint.HeadHash)
+
+		return nil
+	default:
+		return errors.New(chainUsage)
+	}
+}
@@ -1,80 +1,365 @@
 package main
 
 import (
+	"anymind"
+	"anymind/src/anomaly"
 	"anymind/src/api"
+	"anymind/src/apikey"
+	"anymind/src/chain"
+	"anymind/src/changefeed"
+	"anymind/src/config"
+	"anymind/src/grpcapi"
 	"anymind/src/httpapi"
+	"anymind/src/instrumenting"
+	"anymind/src/jwtauth"
 	"anymind/src/persistence"
+	"anymind/src/pricefeed"
+	"anymind/src/reqsign"
+	"anymind/src/tracing"
+	"anymind/src/webhook"
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 	_ "github.com/jackc/pgx/v5/stdlib"
-	"github.com/spf13/viper"
+	"github.com/spf13/pflag"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
 	"go.uber.org/zap"
 	"os"
 	"os/signal"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
+	"time"
 )
 
-type appCfg struct {
-	port  int
-	pgdsn string
+// commands run instead of the service, they read configuration from file and env only
+// since arguments are their own.
+var commands = map[string]func(ctx context.Context, cfg *config.Config, db *sql.DB, args []string) error{
+	"apikey":  apiKeyCmd,
+	"audit":   auditCmd,
+	"chain":   chainCmd,
+	"migrate": migrateCmd,
+	"rates":   ratesCmd,
 }
 
-// loadCfg will initialize configuration from env var.
-func loadCfg() *appCfg {
-	viper.AutomaticEnv()
-	viper.AllowEmptyEnv(false)
+func main() {
+	os.Exit(run())
+}
 
-	cfg := &appCfg{
-		port:  viper.GetInt("HTTP_PORT"),
-		pgdsn: viper.GetString("PG_DSN"),
+// run start every service and block until signal or service failure, it return process exit status.
+func run() int {
+	args := os.Args[1:]
+	var command string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
 	}
 
-	return cfg
-}
+	cfgArgs := args
+	if _, ok := commands[command]; ok {
+		cfgArgs = nil
+	}
 
-func main() {
-	cfg := loadCfg()
-	logger, err := zap.NewDevelopment()
+	cfg, err := config.Load("websvc", cfgArgs)
+	if errors.Is(err, pflag.ErrHelp) {
+		return 0
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+
+		return 2
+	}
+
+	// config command print effective configuration, e.g. to check precedence or seed a config file.
+	if command == "config" {
+		out, err := cfg.Redacted().YAML()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+
+			return 1
+		}
+
+		os.Stdout.Write(out)
+
+		return 0
+	}
+
+	logger, logLevel, err := newLogger(cfg.Log)
 	if err != nil {
 		panic(err)
 	}
+	defer logger.Sync()
 
 	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
-	db, err := sql.Open("pgx", cfg.pgdsn)
+	db, err := sql.Open("pgx", cfg.DB.DSN)
 	if err != nil {
 		logger.Fatal("unable to connect db", zap.Error(err))
 	}
 	defer db.Close()
 
+	setPool(db, cfg.DB)
+
+	if command != "" {
+		cmd, ok := commands[command]
+		if !ok {
+			logger.Error("unknown command", zap.String("command", command))
+
+			return 2
+		}
+
+		err = cmd(ctx, cfg, db, args)
+		if err != nil {
+			logger.Error("command failed", zap.Error(err))
+
+			return 1
+		}
+
+		return 0
+	}
+
+	logger.Debug("configuration loaded", zap.Any("config", cfg.Redacted()))
+
+	tracerProvider, err := tracing.NewProvider(
+		ctx,
+		cfg.Trace.Exporter,
+		tracing.WithSampleRatio(cfg.Trace.SampleRatio))
+	if err != nil {
+		logger.Fatal("unable to setup tracing", zap.Error(err))
+	}
+	defer tracerProvider.Shutdown(context.Background())
+
+	otel.SetTracerProvider(tracerProvider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	metrics := newServiceMetrics(db)
+
 	persistenceSvc := persistence.NewService(
 		db,
-		persistence.WithLogger(logger))
+		persistence.WithLogger(logger),
+		persistence.WithQueryDuration(metrics.queryDuration),
+		persistence.WithRetryCounter(metrics.retries),
+		persistence.WithSerializationRetries(cfg.DB.SerializationRetries))
+
+	err = waitForDB(ctx, persistenceSvc, cfg.DB.StartupRetries, cfg.DB.StartupRetryDelay, logger)
+	if err != nil {
+		logger.Fatal("database is not usable, run `websvc migrate` if schema is outdated", zap.Error(err))
+	}
+
+	priceSvc := pricefeed.NewService(
+		persistenceSvc,
+		pricefeed.WithLogger(logger))
+
+	// anomalies recorded earlier stay listed and reviewable when detection is turned off.
+	var detector anymind.AnomalyDetector
+	var detectorSvc *anomaly.Service
+	if cfg.Anomaly.Enabled {
+		detectorSvc = anomaly.NewService(
+			anomaly.WithWindow(cfg.Anomaly.Window),
+			anomaly.WithMinSamples(cfg.Anomaly.MinSamples),
+			anomaly.WithThreshold(cfg.Anomaly.Threshold),
+			anomaly.WithHourShare(cfg.Anomaly.HourShare))
+		detector = detectorSvc
+
+		err = seedDetector(ctx, detectorSvc, persistenceSvc, cfg.Anomaly.Window)
+		if err != nil {
+			logger.Warn("unable to seed anomaly detector, rolling stats start empty", zap.Error(err))
+		}
+	}
+
+	apiCore := api.NewService(
+		instrumenting.NewPersistenceService(
+			persistenceSvc,
+			instrumenting.WithDuration(metrics.persistenceDuration)),
+		api.WithLogger(logger),
+		api.WithAssetService(persistenceSvc),
+		api.WithPriceService(priceSvc),
+		api.WithStatsService(persistenceSvc),
+		api.WithCandleService(persistenceSvc),
+		api.WithAnomalyDetector(detector),
+		api.WithAnomalyService(persistenceSvc),
+		api.WithAnomalyHold(cfg.Anomaly.Hold),
+		api.WithDepositStatusService(persistenceSvc),
+		api.WithMaxClockSkew(cfg.Deposit.MaxClockSkew))
+
+	apiSvc := instrumenting.NewAPIService(
+		apiCore,
+		instrumenting.WithDuration(metrics.apiDuration),
+		instrumenting.WithDepositCounter(metrics.deposits),
+		instrumenting.WithBalanceGauge(metrics.balance))
+	anomalySvc := instrumenting.NewAnomalyService(
+		apiCore,
+		instrumenting.WithDuration(metrics.apiDuration),
+		instrumenting.WithDepositCounter(metrics.deposits))
+	depositStatusSvc := instrumenting.NewDepositStatusService(
+		apiCore,
+		instrumenting.WithDuration(metrics.apiDuration),
+		instrumenting.WithDepositCounter(metrics.deposits))
+
+	err = refreshBalance(ctx, apiSvc, apiCore)
+	if err != nil {
+		logger.Warn("unable to load balance metric", zap.Error(err))
+	}
+
+	depositFeed := changefeed.NewService(
+		cfg.DB.DSN,
+		persistenceSvc,
+		changefeed.WithLogger(logger))
+
+	var authenticator anymind.Authenticator = apikey.NewService(
+		persistenceSvc,
+		apikey.WithLogger(logger))
+	if cfg.Auth.JWKSURL != "" {
+		authenticator = anymind.Authenticators{
+			authenticator,
+			jwtauth.NewService(
+				cfg.Auth.JWKSURL,
+				jwtauth.WithAudience(cfg.Auth.JWTAudience),
+				jwtauth.WithIssuer(cfg.Auth.JWTIssuer),
+				jwtauth.WithLeeway(time.Minute),
+				jwtauth.WithLogger(logger)),
+		}
+	}
+	if cfg.Auth.Disabled {
+		logger.Warn("authentication is disabled")
+		authenticator = nil
+	}
+
+	// webhooks is left nil when disabled, so httpapi does not register its routes.
+	var webhookSvc *webhook.Service
+	var webhooks anymind.WebhookService
+	if cfg.Features.Webhooks {
+		webhookSvc = webhook.NewService(
+			persistenceSvc,
+			webhook.WithLogger(logger))
+		webhooks = webhookSvc
+	}
 
-	apiSvc := api.NewService(
+	var verifier anymind.RequestVerifier
+	if len(cfg.Auth.SigningSecrets) > 0 {
+		verifier = reqsign.NewService(
+			cfg.Auth.SigningSecrets,
+			persistenceSvc,
+			reqsign.WithLogger(logger))
+	}
+
+	// checkpoints are only written when signing key is set, chain can still be verified without.
+	var chainOpts []chain.Option
+	if cfg.Chain.SigningKey != "" {
+		key, err := chain.ParseSigningKey(cfg.Chain.SigningKey)
+		if err != nil {
+			logger.Fatal("invalid chain signing key", zap.Error(err))
+		}
+
+		chainOpts = append(chainOpts, chain.WithSigningKey(key))
+	}
+	chainSvc := chain.NewService(
 		persistenceSvc,
-		api.WithLogger(logger))
+		append(chainOpts,
+			chain.WithInterval(cfg.Chain.CheckpointInterval),
+			chain.WithAnchorFile(cfg.Chain.CheckpointFile),
+			chain.WithLogger(logger))...)
+
+	reloader := config.NewReloader(cfg, "websvc", args, config.WithLogger(logger))
 
 	httpService := httpapi.NewService(
 		apiSvc,
 		httpapi.WithLogger(logger),
-		httpapi.WithListenPort(8080))
+		httpapi.WithWebhookService(webhooks),
+		httpapi.WithConfigService(reloader),
+		httpapi.WithAuditService(persistenceSvc),
+		httpapi.WithChainService(chainSvc),
+		httpapi.WithAssetService(apiCore),
+		httpapi.WithPriceService(priceSvc),
+		httpapi.WithStatsService(apiCore),
+		httpapi.WithCandleService(apiCore),
+		httpapi.WithAnomalyService(anomalySvc),
+		httpapi.WithDepositStatusService(depositStatusSvc),
+		httpapi.WithSwaggerUI(cfg.Features.SwaggerUI),
+		httpapi.WithAuthenticator(authenticator),
+		httpapi.WithRequestVerifier(verifier),
+		httpapi.WithRateLimit(cfg.HTTP.RateLimit, cfg.HTTP.RateBurst),
+		httpapi.WithIPRateLimit(cfg.HTTP.IPRateLimit, cfg.HTTP.IPRateBurst),
+		httpapi.WithMaxInFlightWrites(cfg.HTTP.MaxInFlightWrites),
+		httpapi.WithThrottledCounter(metrics.throttled),
+		httpapi.WithRequestDuration(metrics.requestDuration),
+		httpapi.WithHealthCheck("database", persistenceSvc),
+		httpapi.WithDrainDelay(cfg.HTTP.DrainDelay),
+		httpapi.WithShutdownTimeout(cfg.HTTP.ShutdownTimeout),
+		httpapi.WithReadTimeout(cfg.HTTP.ReadTimeout),
+		httpapi.WithReadHeaderTimeout(cfg.HTTP.ReadHeaderTimeout),
+		httpapi.WithWriteTimeout(cfg.HTTP.WriteTimeout),
+		httpapi.WithIdleTimeout(cfg.HTTP.IdleTimeout),
+		httpapi.WithMetricsAddr(cfg.HTTP.MetricsAddr),
+		httpapi.WithListenAddr(cfg.HTTP.Addr))
+
+	// only reloadable settings can change here, see config.Reloader.
+	reloader.OnReload(func(cfg *config.Config) {
+		level, _ := zap.ParseAtomicLevel(cfg.Log.Level)
+		logLevel.SetLevel(level.Level())
+		setPool(db, cfg.DB)
+		persistenceSvc.SetSerializationRetries(cfg.DB.SerializationRetries)
+		apiCore.SetMaxClockSkew(cfg.Deposit.MaxClockSkew)
+		httpService.SetRateLimit(cfg.HTTP.RateLimit, cfg.HTTP.RateBurst)
+		httpService.SetIPRateLimit(cfg.HTTP.IPRateLimit, cfg.HTTP.IPRateBurst)
+	})
 
 	var svcRunning sync.WaitGroup
+	var failed atomic.Bool
 
-	svcRunning.Add(1)
-	go func() {
-		defer svcRunning.Done()
-		httpService.Start(ctx)
-		<-ctx.Done()
-		cancel()
-	}()
+	// runService start service in background, any service stopping cancel the others,
+	// and a failure make the process exit with non zero status.
+	runService := func(name string, start func(context.Context) error) {
+		svcRunning.Add(1)
+		go func() {
+			defer svcRunning.Done()
+			defer cancel()
 
-	defer func() {
-		cancel()
-		svcRunning.Wait()
-	}()
+			err := start(ctx)
+			if err != nil {
+				failed.Store(true)
+				logger.Error("service failed", zap.String("service", name), zap.Error(err))
+			}
+		}()
+	}
+
+	// grpc transport is optional, it is only started when grpc.addr is set.
+	if cfg.GRPC.Addr != "" {
+		grpcService := grpcapi.NewService(
+			apiSvc,
+			grpcapi.WithLogger(logger),
+			grpcapi.WithDepositFeed(depositFeed),
+			grpcapi.WithAuthenticator(authenticator),
+			grpcapi.WithDepositMiddleware(httpService.DepositMiddleware()),
+			grpcapi.WithListenAddr(cfg.GRPC.Addr))
+
+		runService("grpc", grpcService.Start)
+	}
+
+	// metrics expose balance of every asset, they are only served on their own address when it is set.
+	if cfg.HTTP.MetricsAddr != "" {
+		runService("metrics", httpService.StartMetrics)
+	}
+
+	runService("http", httpService.Start)
+	runService("config reload", reloader.Start)
+	runService("changefeed", depositFeed.Start)
+	runService("balance metric", refreshBalanceEvery(apiSvc, apiCore, logger))
+	if webhookSvc != nil {
+		runService("webhook", webhookSvc.Start)
+	}
+	if cfg.Chain.SigningKey != "" {
+		runService("chain checkpoint", chainSvc.Start)
+	}
+	if detectorSvc != nil {
+		runService("anomaly seed", seedDetectorEvery(detectorSvc, persistenceSvc, cfg.Anomaly.Window, logger))
+	}
+	if cfg.Deposit.PendingExpiry > 0 {
+		runService("pending expiry", expirePending(depositStatusSvc, cfg.Deposit.PendingExpiry, logger))
+	}
 
 	// wait for interrupt or terminate signal
 	waiter := make(chan os.Signal, 1)
@@ -84,4 +369,85 @@ func main() {
 		logger.Info("catch exit signal")
 	case <-ctx.Done():
 	}
+
+	cancel()
+	svcRunning.Wait()
+
+	if failed.Load() {
+		return 1
+	}
+
+	return 0
+}
+
+// refreshBalance load balance metric of every registered asset.
+func refreshBalance(ctx context.Context, apiSvc *instrumenting.APIService, assets anymind.AssetService) error {
+	registered, err := assets.ListAssets(ctx)
+	if err != nil {
+		return err
+	}
+
+	codes := make([]string, 0, len(registered))
+	for _, asset := range registered {
+		codes = append(codes, asset.Code)
+	}
+
+	return apiSvc.RefreshBalance(ctx, codes)
+}
+
+// waitForDB check database with bounded retries, so the service does not accept traffic it cannot serve.
+func waitForDB(ctx context.Context, db *persistence.Service, attempts int, delay time.Duration, logger *zap.Logger) error {
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		checkCtx, cancel := context.WithTimeout(ctx, delay)
+		err = db.CheckHealth(checkCtx)
+		cancel()
+		if err == nil {
+			return nil
+		}
+
+		logger.Warn("database not ready", zap.Int("attempt", attempt), zap.Error(err))
+		if attempt == attempts {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+
+	return fmt.Errorf("database not ready after %d attempts: %w", attempts, err)
+}
+
+// newLogger build json production logger, or human readable one for console format.
+// It also return its level, so it can be changed on reload.
+func newLogger(cfg config.Log) (*zap.Logger, zap.AtomicLevel, error) {
+	zapCfg := zap.NewProductionConfig()
+	if cfg.Format == "console" {
+		zapCfg = zap.NewDevelopmentConfig()
+	}
+
+	level, err := zap.ParseAtomicLevel(cfg.Level)
+	if err != nil {
+		return nil, level, err
+	}
+	zapCfg.Level = level
+
+	zapCfg.Sampling = nil
+	if cfg.SampleInitial > 0 {
+		zapCfg.Sampling = &zap.SamplingConfig{Initial: cfg.SampleInitial, Thereafter: cfg.SampleThereafter}
+	}
+
+	logger, err := zapCfg.Build()
+
+	return logger, level, err
+}
+
+func setPool(db *sql.DB, cfg config.DB) {
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
 }
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"anymind"
+	"anymind/src/instrumenting"
+	"context"
+	"database/sql"
+	"github.com/go-kit/kit/metrics"
+	kitprometheus "github.com/go-kit/kit/metrics/prometheus"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"go.uber.org/zap"
+	"time"
+)
+
+const metricsNamespace = "anymind"
+
+// balanceRefreshInterval is how often balance metric is read from database.
+const balanceRefreshInterval = 30 * time.Second
+
+// serviceMetrics is registered to default prometheus registry, which is served by httpapi at /metrics on http.metrics_addr.
+type serviceMetrics struct {
+	requestDuration     metrics.Histogram
+	throttled           metrics.Counter
+	apiDuration         metrics.Histogram
+	persistenceDuration metrics.Histogram
+	queryDuration       metrics.Histogram
+	retries             metrics.Counter
+	deposits            metrics.Counter
+	balance             metrics.Gauge
+}
+
+func newServiceMetrics(db *sql.DB) *serviceMetrics {
+	stdprometheus.MustRegister(collectors.NewDBStatsCollector(db, metricsNamespace))
+
+	return &serviceMetrics{
+		requestDuration: kitprometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "Latency of api requests.",
+			Buckets:   stdprometheus.DefBuckets,
+		}, []string{"route", "method", "status"}),
+		throttled: kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "http",
+			Name:      "throttled_requests_total",
+			Help:      "Number of requests rejected by rate or in-flight limit.",
+		}, []string{"route", "reason"}),
+		apiDuration: kitprometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "api",
+			Name:      "call_duration_seconds",
+			Help:      "Latency of api service calls.",
+			Buckets:   stdprometheus.DefBuckets,
+		}, []string{"method", "error"}),
+		persistenceDuration: kitprometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "persistence",
+			Name:      "call_duration_seconds",
+			Help:      "Latency of persistence service calls, including transaction retries.",
+			Buckets:   stdprometheus.DefBuckets,
+		}, []string{"method", "error"}),
+		queryDuration: kitprometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "persistence",
+			Name:      "query_duration_seconds",
+			Help:      "Latency of database queries.",
+			Buckets:   stdprometheus.DefBuckets,
+		}, []string{"query"}),
+		retries: kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "persistence",
+			Name:      "serialization_retries_total",
+			Help:      "Number of transactions retried after serialization failure.",
+		}, []string{"operation"}),
+		deposits: kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "deposits_total",
+			Help:      "Number of settled deposits, counted when credited to balance.",
+		}, []string{}),
+		balance: kitprometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "balance",
+			Help:      "Current balance by asset, read from database every refresh interval.",
+		}, []string{"asset"}),
+	}
+}
+
+// refreshBalanceEvery return service keeping balance metric in line with database, so deposits settled by any
+// instance are reflected.
+func refreshBalanceEvery(apiSvc *instrumenting.APIService, assets anymind.AssetService, logger *zap.Logger) func(context.Context) error {
+	return func(ctx context.Context) error {
+		ticker := time.NewTicker(balanceRefreshInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+			}
+
+			err := refreshBalance(ctx, apiSvc, assets)
+			if err != nil {
+				logger.Error("failed to refresh balance metric", zap.Error(err))
+			}
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"anymind/src/config"
+	"anymind/src/persistence"
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+)
+
+// migrateCmd bring database schema to the version expected by this build.
+func migrateCmd(ctx context.Context, _ *config.Config, db *sql.DB, args []string) error {
+	fs := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	baseline := fs.Int("baseline", -1, "record schema as already migrated up to version, for database created before versioning")
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	if *baseline >= 0 {
+		err = persistence.Baseline(ctx, db, *baseline)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("schema baselined at version %d\n", *baseline)
+
+		return nil
+	}
+
+	from, err := persistence.Migrate(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("schema migrated from version %d to %d\n", from, persistence.SchemaVersion)
+
+	return nil
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"anymind"
+	"context"
+	"go.uber.org/zap"
+	"time"
+)
+
+// pendingExpiryInterval is how often deposits pending for too long are failed.
+const pendingExpiryInterval = time.Minute
+
+// expirePending return service failing deposits pending for longer than expiry, see deposit.pending_expiry.
+func expirePending(svc anymind.DepositStatusService, expiry time.Duration, logger *zap.Logger) func(context.Context) error {
+	return func(ctx context.Context) error {
+		ctx = anymind.WithPrincipal(ctx, &anymind.Principal{Subject: "websvc:pending-expiry"})
+
+		ticker := time.NewTicker(pendingExpiryInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+			}
+
+			n, err := svc.ExpirePendingDeposits(ctx, time.Now().Add(-expiry))
+			if err != nil {
+				logger.Error("failed to expire pending deposits", zap.Error(err))
+
+				continue
+			}
+
+			if n > 0 {
+				logger.Info("pending deposits expired", zap.Int64("count", n))
+			}
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"anymind/src/config"
+	"anymind/src/persistence"
+	"anymind/src/pricefeed"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+const ratesUsage = `usage:
+  websvc rates import <file.csv|->
+
+csv header is hour,base,quote,rate, e.g.
+  2022-01-01T10:00:00Z,BTC,USD,46000.25`
+
+// ratesCmd import hourly exchange rates used to value historical balance.
+func ratesCmd(ctx context.Context, _ *config.Config, db *sql.DB, args []string) error {
+	if len(args) != 2 || args[0] != "import" {
+		return errors.New(ratesUsage)
+	}
+
+	var in io.Reader = os.Stdin
+	if args[1] != "-" {
+		f, err := os.Open(args[1])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		in = f
+	}
+
+	rates, err := pricefeed.ParseCSV(in)
+	if err != nil {
+		return err
+	}
+
+	err = pricefeed.NewService(persistence.NewService(db)).ImportRates(cliPrincipal(ctx), rates)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%d rates imported\n", len(rates))
+
+	return nil
+}
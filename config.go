@@ -0,0 +1,21 @@
+package anymind
+
+import (
+	"context"
+	"time"
+)
+
+// ActiveConfig is configuration applied to running services, secrets are redacted.
+type ActiveConfig struct {
+	// Version start at 1 and increase on every applied reload.
+	Version  int
+	LoadedAt time.Time
+	Settings map[string]any
+}
+
+// ConfigService expose configuration of running services.
+//
+//go:generate moq -out src/mock/mock_config_service.go -pkg mock . ConfigService
+type ConfigService interface {
+	ActiveConfig(ctx context.Context) (*ActiveConfig, error)
+}
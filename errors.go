@@ -1,14 +1,27 @@
 package anymind
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
 
 type ErrorType int
 
 const (
 	InternalErr = iota
 	ParameterErr
+	NotFoundErr
+	UnauthorizedErr
+	ForbiddenErr
+	TooManyRequestsErr
 )
 
+// ErrNotFound is returned by persistence layer when requested record does not exist.
+var ErrNotFound = errors.New("not found")
+
+// ErrAlreadyExists is returned by persistence layer when record to create conflict with existing one.
+var ErrAlreadyExists = errors.New("already exists")
+
 type Error struct {
 	Type    int
 	Cause   error
@@ -25,6 +38,14 @@ func (e Error) Error() string {
 		return fmt.Sprintf("internal error: %s", e.Cause)
 	case ParameterErr:
 		return fmt.Sprintf("parameter error: %s", e.Cause)
+	case NotFoundErr:
+		return fmt.Sprintf("not found: %s", e.Cause)
+	case UnauthorizedErr:
+		return fmt.Sprintf("unauthorized: %s", e.Cause)
+	case ForbiddenErr:
+		return fmt.Sprintf("forbidden: %s", e.Cause)
+	case TooManyRequestsErr:
+		return fmt.Sprintf("too many requests: %s", e.Cause)
 	}
 
 	return "error"
@@ -43,3 +64,31 @@ func InternalError(err error) *Error {
 		Cause: err,
 	}
 }
+
+func NotFoundError(err error) *Error {
+	return &Error{
+		Type:  NotFoundErr,
+		Cause: err,
+	}
+}
+
+func UnauthorizedError(err error) *Error {
+	return &Error{
+		Type:  UnauthorizedErr,
+		Cause: err,
+	}
+}
+
+func ForbiddenError(err error) *Error {
+	return &Error{
+		Type:  ForbiddenErr,
+		Cause: err,
+	}
+}
+
+func TooManyRequestsError(err error) *Error {
+	return &Error{
+		Type:  TooManyRequestsErr,
+		Cause: err,
+	}
+}
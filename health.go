@@ -0,0 +1,11 @@
+package anymind
+
+import "context"
+
+// HealthChecker check dependency required to serve traffic.
+//
+//go:generate moq -out src/mock/mock_health_checker.go -pkg mock . HealthChecker
+type HealthChecker interface {
+	// CheckHealth return error describing why dependency is not usable.
+	CheckHealth(ctx context.Context) error
+}
@@ -0,0 +1,38 @@
+package anymind
+
+import (
+	"github.com/cockroachdb/apd"
+	"time"
+)
+
+// FillHourly expand sparse hourly balance into one entry per hour between req.Start and req.End.
+// Hour without data carry the balance of the previous hour, but not its value.
+func FillHourly(req *HistoricalDataReq, entries []*HistoricalData) []*HistoricalData {
+	var res []*HistoricalData
+
+	pos := 0
+	cur := BucketOf(req.Start)
+	val := apd.New(0, 0)
+	for {
+		if cur.After(req.End) {
+			break
+		}
+
+		var value *apd.Decimal
+		if pos < len(entries) && entries[pos].DateTime.Equal(cur) {
+			val = &entries[pos].Amount
+			value = entries[pos].Value
+			pos++
+		}
+
+		res = append(res, &HistoricalData{
+			DateTime: cur,
+			Amount:   *val,
+			Value:    value,
+		})
+
+		cur = cur.Add(time.Hour)
+	}
+
+	return res
+}
@@ -0,0 +1,90 @@
+package anymind
+
+import (
+	"context"
+	"github.com/cockroachdb/apd"
+	"time"
+)
+
+// Deposit status, pending deposit is settled as confirmed or failed.
+const (
+	DepositPending   = "pending"
+	DepositConfirmed = "confirmed"
+	DepositFailed    = "failed"
+)
+
+// DepositStatus return status of deposit input, DepositConfirmed when it is empty.
+func DepositStatus(status string) string {
+	if status == "" {
+		return DepositConfirmed
+	}
+
+	return status
+}
+
+// AddPending add pending deposits to sparse confirmed hourly balance of req, as returned by Historical.
+// Pending is net amount of pending deposits per hourly bucket up to req.End, bucket before req.Start count
+// toward the first hour. Result is sparse too, with an entry at every hour either balance change.
+func AddPending(req *HistoricalDataReq, confirmed []*HistoricalData, pending []*HistoricalData) ([]*HistoricalData, error) {
+	first := BucketOf(req.Start)
+	at := func(entry *HistoricalData) time.Time {
+		if entry.DateTime.Before(first) {
+			return first
+		}
+
+		return entry.DateTime
+	}
+
+	var res []*HistoricalData
+	var balance, pendingSum apd.Decimal
+	c, p := 0, 0
+	for c < len(confirmed) || p < len(pending) {
+		// next hour either balance change at, both are consumed when they change at the same hour.
+		var cur time.Time
+		switch {
+		case p == len(pending):
+			cur = at(confirmed[c])
+		case c == len(confirmed):
+			cur = at(pending[p])
+		case at(pending[p]).Before(at(confirmed[c])):
+			cur = at(pending[p])
+		default:
+			cur = at(confirmed[c])
+		}
+
+		for c < len(confirmed) && !at(confirmed[c]).After(cur) {
+			balance.Set(&confirmed[c].Amount)
+			c++
+		}
+
+		for p < len(pending) && !at(pending[p]).After(cur) {
+			// precision of base context is unlimited, sum is exact.
+			_, err := apd.BaseContext.Add(&pendingSum, &pendingSum, &pending[p].Amount)
+			if err != nil {
+				return nil, err
+			}
+			p++
+		}
+
+		entry := &HistoricalData{DateTime: cur}
+		_, err := apd.BaseContext.Add(&entry.Amount, &balance, &pendingSum)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, entry)
+	}
+
+	return res, nil
+}
+
+// DepositStatusService settle pending deposits.
+//
+//go:generate moq -out src/mock/mock_deposit_status_service.go -pkg mock . DepositStatusService
+type DepositStatusService interface {
+	// SetDepositStatus set pending deposit to DepositConfirmed, applying its amount to balance, or DepositFailed.
+	// ErrNotFound is returned when there is no pending deposit with id made by ctx principal, any principal for admin.
+	SetDepositStatus(ctx context.Context, id int64, status string) (*DepositEvent, error)
+	// ExpirePendingDeposits fail deposits pending since before given time and return how many were failed.
+	ExpirePendingDeposits(ctx context.Context, before time.Time) (int64, error)
+}
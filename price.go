@@ -0,0 +1,88 @@
+package anymind
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/cockroachdb/apd"
+	"time"
+)
+
+// ValuationRounding is rounding of balance valued in quote asset, value is rounded to scale of quote asset.
+const ValuationRounding = apd.RoundHalfEven
+
+// valuationContext multiply balance by rate exactly, precision is far above amount and rate digits.
+var valuationContext = func() *apd.Context {
+	c := apd.BaseContext.WithPrecision(100)
+	c.Rounding = ValuationRounding
+
+	return c
+}()
+
+// Rate is price of one Base asset in Quote asset, it value the historical bucket at Hour.
+type Rate struct {
+	Base  string
+	Quote string
+	Hour  time.Time
+	Rate  apd.Decimal
+}
+
+// Validate check rate to be imported.
+func (r *Rate) Validate() error {
+	switch {
+	case !assetCodePattern.MatchString(r.Base) || !assetCodePattern.MatchString(r.Quote):
+		return fmt.Errorf("invalid asset pair %q/%q", r.Base, r.Quote)
+	case r.Base == r.Quote:
+		return errors.New("base and quote asset must differ")
+	case r.Hour.IsZero() || !r.Hour.Equal(r.Hour.Truncate(time.Hour)):
+		return errors.New("rate hour must be on the hour")
+	case r.Rate.Form != apd.Finite || r.Rate.Sign() <= 0:
+		return errors.New("rate must be positive")
+	}
+
+	return nil
+}
+
+// ValueHourly fill entries like FillHourly, then set Value of each hour to its balance multiplied by the rate of the
+// same hour, rounded to scale decimal places with ValuationRounding. Hour without rate keep nil Value.
+func ValueHourly(req *HistoricalDataReq, entries []*HistoricalData, rates []*Rate, scale int) ([]*HistoricalData, error) {
+	byHour := make(map[time.Time]*apd.Decimal, len(rates))
+	for _, rate := range rates {
+		byHour[rate.Hour.UTC()] = &rate.Rate
+	}
+
+	res := FillHourly(req, entries)
+	for _, entry := range res {
+		rate, ok := byHour[entry.DateTime.UTC()]
+		if !ok {
+			entry.Value = nil
+			continue
+		}
+
+		value := new(apd.Decimal)
+		_, err := valuationContext.Mul(value, &entry.Amount, rate)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = valuationContext.Quantize(value, value, -int32(scale))
+		if err != nil {
+			return nil, err
+		}
+
+		entry.Value = value
+	}
+
+	return res, nil
+}
+
+// PriceService store hourly exchange rates between assets.
+//
+//go:generate moq -out src/mock/mock_price_service.go -pkg mock . PriceService
+type PriceService interface {
+	// ImportRates insert rates or replace rates of the same pair and hour, error is ErrNotFound when an asset is
+	// unknown.
+	ImportRates(ctx context.Context, rates []*Rate) error
+	// Rates return rates of base in quote between start and end inclusive, ordered by hour.
+	Rates(ctx context.Context, base string, quote string, start time.Time, end time.Time) ([]*Rate, error)
+}
@@ -0,0 +1,34 @@
+package anymind
+
+import "context"
+
+// RequestIDHeader carry id correlating log lines of a request, it is accepted from caller or generated.
+const RequestIDHeader = "X-Request-ID"
+
+type requestIDKey struct{}
+
+// WithRequestID return context carrying request id.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext return id of the request, if it was set.
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDKey{}).(string)
+
+	return id, ok
+}
+
+type clientIPKey struct{}
+
+// WithClientIP return context carrying ip address of the caller.
+func WithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPKey{}, ip)
+}
+
+// ClientIPFromContext return ip address of the caller, if it is known.
+func ClientIPFromContext(ctx context.Context) (string, bool) {
+	ip, ok := ctx.Value(clientIPKey{}).(string)
+
+	return ip, ok
+}
@@ -0,0 +1,58 @@
+package anymind
+
+import (
+	"context"
+	"time"
+)
+
+// Headers carrying request signature, see SignedRequest.
+const (
+	SignatureKeyHeader       = "X-Signature-Key"
+	SignatureTimestampHeader = "X-Signature-Timestamp"
+	SignatureNonceHeader     = "X-Signature-Nonce"
+	SignatureHeader          = "X-Signature"
+)
+
+// SignedRequest is request signed by partner with shared secret identified by KeyID.
+type SignedRequest struct {
+	KeyID  string
+	Method string
+	Path   string
+	// Timestamp is unix time in seconds when request was signed.
+	Timestamp string
+	Nonce     string
+	Signature string
+	Body      []byte
+}
+
+type signedRequestKey struct{}
+
+// WithSignedRequest return context carrying signature of the request and what it covers.
+func WithSignedRequest(ctx context.Context, req *SignedRequest) context.Context {
+	return context.WithValue(ctx, signedRequestKey{}, req)
+}
+
+// SignedRequestFromContext return signed request, if transport read one.
+func SignedRequestFromContext(ctx context.Context) (*SignedRequest, bool) {
+	req, ok := ctx.Value(signedRequestKey{}).(*SignedRequest)
+
+	return req, ok
+}
+
+// RequestVerifier check signature of request and reject replayed request.
+// It return UnauthorizedErr when request is not accepted.
+//
+//go:generate moq -out src/mock/mock_request_verifier.go -pkg mock . RequestVerifier
+type RequestVerifier interface {
+	Verify(ctx context.Context, req *SignedRequest) error
+}
+
+// NonceStore remember nonces of signed requests, so a request replayed to any instance is rejected.
+//
+//go:generate moq -out src/mock/mock_nonce_store.go -pkg mock . NonceStore
+type NonceStore interface {
+	// ClaimNonce record nonce of keyID until expiresAt, it return false when nonce is recorded and not expired at now.
+	ClaimNonce(ctx context.Context, keyID, nonce string, now, expiresAt time.Time) (bool, error)
+	// PruneNonces forget nonces expired before.
+	PruneNonces(ctx context.Context, before time.Time) error
+}
@@ -0,0 +1,31 @@
+package anomaly
+
+type Option func(*Service)
+
+// WithWindow set how many recent deposits of a wallet rolling stats are computed from.
+func WithWindow(window int) Option {
+	return func(svc *Service) {
+		svc.window = window
+	}
+}
+
+// WithMinSamples set how many deposits a wallet need before its deposits are scored.
+func WithMinSamples(samples int) Option {
+	return func(svc *Service) {
+		svc.minSamples = samples
+	}
+}
+
+// WithThreshold set robust z-score of amount above which deposit is flagged.
+func WithThreshold(threshold float64) Option {
+	return func(svc *Service) {
+		svc.threshold = threshold
+	}
+}
+
+// WithHourShare set share of recent deposits below which deposit hour is unusual, 0 disable the check.
+func WithHourShare(share float64) Option {
+	return func(svc *Service) {
+		svc.hourShare = share
+	}
+}
@@ -0,0 +1,216 @@
+package anomaly
+
+import (
+	"anymind"
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+)
+
+var _ anymind.AnomalyDetector = &Service{}
+
+// madScale make median absolute deviation consistent with standard deviation of normal distribution.
+const madScale = 0.6745
+
+// minMAD is lowest deviation of log10 amount used for scoring, wallet receiving always the same amount
+// would otherwise flag any other amount.
+const minMAD = 0.25
+
+// Service detect deposits out of pattern with rolling median and median absolute deviation of log10 amount,
+// so a deposit 100x the typical size stand out whatever the typical size is. It also flag deposits at an hour
+// of day few recent deposits were made around.
+//
+// Rolling stats are kept in memory of the replica, they are built from deposits it is given to Observe. Replicas only
+// observe deposits they credit, so stats are Reset from stored deposits at start and periodically after, replicas then
+// judge deposits against the same window.
+type Service struct {
+	window     int
+	minSamples int
+	threshold  float64
+	hourShare  float64
+
+	mu      sync.Mutex
+	wallets map[string]*wallet
+}
+
+// wallet keep recent deposits of an asset in a ring.
+type wallet struct {
+	amounts []float64
+	hours   []int
+	next    int
+	byHour  [24]int
+}
+
+func NewService(opts ...Option) *Service {
+	s := &Service{
+		window:     100,
+		minSamples: 20,
+		threshold:  3.5,
+		hourShare:  0.01,
+		wallets:    make(map[string]*wallet),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if s.window < 1 {
+		s.window = 1
+	}
+
+	return s
+}
+
+func (s *Service) Inspect(_ context.Context, input *anymind.DepositInput) (*anymind.Anomaly, error) {
+	x, hour, err := sample(input)
+	if err != nil {
+		return nil, err
+	}
+
+	asset := anymind.AssetCode(input.Asset)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w, ok := s.wallets[asset]
+	if !ok || len(w.amounts) < s.minSamples {
+		return nil, nil
+	}
+
+	var res *anymind.Anomaly
+	score := w.score(x)
+	share := w.share(hour)
+
+	switch {
+	case math.Abs(score) > s.threshold:
+		res = &anymind.Anomaly{Reason: anymind.AnomalyReasonAmount, Score: score}
+	// hour is only judged on a full window, a few deposits do not tell which hours are usual.
+	case len(w.hours) >= s.window && share < s.hourShare:
+		res = &anymind.Anomaly{Reason: anymind.AnomalyReasonHour, Score: share}
+	default:
+		return nil, nil
+	}
+
+	res.DateTime = input.DateTime
+	res.Amount = input.Amount
+	res.Asset = asset
+
+	return res, nil
+}
+
+func (s *Service) Observe(_ context.Context, input *anymind.DepositInput) error {
+	x, hour, err := sample(input)
+	if err != nil {
+		return err
+	}
+
+	asset := anymind.AssetCode(input.Asset)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w, ok := s.wallets[asset]
+	if !ok {
+		w = &wallet{}
+		s.wallets[asset] = w
+	}
+
+	w.add(x, hour, s.window)
+
+	return nil
+}
+
+// Reset replace rolling stats of every wallet by deposits, oldest first. Wallet without deposits is dropped.
+func (s *Service) Reset(deposits []*anymind.DepositInput) error {
+	wallets := make(map[string]*wallet)
+	for _, input := range deposits {
+		x, hour, err := sample(input)
+		if err != nil {
+			return err
+		}
+
+		asset := anymind.AssetCode(input.Asset)
+		w, ok := wallets[asset]
+		if !ok {
+			w = &wallet{}
+			wallets[asset] = w
+		}
+
+		w.add(x, hour, s.window)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.wallets = wallets
+
+	return nil
+}
+
+// sample return log10 amount and hour of day of deposit.
+func sample(input *anymind.DepositInput) (float64, int, error) {
+	amount, err := input.Amount.Float64()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if amount <= 0 {
+		return 0, 0, fmt.Errorf("amount %f is not positive", &input.Amount)
+	}
+
+	return math.Log10(amount), input.DateTime.UTC().Hour(), nil
+}
+
+// score return robust z-score of x against recent amounts.
+func (w *wallet) score(x float64) float64 {
+	m := median(w.amounts)
+
+	deviations := make([]float64, len(w.amounts))
+	for i, amount := range w.amounts {
+		deviations[i] = math.Abs(amount - m)
+	}
+
+	mad := median(deviations)
+	if mad < minMAD {
+		mad = minMAD
+	}
+
+	return madScale * (x - m) / mad
+}
+
+// share return share of recent deposits made within an hour of given hour of day.
+func (w *wallet) share(hour int) float64 {
+	count := w.byHour[(hour+23)%24] + w.byHour[hour] + w.byHour[(hour+1)%24]
+
+	return float64(count) / float64(len(w.hours))
+}
+
+func (w *wallet) add(x float64, hour int, window int) {
+	if len(w.amounts) < window {
+		w.amounts = append(w.amounts, x)
+		w.hours = append(w.hours, hour)
+		w.byHour[hour]++
+
+		return
+	}
+
+	w.byHour[w.hours[w.next]]--
+	w.amounts[w.next] = x
+	w.hours[w.next] = hour
+	w.byHour[hour]++
+	w.next = (w.next + 1) % window
+}
+
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
@@ -0,0 +1,114 @@
+package anomaly
+
+import (
+	"anymind"
+	"context"
+	"github.com/cockroachdb/apd"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func deposit(amount string, at time.Time, asset string) *anymind.DepositInput {
+	d, _, err := apd.NewFromString(amount)
+	if err != nil {
+		panic(err)
+	}
+
+	return &anymind.DepositInput{DateTime: at, Amount: *d, Asset: asset}
+}
+
+func TestInspectAmount(t *testing.T) {
+	ctx := context.Background()
+	svc := NewService(WithMinSamples(10), WithHourShare(0))
+	at := time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	for i, amount := range []string{"1", "0.5", "2", "1.5", "0.8", "1.2", "1", "3", "0.7", "1.1"} {
+		res, err := svc.Inspect(ctx, deposit(amount, at, ""))
+		require.NoError(t, err)
+		require.Nil(t, res, "deposit %d is scored before min samples", i)
+		require.NoError(t, svc.Observe(ctx, deposit(amount, at, "")))
+	}
+
+	res, err := svc.Inspect(ctx, deposit("100", at, ""))
+	require.NoError(t, err)
+	require.NotNil(t, res)
+	require.Equal(t, anymind.AnomalyReasonAmount, res.Reason)
+	require.Equal(t, "BTC", res.Asset)
+	require.Greater(t, res.Score, 3.5)
+
+	res, err = svc.Inspect(ctx, deposit("0.0001", at, ""))
+	require.NoError(t, err)
+	require.NotNil(t, res)
+	require.Less(t, res.Score, -3.5)
+
+	res, err = svc.Inspect(ctx, deposit("2.5", at, ""))
+	require.NoError(t, err)
+	require.Nil(t, res)
+
+	// outliers that are only inspected, as held ones, do not become usual.
+	for i := 0; i < 20; i++ {
+		res, err = svc.Inspect(ctx, deposit("100", at, ""))
+		require.NoError(t, err)
+		require.NotNil(t, res)
+	}
+
+	// wallet of another asset has no history yet.
+	res, err = svc.Inspect(ctx, deposit("100", at, "ETH"))
+	require.NoError(t, err)
+	require.Nil(t, res)
+}
+
+func TestInspectHour(t *testing.T) {
+	ctx := context.Background()
+	svc := NewService(WithWindow(20), WithMinSamples(10))
+	day := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 20; i++ {
+		input := deposit("1", day.Add(time.Duration(9+i%3)*time.Hour), "")
+		res, err := svc.Inspect(ctx, input)
+		require.NoError(t, err)
+		require.Nil(t, res)
+		require.NoError(t, svc.Observe(ctx, input))
+	}
+
+	res, err := svc.Inspect(ctx, deposit("1", day.Add(3*time.Hour), ""))
+	require.NoError(t, err)
+	require.NotNil(t, res)
+	require.Equal(t, anymind.AnomalyReasonHour, res.Reason)
+
+	// an hour next to usual ones is not flagged.
+	res, err = svc.Inspect(ctx, deposit("1", day.Add(12*time.Hour), ""))
+	require.NoError(t, err)
+	require.Nil(t, res)
+}
+
+func TestReset(t *testing.T) {
+	ctx := context.Background()
+	at := time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	var stored []*anymind.DepositInput
+	for i := 0; i < 10; i++ {
+		stored = append(stored, deposit("1", at, ""))
+	}
+
+	// replica that observed other deposits judge the same as one that did not once both are reset.
+	first := NewService(WithMinSamples(10), WithHourShare(0))
+	second := NewService(WithMinSamples(10), WithHourShare(0))
+	for i := 0; i < 10; i++ {
+		require.NoError(t, first.Observe(ctx, deposit("100", at, "")))
+		require.NoError(t, first.Observe(ctx, deposit("1", at, "ETH")))
+	}
+
+	for _, svc := range []*Service{first, second} {
+		require.NoError(t, svc.Reset(stored))
+
+		res, err := svc.Inspect(ctx, deposit("100", at, ""))
+		require.NoError(t, err)
+		require.NotNil(t, res)
+
+		res, err = svc.Inspect(ctx, deposit("100", at, "ETH"))
+		require.NoError(t, err)
+		require.Nil(t, res)
+	}
+}
@@ -1,6 +1,10 @@
 package api
 
-import "go.uber.org/zap"
+import (
+	"anymind"
+	"go.uber.org/zap"
+	"time"
+)
 
 type Option func(*Service)
 
@@ -9,3 +13,66 @@ func WithLogger(logger *zap.Logger) Option {
 		svc.logger = logger
 	}
 }
+
+// WithAssetService validate deposit against assets registered in assets, otherwise only anymind.DefaultAsset is known.
+func WithAssetService(assets anymind.AssetService) Option {
+	return func(svc *Service) {
+		svc.assets = assets
+	}
+}
+
+// WithPriceService value historical balance in HistoricalDataReq.Quote with rates of prices.
+func WithPriceService(prices anymind.PriceService) Option {
+	return func(svc *Service) {
+		svc.prices = prices
+	}
+}
+
+// WithStatsService serve deposit stats from stats.
+func WithStatsService(stats anymind.StatsService) Option {
+	return func(svc *Service) {
+		svc.stats = stats
+	}
+}
+
+// WithCandleService serve OHLC balance candles from candles.
+func WithCandleService(candles anymind.CandleService) Option {
+	return func(svc *Service) {
+		svc.candles = candles
+	}
+}
+
+// WithAnomalyDetector inspect every deposit with detector.
+func WithAnomalyDetector(detector anymind.AnomalyDetector) Option {
+	return func(svc *Service) {
+		svc.detector = detector
+	}
+}
+
+// WithAnomalyService record anomalies raised by detector in anomalies, and serve their review.
+func WithAnomalyService(anomalies anymind.AnomalyService) Option {
+	return func(svc *Service) {
+		svc.anomalies = anomalies
+	}
+}
+
+// WithAnomalyHold keep deposit raising an anomaly uncredited until it is released.
+func WithAnomalyHold(hold bool) Option {
+	return func(svc *Service) {
+		svc.holdAnomalies = hold
+	}
+}
+
+// WithDepositStatusService accept pending deposits, settled with depositStatus.
+func WithDepositStatusService(depositStatus anymind.DepositStatusService) Option {
+	return func(svc *Service) {
+		svc.depositStatus = depositStatus
+	}
+}
+
+// WithMaxClockSkew reject deposit whose datetime is more than skew in the future.
+func WithMaxClockSkew(skew time.Duration) Option {
+	return func(svc *Service) {
+		svc.SetMaxClockSkew(skew)
+	}
+}
@@ -2,13 +2,28 @@ package api
 
 import (
 	"anymind"
+	"anymind/src/tracing"
 	"context"
 	"errors"
+	"fmt"
 	"github.com/cockroachdb/apd"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 var _ anymind.APIService = &Service{}
+var _ anymind.AssetService = &Service{}
+var _ anymind.StatsService = &Service{}
+var _ anymind.CandleService = &Service{}
+var _ anymind.AnomalyService = &Service{}
+var _ anymind.DepositStatusService = &Service{}
+
+var tracer = otel.Tracer("anymind/src/api")
 
 var (
 	ErrInvalid  = errors.New("invalid argument")
@@ -19,8 +34,36 @@ var (
 type Service struct {
 	persistence anymind.PersistenceService
 	logger      *zap.Logger
+	// maxClockSkew is how far in the future deposit datetime is accepted, 0 accept any datetime.
+	// It is a time.Duration, stored atomically since it can change while serving, see SetMaxClockSkew.
+	maxClockSkew atomic.Int64
+	// assets is asset registry, only DefaultAsset is known when it is nil.
+	assets anymind.AssetService
+	// assetCache keep assets already looked up, asset can not change once registered.
+	assetCache sync.Map
+	// prices value historical balance in quote asset, quote is rejected when it is nil.
+	prices anymind.PriceService
+	stats  anymind.StatsService
+	// candles build OHLC balance, see anymind.CandleService.
+	candles anymind.CandleService
+	// detector score deposits, anomaly is only logged when anomalies is nil.
+	detector  anymind.AnomalyDetector
+	anomalies anymind.AnomalyService
+	// holdAnomalies keep flagged deposit uncredited until it is reviewed, it require anomalies.
+	holdAnomalies bool
+	// depositStatus settle pending deposits, pending deposit is rejected when it is nil.
+	depositStatus anymind.DepositStatusService
 }
 
+// maxAnomalyLimit bound number of anomalies listed at once.
+const maxAnomalyLimit = 1000
+
+// maxStatsRange bound range of Stats request.
+const maxStatsRange = 31 * 24 * time.Hour
+
+// defaultAsset is the asset served before assets were introduced.
+var defaultAsset = &anymind.Asset{Code: anymind.DefaultAsset, Scale: 8, DisplayPrecision: 8}
+
 func NewService(persistence anymind.PersistenceService, opts ...Option) *Service {
 	s := &Service{
 		persistence: persistence,
@@ -37,14 +80,142 @@ func NewService(persistence anymind.PersistenceService, opts ...Option) *Service
 	return s
 }
 
-func (s *Service) Deposit(ctx context.Context, input *anymind.DepositInput) error {
+func (s *Service) Deposit(ctx context.Context, input *anymind.DepositInput) (err error) {
+	ctx, span := tracer.Start(ctx, "api.Deposit")
+	defer func() { endSpan(span, err) }()
+
 	if input.Amount.Form != apd.Finite ||
 		input.Amount.Negative ||
 		input.Amount.IsZero() {
 		return anymind.ParameterError(errors.New("invalid amount"))
 	}
 
-	err := s.persistence.Deposit(ctx, input)
+	asset, err := s.requestAsset(ctx, input.Asset)
+	if err != nil {
+		return err
+	}
+
+	err = asset.CheckAmount(&input.Amount)
+	if err != nil {
+		return anymind.ParameterError(err)
+	}
+	input.Asset = asset.Code
+
+	if skew := time.Duration(s.maxClockSkew.Load()); skew > 0 && input.DateTime.After(time.Now().Add(skew)) {
+		return anymind.ParameterError(fmt.Errorf("datetime is more than %s in the future", skew))
+	}
+
+	switch input.Status {
+	case "", anymind.DepositConfirmed:
+	case anymind.DepositPending:
+		if s.depositStatus == nil {
+			return anymind.ParameterError(errors.New("pending deposit is not available"))
+		}
+	default:
+		return anymind.ParameterError(fmt.Errorf("status must be %s or %s", anymind.DepositConfirmed, anymind.DepositPending))
+	}
+
+	// held deposit must be inspected before it is credited, otherwise deposit is inspected once it is stored.
+	holding := s.holdAnomalies && s.anomalies != nil
+	if holding {
+		if anomaly := s.inspect(ctx, input); anomaly != nil {
+			return s.hold(ctx, input, anomaly)
+		}
+	}
+
+	err = s.persistence.Deposit(ctx, input)
+	if err != nil {
+		tracing.Logger(ctx, s.logger).Error("unable to store deposit", zap.Error(err))
+
+		return anymind.InternalError(err)
+	}
+
+	if !holding {
+		if anomaly := s.inspect(ctx, input); anomaly != nil {
+			anomaly.DepositID = input.ID
+			s.flag(ctx, anomaly)
+		}
+	}
+
+	if anymind.DepositStatus(input.Status) == anymind.DepositConfirmed {
+		s.observe(ctx, input)
+	}
+
+	return nil
+}
+
+// inspect return anomaly raised by detector, detector failure is logged so deposit does not depend on it.
+func (s *Service) inspect(ctx context.Context, input *anymind.DepositInput) *anymind.Anomaly {
+	if s.detector == nil {
+		return nil
+	}
+
+	anomaly, err := s.detector.Inspect(ctx, input)
+	if err != nil {
+		tracing.Logger(ctx, s.logger).Error("unable to inspect deposit", zap.Error(err))
+
+		return nil
+	}
+
+	if anomaly != nil {
+		anomaly.DepositStatus = anymind.DepositStatus(input.Status)
+	}
+
+	return anomaly
+}
+
+// observe add credited deposit to rolling stats of detector, failure is only logged.
+func (s *Service) observe(ctx context.Context, input *anymind.DepositInput) {
+	if s.detector == nil {
+		return
+	}
+
+	err := s.detector.Observe(ctx, input)
+	if err != nil {
+		tracing.Logger(ctx, s.logger).Error("unable to observe deposit", zap.Error(err))
+	}
+}
+
+// hold record anomaly instead of crediting its deposit, input.AnomalyID is set so depositor can tell held deposit
+// from credited one.
+func (s *Service) hold(ctx context.Context, input *anymind.DepositInput, anomaly *anymind.Anomaly) error {
+	anomaly.Status = anymind.AnomalyHeld
+	err := s.anomalies.RecordAnomaly(ctx, anomaly)
+	if err != nil {
+		tracing.Logger(ctx, s.logger).Error("unable to hold deposit", zap.Error(err))
+
+		return anymind.InternalError(err)
+	}
+	input.AnomalyID = anomaly.ID
+
+	tracing.Logger(ctx, s.logger).Warn("deposit held for review",
+		zap.Int64("anomaly_id", anomaly.ID), zap.String("reason", anomaly.Reason), zap.Float64("score", anomaly.Score))
+
+	return nil
+}
+
+// flag record anomaly of credited deposit, failure is only logged since deposit is already stored.
+func (s *Service) flag(ctx context.Context, anomaly *anymind.Anomaly) {
+	logger := tracing.Logger(ctx, s.logger)
+	logger.Warn("deposit out of pattern", zap.String("reason", anomaly.Reason), zap.Float64("score", anomaly.Score))
+
+	if s.anomalies == nil {
+		return
+	}
+
+	anomaly.Status = anymind.AnomalyFlagged
+	err := s.anomalies.RecordAnomaly(ctx, anomaly)
+	if err != nil {
+		logger.Error("unable to record anomaly", zap.Error(err))
+	}
+}
+
+func (s *Service) RecordAnomaly(ctx context.Context, anomaly *anymind.Anomaly) error {
+	if s.anomalies == nil {
+		return anymind.InternalError(errors.New("anomalies are not available"))
+	}
+
+	err := s.anomalies.RecordAnomaly(ctx, anomaly)
 	if err != nil {
 		return anymind.InternalError(err)
 	}
@@ -52,15 +223,343 @@ func (s *Service) Deposit(ctx context.Context, input *anymind.DepositInput) erro
 	return nil
 }
 
-func (s *Service) Historical(ctx context.Context, req *anymind.HistoricalDataReq) ([]*anymind.HistoricalData, error) {
+func (s *Service) ListAnomalies(ctx context.Context, status string, limit int) ([]*anymind.Anomaly, error) {
+	if s.anomalies == nil {
+		return nil, anymind.InternalError(errors.New("anomalies are not available"))
+	}
+
+	switch status {
+	case "", anymind.AnomalyFlagged, anymind.AnomalyHeld, anymind.AnomalyReleased, anymind.AnomalyRejected:
+	default:
+		return nil, anymind.ParameterError(fmt.Errorf("unknown anomaly status %q", status))
+	}
+
+	if limit <= 0 || limit > maxAnomalyLimit {
+		return nil, anymind.ParameterError(fmt.Errorf("limit must be between 1 and %d", maxAnomalyLimit))
+	}
+
+	res, err := s.anomalies.ListAnomalies(ctx, status, limit)
+	if err != nil {
+		return nil, anymind.InternalError(err)
+	}
+
+	return res, nil
+}
+
+// ReviewAnomaly release or reject held deposit, released deposit is credited.
+func (s *Service) ReviewAnomaly(ctx context.Context, id int64, status string) (*anymind.Anomaly, error) {
+	if s.anomalies == nil {
+		return nil, anymind.InternalError(errors.New("anomalies are not available"))
+	}
+
+	if status != anymind.AnomalyReleased && status != anymind.AnomalyRejected {
+		return nil, anymind.ParameterError(fmt.Errorf("status must be %s or %s", anymind.AnomalyReleased, anymind.AnomalyRejected))
+	}
+
+	res, err := s.anomalies.ReviewAnomaly(ctx, id, status)
+	if errors.Is(err, anymind.ErrNotFound) {
+		return nil, anymind.NotFoundError(fmt.Errorf("no held anomaly %d", id))
+	}
+	if err != nil {
+		tracing.Logger(ctx, s.logger).Error("unable to review anomaly", zap.Error(err))
+
+		return nil, anymind.InternalError(err)
+	}
+
+	if res.Status == anymind.AnomalyReleased {
+		if input := res.DepositInput(); anymind.DepositStatus(input.Status) == anymind.DepositConfirmed {
+			s.observe(ctx, input)
+		}
+	}
+
+	return res, nil
+}
+
+// SetDepositStatus settle pending deposit id as confirmed or failed.
+func (s *Service) SetDepositStatus(ctx context.Context, id int64, status string) (res *anymind.DepositEvent, err error) {
+	ctx, span := tracer.Start(ctx, "api.SetDepositStatus")
+	defer func() { endSpan(span, err) }()
+
+	if s.depositStatus == nil {
+		return nil, anymind.InternalError(errors.New("deposit status is not available"))
+	}
+
+	if status != anymind.DepositConfirmed && status != anymind.DepositFailed {
+		return nil, anymind.ParameterError(fmt.Errorf("status must be %s or %s", anymind.DepositConfirmed, anymind.DepositFailed))
+	}
+
+	res, err = s.depositStatus.SetDepositStatus(ctx, id, status)
+	if errors.Is(err, anymind.ErrNotFound) {
+		return nil, anymind.NotFoundError(fmt.Errorf("no pending deposit %d", id))
+	}
+	if err != nil {
+		tracing.Logger(ctx, s.logger).Error("unable to set deposit status", zap.Error(err))
+
+		return nil, anymind.InternalError(err)
+	}
+
+	if status == anymind.DepositConfirmed {
+		s.observe(ctx, &anymind.DepositInput{DateTime: res.DateTime, Amount: res.Amount, Asset: res.Asset})
+	}
+
+	return res, nil
+}
+
+// ExpirePendingDeposits fail deposits pending since before given time.
+func (s *Service) ExpirePendingDeposits(ctx context.Context, before time.Time) (int64, error) {
+	if s.depositStatus == nil {
+		return 0, anymind.InternalError(errors.New("deposit status is not available"))
+	}
+
+	n, err := s.depositStatus.ExpirePendingDeposits(ctx, before)
+	if err != nil {
+		tracing.Logger(ctx, s.logger).Error("unable to expire pending deposits", zap.Error(err))
+
+		return 0, anymind.InternalError(err)
+	}
+
+	return n, nil
+}
+
+// SetMaxClockSkew change how far in the future deposit datetime is accepted, 0 accept any datetime.
+func (s *Service) SetMaxClockSkew(skew time.Duration) {
+	s.maxClockSkew.Store(int64(skew))
+}
+
+func (s *Service) Historical(
+	ctx context.Context,
+	req *anymind.HistoricalDataReq,
+) (res []*anymind.HistoricalData, err error) {
+	ctx, span := tracer.Start(ctx, "api.Historical")
+	defer func() { endSpan(span, err) }()
+
 	if req.Start.After(req.End) {
 		return nil, anymind.ParameterError(errors.New("invalid start and end date"))
 	}
 
-	res, err := s.persistence.Historical(ctx, req)
+	asset, err := s.requestAsset(ctx, req.Asset)
+	if err != nil {
+		return nil, err
+	}
+	req.Asset = asset.Code
+
+	var quote *anymind.Asset
+	if req.Quote != "" {
+		quote, err = s.quote(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	res, err = s.persistence.Historical(ctx, req)
 	if err != nil {
+		tracing.Logger(ctx, s.logger).Error("unable to load historical balance", zap.Error(err))
+
 		return res, anymind.InternalError(err)
 	}
 
+	if quote == nil {
+		return res, nil
+	}
+
+	rates, err := s.prices.Rates(ctx, asset.Code, quote.Code, req.Start, req.End)
+	if err != nil {
+		tracing.Logger(ctx, s.logger).Error("unable to load exchange rates", zap.Error(err))
+
+		return nil, anymind.InternalError(err)
+	}
+
+	// every hour is returned, since rate change even when balance does not.
+	res, err = anymind.ValueHourly(req, res, rates, quote.Scale)
+	if err != nil {
+		return nil, anymind.InternalError(err)
+	}
+
+	return res, nil
+}
+
+// Stats return deposit stats of every hourly bucket, range is bounded by maxStatsRange since deposits are scanned.
+func (s *Service) Stats(ctx context.Context, req *anymind.HistoricalDataReq) (res []*anymind.BucketStats, err error) {
+	ctx, span := tracer.Start(ctx, "api.Stats")
+	defer func() { endSpan(span, err) }()
+
+	if s.stats == nil {
+		return nil, anymind.InternalError(errors.New("stats is not available"))
+	}
+
+	if req.Start.After(req.End) {
+		return nil, anymind.ParameterError(errors.New("invalid start and end date"))
+	}
+
+	if req.End.Sub(req.Start) > maxStatsRange {
+		return nil, anymind.ParameterError(fmt.Errorf("stats range must not exceed %s", maxStatsRange))
+	}
+
+	if req.Quote != "" {
+		return nil, anymind.ParameterError(errors.New("quote is not supported by stats"))
+	}
+
+	asset, err := s.requestAsset(ctx, req.Asset)
+	if err != nil {
+		return nil, err
+	}
+	req.Asset = asset.Code
+
+	res, err = s.stats.Stats(ctx, req)
+	if err != nil {
+		tracing.Logger(ctx, s.logger).Error("unable to load stats", zap.Error(err))
+
+		return nil, anymind.InternalError(err)
+	}
+
+	return res, nil
+}
+
+// Candles return OHLC balance of every bucket, granularity default to anymind.DefaultGranularity.
+func (s *Service) Candles(ctx context.Context, req *anymind.CandleReq) (res []*anymind.Candle, err error) {
+	ctx, span := tracer.Start(ctx, "api.Candles")
+	defer func() { endSpan(span, err) }()
+
+	if s.candles == nil {
+		return nil, anymind.InternalError(errors.New("candles are not available"))
+	}
+
+	if req.Granularity == 0 {
+		req.Granularity = anymind.DefaultGranularity
+	}
+
+	err = req.Validate()
+	if err != nil {
+		return nil, anymind.ParameterError(err)
+	}
+
+	asset, err := s.requestAsset(ctx, req.Asset)
+	if err != nil {
+		return nil, err
+	}
+	req.Asset = asset.Code
+
+	res, err = s.candles.Candles(ctx, req)
+	if err != nil {
+		tracing.Logger(ctx, s.logger).Error("unable to load candles", zap.Error(err))
+
+		return nil, anymind.InternalError(err)
+	}
+
+	return res, nil
+}
+
+// requestAsset return asset named by request, unknown asset is a parameter error.
+func (s *Service) requestAsset(ctx context.Context, code string) (*anymind.Asset, error) {
+	asset, err := s.asset(ctx, code)
+	if errors.Is(err, anymind.ErrNotFound) {
+		return nil, anymind.ParameterError(fmt.Errorf("unknown asset %s", anymind.AssetCode(code)))
+	}
+	if err != nil {
+		return nil, anymind.InternalError(err)
+	}
+
+	return asset, nil
+}
+
+// quote return asset historical balance is valued in.
+func (s *Service) quote(ctx context.Context, req *anymind.HistoricalDataReq) (*anymind.Asset, error) {
+	if s.prices == nil {
+		return nil, anymind.ParameterError(errors.New("valuation in quote asset is not enabled"))
+	}
+
+	quote, err := s.asset(ctx, req.Quote)
+	if errors.Is(err, anymind.ErrNotFound) {
+		return nil, anymind.ParameterError(fmt.Errorf("unknown quote asset %s", req.Quote))
+	}
+	if err != nil {
+		return nil, anymind.InternalError(err)
+	}
+
+	if quote.Code == req.Asset {
+		return nil, anymind.ParameterError(errors.New("quote asset must differ from asset"))
+	}
+
+	return quote, nil
+}
+
+// asset return registered asset, error is anymind.ErrNotFound when code is unknown.
+func (s *Service) asset(ctx context.Context, code string) (*anymind.Asset, error) {
+	code = anymind.AssetCode(code)
+	if cached, ok := s.assetCache.Load(code); ok {
+		return cached.(*anymind.Asset), nil
+	}
+
+	if s.assets == nil {
+		if code == anymind.DefaultAsset {
+			return defaultAsset, nil
+		}
+
+		return nil, anymind.ErrNotFound
+	}
+
+	asset, err := s.assets.Asset(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+
+	s.assetCache.Store(code, asset)
+
+	return asset, nil
+}
+
+func (s *Service) Asset(ctx context.Context, code string) (*anymind.Asset, error) {
+	asset, err := s.asset(ctx, code)
+	if errors.Is(err, anymind.ErrNotFound) {
+		return nil, anymind.NotFoundError(fmt.Errorf("unknown asset %s", anymind.AssetCode(code)))
+	}
+	if err != nil {
+		return nil, anymind.InternalError(err)
+	}
+
+	return asset, nil
+}
+
+func (s *Service) ListAssets(ctx context.Context) ([]*anymind.Asset, error) {
+	if s.assets == nil {
+		return []*anymind.Asset{defaultAsset}, nil
+	}
+
+	res, err := s.assets.ListAssets(ctx)
+	if err != nil {
+		return nil, anymind.InternalError(err)
+	}
+
 	return res, nil
 }
+
+// CreateAsset register asset, scale of registered asset can not be changed since stored amounts rely on it.
+func (s *Service) CreateAsset(ctx context.Context, asset *anymind.Asset) error {
+	err := asset.Validate()
+	if err != nil {
+		return anymind.ParameterError(err)
+	}
+
+	if s.assets == nil {
+		return anymind.InternalError(errors.New("asset registry is not available"))
+	}
+
+	err = s.assets.CreateAsset(ctx, asset)
+	if errors.Is(err, anymind.ErrAlreadyExists) {
+		return anymind.ParameterError(fmt.Errorf("asset %s already exists", asset.Code))
+	}
+	if err != nil {
+		return anymind.InternalError(err)
+	}
+
+	return nil
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
@@ -4,6 +4,7 @@ import (
 	"anymind"
 	"anymind/src/mock"
 	"context"
+	"errors"
 	"fmt"
 	"github.com/cockroachdb/apd"
 	"github.com/stretchr/testify/require"
@@ -20,6 +21,15 @@ func mustApd(number string) apd.Decimal {
 	return *val
 }
 
+func mustTime(ts string) time.Time {
+	val, err := time.Parse(time.RFC3339, ts)
+	if err != nil {
+		panic(err)
+	}
+
+	return val
+}
+
 func TestDepositInvalidValue(t *testing.T) {
 	persistSvc := &mock.PersistenceServiceMock{}
 
@@ -86,6 +96,34 @@ func TestDepositValidValue(t *testing.T) {
 	}
 }
 
+func TestDepositClockSkew(t *testing.T) {
+	ctx := context.Background()
+	svc := NewService(&mock.PersistenceServiceMock{
+		DepositFunc: func(context.Context, *anymind.DepositInput) error {
+			return nil
+		},
+	}, WithMaxClockSkew(time.Minute))
+
+	future := &anymind.DepositInput{
+		DateTime: time.Now().Add(time.Hour),
+		Amount:   mustApd("1"),
+	}
+
+	err := svc.Deposit(ctx, future)
+	anyErr := anymind.ParameterError(nil)
+	require.ErrorAs(t, err, &anyErr)
+	require.Equal(t, anymind.ParameterErr, anyErr.Type)
+
+	err = svc.Deposit(ctx, &anymind.DepositInput{
+		DateTime: time.Now().Add(30 * time.Second),
+		Amount:   mustApd("1"),
+	})
+	require.NoError(t, err)
+
+	svc.SetMaxClockSkew(0)
+	require.NoError(t, svc.Deposit(ctx, future))
+}
+
 func TestHistoricalInvalidValue(t *testing.T) {
 	persistSvc := &mock.PersistenceServiceMock{}
 
@@ -129,3 +167,402 @@ func TestHistoricalValidValue(t *testing.T) {
 	require.Len(t, res, 1)
 	require.Equal(t, "123", fmt.Sprintf("%f", &res[0].Amount))
 }
+
+func TestDepositAssetPrecision(t *testing.T) {
+	ctx := context.Background()
+	lookups := 0
+	assets := &mock.AssetServiceMock{
+		AssetFunc: func(_ context.Context, code string) (*anymind.Asset, error) {
+			lookups++
+			if code != "ETH" {
+				return nil, anymind.ErrNotFound
+			}
+
+			return &anymind.Asset{Code: "ETH", Scale: 18, DisplayPrecision: 6}, nil
+		},
+	}
+
+	var deposited []string
+	persistSvc := &mock.PersistenceServiceMock{
+		DepositFunc: func(_ context.Context, input *anymind.DepositInput) error {
+			deposited = append(deposited, input.Asset)
+
+			return nil
+		},
+	}
+
+	svc := NewService(persistSvc, WithAssetService(assets))
+
+	testCases := []struct {
+		asset  string
+		amount string
+		err    string
+	}{
+		{asset: "ETH", amount: "0.000000000000000001"},
+		{asset: "ETH", amount: "1.0000000000000000010000"},
+		{asset: "ETH", amount: "0.0000000000000000001", err: "ETH amount can have at most 18 decimal places"},
+		{asset: "DOGE", amount: "1", err: "unknown asset DOGE"},
+	}
+
+	for _, tc := range testCases {
+		err := svc.Deposit(ctx, &anymind.DepositInput{
+			DateTime: time.Now(),
+			Amount:   mustApd(tc.amount),
+			Asset:    tc.asset,
+		})
+		if tc.err == "" {
+			require.NoError(t, err)
+			continue
+		}
+
+		anyErr := anymind.ParameterError(nil)
+		require.ErrorAs(t, err, &anyErr)
+		require.Equal(t, anymind.ParameterErr, anyErr.Type)
+		require.ErrorContains(t, err, tc.err)
+	}
+
+	require.Equal(t, []string{"ETH", "ETH"}, deposited)
+	// found asset is cached.
+	require.Equal(t, 2, lookups)
+}
+
+func TestDepositDefaultAsset(t *testing.T) {
+	persistSvc := &mock.PersistenceServiceMock{
+		DepositFunc: func(_ context.Context, input *anymind.DepositInput) error {
+			require.Equal(t, anymind.DefaultAsset, input.Asset)
+
+			return nil
+		},
+	}
+
+	svc := NewService(persistSvc)
+
+	err := svc.Deposit(context.Background(), &anymind.DepositInput{DateTime: time.Now(), Amount: mustApd("0.00000001")})
+	require.NoError(t, err)
+
+	err = svc.Deposit(context.Background(), &anymind.DepositInput{DateTime: time.Now(), Amount: mustApd("0.000000001")})
+	require.ErrorContains(t, err, "BTC amount can have at most 8 decimal places")
+}
+
+func TestHistoricalQuote(t *testing.T) {
+	ctx := context.Background()
+	assets := &mock.AssetServiceMock{
+		AssetFunc: func(_ context.Context, code string) (*anymind.Asset, error) {
+			switch code {
+			case "BTC":
+				return &anymind.Asset{Code: "BTC", Scale: 8, DisplayPrecision: 8}, nil
+			case "USD":
+				return &anymind.Asset{Code: "USD", Scale: 2, DisplayPrecision: 2}, nil
+			default:
+				return nil, anymind.ErrNotFound
+			}
+		},
+	}
+	persistSvc := &mock.PersistenceServiceMock{
+		HistoricalFunc: func(_ context.Context, _ *anymind.HistoricalDataReq) ([]*anymind.HistoricalData, error) {
+			return []*anymind.HistoricalData{
+				{DateTime: mustTime("2020-01-01T10:00:00Z"), Amount: mustApd("0.12345678")},
+			}, nil
+		},
+	}
+	prices := &mock.PriceServiceMock{
+		RatesFunc: func(_ context.Context, base string, quote string, _ time.Time, _ time.Time) ([]*anymind.Rate, error) {
+			require.Equal(t, "BTC", base)
+			require.Equal(t, "USD", quote)
+
+			return []*anymind.Rate{
+				{Base: "BTC", Quote: "USD", Hour: mustTime("2020-01-01T10:00:00Z"), Rate: mustApd("40500.5")},
+				{Base: "BTC", Quote: "USD", Hour: mustTime("2020-01-01T12:00:00Z"), Rate: mustApd("40000")},
+			}, nil
+		},
+	}
+
+	svc := NewService(persistSvc, WithAssetService(assets), WithPriceService(prices))
+
+	res, err := svc.Historical(ctx, &anymind.HistoricalDataReq{
+		Start: mustTime("2020-01-01T10:00:00Z"),
+		End:   mustTime("2020-01-01T12:00:00Z"),
+		Quote: "USD",
+	})
+	require.NoError(t, err)
+	require.Len(t, res, 3)
+
+	// 0.12345678 * 40500.5 = 5000.0613...
+	require.Equal(t, "5000.06", fmt.Sprintf("%f", res[0].Value))
+	// missing rate is not carried from the previous hour.
+	require.Nil(t, res[1].Value)
+	require.Equal(t, "0.12345678", fmt.Sprintf("%f", &res[1].Amount))
+	require.Equal(t, "4938.27", fmt.Sprintf("%f", res[2].Value))
+
+	for _, quote := range []string{"EUR", "BTC"} {
+		_, err = svc.Historical(ctx, &anymind.HistoricalDataReq{Start: time.Now(), End: time.Now(), Quote: quote})
+		anyErr := anymind.ParameterError(nil)
+		require.ErrorAs(t, err, &anyErr)
+		require.Equal(t, anymind.ParameterErr, anyErr.Type)
+	}
+
+	prices.RatesFunc = func(_ context.Context, _ string, _ string, _ time.Time, _ time.Time) ([]*anymind.Rate, error) {
+		return nil, errors.New("db down")
+	}
+	_, err = svc.Historical(ctx, &anymind.HistoricalDataReq{Start: time.Now(), End: time.Now(), Quote: "USD"})
+	anyErr := anymind.InternalError(nil)
+	require.ErrorAs(t, err, &anyErr)
+	require.Equal(t, anymind.InternalErr, anyErr.Type)
+}
+
+func TestStats(t *testing.T) {
+	ctx := context.Background()
+	var got *anymind.HistoricalDataReq
+	stats := &mock.StatsServiceMock{
+		StatsFunc: func(_ context.Context, req *anymind.HistoricalDataReq) ([]*anymind.BucketStats, error) {
+			got = req
+
+			return []*anymind.BucketStats{{DateTime: req.End}}, nil
+		},
+	}
+
+	svc := NewService(&mock.PersistenceServiceMock{}, WithStatsService(stats))
+
+	res, err := svc.Stats(ctx, &anymind.HistoricalDataReq{
+		Start: mustTime("2020-01-01T10:00:00Z"),
+		End:   mustTime("2020-01-01T12:00:00Z"),
+	})
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	require.Equal(t, "BTC", got.Asset)
+
+	testCases := []*anymind.HistoricalDataReq{
+		{Start: mustTime("2020-01-02T00:00:00Z"), End: mustTime("2020-01-01T00:00:00Z")},
+		{Start: mustTime("2020-01-01T00:00:00Z"), End: mustTime("2020-03-01T00:00:00Z")},
+		{Start: mustTime("2020-01-01T00:00:00Z"), End: mustTime("2020-01-01T00:00:00Z"), Quote: "USD"},
+		{Start: mustTime("2020-01-01T00:00:00Z"), End: mustTime("2020-01-01T00:00:00Z"), Asset: "ETH"},
+	}
+	for _, req := range testCases {
+		_, err = svc.Stats(ctx, req)
+		anyErr := anymind.ParameterError(nil)
+		require.ErrorAs(t, err, &anyErr)
+		require.Equal(t, anymind.ParameterErr, anyErr.Type)
+	}
+}
+
+func TestCandles(t *testing.T) {
+	ctx := context.Background()
+	var got *anymind.CandleReq
+	candles := &mock.CandleServiceMock{
+		CandlesFunc: func(_ context.Context, req *anymind.CandleReq) ([]*anymind.Candle, error) {
+			got = req
+
+			return []*anymind.Candle{{DateTime: req.End}}, nil
+		},
+	}
+
+	svc := NewService(&mock.PersistenceServiceMock{}, WithCandleService(candles))
+
+	res, err := svc.Candles(ctx, &anymind.CandleReq{
+		Start: mustTime("2020-01-01T10:00:00Z"),
+		End:   mustTime("2020-01-01T12:00:00Z"),
+	})
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	require.Equal(t, time.Hour, got.Granularity)
+	require.Equal(t, "BTC", got.Asset)
+
+	testCases := []*anymind.CandleReq{
+		{Start: mustTime("2020-01-02T00:00:00Z"), End: mustTime("2020-01-01T00:00:00Z")},
+		{Start: mustTime("2020-01-01T00:00:00Z"), End: mustTime("2020-01-02T00:00:00Z"), Granularity: 5 * time.Hour},
+		{Start: mustTime("2020-01-01T00:00:00Z"), End: mustTime("2020-01-02T00:00:00Z"), Granularity: 30 * time.Minute},
+		{Start: mustTime("2020-01-01T00:00:00Z"), End: mustTime("2021-01-01T00:00:00Z")},
+		{Start: mustTime("2020-01-01T00:00:00Z"), End: mustTime("2020-01-01T00:00:00Z"), Asset: "ETH"},
+	}
+	for _, req := range testCases {
+		_, err = svc.Candles(ctx, req)
+		anyErr := anymind.ParameterError(nil)
+		require.ErrorAs(t, err, &anyErr)
+		require.Equal(t, anymind.ParameterErr, anyErr.Type)
+	}
+}
+
+func TestDepositAnomaly(t *testing.T) {
+	ctx := context.Background()
+
+	for _, hold := range []bool{false, true} {
+		hold := hold
+
+		t.Run(fmt.Sprintf("hold %t", hold), func(t *testing.T) {
+			var calls []string
+			persistSvc := &mock.PersistenceServiceMock{
+				DepositFunc: func(_ context.Context, input *anymind.DepositInput) error {
+					calls = append(calls, "deposit")
+					input.ID = int64(len(calls))
+
+					return nil
+				},
+			}
+			detector := &mock.AnomalyDetectorMock{
+				InspectFunc: func(_ context.Context, input *anymind.DepositInput) (*anymind.Anomaly, error) {
+					calls = append(calls, "inspect")
+					if input.Amount.Cmp(apd.New(100, 0)) < 0 {
+						return nil, nil
+					}
+
+					return &anymind.Anomaly{Reason: anymind.AnomalyReasonAmount, Amount: input.Amount}, nil
+				},
+				ObserveFunc: func(_ context.Context, _ *anymind.DepositInput) error {
+					calls = append(calls, "observe")
+
+					return nil
+				},
+			}
+			var recorded []*anymind.Anomaly
+			anomalies := &mock.AnomalyServiceMock{
+				RecordAnomalyFunc: func(_ context.Context, anomaly *anymind.Anomaly) error {
+					recorded = append(recorded, anomaly)
+					anomaly.ID = int64(len(recorded))
+
+					return nil
+				},
+			}
+
+			svc := NewService(persistSvc, WithAnomalyDetector(detector), WithAnomalyService(anomalies), WithAnomalyHold(hold))
+
+			require.NoError(t, svc.Deposit(ctx, &anymind.DepositInput{DateTime: time.Now(), Amount: mustApd("1")}))
+			input := &anymind.DepositInput{DateTime: time.Now(), Amount: mustApd("500")}
+			require.NoError(t, svc.Deposit(ctx, input))
+			require.Len(t, recorded, 1)
+
+			if hold {
+				// held deposit is not observed, so it does not widen the pattern.
+				require.Equal(t, []string{"inspect", "deposit", "observe", "inspect"}, calls)
+				require.Equal(t, anymind.AnomalyHeld, recorded[0].Status)
+				require.Equal(t, anymind.DepositConfirmed, recorded[0].DepositStatus)
+				// depositor is given the anomaly as reference of held deposit.
+				require.Equal(t, int64(0), input.ID)
+				require.Equal(t, int64(1), input.AnomalyID)
+
+				// held pending deposit is released as pending.
+				svc = NewService(persistSvc, WithAnomalyDetector(detector), WithAnomalyService(anomalies), WithAnomalyHold(hold),
+					WithDepositStatusService(&mock.DepositStatusServiceMock{}))
+				require.NoError(t, svc.Deposit(ctx, &anymind.DepositInput{
+					DateTime: time.Now(), Amount: mustApd("500"), Status: anymind.DepositPending}))
+				require.Len(t, recorded, 2)
+				require.Equal(t, anymind.DepositPending, recorded[1].DepositInput().Status)
+			} else {
+				require.Equal(t, []string{"deposit", "inspect", "observe", "deposit", "inspect", "observe"}, calls)
+				require.Equal(t, anymind.AnomalyFlagged, recorded[0].Status)
+				require.Equal(t, input.ID, recorded[0].DepositID)
+				require.Equal(t, int64(0), input.AnomalyID)
+			}
+		})
+	}
+}
+
+func TestReviewAnomaly(t *testing.T) {
+	ctx := context.Background()
+	anomalies := &mock.AnomalyServiceMock{
+		ReviewAnomalyFunc: func(_ context.Context, id int64, status string) (*anymind.Anomaly, error) {
+			switch id {
+			case 1:
+				return &anymind.Anomaly{ID: id, Status: status}, nil
+			case 3:
+				return &anymind.Anomaly{ID: id, Status: status, DepositStatus: anymind.DepositPending}, nil
+			}
+
+			return nil, anymind.ErrNotFound
+		},
+	}
+
+	detector := &mock.AnomalyDetectorMock{
+		ObserveFunc: func(_ context.Context, _ *anymind.DepositInput) error {
+			return nil
+		},
+	}
+
+	svc := NewService(&mock.PersistenceServiceMock{}, WithAnomalyService(anomalies), WithAnomalyDetector(detector))
+
+	res, err := svc.ReviewAnomaly(ctx, 1, anymind.AnomalyReleased)
+	require.NoError(t, err)
+	require.Equal(t, anymind.AnomalyReleased, res.Status)
+
+	// only released deposit is credited, so only it is observed, pending one once it is confirmed.
+	_, err = svc.ReviewAnomaly(ctx, 1, anymind.AnomalyRejected)
+	require.NoError(t, err)
+	res, err = svc.ReviewAnomaly(ctx, 3, anymind.AnomalyReleased)
+	require.NoError(t, err)
+	require.Equal(t, anymind.DepositPending, res.DepositInput().Status)
+	require.Len(t, detector.ObserveCalls(), 1)
+
+	_, err = svc.ReviewAnomaly(ctx, 2, anymind.AnomalyRejected)
+	anyErr := anymind.ParameterError(nil)
+	require.ErrorAs(t, err, &anyErr)
+	require.Equal(t, anymind.NotFoundErr, anyErr.Type)
+
+	_, err = svc.ReviewAnomaly(ctx, 1, anymind.AnomalyHeld)
+	require.ErrorAs(t, err, &anyErr)
+	require.Equal(t, anymind.ParameterErr, anyErr.Type)
+
+	_, err = svc.ListAnomalies(ctx, "open", 10)
+	require.ErrorAs(t, err, &anyErr)
+	require.Equal(t, anymind.ParameterErr, anyErr.Type)
+}
+
+func TestDepositPending(t *testing.T) {
+	ctx := context.Background()
+	var stored []*anymind.DepositInput
+	persistSvc := &mock.PersistenceServiceMock{
+		DepositFunc: func(_ context.Context, input *anymind.DepositInput) error {
+			stored = append(stored, input)
+
+			return nil
+		},
+	}
+
+	input := func(status string) *anymind.DepositInput {
+		return &anymind.DepositInput{DateTime: mustTime("2019-10-05T14:45:05+07:00"), Amount: mustApd("1.5"), Status: status}
+	}
+
+	// pending deposit can not be settled without deposit status service.
+	err := NewService(persistSvc).Deposit(ctx, input(anymind.DepositPending))
+	anyErr := anymind.ParameterError(nil)
+	require.ErrorAs(t, err, &anyErr)
+	require.Equal(t, anymind.ParameterErr, anyErr.Type)
+
+	svc := NewService(persistSvc, WithDepositStatusService(&mock.DepositStatusServiceMock{}))
+	require.NoError(t, svc.Deposit(ctx, input(anymind.DepositPending)))
+	require.NoError(t, svc.Deposit(ctx, input("")))
+	require.Len(t, stored, 2)
+	require.Equal(t, anymind.DepositPending, stored[0].Status)
+
+	err = svc.Deposit(ctx, input(anymind.DepositFailed))
+	require.ErrorAs(t, err, &anyErr)
+	require.Equal(t, anymind.ParameterErr, anyErr.Type)
+	require.Len(t, stored, 2)
+}
+
+func TestSetDepositStatus(t *testing.T) {
+	ctx := context.Background()
+	depositStatus := &mock.DepositStatusServiceMock{
+		SetDepositStatusFunc: func(_ context.Context, id int64, status string) (*anymind.DepositEvent, error) {
+			if id != 1 {
+				return nil, anymind.ErrNotFound
+			}
+
+			return &anymind.DepositEvent{ID: id, Amount: mustApd("1.5")}, nil
+		},
+	}
+
+	svc := NewService(&mock.PersistenceServiceMock{}, WithDepositStatusService(depositStatus))
+
+	res, err := svc.SetDepositStatus(ctx, 1, anymind.DepositConfirmed)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), res.ID)
+
+	_, err = svc.SetDepositStatus(ctx, 2, anymind.DepositFailed)
+	anyErr := anymind.ParameterError(nil)
+	require.ErrorAs(t, err, &anyErr)
+	require.Equal(t, anymind.NotFoundErr, anyErr.Type)
+
+	_, err = svc.SetDepositStatus(ctx, 1, anymind.DepositPending)
+	require.ErrorAs(t, err, &anyErr)
+	require.Equal(t, anymind.ParameterErr, anyErr.Type)
+	require.Len(t, depositStatus.SetDepositStatusCalls(), 2)
+}
@@ -0,0 +1,11 @@
+package apikey
+
+import "go.uber.org/zap"
+
+type Option func(*Service)
+
+func WithLogger(logger *zap.Logger) Option {
+	return func(svc *Service) {
+		svc.logger = logger
+	}
+}
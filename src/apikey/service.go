@@ -0,0 +1,147 @@
+package apikey
+
+import (
+	"anymind"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"strings"
+	"time"
+)
+
+var _ anymind.Authenticator = &Service{}
+
+// KeyPrefix is prepended to every generated key, so leaked key is easy to recognize.
+const KeyPrefix = "amk_"
+
+const displayPrefixLength = len(KeyPrefix) + 6
+
+var ErrInvalidKey = errors.New("invalid api key")
+
+var knownScopes = map[string]bool{
+	anymind.ScopeDepositWrite: true,
+	anymind.ScopeHistoryRead:  true,
+	anymind.ScopeAdmin:        true,
+}
+
+// Service issue api keys and authenticate request using them.
+type Service struct {
+	store  anymind.APIKeyPersistenceService
+	logger *zap.Logger
+	now    func() time.Time
+}
+
+func NewService(store anymind.APIKeyPersistenceService, opts ...Option) *Service {
+	s := &Service{
+		store: store,
+		now:   time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if s.logger == nil {
+		s.logger = zap.NewNop()
+	}
+
+	return s
+}
+
+// HashKey return hash stored in place of the plain key.
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// Create generate new key, plain key is only returned here.
+func (s *Service) Create(ctx context.Context, name string, scopes []string) (string, *anymind.APIKey, error) {
+	if name == "" {
+		return "", nil, anymind.ParameterError(errors.New("empty key name"))
+	}
+
+	if len(scopes) == 0 {
+		return "", nil, anymind.ParameterError(errors.New("empty key scopes"))
+	}
+
+	for _, scope := range scopes {
+		if !knownScopes[scope] {
+			return "", nil, anymind.ParameterError(fmt.Errorf("unknown scope %q", scope))
+		}
+	}
+
+	secret := make([]byte, 32)
+	_, err := rand.Read(secret)
+	if err != nil {
+		return "", nil, anymind.InternalError(err)
+	}
+
+	plain := KeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+	key := &anymind.APIKey{
+		Name:   name,
+		Prefix: plain[:displayPrefixLength],
+		Hash:   HashKey(plain),
+		Scopes: scopes,
+	}
+
+	err = s.store.CreateAPIKey(ctx, key)
+	if err != nil {
+		return "", nil, anymind.InternalError(err)
+	}
+
+	return plain, key, nil
+}
+
+func (s *Service) List(ctx context.Context) ([]*anymind.APIKey, error) {
+	keys, err := s.store.ListAPIKeys(ctx)
+	if err != nil {
+		return nil, anymind.InternalError(err)
+	}
+
+	return keys, nil
+}
+
+func (s *Service) Revoke(ctx context.Context, id int64) error {
+	err := s.store.RevokeAPIKey(ctx, id, s.now())
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, anymind.ErrNotFound):
+		return anymind.NotFoundError(err)
+	default:
+		return anymind.InternalError(err)
+	}
+}
+
+func (s *Service) Authenticate(ctx context.Context, credential string) (*anymind.Principal, error) {
+	if !strings.HasPrefix(credential, KeyPrefix) {
+		return nil, anymind.UnauthorizedError(ErrInvalidKey)
+	}
+
+	key, err := s.store.FindAPIKey(ctx, HashKey(credential))
+	if errors.Is(err, anymind.ErrNotFound) {
+		return nil, anymind.UnauthorizedError(ErrInvalidKey)
+	}
+
+	if err != nil {
+		return nil, anymind.InternalError(err)
+	}
+
+	if key.RevokedAt != nil {
+		s.logger.Warn("revoked api key used", zap.Int64("key", key.ID))
+
+		return nil, anymind.UnauthorizedError(ErrInvalidKey)
+	}
+
+	return &anymind.Principal{
+		Subject: "apikey:" + key.Name,
+		KeyID:   key.ID,
+		Scopes:  key.Scopes,
+	}, nil
+}
@@ -0,0 +1,101 @@
+package apikey
+
+import (
+	"anymind"
+	"anymind/src/mock"
+	"context"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+	"time"
+)
+
+func memoryStore() *mock.APIKeyPersistenceServiceMock {
+	keys := map[string]*anymind.APIKey{}
+
+	return &mock.APIKeyPersistenceServiceMock{
+		CreateAPIKeyFunc: func(_ context.Context, key *anymind.APIKey) error {
+			key.ID = int64(len(keys) + 1)
+			keys[key.Hash] = key
+
+			return nil
+		},
+		FindAPIKeyFunc: func(_ context.Context, hash string) (*anymind.APIKey, error) {
+			key, ok := keys[hash]
+			if !ok {
+				return nil, anymind.ErrNotFound
+			}
+
+			return key, nil
+		},
+		RevokeAPIKeyFunc: func(_ context.Context, id int64, at time.Time) error {
+			for _, key := range keys {
+				if key.ID == id {
+					key.RevokedAt = &at
+
+					return nil
+				}
+			}
+
+			return anymind.ErrNotFound
+		},
+	}
+}
+
+func requireErrType(t *testing.T, errType int, err error) {
+	anyErr := anymind.ParameterError(nil)
+	require.ErrorAs(t, err, &anyErr)
+	require.Equal(t, errType, anyErr.Type)
+}
+
+func TestCreateInvalid(t *testing.T) {
+	svc := NewService(memoryStore())
+	ctx := context.Background()
+
+	_, _, err := svc.Create(ctx, "", []string{anymind.ScopeAdmin})
+	requireErrType(t, anymind.ParameterErr, err)
+
+	_, _, err = svc.Create(ctx, "partner", nil)
+	requireErrType(t, anymind.ParameterErr, err)
+
+	_, _, err = svc.Create(ctx, "partner", []string{"deposit:delete"})
+	requireErrType(t, anymind.ParameterErr, err)
+}
+
+func TestAuthenticate(t *testing.T) {
+	store := memoryStore()
+	svc := NewService(store)
+	ctx := context.Background()
+
+	plain, key, err := svc.Create(ctx, "partner", []string{anymind.ScopeDepositWrite})
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(plain, KeyPrefix))
+	require.True(t, strings.HasPrefix(plain, key.Prefix))
+	require.NotContains(t, key.Hash, plain)
+
+	principal, err := svc.Authenticate(ctx, plain)
+	require.NoError(t, err)
+	require.Equal(t, key.ID, principal.KeyID)
+	require.Equal(t, "apikey:partner", principal.Subject)
+	require.True(t, principal.HasScope(anymind.ScopeDepositWrite))
+	require.False(t, principal.HasScope(anymind.ScopeHistoryRead))
+
+	_, err = svc.Authenticate(ctx, plain+"x")
+	requireErrType(t, anymind.UnauthorizedErr, err)
+
+	_, err = svc.Authenticate(ctx, "not-a-key")
+	requireErrType(t, anymind.UnauthorizedErr, err)
+
+	require.NoError(t, svc.Revoke(ctx, key.ID))
+	_, err = svc.Authenticate(ctx, plain)
+	requireErrType(t, anymind.UnauthorizedErr, err)
+
+	requireErrType(t, anymind.NotFoundErr, svc.Revoke(ctx, 99))
+}
+
+func TestAdminScope(t *testing.T) {
+	p := &anymind.Principal{Scopes: []string{anymind.ScopeAdmin}}
+
+	require.True(t, p.HasScope(anymind.ScopeDepositWrite))
+	require.True(t, p.HasScope(anymind.ScopeHistoryRead))
+}
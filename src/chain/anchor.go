@@ -0,0 +1,115 @@
+package chain
+
+import (
+	"anymind"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+)
+
+// anchor is checkpoint as appended to anchor file, one json object per line.
+type anchor struct {
+	ID        int64     `json:"id"`
+	At        time.Time `json:"at"`
+	LastID    int64     `json:"lastId"`
+	HeadHash  string    `json:"headHash"`
+	Signature []byte    `json:"signature"`
+}
+
+// appendAnchor append checkpoint to anchor file, nothing is written when anchor file is not set.
+func (s *Service) appendAnchor(checkpoint *anymind.ChainCheckpoint) error {
+	if s.anchorFile == "" {
+		return nil
+	}
+
+	line, err := json.Marshal(&anchor{
+		ID:        checkpoint.ID,
+		At:        checkpoint.At,
+		LastID:    checkpoint.LastID,
+		HeadHash:  checkpoint.HeadHash,
+		Signature: checkpoint.Signature,
+	})
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(s.anchorFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+
+	_, err = f.Write(append(line, '\n'))
+	if err != nil {
+		f.Close()
+
+		return err
+	}
+
+	err = f.Sync()
+	if err != nil {
+		f.Close()
+
+		return err
+	}
+
+	return f.Close()
+}
+
+// readAnchors return checkpoints of anchor file, file that does not exist yet has none.
+func (s *Service) readAnchors() ([]*anchor, error) {
+	if s.anchorFile == "" {
+		return nil, nil
+	}
+
+	content, err := os.ReadFile(s.anchorFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var res []*anchor
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		var a anchor
+		err = json.Unmarshal(scanner.Bytes(), &a)
+		if err != nil {
+			return nil, fmt.Errorf("anchor file line %d: %w", line, err)
+		}
+
+		res = append(res, &a)
+	}
+
+	return res, scanner.Err()
+}
+
+// missingAnchor return first anchored checkpoint that is not stored as it was anchored, nil when every one is.
+func missingAnchor(anchors []*anchor, checkpoints []*anymind.ChainCheckpoint) *anchor {
+	stored := make(map[int64]*anymind.ChainCheckpoint, len(checkpoints))
+	for _, checkpoint := range checkpoints {
+		stored[checkpoint.ID] = checkpoint
+	}
+
+	for _, a := range anchors {
+		checkpoint, ok := stored[a.ID]
+		if !ok ||
+			checkpoint.LastID != a.LastID ||
+			checkpoint.HeadHash != a.HeadHash ||
+			!checkpoint.At.Equal(a.At) ||
+			!bytes.Equal(checkpoint.Signature, a.Signature) {
+			return a
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,37 @@
+package chain
+
+import (
+	"crypto/ed25519"
+	"go.uber.org/zap"
+	"time"
+)
+
+type Option func(*Service)
+
+func WithLogger(logger *zap.Logger) Option {
+	return func(svc *Service) {
+		svc.logger = logger
+	}
+}
+
+// WithSigningKey sign checkpoints with key, checkpoint signatures are checked against its public key.
+func WithSigningKey(key ed25519.PrivateKey) Option {
+	return func(svc *Service) {
+		svc.key = key
+	}
+}
+
+// WithInterval set how often chain head is checkpointed.
+func WithInterval(interval time.Duration) Option {
+	return func(svc *Service) {
+		svc.interval = interval
+	}
+}
+
+// WithAnchorFile append every checkpoint to file outside the database, verification fail when one of them is no
+// longer in the database.
+func WithAnchorFile(path string) Option {
+	return func(svc *Service) {
+		svc.anchorFile = path
+	}
+}
@@ -0,0 +1,173 @@
+package chain
+
+import (
+	"anymind"
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"time"
+)
+
+var _ anymind.ChainService = &Service{}
+
+// Service checkpoint head of deposit chain and verify the chain.
+type Service struct {
+	store    anymind.ChainPersistenceService
+	key      ed25519.PrivateKey
+	interval time.Duration
+	// anchorFile is file outside the database checkpoints are appended to, see WithAnchorFile.
+	anchorFile string
+	logger     *zap.Logger
+	now        func() time.Time
+}
+
+func NewService(store anymind.ChainPersistenceService, opts ...Option) *Service {
+	s := &Service{
+		store:    store,
+		interval: time.Hour,
+		now:      time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if s.logger == nil {
+		s.logger = zap.NewNop()
+	}
+
+	return s
+}
+
+// ParseSigningKey decode base64 ed25519 key, either 32 bytes seed or 64 bytes private key.
+func ParseSigningKey(encoded string) (ed25519.PrivateKey, error) {
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("signing key is not base64: %w", err)
+	}
+
+	switch len(raw) {
+	case ed25519.SeedSize:
+		return ed25519.NewKeyFromSeed(raw), nil
+	case ed25519.PrivateKeySize:
+		return ed25519.PrivateKey(raw), nil
+	default:
+		return nil, fmt.Errorf("signing key must be %d or %d bytes", ed25519.SeedSize, ed25519.PrivateKeySize)
+	}
+}
+
+// Start checkpoint chain head every interval until ctx is done, head is only checkpointed when it moved.
+func (s *Service) Start(ctx context.Context) error {
+	if s.key == nil {
+		return errors.New("checkpoint require signing key")
+	}
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	// settlement move the head without a new deposit, head is compared by hash.
+	var lastHash string
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		_, headHash, err := s.store.DepositChainHead(ctx)
+		if err != nil {
+			s.logger.Error("failed to read deposit chain head", zap.Error(err))
+
+			continue
+		}
+
+		if headHash == lastHash {
+			continue
+		}
+
+		checkpoint, err := s.Checkpoint(ctx)
+		if err != nil {
+			s.logger.Error("failed to checkpoint deposit chain", zap.Error(err))
+
+			continue
+		}
+
+		lastHash = checkpoint.HeadHash
+	}
+}
+
+// Checkpoint sign and store current chain head, nothing is stored while there is no deposit.
+func (s *Service) Checkpoint(ctx context.Context) (*anymind.ChainCheckpoint, error) {
+	if s.key == nil {
+		return nil, errors.New("checkpoint require signing key")
+	}
+
+	headID, headHash, err := s.store.DepositChainHead(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	checkpoint := &anymind.ChainCheckpoint{
+		// database keep microsecond, signature must be computed on what is stored.
+		At:       s.now().UTC().Truncate(time.Microsecond),
+		LastID:   headID,
+		HeadHash: headHash,
+	}
+	if headID == 0 {
+		return checkpoint, nil
+	}
+
+	checkpoint.Sign(s.key)
+
+	err = s.store.SaveChainCheckpoint(ctx, checkpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.appendAnchor(checkpoint)
+	if err != nil {
+		return nil, fmt.Errorf("anchor checkpoint %d: %w", checkpoint.ID, err)
+	}
+
+	s.logger.Info("deposit chain checkpointed", zap.Int64("last_id", checkpoint.LastID), zap.String("head_hash", checkpoint.HeadHash))
+
+	return checkpoint, nil
+}
+
+// VerifyChain check anchored checkpoints are still stored, checkpoint signatures, then walk the chain.
+// Signatures are not checked without signing key.
+func (s *Service) VerifyChain(ctx context.Context) (*anymind.ChainReport, error) {
+	checkpoints, err := s.store.ListChainCheckpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	anchors, err := s.readAnchors()
+	if err != nil {
+		return nil, err
+	}
+
+	if missing := missingAnchor(anchors, checkpoints); missing != nil {
+		return &anymind.ChainReport{
+			Checkpoints: len(checkpoints),
+			Break:       &anymind.ChainBreak{ID: missing.ID, Checkpoint: true, Reason: "anchored checkpoint was removed or altered"},
+		}, nil
+	}
+
+	if s.key != nil {
+		public := s.key.Public().(ed25519.PublicKey)
+		for _, checkpoint := range checkpoints {
+			if !ed25519.Verify(public, checkpoint.SignedMessage(), checkpoint.Signature) {
+				return &anymind.ChainReport{
+					Checkpoints: len(checkpoints),
+					Break:       &anymind.ChainBreak{ID: checkpoint.ID, Checkpoint: true, Reason: "signature is invalid"},
+				}, nil
+			}
+		}
+	}
+
+	return s.store.VerifyDepositChain(ctx, checkpoints)
+}
@@ -0,0 +1,131 @@
+package chain
+
+import (
+	"anymind"
+	"anymind/src/mock"
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"github.com/stretchr/testify/require"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newStore(headID int64, saved *[]*anymind.ChainCheckpoint) *mock.ChainPersistenceServiceMock {
+	return &mock.ChainPersistenceServiceMock{
+		DepositChainHeadFunc: func(_ context.Context) (int64, string, error) {
+			if headID == 0 {
+				return 0, "", nil
+			}
+
+			return headID, "head", nil
+		},
+		SaveChainCheckpointFunc: func(_ context.Context, checkpoint *anymind.ChainCheckpoint) error {
+			checkpoint.ID = int64(len(*saved) + 1)
+			*saved = append(*saved, checkpoint)
+
+			return nil
+		},
+		ListChainCheckpointsFunc: func(_ context.Context) ([]*anymind.ChainCheckpoint, error) {
+			return *saved, nil
+		},
+		VerifyDepositChainFunc: func(_ context.Context, checkpoints []*anymind.ChainCheckpoint) (*anymind.ChainReport, error) {
+			return &anymind.ChainReport{Verified: int(headID), HeadID: headID, HeadHash: "head", Checkpoints: len(checkpoints)}, nil
+		},
+	}
+}
+
+func TestCheckpointAndVerify(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	var saved []*anymind.ChainCheckpoint
+	store := newStore(3, &saved)
+	svc := NewService(store, WithSigningKey(key))
+	svc.now = func() time.Time { return time.Date(2020, 1, 1, 0, 0, 0, 1500, time.UTC) }
+
+	checkpoint, err := svc.Checkpoint(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, int64(3), checkpoint.LastID)
+	require.Equal(t, "head", checkpoint.HeadHash)
+	require.Equal(t, time.Date(2020, 1, 1, 0, 0, 0, 1000, time.UTC), checkpoint.At)
+	require.Len(t, saved, 1)
+
+	report, err := svc.VerifyChain(context.Background())
+	require.NoError(t, err)
+	require.Nil(t, report.Break)
+	require.Equal(t, 1, report.Checkpoints)
+
+	// checkpoint rewritten along with the chain does not match its signature.
+	saved[0].HeadHash = "forged"
+	report, err = svc.VerifyChain(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, &anymind.ChainBreak{ID: 1, Checkpoint: true, Reason: "signature is invalid"}, report.Break)
+}
+
+func TestAnchorFile(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	var saved []*anymind.ChainCheckpoint
+	path := filepath.Join(t.TempDir(), "checkpoints.jsonl")
+	svc := NewService(newStore(3, &saved), WithSigningKey(key), WithAnchorFile(path))
+	ctx := context.Background()
+
+	_, err = svc.Checkpoint(ctx)
+	require.NoError(t, err)
+	_, err = svc.Checkpoint(ctx)
+	require.NoError(t, err)
+
+	anchors, err := svc.readAnchors()
+	require.NoError(t, err)
+	require.Len(t, anchors, 2)
+	require.Equal(t, saved[1].HeadHash, anchors[1].HeadHash)
+	require.Equal(t, saved[1].Signature, anchors[1].Signature)
+
+	report, err := svc.VerifyChain(ctx)
+	require.NoError(t, err)
+	require.Nil(t, report.Break)
+
+	// checkpoints truncated in database along with the chain tail.
+	saved = saved[:1]
+	report, err = svc.VerifyChain(ctx)
+	require.NoError(t, err)
+	require.Equal(t, &anymind.ChainBreak{ID: 2, Checkpoint: true, Reason: "anchored checkpoint was removed or altered"}, report.Break)
+}
+
+func TestCheckpointEmptyChain(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	var saved []*anymind.ChainCheckpoint
+	svc := NewService(newStore(0, &saved), WithSigningKey(key))
+
+	_, err = svc.Checkpoint(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, saved)
+
+	_, err = NewService(newStore(0, &saved)).Checkpoint(context.Background())
+	require.Error(t, err)
+}
+
+func TestParseSigningKey(t *testing.T) {
+	seed := make([]byte, ed25519.SeedSize)
+	seed[0] = 1
+	expected := ed25519.NewKeyFromSeed(seed)
+
+	key, err := ParseSigningKey(base64.StdEncoding.EncodeToString(seed))
+	require.NoError(t, err)
+	require.Equal(t, expected, key)
+
+	key, err = ParseSigningKey(base64.StdEncoding.EncodeToString(expected))
+	require.NoError(t, err)
+	require.Equal(t, expected, key)
+
+	_, err = ParseSigningKey("not base64!")
+	require.Error(t, err)
+
+	_, err = ParseSigningKey(base64.StdEncoding.EncodeToString([]byte("short")))
+	require.Error(t, err)
+}
@@ -0,0 +1,28 @@
+package changefeed
+
+import (
+	"go.uber.org/zap"
+	"time"
+)
+
+type Option func(*Service)
+
+func WithLogger(logger *zap.Logger) Option {
+	return func(svc *Service) {
+		svc.logger = logger
+	}
+}
+
+// WithReconnectDelay set waiting time before reconnecting a failed listener.
+func WithReconnectDelay(delay time.Duration) Option {
+	return func(svc *Service) {
+		svc.reconnectDelay = delay
+	}
+}
+
+// WithBufferSize set channel size of each subscriber, event is dropped when subscriber buffer is full.
+func WithBufferSize(size int) Option {
+	return func(svc *Service) {
+		svc.bufferSize = size
+	}
+}
@@ -0,0 +1,198 @@
+package changefeed
+
+import (
+	"anymind"
+	"anymind/src/persistence"
+	"context"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+	"sync"
+	"time"
+)
+
+var _ anymind.DepositFeed = &Service{}
+
+const backfillBatch = 500
+
+// HistoryStore provide deposit histories used to close the gap after reconnect.
+type HistoryStore interface {
+	LatestDepositSeq(ctx context.Context) (int64, error)
+	DepositsAfter(ctx context.Context, afterSeq int64, limit int) ([]*anymind.DepositEvent, error)
+}
+
+// Service listen postgres deposit notification and fan it out to local subscribers.
+type Service struct {
+	dsn            string
+	store          HistoryStore
+	logger         *zap.Logger
+	reconnectDelay time.Duration
+	bufferSize     int
+
+	mu          sync.Mutex
+	subscribers map[chan *anymind.DepositEvent]struct{}
+
+	// connected, lastSeq and backfilled is only accessed by Start goroutine.
+	connected  bool
+	lastSeq    int64
+	backfilled map[int64]struct{}
+}
+
+func NewService(dsn string, store HistoryStore, opts ...Option) *Service {
+	s := &Service{
+		dsn:            dsn,
+		store:          store,
+		reconnectDelay: 5 * time.Second,
+		bufferSize:     64,
+		subscribers:    map[chan *anymind.DepositEvent]struct{}{},
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if s.logger == nil {
+		s.logger = zap.NewNop()
+	}
+
+	return s
+}
+
+func (s *Service) Subscribe(ctx context.Context) <-chan *anymind.DepositEvent {
+	ch := make(chan *anymind.DepositEvent, s.bufferSize)
+
+	s.mu.Lock()
+	s.subscribers[ch] = struct{}{}
+	s.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+
+		s.mu.Lock()
+		delete(s.subscribers, ch)
+		close(ch)
+		s.mu.Unlock()
+	}()
+
+	return ch
+}
+
+// Start listen for notification until ctx is done, listener is reconnected when connection is lost.
+func (s *Service) Start(ctx context.Context) error {
+	for {
+		err := s.listen(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		s.logger.Error("change feed listener failed", zap.Error(err))
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(s.reconnectDelay):
+		}
+	}
+}
+
+func (s *Service) listen(ctx context.Context) error {
+	conn, err := pgx.Connect(ctx, s.dsn)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+
+	_, err = conn.Exec(ctx, "LISTEN "+persistence.DepositChannel)
+	if err != nil {
+		return err
+	}
+
+	// backfill only after LISTEN succeed, so no deposit committed in between is lost.
+	err = s.backfill(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info("change feed listening", zap.Int64("lastSeq", s.lastSeq))
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		ev, err := persistence.DecodeDepositEvent(n.Payload)
+		if err != nil {
+			s.logger.Error("invalid deposit notification", zap.String("payload", n.Payload), zap.Error(err))
+
+			continue
+		}
+
+		s.receive(ev)
+	}
+}
+
+// backfill publish every deposit committed since the last received one.
+// On first connect there is nothing to catch up, so it only record the latest seq.
+func (s *Service) backfill(ctx context.Context) error {
+	s.backfilled = map[int64]struct{}{}
+
+	// lastSeq stay 0 while no deposit is confirmed, it can not tell whether listener connected before.
+	if !s.connected {
+		seq, err := s.store.LatestDepositSeq(ctx)
+		if err != nil {
+			return err
+		}
+
+		s.connected, s.lastSeq = true, seq
+
+		return nil
+	}
+
+	for {
+		events, err := s.store.DepositsAfter(ctx, s.lastSeq, backfillBatch)
+		if err != nil {
+			return err
+		}
+
+		for _, ev := range events {
+			s.backfilled[ev.ID] = struct{}{}
+			s.advance(ev)
+			s.publish(ev)
+		}
+
+		if len(events) < backfillBatch {
+			return nil
+		}
+	}
+}
+
+// receive publish event unless it was already published by backfill.
+func (s *Service) receive(ev *anymind.DepositEvent) {
+	if _, ok := s.backfilled[ev.ID]; ok {
+		delete(s.backfilled, ev.ID)
+
+		return
+	}
+
+	s.advance(ev)
+	s.publish(ev)
+}
+
+func (s *Service) advance(ev *anymind.DepositEvent) {
+	if ev.Seq > s.lastSeq {
+		s.lastSeq = ev.Seq
+	}
+}
+
+func (s *Service) publish(ev *anymind.DepositEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for ch := range s.subscribers {
+		select {
+		case ch <- ev:
+		default:
+			s.logger.Warn("subscriber buffer full, dropping deposit event", zap.Int64("id", ev.ID))
+		}
+	}
+}
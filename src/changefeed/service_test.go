@@ -0,0 +1,144 @@
+package changefeed
+
+import (
+	"anymind"
+	"context"
+	"github.com/cockroachdb/apd"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+type historyStoreStub struct {
+	latest int64
+	events []*anymind.DepositEvent
+}
+
+func (h *historyStoreStub) LatestDepositSeq(_ context.Context) (int64, error) {
+	return h.latest, nil
+}
+
+func (h *historyStoreStub) DepositsAfter(_ context.Context, afterSeq int64, limit int) ([]*anymind.DepositEvent, error) {
+	var res []*anymind.DepositEvent
+	for _, ev := range h.events {
+		if ev.Seq > afterSeq && len(res) < limit {
+			res = append(res, ev)
+		}
+	}
+
+	return res, nil
+}
+
+func event(id int64) *anymind.DepositEvent {
+	return &anymind.DepositEvent{
+		ID:       id,
+		DateTime: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		Amount:   *apd.New(id, 0),
+		Seq:      id,
+	}
+}
+
+func receiveIDs(t *testing.T, ch <-chan *anymind.DepositEvent, n int) []int64 {
+	var res []int64
+	for i := 0; i < n; i++ {
+		select {
+		case ev := <-ch:
+			res = append(res, ev.ID)
+		case <-time.After(time.Second):
+			t.Fatalf("expected %d events, got %d", n, len(res))
+		}
+	}
+
+	return res
+}
+
+func TestFanOut(t *testing.T) {
+	svc := NewService("", &historyStoreStub{})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	first := svc.Subscribe(ctx)
+	second := svc.Subscribe(ctx)
+
+	svc.receive(event(1))
+	svc.receive(event(2))
+
+	require.Equal(t, []int64{1, 2}, receiveIDs(t, first, 2))
+	require.Equal(t, []int64{1, 2}, receiveIDs(t, second, 2))
+}
+
+func TestUnsubscribe(t *testing.T) {
+	svc := NewService("", &historyStoreStub{})
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ch := svc.Subscribe(ctx)
+	cancel()
+
+	select {
+	case _, ok := <-ch:
+		require.False(t, ok)
+	case <-time.After(time.Second):
+		t.Fatal("channel is not closed")
+	}
+}
+
+func TestBackfillAfterReconnect(t *testing.T) {
+	store := &historyStoreStub{latest: 2}
+	svc := NewService("", store)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch := svc.Subscribe(ctx)
+
+	// first connect only record latest seq.
+	require.NoError(t, svc.backfill(ctx))
+	require.Equal(t, int64(2), svc.lastSeq)
+
+	// deposits committed while listener was disconnected.
+	store.events = []*anymind.DepositEvent{event(1), event(2), event(3), event(4)}
+	require.NoError(t, svc.backfill(ctx))
+	require.Equal(t, []int64{3, 4}, receiveIDs(t, ch, 2))
+
+	// notification queued during backfill is not published twice.
+	svc.receive(event(4))
+	svc.receive(event(5))
+	require.Equal(t, []int64{5}, receiveIDs(t, ch, 1))
+	require.Equal(t, int64(5), svc.lastSeq)
+
+	// pending deposit confirmed after newer deposits is backfilled by its confirmation seq.
+	confirmed := event(1)
+	confirmed.Seq = 6
+	store.events = append(store.events, event(5), confirmed)
+	require.NoError(t, svc.backfill(ctx))
+	require.Equal(t, []int64{1}, receiveIDs(t, ch, 1))
+	require.Equal(t, int64(6), svc.lastSeq)
+}
+
+func TestBackfillFromEmptyFeed(t *testing.T) {
+	store := &historyStoreStub{}
+	svc := NewService("", store)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch := svc.Subscribe(ctx)
+
+	require.NoError(t, svc.backfill(ctx))
+	require.Equal(t, int64(0), svc.lastSeq)
+
+	// first deposits were committed while listener was disconnected.
+	store.events = []*anymind.DepositEvent{event(1), event(2)}
+	require.NoError(t, svc.backfill(ctx))
+	require.Equal(t, []int64{1, 2}, receiveIDs(t, ch, 2))
+}
+
+func TestSlowSubscriberDoesNotBlock(t *testing.T) {
+	svc := NewService("", &historyStoreStub{}, WithBufferSize(1))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch := svc.Subscribe(ctx)
+	svc.receive(event(1))
+	svc.receive(event(2))
+
+	require.Equal(t, []int64{1}, receiveIDs(t, ch, 1))
+}
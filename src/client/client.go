@@ -0,0 +1,299 @@
+package client
+
+import (
+	"anymind"
+	"anymind/src/reqsign"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/cockroachdb/apd"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var _ anymind.APIService = &Client{}
+
+const (
+	depositPath    = "/deposit"
+	historicalPath = "/historical"
+)
+
+// Client call remote http api, it can be used in place of local api.Service.
+type Client struct {
+	baseURL string
+	http    *http.Client
+	logger  *zap.Logger
+	apiKey  string
+	// signKeyID and signSecret sign request when set, see reqsign.Sign.
+	signKeyID  string
+	signSecret string
+	timeout    time.Duration
+	retries    int
+	retryWait  time.Duration
+}
+
+type depositRequest struct {
+	DateTime time.Time `json:"datetime"`
+	Amount   string    `json:"amount"`
+	Asset    string    `json:"asset,omitempty"`
+	Status   string    `json:"status,omitempty"`
+}
+
+type depositResponse struct {
+	ID int64 `json:"id"`
+}
+
+type historicalRequest struct {
+	Start          time.Time `json:"startDatetime"`
+	End            time.Time `json:"endDateTime"`
+	Asset          string    `json:"asset,omitempty"`
+	Quote          string    `json:"quote,omitempty"`
+	IncludePending bool      `json:"includePending,omitempty"`
+}
+
+type historicalEntry struct {
+	DateTime time.Time `json:"datetime"`
+	Amount   string    `json:"amount"`
+	Value    *string   `json:"value"`
+}
+
+type errorResponse struct {
+	Message string `json:"message"`
+}
+
+func NewClient(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported url scheme %q", u.Scheme)
+	}
+
+	c := &Client{
+		baseURL:   strings.TrimRight(u.String(), "/"),
+		http:      http.DefaultClient,
+		timeout:   10 * time.Second,
+		retries:   2,
+		retryWait: 200 * time.Millisecond,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.logger == nil {
+		c.logger = zap.NewNop()
+	}
+
+	return c, nil
+}
+
+// Deposit is not retried, since a retry after lost response could credit the amount twice.
+func (c *Client) Deposit(ctx context.Context, input *anymind.DepositInput) error {
+	body := &depositRequest{
+		DateTime: input.DateTime,
+		Amount:   fmt.Sprintf("%f", &input.Amount),
+		Asset:    input.Asset,
+		Status:   input.Status,
+	}
+
+	var res depositResponse
+	err := c.call(ctx, http.MethodPost, depositPath, body, &res, 0)
+	if err != nil {
+		return err
+	}
+	input.ID = res.ID
+
+	return nil
+}
+
+func (c *Client) Historical(ctx context.Context, req *anymind.HistoricalDataReq) ([]*anymind.HistoricalData, error) {
+	body := &historicalRequest{
+		Start:          req.Start,
+		End:            req.End,
+		Asset:          req.Asset,
+		Quote:          req.Quote,
+		IncludePending: req.IncludePending,
+	}
+
+	var entries []*historicalEntry
+	err := c.call(ctx, http.MethodPost, historicalPath, body, &entries, c.retries)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*anymind.HistoricalData, 0, len(entries))
+	for _, entry := range entries {
+		amount, _, err := apd.NewFromString(entry.Amount)
+		if err != nil {
+			return nil, anymind.InternalError(fmt.Errorf("invalid amount in response: %w", err))
+		}
+
+		data := &anymind.HistoricalData{
+			DateTime: entry.DateTime,
+			Amount:   *amount,
+		}
+
+		if entry.Value != nil {
+			data.Value, _, err = apd.NewFromString(*entry.Value)
+			if err != nil {
+				return nil, anymind.InternalError(fmt.Errorf("invalid value in response: %w", err))
+			}
+		}
+
+		res = append(res, data)
+	}
+
+	return res, nil
+}
+
+// call send request and decode json response into out, failed call is retried up to retries times
+// when the failure is transient.
+func (c *Client) call(ctx context.Context, method string, path string, in any, out any, retries int) error {
+	payload, err := json.Marshal(in)
+	if err != nil {
+		return anymind.ParameterError(err)
+	}
+
+	wait := c.retryWait
+	for attempt := 0; ; attempt++ {
+		retry, err := c.do(ctx, method, path, payload, out)
+		if err == nil || !retry || attempt >= retries {
+			return err
+		}
+
+		c.logger.Warn("retrying request", zap.String("path", path), zap.Int("attempt", attempt+1), zap.Error(err))
+
+		select {
+		case <-ctx.Done():
+			return anymind.InternalError(ctx.Err())
+		case <-time.After(wait):
+		}
+
+		wait *= 2
+	}
+}
+
+// do run a single attempt, it also report whether the failure is worth retrying.
+func (c *Client) do(ctx context.Context, method string, path string, payload []byte, out any) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return false, anymind.InternalError(err)
+	}
+
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Accept", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+	if id, ok := anymind.RequestIDFromContext(ctx); ok {
+		req.Header.Set(anymind.RequestIDHeader, id)
+	}
+
+	if c.signKeyID != "" {
+		err = c.sign(req, payload)
+		if err != nil {
+			return false, anymind.InternalError(err)
+		}
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return true, anymind.InternalError(err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return true, anymind.InternalError(err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+
+		return retry, decodeError(resp.StatusCode, body)
+	}
+
+	if out == nil {
+		return false, nil
+	}
+
+	err = json.Unmarshal(body, out)
+	if err != nil {
+		return false, anymind.InternalError(fmt.Errorf("invalid response: %w", err))
+	}
+
+	return false, nil
+}
+
+// sign set signature headers, every attempt is signed with fresh nonce so retry is not rejected as replay.
+func (c *Client) sign(req *http.Request, payload []byte) error {
+	nonce := make([]byte, 16)
+	_, err := rand.Read(nonce)
+	if err != nil {
+		return err
+	}
+
+	signed := &anymind.SignedRequest{
+		KeyID:     c.signKeyID,
+		Method:    req.Method,
+		Path:      req.URL.Path,
+		Timestamp: strconv.FormatInt(time.Now().Unix(), 10),
+		Nonce:     hex.EncodeToString(nonce),
+		Body:      payload,
+	}
+
+	req.Header.Set(anymind.SignatureKeyHeader, signed.KeyID)
+	req.Header.Set(anymind.SignatureTimestampHeader, signed.Timestamp)
+	req.Header.Set(anymind.SignatureNonceHeader, signed.Nonce)
+	req.Header.Set(anymind.SignatureHeader, reqsign.Sign(c.signSecret, signed))
+
+	return nil
+}
+
+// decodeError convert error response back into *anymind.Error.
+func decodeError(statusCode int, body []byte) error {
+	var resp errorResponse
+	err := json.Unmarshal(body, &resp)
+	if err != nil || resp.Message == "" {
+		resp.Message = http.StatusText(statusCode)
+	}
+
+	errType := anymind.InternalErr
+	switch statusCode {
+	case http.StatusBadRequest:
+		errType = anymind.ParameterErr
+	case http.StatusNotFound:
+		errType = anymind.NotFoundErr
+	case http.StatusUnauthorized:
+		errType = anymind.UnauthorizedErr
+	case http.StatusForbidden:
+		errType = anymind.ForbiddenErr
+	case http.StatusTooManyRequests:
+		errType = anymind.TooManyRequestsErr
+	}
+
+	return &anymind.Error{
+		Type:    errType,
+		Cause:   errors.New(resp.Message),
+		Message: resp.Message,
+	}
+}
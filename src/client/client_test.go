@@ -0,0 +1,235 @@
+package client
+
+import (
+	"anymind"
+	"anymind/src/httpapi"
+	"anymind/src/mock"
+	"anymind/src/reqsign"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/cockroachdb/apd"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func mustApd(number string) apd.Decimal {
+	val, _, err := apd.NewFromString(number)
+	if err != nil {
+		panic(err)
+	}
+
+	return *val
+}
+
+func mustTime(ts string) time.Time {
+	val, err := time.Parse(time.RFC3339, ts)
+	if err != nil {
+		panic(err)
+	}
+
+	return val
+}
+
+// newTestClient return client talking to httpapi router backed by given api mock.
+func newTestClient(t *testing.T, api anymind.APIService, opts ...Option) *Client {
+	srv := httptest.NewServer(httpapi.NewService(api).NewRouter())
+	t.Cleanup(srv.Close)
+
+	c, err := NewClient(srv.URL, opts...)
+	require.NoError(t, err)
+
+	return c
+}
+
+func TestDeposit(t *testing.T) {
+	c := newTestClient(t, &mock.APIServiceMock{
+		DepositFunc: func(_ context.Context, input *anymind.DepositInput) error {
+			require.Equal(t, "2020-01-01T01:01:01Z", input.DateTime.Format(time.RFC3339))
+			require.Equal(t, "0.00000001", fmt.Sprintf("%f", &input.Amount))
+
+			return nil
+		},
+	})
+
+	err := c.Deposit(context.Background(), &anymind.DepositInput{
+		DateTime: mustTime("2020-01-01T01:01:01Z"),
+		Amount:   mustApd("0.00000001"),
+	})
+
+	require.NoError(t, err)
+}
+
+func TestDepositError(t *testing.T) {
+	testCases := []struct {
+		name    string
+		err     error
+		errType int
+	}{
+		{
+			name:    "parameter error",
+			err:     anymind.ParameterError(errors.New("invalid amount")),
+			errType: anymind.ParameterErr,
+		},
+		{
+			name:    "internal error",
+			err:     anymind.InternalError(errors.New("db down")),
+			errType: anymind.InternalErr,
+		},
+		{
+			name:    "too many requests",
+			err:     anymind.TooManyRequestsError(errors.New("rate limit exceeded")),
+			errType: anymind.TooManyRequestsErr,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			calls := 0
+			c := newTestClient(t, &mock.APIServiceMock{
+				DepositFunc: func(_ context.Context, _ *anymind.DepositInput) error {
+					calls++
+
+					return tc.err
+				},
+			}, WithRetry(3, time.Millisecond))
+
+			err := c.Deposit(context.Background(), &anymind.DepositInput{
+				DateTime: time.Now(),
+				Amount:   mustApd("1"),
+			})
+
+			anyErr := anymind.ParameterError(nil)
+			require.ErrorAs(t, err, &anyErr)
+			require.Equal(t, tc.errType, anyErr.Type)
+			require.Equal(t, tc.err.Error(), anyErr.Error())
+			// deposit is never retried.
+			require.Equal(t, 1, calls)
+		})
+	}
+}
+
+func TestHistorical(t *testing.T) {
+	c := newTestClient(t, &mock.APIServiceMock{
+		HistoricalFunc: func(_ context.Context, req *anymind.HistoricalDataReq) ([]*anymind.HistoricalData, error) {
+			require.Equal(t, mustTime("2020-01-01T00:00:00Z"), req.Start)
+
+			return []*anymind.HistoricalData{
+				{
+					DateTime: mustTime("2020-01-01T01:00:00Z"),
+					Amount:   mustApd("123.456"),
+				},
+			}, nil
+		},
+	})
+
+	res, err := c.Historical(context.Background(), &anymind.HistoricalDataReq{
+		Start: mustTime("2020-01-01T00:00:00Z"),
+		End:   mustTime("2020-01-01T01:00:00Z"),
+	})
+
+	require.NoError(t, err)
+	require.Len(t, res, 2)
+	require.Equal(t, "0", fmt.Sprintf("%f", &res[0].Amount))
+	require.Equal(t, mustTime("2020-01-01T01:00:00Z"), res[1].DateTime)
+	require.Equal(t, "123.456", fmt.Sprintf("%f", &res[1].Amount))
+}
+
+func TestHistoricalRetry(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		w.Write([]byte(`[{"datetime": "2020-01-01T00:00:00Z", "amount": "1"}]`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL, WithRetry(2, time.Millisecond))
+	require.NoError(t, err)
+
+	res, err := c.Historical(context.Background(), &anymind.HistoricalDataReq{})
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	require.Equal(t, 3, calls)
+}
+
+func TestHistoricalRetryExhausted(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL, WithRetry(1, time.Millisecond))
+	require.NoError(t, err)
+
+	_, err = c.Historical(context.Background(), &anymind.HistoricalDataReq{})
+
+	anyErr := anymind.InternalError(nil)
+	require.ErrorAs(t, err, &anyErr)
+	require.Equal(t, anymind.InternalErr, anyErr.Type)
+	require.Equal(t, 2, calls)
+}
+
+func TestTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL, WithTimeout(10*time.Millisecond), WithRetry(0, 0))
+	require.NoError(t, err)
+
+	err = c.Deposit(context.Background(), &anymind.DepositInput{Amount: mustApd("1")})
+	require.Error(t, err)
+}
+
+func TestDepositSigned(t *testing.T) {
+	api := &mock.APIServiceMock{
+		DepositFunc: func(_ context.Context, _ *anymind.DepositInput) error {
+			return nil
+		},
+	}
+	nonces := &mock.NonceStoreMock{
+		ClaimNonceFunc: func(_ context.Context, _, _ string, _, _ time.Time) (bool, error) {
+			return true, nil
+		},
+		PruneNoncesFunc: func(_ context.Context, _ time.Time) error {
+			return nil
+		},
+	}
+	srv := httptest.NewServer(httpapi.NewService(api,
+		httpapi.WithRequestVerifier(reqsign.NewService(map[string]string{"partner": "secret"}, nonces)),
+	).NewRouter())
+	t.Cleanup(srv.Close)
+
+	input := &anymind.DepositInput{
+		DateTime: mustTime("2020-01-01T01:01:01Z"),
+		Amount:   mustApd("1"),
+	}
+
+	c, err := NewClient(srv.URL, WithRequestSigning("partner", "secret"))
+	require.NoError(t, err)
+	require.NoError(t, c.Deposit(context.Background(), input))
+	require.NoError(t, c.Deposit(context.Background(), input))
+
+	c, err = NewClient(srv.URL, WithRequestSigning("partner", "wrong"))
+	require.NoError(t, err)
+	err = c.Deposit(context.Background(), input)
+	anyErr := anymind.ParameterError(nil)
+	require.ErrorAs(t, err, &anyErr)
+	require.Equal(t, anymind.UnauthorizedErr, anyErr.Type)
+
+	require.Len(t, api.DepositCalls(), 2)
+}
@@ -0,0 +1,53 @@
+package client
+
+import (
+	"go.uber.org/zap"
+	"net/http"
+	"time"
+)
+
+type Option func(*Client)
+
+func WithLogger(logger *zap.Logger) Option {
+	return func(c *Client) {
+		c.logger = logger
+	}
+}
+
+// WithHTTPClient set underlying http client, its transport is reused but timeout is controlled by WithTimeout.
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *Client) {
+		c.http = client
+	}
+}
+
+// WithTimeout set maximum duration of a single attempt.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// WithRetry set number of retry and the initial wait between them for idempotent call.
+// Wait is doubled after each retry.
+func WithRetry(retries int, wait time.Duration) Option {
+	return func(c *Client) {
+		c.retries = retries
+		c.retryWait = wait
+	}
+}
+
+// WithAPIKey send key as bearer credential with every request.
+func WithAPIKey(key string) Option {
+	return func(c *Client) {
+		c.apiKey = key
+	}
+}
+
+// WithRequestSigning sign every request with partner secret identified by keyID.
+func WithRequestSigning(keyID string, secret string) Option {
+	return func(c *Client) {
+		c.signKeyID = keyID
+		c.signSecret = secret
+	}
+}
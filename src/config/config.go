@@ -0,0 +1,302 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config is websvc configuration, yaml tag is the key used in config file and flag name is derived from it,
+// e.g. `http.read_timeout` is set by `--http-read-timeout`.
+type Config struct {
+	HTTP     HTTP     `yaml:"http"`
+	GRPC     GRPC     `yaml:"grpc"`
+	DB       DB       `yaml:"db"`
+	Log      Log      `yaml:"log"`
+	Auth     Auth     `yaml:"auth"`
+	Trace    Trace    `yaml:"trace"`
+	Features Features `yaml:"features"`
+	Deposit  Deposit  `yaml:"deposit"`
+	Chain    Chain    `yaml:"chain"`
+	Anomaly  Anomaly  `yaml:"anomaly"`
+
+	// file is config file read by Load, it is watched for reload.
+	file string
+}
+
+type HTTP struct {
+	Addr              string        `yaml:"addr"`
+	ReadTimeout       time.Duration `yaml:"read_timeout"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout"`
+	WriteTimeout      time.Duration `yaml:"write_timeout"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout"`
+	ShutdownTimeout   time.Duration `yaml:"shutdown_timeout"`
+	DrainDelay        time.Duration `yaml:"drain_delay"`
+	// RateLimit is requests per second allowed for every client, 0 disable the limit.
+	RateLimit         float64 `yaml:"rate_limit"`
+	RateBurst         int     `yaml:"rate_burst"`
+	IPRateLimit       float64 `yaml:"ip_rate_limit"`
+	IPRateBurst       int     `yaml:"ip_rate_burst"`
+	MaxInFlightWrites int     `yaml:"max_inflight_writes"`
+	// MetricsAddr is empty when metrics are not served.
+	MetricsAddr string `yaml:"metrics_addr"`
+}
+
+type GRPC struct {
+	// Addr is empty when grpc transport is disabled.
+	Addr string `yaml:"addr"`
+}
+
+type DB struct {
+	DSN               string        `yaml:"dsn"`
+	MaxOpenConns      int           `yaml:"max_open_conns"`
+	MaxIdleConns      int           `yaml:"max_idle_conns"`
+	ConnMaxLifetime   time.Duration `yaml:"conn_max_lifetime"`
+	ConnMaxIdleTime   time.Duration `yaml:"conn_max_idle_time"`
+	StartupRetries    int           `yaml:"startup_retries"`
+	StartupRetryDelay time.Duration `yaml:"startup_retry_delay"`
+	// SerializationRetries is how many times deposit conflicting with concurrent one is retried.
+	SerializationRetries int `yaml:"serialization_retries"`
+}
+
+type Log struct {
+	Level  string `yaml:"level"`
+	Format string `yaml:"format"`
+	// SampleInitial lines with same message and level are logged every second, then only every SampleThereafter-th.
+	// Sampling is disabled when SampleInitial is 0.
+	SampleInitial    int `yaml:"sample_initial"`
+	SampleThereafter int `yaml:"sample_thereafter"`
+}
+
+type Auth struct {
+	// Disabled turn off credential check, only meant for local development.
+	Disabled bool `yaml:"disabled"`
+	// JWKSURL is file path or url of identity provider keys, bearer jwt are accepted when it is set.
+	JWKSURL     string `yaml:"jwks_url"`
+	JWTAudience string `yaml:"jwt_audience"`
+	JWTIssuer   string `yaml:"jwt_issuer"`
+	// SigningSecrets map partner key id to secret, deposit must be signed when it is not empty.
+	SigningSecrets map[string]string `yaml:"signing_secrets"`
+}
+
+type Trace struct {
+	// Exporter is one of exporter supported by tracing.NewProvider.
+	Exporter    string  `yaml:"exporter"`
+	SampleRatio float64 `yaml:"sample_ratio"`
+}
+
+type Deposit struct {
+	// MaxClockSkew is how far in the future deposit datetime is accepted, 0 accept any datetime.
+	MaxClockSkew time.Duration `yaml:"max_clock_skew"`
+	// PendingExpiry is how long deposit stay pending before it is failed, 0 keep it pending until it is settled.
+	PendingExpiry time.Duration `yaml:"pending_expiry"`
+}
+
+type Chain struct {
+	// SigningKey is base64 ed25519 seed or private key, deposit chain is only checkpointed when it is set.
+	SigningKey         string        `yaml:"signing_key"`
+	CheckpointInterval time.Duration `yaml:"checkpoint_interval"`
+	// CheckpointFile is file outside the database checkpoints are appended to, verify then detect checkpoints
+	// removed from the database.
+	CheckpointFile string `yaml:"checkpoint_file"`
+}
+
+type Anomaly struct {
+	// Enabled inspect every deposit against rolling stats of its asset, see anomaly.Service.
+	Enabled bool `yaml:"enabled"`
+	// Hold keep deposit out of pattern uncredited until it is released.
+	Hold       bool    `yaml:"hold"`
+	Window     int     `yaml:"window"`
+	MinSamples int     `yaml:"min_samples"`
+	Threshold  float64 `yaml:"threshold"`
+	HourShare  float64 `yaml:"hour_share"`
+}
+
+type Features struct {
+	SwaggerUI bool `yaml:"swagger_ui"`
+	Webhooks  bool `yaml:"webhooks"`
+}
+
+// setting describe a configuration key, env list the environment variables it is read from.
+type setting struct {
+	key   string
+	env   []string
+	def   any
+	usage string
+}
+
+var settings = []setting{
+	{"http.addr", []string{"HTTP_ADDR", "HTTP_PORT"}, ":8080", "http listen address, host:port or port"},
+	{"http.read_timeout", []string{"HTTP_READ_TIMEOUT"}, 15 * time.Second, "time to read whole request"},
+	{"http.read_header_timeout", []string{"HTTP_READ_HEADER_TIMEOUT"}, 5 * time.Second, "time to read request header"},
+	{"http.write_timeout", []string{"HTTP_WRITE_TIMEOUT"}, 30 * time.Second, "time to handle request and write response"},
+	{"http.idle_timeout", []string{"HTTP_IDLE_TIMEOUT"}, 60 * time.Second, "time keep-alive connection wait for next request"},
+	{"http.shutdown_timeout", []string{"HTTP_SHUTDOWN_TIMEOUT"}, 30 * time.Second, "grace period of in-flight request on shutdown"},
+	{"http.drain_delay", []string{"HTTP_DRAIN_DELAY", "DRAIN_DELAY"}, time.Duration(0), "time readiness report draining before listener close"},
+	{"http.rate_limit", []string{"RATE_LIMIT"}, 0.0, "requests per second allowed for every client, 0 disable the limit"},
+	{"http.rate_burst", []string{"RATE_LIMIT_BURST"}, 0, "burst above rate limit, 0 derive it from the limit"},
+	{"http.ip_rate_limit", []string{"IP_RATE_LIMIT"}, 0.0, "requests per second allowed for every client ip, checked before credential, 0 disable the limit"},
+	{"http.ip_rate_burst", []string{"IP_RATE_LIMIT_BURST"}, 0, "burst above ip rate limit, 0 derive it from the limit"},
+	{"http.max_inflight_writes", []string{"MAX_INFLIGHT_WRITES"}, 0, "deposits handled at once, 0 disable the cap"},
+	{"http.metrics_addr", []string{"METRICS_ADDR"}, "", "prometheus metrics listen address, apart from the api, metrics are not served when empty"},
+	{"grpc.addr", []string{"GRPC_ADDR", "GRPC_PORT"}, "", "grpc listen address, grpc is disabled when empty"},
+	{"db.dsn", []string{"DB_DSN", "PG_DSN"}, "", "postgresql connection string"},
+	{"db.max_open_conns", []string{"DB_MAX_OPEN_CONNS"}, 20, "open connections limit, 0 is unlimited"},
+	{"db.max_idle_conns", []string{"DB_MAX_IDLE_CONNS"}, 5, "idle connections kept in pool"},
+	{"db.conn_max_lifetime", []string{"DB_CONN_MAX_LIFETIME"}, 30 * time.Minute, "connection is closed after this age, 0 keep it"},
+	{"db.conn_max_idle_time", []string{"DB_CONN_MAX_IDLE_TIME"}, 5 * time.Minute, "idle connection is closed after this time, 0 keep it"},
+	{"db.startup_retries", []string{"DB_STARTUP_RETRIES", "STARTUP_RETRIES"}, 10, "database checks at startup before giving up"},
+	{"db.startup_retry_delay", []string{"DB_STARTUP_RETRY_DELAY", "STARTUP_RETRY_DELAY"}, 2 * time.Second, "delay between startup checks"},
+	{"db.serialization_retries", []string{"DB_SERIALIZATION_RETRIES"}, 3, "deposit retries after serialization failure"},
+	{"log.level", []string{"LOG_LEVEL"}, "info", "debug, info, warn or error"},
+	{"log.format", []string{"LOG_FORMAT"}, "json", "json or console"},
+	{"log.sample_initial", []string{"LOG_SAMPLE_INITIAL"}, 100, "lines per second with same message logged before sampling, 0 disable sampling"},
+	{"log.sample_thereafter", []string{"LOG_SAMPLE_THEREAFTER"}, 100, "only every n-th line is logged once sampling"},
+	{"auth.disabled", []string{"AUTH_DISABLED"}, false, "turn off credential check, local development only"},
+	{"auth.jwks_url", []string{"JWKS_URL"}, "", "file path or url of identity provider keys"},
+	{"auth.jwt_audience", []string{"JWT_AUDIENCE"}, "", "required jwt audience"},
+	{"auth.jwt_issuer", []string{"JWT_ISSUER"}, "", "required jwt issuer"},
+	{"auth.signing_secrets", []string{"SIGNING_SECRETS"}, map[string]string{}, "partner key id to request signing secret"},
+	{"trace.exporter", []string{"TRACE_EXPORTER"}, "none", "none, stdout, otlp or otlpfile:<path>"},
+	{"trace.sample_ratio", []string{"TRACE_SAMPLE_RATIO"}, 1.0, "fraction of new traces recorded"},
+	{"features.swagger_ui", []string{"SWAGGER_UI"}, false, "serve swagger ui at /docs/"},
+	{"features.webhooks", []string{"WEBHOOKS_ENABLED"}, true, "serve webhook routes and deliver webhooks"},
+	{"deposit.max_clock_skew", []string{"DEPOSIT_MAX_CLOCK_SKEW"}, time.Duration(0), "how far in the future deposit datetime is accepted, 0 accept any"},
+	{"deposit.pending_expiry", []string{"DEPOSIT_PENDING_EXPIRY"}, 24 * time.Hour, "how long deposit stay pending before it is failed, 0 never expire it"},
+	{"chain.signing_key", []string{"CHAIN_SIGNING_KEY"}, "", "base64 ed25519 key signing deposit chain checkpoints, checkpoints are disabled when empty"},
+	{"chain.checkpoint_interval", []string{"CHAIN_CHECKPOINT_INTERVAL"}, time.Hour, "how often deposit chain head is checkpointed"},
+	{"chain.checkpoint_file", []string{"CHAIN_CHECKPOINT_FILE"}, "", "file signed checkpoints are also appended to, checked by chain verify"},
+	{"anomaly.enabled", []string{"ANOMALY_ENABLED"}, false, "inspect deposits and record those out of pattern"},
+	{"anomaly.hold", []string{"ANOMALY_HOLD"}, false, "keep deposit out of pattern uncredited until it is released"},
+	{"anomaly.window", []string{"ANOMALY_WINDOW"}, 100, "recent deposits of an asset rolling stats are computed from"},
+	{"anomaly.min_samples", []string{"ANOMALY_MIN_SAMPLES"}, 20, "deposits of an asset seen before its deposits are scored"},
+	{"anomaly.threshold", []string{"ANOMALY_THRESHOLD"}, 3.5, "robust z-score of log amount above which deposit is flagged"},
+	{"anomaly.hour_share", []string{"ANOMALY_HOUR_SHARE"}, 0.01, "share of recent deposits around an hour below which it is unusual, 0 disable the check"},
+}
+
+// reloadable settings are applied to running services on reload, change of any other setting require restart.
+var reloadable = map[string]bool{
+	"http.rate_limit":          true,
+	"http.rate_burst":          true,
+	"http.ip_rate_limit":       true,
+	"http.ip_rate_burst":       true,
+	"db.max_open_conns":        true,
+	"db.max_idle_conns":        true,
+	"db.conn_max_lifetime":     true,
+	"db.conn_max_idle_time":    true,
+	"db.serialization_retries": true,
+	"log.level":                true,
+	"deposit.max_clock_skew":   true,
+}
+
+// fileEnv is environment variable giving config file, --config flag take precedence.
+const fileEnv = "CONFIG_FILE"
+
+// Load read configuration from, by increasing precedence: defaults, config file, environment variables and flags.
+// Config file format is taken from its extension, e.g. yaml or toml.
+func Load(name string, args []string) (*Config, error) {
+	v := viper.New()
+	v.AllowEmptyEnv(false)
+
+	fs := pflag.NewFlagSet(name, pflag.ContinueOnError)
+	file := fs.String("config", "", "config file, yaml or toml (env "+fileEnv+")")
+
+	for _, s := range settings {
+		v.SetDefault(s.key, s.def)
+
+		flagName := strings.NewReplacer(".", "-", "_", "-").Replace(s.key)
+		usage := fmt.Sprintf("%s (env %s)", s.usage, strings.Join(s.env, ", "))
+		switch def := s.def.(type) {
+		case string:
+			fs.String(flagName, def, usage)
+		case int:
+			fs.Int(flagName, def, usage)
+		case float64:
+			fs.Float64(flagName, def, usage)
+		case bool:
+			fs.Bool(flagName, def, usage)
+		case time.Duration:
+			fs.Duration(flagName, def, usage)
+		case map[string]string:
+			fs.StringToString(flagName, def, usage)
+		}
+
+		err := v.BindPFlag(s.key, fs.Lookup(flagName))
+		if err != nil {
+			return nil, err
+		}
+
+		// first variable set win, so new name take precedence over legacy one.
+		err = v.BindEnv(append([]string{s.key}, s.env...)...)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err := fs.Parse(args)
+	if err != nil {
+		return nil, err
+	}
+
+	if *file == "" {
+		*file = os.Getenv(fileEnv)
+	}
+
+	if *file != "" {
+		v.SetConfigFile(*file)
+		err = v.ReadInConfig()
+		if err != nil {
+			return nil, fmt.Errorf("read config file: %w", err)
+		}
+	}
+
+	cfg := &Config{}
+	err = v.Unmarshal(cfg, func(dc *mapstructure.DecoderConfig) {
+		dc.TagName = "yaml"
+		dc.ErrorUnused = true
+		dc.DecodeHook = mapstructure.ComposeDecodeHookFunc(
+			mapstructure.StringToTimeDurationHookFunc(),
+			jsonToMapHook)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.file = *file
+	cfg.HTTP.Addr = portToAddr(cfg.HTTP.Addr)
+	cfg.GRPC.Addr = portToAddr(cfg.GRPC.Addr)
+	cfg.HTTP.MetricsAddr = portToAddr(cfg.HTTP.MetricsAddr)
+
+	return cfg, cfg.Validate()
+}
+
+// portToAddr accept bare port, as legacy HTTP_PORT and GRPC_PORT only held the port.
+func portToAddr(addr string) string {
+	if _, err := strconv.Atoi(addr); err == nil {
+		return ":" + addr
+	}
+
+	return addr
+}
+
+// jsonToMapHook decode map given as json string, e.g. SIGNING_SECRETS env variable.
+func jsonToMapHook(from reflect.Type, to reflect.Type, data any) (any, error) {
+	if from.Kind() != reflect.String || to.Kind() != reflect.Map {
+		return data, nil
+	}
+
+	res := map[string]string{}
+	err := json.Unmarshal([]byte(data.(string)), &res)
+	if err != nil {
+		return nil, fmt.Errorf("expect json object: %w", err)
+	}
+
+	return res, nil
+}
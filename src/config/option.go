@@ -0,0 +1,11 @@
+package config
+
+import "go.uber.org/zap"
+
+type Option func(*Reloader)
+
+func WithLogger(logger *zap.Logger) Option {
+	return func(r *Reloader) {
+		r.logger = logger
+	}
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"gopkg.in/yaml.v3"
+	"net/url"
+	"reflect"
+	"regexp"
+	"time"
+)
+
+const redacted = "<redacted>"
+
+var dsnPasswordPattern = regexp.MustCompile(`(password=)('[^']*'|\S+)`)
+
+// Redacted return copy of configuration with secrets replaced, it is safe to print or log.
+func (c *Config) Redacted() *Config {
+	res := *c
+	res.DB.DSN = redactDSN(c.DB.DSN)
+
+	res.Auth.SigningSecrets = make(map[string]string, len(c.Auth.SigningSecrets))
+	for keyID := range c.Auth.SigningSecrets {
+		res.Auth.SigningSecrets[keyID] = redacted
+	}
+
+	if c.Chain.SigningKey != "" {
+		res.Chain.SigningKey = redacted
+	}
+
+	return &res
+}
+
+// redactDSN hide password of both url and keyword/value connection string.
+func redactDSN(dsn string) string {
+	u, err := url.Parse(dsn)
+	if err == nil && u.Scheme != "" {
+		if _, ok := u.User.Password(); ok {
+			u.User = url.UserPassword(u.User.Username(), redacted)
+		}
+
+		query := u.Query()
+		if query.Has("password") {
+			query.Set("password", redacted)
+			u.RawQuery = query.Encode()
+		}
+
+		return u.String()
+	}
+
+	return dsnPasswordPattern.ReplaceAllString(dsn, "${1}"+redacted)
+}
+
+// Map return configuration as nested map keyed like config file, secrets are not redacted, see Redacted.
+func (c *Config) Map() map[string]any {
+	return toMap(reflect.ValueOf(*c))
+}
+
+// YAML encode configuration in config file format, secrets are not redacted, see Redacted.
+func (c *Config) YAML() ([]byte, error) {
+	return yaml.Marshal(c.Map())
+}
+
+// toMap convert struct to nested map keyed by yaml tag, duration is written as string so it can be read back.
+func toMap(v reflect.Value) map[string]any {
+	res := map[string]any{}
+	for i := 0; i < v.NumField(); i++ {
+		if !v.Type().Field(i).IsExported() {
+			continue
+		}
+
+		key := v.Type().Field(i).Tag.Get("yaml")
+		field := v.Field(i)
+
+		switch val := field.Interface().(type) {
+		case time.Duration:
+			res[key] = val.String()
+		default:
+			if field.Kind() == reflect.Struct {
+				res[key] = toMap(field)
+			} else {
+				res[key] = val
+			}
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,206 @@
+package config
+
+import (
+	"anymind"
+	"context"
+	"fmt"
+	"github.com/fsnotify/fsnotify"
+	"go.uber.org/zap"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+var _ anymind.ConfigService = &Reloader{}
+
+// reloadDebounce group burst of file events, editor usually write file in several steps.
+const reloadDebounce = 200 * time.Millisecond
+
+// Reloader reload configuration on config file change or SIGHUP, and apply reloadable settings to running services.
+type Reloader struct {
+	name   string
+	args   []string
+	logger *zap.Logger
+	now    func() time.Time
+
+	mu       sync.Mutex
+	current  *Config
+	version  int
+	loadedAt time.Time
+	appliers []func(*Config)
+}
+
+// NewReloader keep cfg as active configuration, name and args must be the ones given to Load,
+// so flags keep precedence over reloaded file.
+func NewReloader(cfg *Config, name string, args []string, opts ...Option) *Reloader {
+	r := &Reloader{
+		name:    name,
+		args:    args,
+		now:     time.Now,
+		current: cfg,
+		version: 1,
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	if r.logger == nil {
+		r.logger = zap.NewNop()
+	}
+
+	r.loadedAt = r.now()
+
+	return r
+}
+
+// OnReload register apply, it is called with new configuration once reload is accepted.
+// Only reloadable settings can differ from active configuration.
+func (r *Reloader) OnReload(apply func(*Config)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.appliers = append(r.appliers, apply)
+}
+
+// Reload load configuration again, change is rejected as a whole when any changed setting is not reloadable.
+func (r *Reloader) Reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	next, err := Load(r.name, r.args)
+	if err != nil {
+		r.logger.Error("configuration reload failed", zap.Error(err))
+
+		return err
+	}
+
+	changed, unsafe := diff(r.current, next)
+	if len(changed) == 0 {
+		r.logger.Debug("configuration unchanged")
+
+		return nil
+	}
+
+	if len(unsafe) > 0 {
+		r.logger.Warn("configuration reload rejected, restart to apply",
+			zap.Strings("unsafe", unsafe),
+			zap.Strings("diff", changed))
+
+		return fmt.Errorf("settings require restart: %s", strings.Join(unsafe, ", "))
+	}
+
+	for _, apply := range r.appliers {
+		apply(next)
+	}
+
+	r.current = next
+	r.version++
+	r.loadedAt = r.now()
+
+	r.logger.Info("configuration reloaded", zap.Int("version", r.version), zap.Strings("diff", changed))
+
+	return nil
+}
+
+func (r *Reloader) ActiveConfig(_ context.Context) (*anymind.ActiveConfig, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return &anymind.ActiveConfig{
+		Version:  r.version,
+		LoadedAt: r.loadedAt,
+		Settings: r.current.Redacted().Map(),
+	}, nil
+}
+
+// Start reload on SIGHUP and on change in directory of config file, until ctx is done.
+// Directory is watched rather than the file, since editors and kubernetes replace the file.
+func (r *Reloader) Start(ctx context.Context) error {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+
+	var events chan fsnotify.Event
+	var watchErrors chan error
+	if r.current.file != "" {
+		watcher, err := fsnotify.NewWatcher()
+		if err != nil {
+			return err
+		}
+		defer watcher.Close()
+
+		err = watcher.Add(filepath.Dir(r.current.file))
+		if err != nil {
+			return err
+		}
+
+		events, watchErrors = watcher.Events, watcher.Errors
+	}
+
+	var debounce <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-hangup:
+			r.logger.Info("reloading configuration on SIGHUP")
+			r.Reload()
+		case <-events:
+			debounce = time.After(reloadDebounce)
+		case <-debounce:
+			debounce = nil
+			r.Reload()
+		case err := <-watchErrors:
+			r.logger.Warn("config file watch failed", zap.Error(err))
+		}
+	}
+}
+
+// diff list changed settings as `key: old -> new` with secrets redacted, and the changed keys that are not reloadable.
+func diff(current *Config, next *Config) ([]string, []string) {
+	before, after := flatten(current.Map()), flatten(next.Map())
+	shownBefore, shownAfter := flatten(current.Redacted().Map()), flatten(next.Redacted().Map())
+
+	var changed, unsafe []string
+	for key := range after {
+		if reflect.DeepEqual(before[key], after[key]) {
+			continue
+		}
+
+		changed = append(changed, fmt.Sprintf("%s: %v -> %v", key, shownBefore[key], shownAfter[key]))
+		if !reloadable[key] {
+			unsafe = append(unsafe, key)
+		}
+	}
+
+	sort.Strings(changed)
+	sort.Strings(unsafe)
+
+	return changed, unsafe
+}
+
+// flatten nested settings into dotted keys, e.g. `http.rate_limit`.
+func flatten(settings map[string]any) map[string]any {
+	res := map[string]any{}
+	for key, val := range settings {
+		nested, ok := val.(map[string]any)
+		if !ok {
+			res[key] = val
+
+			continue
+		}
+
+		for k, v := range flatten(nested) {
+			res[key+"."+k] = v
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,105 @@
+package config
+
+import (
+	"anymind/src/chain"
+	"anymind/src/tracing"
+	"fmt"
+	"go.uber.org/zap/zapcore"
+	"net"
+	"strings"
+)
+
+// Validate report every invalid setting at once, so misconfiguration is fixed in one go.
+func (c *Config) Validate() error {
+	var problems []string
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			problems = append(problems, fmt.Sprintf(format, args...))
+		}
+	}
+
+	_, _, err := net.SplitHostPort(c.HTTP.Addr)
+	check(err == nil, "http.addr %q must be host:port", c.HTTP.Addr)
+	if c.GRPC.Addr != "" {
+		_, _, err = net.SplitHostPort(c.GRPC.Addr)
+		check(err == nil, "grpc.addr %q must be host:port", c.GRPC.Addr)
+		check(c.GRPC.Addr != c.HTTP.Addr, "grpc.addr must differ from http.addr")
+	}
+	if c.HTTP.MetricsAddr != "" {
+		_, _, err = net.SplitHostPort(c.HTTP.MetricsAddr)
+		check(err == nil, "http.metrics_addr %q must be host:port", c.HTTP.MetricsAddr)
+		check(c.HTTP.MetricsAddr != c.HTTP.Addr, "http.metrics_addr must differ from http.addr")
+		check(c.HTTP.MetricsAddr != c.GRPC.Addr, "http.metrics_addr must differ from grpc.addr")
+	}
+
+	check(c.HTTP.ReadTimeout >= 0, "http.read_timeout must not be negative")
+	check(c.HTTP.ReadHeaderTimeout >= 0, "http.read_header_timeout must not be negative")
+	check(c.HTTP.WriteTimeout >= 0, "http.write_timeout must not be negative")
+	check(c.HTTP.IdleTimeout >= 0, "http.idle_timeout must not be negative")
+	check(c.HTTP.ShutdownTimeout > 0, "http.shutdown_timeout must be positive")
+	check(c.HTTP.DrainDelay >= 0, "http.drain_delay must not be negative")
+	check(c.HTTP.RateLimit >= 0, "http.rate_limit must not be negative")
+	check(c.HTTP.RateBurst >= 0, "http.rate_burst must not be negative")
+	check(c.HTTP.IPRateLimit >= 0, "http.ip_rate_limit must not be negative")
+	check(c.HTTP.IPRateBurst >= 0, "http.ip_rate_burst must not be negative")
+	check(c.HTTP.MaxInFlightWrites >= 0, "http.max_inflight_writes must not be negative")
+
+	check(c.DB.DSN != "", "db.dsn is required")
+	check(c.DB.MaxOpenConns >= 0, "db.max_open_conns must not be negative")
+	check(c.DB.MaxIdleConns >= 0, "db.max_idle_conns must not be negative")
+	check(c.DB.MaxOpenConns == 0 || c.DB.MaxIdleConns <= c.DB.MaxOpenConns, "db.max_idle_conns must not exceed db.max_open_conns")
+	check(c.DB.ConnMaxLifetime >= 0, "db.conn_max_lifetime must not be negative")
+	check(c.DB.ConnMaxIdleTime >= 0, "db.conn_max_idle_time must not be negative")
+	check(c.DB.StartupRetries > 0, "db.startup_retries must be positive")
+	check(c.DB.StartupRetryDelay > 0, "db.startup_retry_delay must be positive")
+	check(c.DB.SerializationRetries >= 0, "db.serialization_retries must not be negative")
+
+	_, err = zapcore.ParseLevel(c.Log.Level)
+	check(err == nil, "log.level %q is unknown", c.Log.Level)
+	check(c.Log.Format == "console" || c.Log.Format == "json", "log.format must be json or console")
+	check(c.Log.SampleInitial >= 0, "log.sample_initial must not be negative")
+	check(c.Log.SampleInitial == 0 || c.Log.SampleThereafter > 0, "log.sample_thereafter must be positive when sampling")
+
+	check(c.Auth.JWKSURL != "" || (c.Auth.JWTAudience == "" && c.Auth.JWTIssuer == ""),
+		"auth.jwt_audience and auth.jwt_issuer require auth.jwks_url")
+	for keyID, secret := range c.Auth.SigningSecrets {
+		check(secret != "", "auth.signing_secrets of %q is empty", keyID)
+	}
+
+	check(validExporter(c.Trace.Exporter), "trace.exporter %q is unknown", c.Trace.Exporter)
+	check(c.Trace.SampleRatio >= 0 && c.Trace.SampleRatio <= 1, "trace.sample_ratio must be between 0 and 1")
+
+	check(c.Deposit.MaxClockSkew >= 0, "deposit.max_clock_skew must not be negative")
+	check(c.Deposit.PendingExpiry >= 0, "deposit.pending_expiry must not be negative")
+
+	if c.Chain.SigningKey != "" {
+		_, err = chain.ParseSigningKey(c.Chain.SigningKey)
+		check(err == nil, "chain.signing_key: %v", err)
+	}
+	check(c.Chain.CheckpointInterval > 0, "chain.checkpoint_interval must be positive")
+
+	check(c.Anomaly.Window > 0, "anomaly.window must be positive")
+	check(c.Anomaly.MinSamples > 0 && c.Anomaly.MinSamples <= c.Anomaly.Window,
+		"anomaly.min_samples must be between 1 and anomaly.window")
+	check(c.Anomaly.Threshold > 0, "anomaly.threshold must be positive")
+	check(c.Anomaly.HourShare >= 0 && c.Anomaly.HourShare <= 1, "anomaly.hour_share must be between 0 and 1")
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid configuration: %s", strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
+// validExporter mirror exporter parsing of tracing.NewProvider.
+func validExporter(exporter string) bool {
+	kind, path, _ := strings.Cut(exporter, ":")
+	switch kind {
+	case "", tracing.ExporterNone, tracing.ExporterStdout, tracing.ExporterOTLP:
+		return true
+	case tracing.ExporterOTLPFile:
+		return path != ""
+	default:
+		return false
+	}
+}
@@ -0,0 +1,106 @@
+package grpcapi
+
+import (
+	"anymind"
+	"context"
+	"errors"
+	"fmt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"strings"
+)
+
+const depositMethod = "/anymind.wallet.v1.Wallet/Deposit"
+
+// methodScopes is scope required by each rpc.
+var methodScopes = map[string]string{
+	depositMethod:                            anymind.ScopeDepositWrite,
+	"/anymind.wallet.v1.Wallet/Historical":   anymind.ScopeHistoryRead,
+	"/anymind.wallet.v1.Wallet/WatchBalance": anymind.ScopeHistoryRead,
+}
+
+// credential return credential from "authorization: Bearer <token>" or "x-api-key" metadata.
+func credential(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	var credential string
+	if values := md.Get("x-api-key"); len(values) > 0 {
+		credential = values[0]
+	}
+
+	if values := md.Get("authorization"); len(values) > 0 {
+		scheme, token, ok := strings.Cut(values[0], " ")
+		if ok && strings.EqualFold(scheme, "Bearer") {
+			credential = strings.TrimSpace(token)
+		}
+	}
+
+	return credential
+}
+
+// authorize authenticate credential sent in metadata, see credential.
+func (s *Service) authorize(ctx context.Context, method string) (context.Context, error) {
+	credential := credential(ctx)
+	if credential == "" {
+		return nil, toStatus(anymind.UnauthorizedError(errors.New("missing credential")))
+	}
+
+	principal, err := s.auth.Authenticate(ctx, credential)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	scope, ok := methodScopes[method]
+	if !ok {
+		scope = anymind.ScopeAdmin
+	}
+
+	if !principal.HasScope(scope) {
+		return nil, toStatus(anymind.ForbiddenError(fmt.Errorf("scope %s is required", scope)))
+	}
+
+	return anymind.WithPrincipal(ctx, principal), nil
+}
+
+func (s *Service) unaryAuth(
+	ctx context.Context,
+	req interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (interface{}, error) {
+	// deposit middleware authorize deposit itself, after ip rate limit and signature check.
+	if info.FullMethod == depositMethod && s.depositMiddleware != nil {
+		return handler(ctx, req)
+	}
+
+	ctx, err := s.authorize(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+func (s *Service) streamAuth(
+	srv interface{},
+	stream grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	ctx, err := s.authorize(stream.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+
+	return handler(srv, &authStream{ServerStream: stream, ctx: ctx})
+}
+
+// authStream replace stream context with the one carrying principal.
+type authStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (a *authStream) Context() context.Context {
+	return a.ctx
+}
@@ -0,0 +1,57 @@
+package grpcapi
+
+import (
+	"anymind"
+	"fmt"
+	"github.com/go-kit/kit/endpoint"
+	"go.uber.org/zap"
+)
+
+type Option func(*Service)
+
+func WithLogger(logger *zap.Logger) Option {
+	return func(svc *Service) {
+		svc.logger = logger
+	}
+}
+
+// WithListenPort listen on every interface at port, see WithListenAddr.
+func WithListenPort(port int) Option {
+	return WithListenAddr(fmt.Sprintf(":%d", port))
+}
+
+// WithListenAddr listen on host:port address, host can be empty to listen on every interface.
+func WithListenAddr(addr string) Option {
+	return func(svc *Service) {
+		svc.addr = addr
+	}
+}
+
+// WithDepositFeed enable WatchBalance streaming.
+func WithDepositFeed(feed anymind.DepositFeed) Option {
+	return func(svc *Service) {
+		svc.feed = feed
+	}
+}
+
+// WithAuthenticator require every rpc to carry credential accepted by auth.
+// It can be given multiple times, credential is then checked against each authenticator in order.
+func WithAuthenticator(auth anymind.Authenticator) Option {
+	return func(svc *Service) {
+		switch {
+		case auth == nil:
+		case svc.auth == nil:
+			svc.auth = auth
+		default:
+			svc.auth = anymind.Authenticators{svc.auth, auth}
+		}
+	}
+}
+
+// WithDepositMiddleware serve Deposit through mw, so it is signed, authorized, limited and audited like HTTP deposit.
+// Deposit is then not authorized by WithAuthenticator interceptor, mw must do it.
+func WithDepositMiddleware(mw endpoint.Middleware) Option {
+	return func(svc *Service) {
+		svc.depositMiddleware = mw
+	}
+}
@@ -0,0 +1,84 @@
+package grpcapi
+
+import (
+	"anymind"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/protobuf/proto"
+	"net"
+	"regexp"
+	"strings"
+)
+
+// requestIDPattern limit id accepted from caller, so it cannot inject arbitrary content into logs.
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// requestContext put request id sent by caller, or a generated one, and caller ip into context.
+// Request id is sent back in response header.
+func requestContext(ctx context.Context) context.Context {
+	key := strings.ToLower(anymind.RequestIDHeader)
+
+	var id string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(key); len(values) > 0 {
+			id = values[0]
+		}
+	}
+
+	if !requestIDPattern.MatchString(id) {
+		id = newRequestID()
+	}
+
+	// header cannot be set when rpc is called directly, not through grpc server.
+	_ = grpc.SetHeader(ctx, metadata.Pairs(key, id))
+
+	ctx = anymind.WithRequestID(ctx, id)
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		ip, _, err := net.SplitHostPort(p.Addr.String())
+		if err != nil {
+			ip = p.Addr.String()
+		}
+
+		ctx = anymind.WithClientIP(ctx, ip)
+	}
+
+	return ctx
+}
+
+func newRequestID() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+
+	return hex.EncodeToString(id)
+}
+
+// signedRequest read signature from metadata, it cover deterministic protobuf encoding of req.
+func signedRequest(ctx context.Context, method string, req proto.Message) (*anymind.SignedRequest, error) {
+	body, err := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+	if err != nil {
+		return nil, anymind.ParameterError(err)
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	get := func(header string) string {
+		if values := md.Get(strings.ToLower(header)); len(values) > 0 {
+			return values[0]
+		}
+
+		return ""
+	}
+
+	return &anymind.SignedRequest{
+		KeyID:     get(anymind.SignatureKeyHeader),
+		Method:    "POST",
+		Path:      method,
+		Timestamp: get(anymind.SignatureTimestampHeader),
+		Nonce:     get(anymind.SignatureNonceHeader),
+		Signature: get(anymind.SignatureHeader),
+		Body:      body,
+	}, nil
+}
@@ -0,0 +1,297 @@
+package grpcapi
+
+import (
+	"anymind"
+	"anymind/src/grpcapi/walletpb"
+	"anymind/src/tracing"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/cockroachdb/apd"
+	"github.com/go-kit/kit/endpoint"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"net"
+	"time"
+)
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative walletpb/wallet.proto
+
+var _ anymind.HTTPService = &Service{}
+var _ walletpb.WalletServer = &Service{}
+
+type Service struct {
+	walletpb.UnimplementedWalletServer
+
+	api    anymind.APIService
+	feed   anymind.DepositFeed
+	auth   anymind.Authenticator
+	addr   string
+	logger *zap.Logger
+
+	depositMiddleware endpoint.Middleware
+	deposit           endpoint.Endpoint
+}
+
+func NewService(api anymind.APIService, opts ...Option) *Service {
+	s := &Service{
+		api:  api,
+		addr: ":9090",
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if s.logger == nil {
+		s.logger = zap.NewNop()
+	}
+
+	s.deposit = func(ctx context.Context, request interface{}) (interface{}, error) {
+		return nil, s.api.Deposit(ctx, request.(*anymind.DepositInput))
+	}
+	if s.depositMiddleware != nil {
+		s.deposit = s.depositMiddleware(s.deposit)
+	}
+
+	return s
+}
+
+// NewServer create grpc server with wallet service registered.
+func (s *Service) NewServer() *grpc.Server {
+	var opts []grpc.ServerOption
+	if s.auth != nil {
+		opts = append(opts, grpc.UnaryInterceptor(s.unaryAuth), grpc.StreamInterceptor(s.streamAuth))
+	}
+
+	srv := grpc.NewServer(opts...)
+	walletpb.RegisterWalletServer(srv, s)
+
+	return srv
+}
+
+func (s *Service) Start(ctx context.Context) error {
+	lis, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return err
+	}
+
+	srv := s.NewServer()
+
+	served := make(chan error, 1)
+	go func() {
+		served <- srv.Serve(lis)
+	}()
+
+	select {
+	case err = <-served:
+		return err
+	case <-ctx.Done():
+		srv.GracefulStop()
+
+		return nil
+	}
+}
+
+func (s *Service) Deposit(ctx context.Context, req *walletpb.DepositRequest) (*walletpb.DepositResponse, error) {
+	ctx = requestContext(ctx)
+	ctx = anymind.WithCredential(ctx, credential(ctx))
+
+	if s.depositMiddleware != nil {
+		signed, err := signedRequest(ctx, depositMethod, req)
+		if err != nil {
+			return nil, toStatus(err)
+		}
+
+		ctx = anymind.WithSignedRequest(ctx, signed)
+	}
+
+	if req.Datetime == nil {
+		return nil, toStatus(anymind.ParameterError(errors.New("empty datetime")))
+	}
+
+	amount, _, err := apd.NewFromString(req.Amount)
+	if err != nil {
+		return nil, toStatus(anymind.ParameterError(err))
+	}
+
+	input := &anymind.DepositInput{
+		DateTime: req.Datetime.AsTime().UTC(),
+		Amount:   *amount,
+		Asset:    req.Asset,
+		Status:   req.Status,
+	}
+	_, err = s.deposit(ctx, input)
+	if err != nil {
+		tracing.Logger(ctx, s.logger).Error("error deposit request", zap.Error(err))
+
+		return nil, toStatus(err)
+	}
+
+	tracing.Logger(ctx, s.logger).Info("success deposit request")
+
+	res := &walletpb.DepositResponse{
+		Datetime:  req.Datetime,
+		Amount:    req.Amount,
+		Asset:     input.Asset,
+		Id:        input.ID,
+		Status:    anymind.DepositStatus(req.Status),
+		AnomalyId: input.AnomalyID,
+	}
+	if input.AnomalyID != 0 {
+		res.Status = anymind.AnomalyHeld
+	}
+
+	return res, nil
+}
+
+func (s *Service) Historical(
+	ctx context.Context,
+	req *walletpb.HistoricalRequest,
+) (*walletpb.HistoricalResponse, error) {
+	ctx = requestContext(ctx)
+
+	if req.StartDatetime == nil || req.EndDatetime == nil {
+		return nil, toStatus(anymind.ParameterError(errors.New("empty start or end datetime")))
+	}
+
+	histreq := &anymind.HistoricalDataReq{
+		Start:          req.StartDatetime.AsTime().UTC(),
+		End:            req.EndDatetime.AsTime().UTC(),
+		Asset:          req.Asset,
+		Quote:          req.Quote,
+		IncludePending: req.IncludePending,
+	}
+
+	res, err := s.api.Historical(ctx, histreq)
+	if err != nil {
+		tracing.Logger(ctx, s.logger).Error("error historical request", zap.Error(err))
+
+		return nil, toStatus(err)
+	}
+
+	tracing.Logger(ctx, s.logger).Info("success historical request")
+
+	resp := &walletpb.HistoricalResponse{}
+	for _, entry := range anymind.FillHourly(histreq, res) {
+		pbEntry := &walletpb.HistoricalEntry{
+			Datetime:    timestamppb.New(entry.DateTime),
+			Amount:      fmt.Sprintf("%f", &entry.Amount),
+			RateMissing: req.Quote != "" && entry.Value == nil,
+		}
+		if entry.Value != nil {
+			pbEntry.Value = fmt.Sprintf("%f", entry.Value)
+		}
+
+		resp.Entries = append(resp.Entries, pbEntry)
+	}
+
+	return resp, nil
+}
+
+func (s *Service) WatchBalance(req *walletpb.WatchBalanceRequest, stream walletpb.Wallet_WatchBalanceServer) error {
+	if s.feed == nil {
+		return status.Error(codes.Unimplemented, "balance watch is not enabled")
+	}
+
+	ctx := stream.Context()
+	asset := anymind.AssetCode(req.Asset)
+
+	// subscribe before reading initial balance, so no deposit is missed in between.
+	events := s.feed.Subscribe(ctx)
+
+	initial, err := s.balance(ctx, time.Now().UTC(), asset)
+	if err != nil {
+		return toStatus(err)
+	}
+
+	err = stream.Send(&walletpb.BalanceUpdate{
+		Datetime: timestamppb.Now(),
+		Amount:   "0",
+		Balance:  fmt.Sprintf("%f", initial),
+		Asset:    asset,
+	})
+	if err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case ev, ok := <-events:
+			if !ok {
+				return nil
+			}
+
+			if anymind.AssetCode(ev.Asset) != asset {
+				continue
+			}
+
+			at := time.Now().UTC()
+			if ev.DateTime.After(at) {
+				at = ev.DateTime
+			}
+
+			balance, err := s.balance(ctx, at, asset)
+			if err != nil {
+				return toStatus(err)
+			}
+
+			err = stream.Send(&walletpb.BalanceUpdate{
+				DepositId: ev.ID,
+				Datetime:  timestamppb.New(ev.DateTime),
+				Amount:    fmt.Sprintf("%f", &ev.Amount),
+				Balance:   fmt.Sprintf("%f", balance),
+				Asset:     asset,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// balance return total balance of asset at given time.
+func (s *Service) balance(ctx context.Context, at time.Time, asset string) (*apd.Decimal, error) {
+	res, err := s.api.Historical(ctx, &anymind.HistoricalDataReq{
+		Start: at,
+		End:   at,
+		Asset: asset,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(res) == 0 {
+		return apd.New(0, 0), nil
+	}
+
+	return &res[len(res)-1].Amount, nil
+}
+
+// toStatus map anymind.Error type into grpc status code.
+func toStatus(err error) error {
+	anyerr := &anymind.Error{}
+	if !errors.As(err, &anyerr) {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	switch anyerr.Type {
+	case anymind.ParameterErr:
+		return status.Error(codes.InvalidArgument, anyerr.Error())
+	case anymind.NotFoundErr:
+		return status.Error(codes.NotFound, anyerr.Error())
+	case anymind.UnauthorizedErr:
+		return status.Error(codes.Unauthenticated, anyerr.Error())
+	case anymind.ForbiddenErr:
+		return status.Error(codes.PermissionDenied, anyerr.Error())
+	case anymind.TooManyRequestsErr:
+		return status.Error(codes.ResourceExhausted, anyerr.Error())
+	default:
+		return status.Error(codes.Internal, anyerr.Error())
+	}
+}
@@ -0,0 +1,348 @@
+package grpcapi
+
+import (
+	"anymind"
+	"anymind/src/grpcapi/walletpb"
+	"anymind/src/mock"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/cockroachdb/apd"
+	"github.com/go-kit/kit/endpoint"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"net"
+	"testing"
+	"time"
+)
+
+func mustApd(number string) apd.Decimal {
+	val, _, err := apd.NewFromString(number)
+	if err != nil {
+		panic(err)
+	}
+
+	return *val
+}
+
+func mustTime(ts string) time.Time {
+	val, err := time.Parse(time.RFC3339, ts)
+	if err != nil {
+		panic(err)
+	}
+
+	return val
+}
+
+func dial(t *testing.T, svc *Service) walletpb.WalletClient {
+	lis := bufconn.Listen(1024 * 1024)
+	srv := svc.NewServer()
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.Dial(
+		"bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return walletpb.NewWalletClient(conn)
+}
+
+func TestDeposit(t *testing.T) {
+	client := dial(t, NewService(&mock.APIServiceMock{
+		DepositFunc: func(_ context.Context, input *anymind.DepositInput) error {
+			require.Equal(t, "2020-01-01T01:01:01Z", input.DateTime.Format(time.RFC3339))
+			require.Equal(t, "123.122223", fmt.Sprintf("%f", &input.Amount))
+
+			return nil
+		},
+	}))
+
+	resp, err := client.Deposit(context.Background(), &walletpb.DepositRequest{
+		Datetime: timestamppb.New(mustTime("2020-01-01T08:01:01+07:00")),
+		Amount:   "123.122223",
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, "123.122223", resp.Amount)
+}
+
+func TestDepositHeld(t *testing.T) {
+	client := dial(t, NewService(&mock.APIServiceMock{
+		DepositFunc: func(_ context.Context, input *anymind.DepositInput) error {
+			input.AnomalyID = 7
+
+			return nil
+		},
+	}))
+
+	resp, err := client.Deposit(context.Background(), &walletpb.DepositRequest{
+		Datetime: timestamppb.New(mustTime("2020-01-01T08:01:01+07:00")),
+		Amount:   "1",
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, int64(0), resp.Id)
+	require.Equal(t, int64(7), resp.AnomalyId)
+	require.Equal(t, anymind.AnomalyHeld, resp.Status)
+}
+
+func TestDepositMiddleware(t *testing.T) {
+	req := &walletpb.DepositRequest{
+		Datetime: timestamppb.New(mustTime("2020-01-01T08:01:01+07:00")),
+		Amount:   "1",
+		Asset:    "btc",
+	}
+	body, err := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+	require.NoError(t, err)
+
+	var (
+		deposited          bool
+		id, ip, credential string
+		signed             *anymind.SignedRequest
+		input              *anymind.DepositInput
+	)
+	mw := func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			id, _ = anymind.RequestIDFromContext(ctx)
+			ip, _ = anymind.ClientIPFromContext(ctx)
+			credential = anymind.CredentialFromContext(ctx)
+			signed, _ = anymind.SignedRequestFromContext(ctx)
+			input, _ = request.(*anymind.DepositInput)
+
+			if input != nil && input.Amount.Cmp(apd.New(1, 0)) != 0 {
+				return nil, anymind.TooManyRequestsError(errors.New("rate limit exceeded"))
+			}
+
+			return next(ctx, request)
+		}
+	}
+
+	// deposit is authorized by middleware, not by interceptor.
+	auth := &mock.AuthenticatorMock{
+		AuthenticateFunc: func(_ context.Context, _ string) (*anymind.Principal, error) {
+			return nil, anymind.UnauthorizedError(errors.New("invalid api key"))
+		},
+	}
+
+	client := dial(t, NewService(&mock.APIServiceMock{
+		DepositFunc: func(_ context.Context, _ *anymind.DepositInput) error {
+			deposited = true
+
+			return nil
+		},
+	}, WithAuthenticator(auth), WithDepositMiddleware(mw)))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(),
+		"x-request-id", "req-1",
+		"x-api-key", "writer",
+		"x-signature-key", "key-1",
+		"x-signature-timestamp", "1577840461",
+		"x-signature-nonce", "nonce-1",
+		"x-signature", "sig",
+	)
+
+	var header metadata.MD
+	_, err = client.Deposit(ctx, req, grpc.Header(&header))
+	require.NoError(t, err)
+	require.True(t, deposited)
+	require.Equal(t, []string{"req-1"}, header.Get("x-request-id"))
+	require.Equal(t, "req-1", id)
+	require.NotEmpty(t, ip)
+	require.Equal(t, "writer", credential)
+	require.Equal(t, &anymind.SignedRequest{
+		KeyID:     "key-1",
+		Method:    "POST",
+		Path:      "/anymind.wallet.v1.Wallet/Deposit",
+		Timestamp: "1577840461",
+		Nonce:     "nonce-1",
+		Signature: "sig",
+		Body:      body,
+	}, signed)
+	require.Equal(t, "btc", input.Asset)
+
+	deposited = false
+	_, err = client.Deposit(ctx, &walletpb.DepositRequest{Datetime: req.Datetime, Amount: "2", Asset: "btc"})
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+	require.False(t, deposited)
+
+	_, err = client.Historical(ctx, &walletpb.HistoricalRequest{
+		StartDatetime: timestamppb.Now(),
+		EndDatetime:   timestamppb.Now(),
+	})
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestDepositErrorCode(t *testing.T) {
+	testCases := []struct {
+		name   string
+		amount string
+		err    error
+		code   codes.Code
+	}{
+		{
+			name:   "unparsable amount",
+			amount: "abc",
+			code:   codes.InvalidArgument,
+		},
+		{
+			name:   "parameter error",
+			amount: "-1",
+			err:    anymind.ParameterError(errors.New("invalid amount")),
+			code:   codes.InvalidArgument,
+		},
+		{
+			name:   "internal error",
+			amount: "1",
+			err:    anymind.InternalError(errors.New("db down")),
+			code:   codes.Internal,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			client := dial(t, NewService(&mock.APIServiceMock{
+				DepositFunc: func(_ context.Context, _ *anymind.DepositInput) error {
+					return tc.err
+				},
+			}))
+
+			_, err := client.Deposit(context.Background(), &walletpb.DepositRequest{
+				Datetime: timestamppb.Now(),
+				Amount:   tc.amount,
+			})
+
+			require.Equal(t, tc.code, status.Code(err))
+		})
+	}
+}
+
+func TestHistorical(t *testing.T) {
+	client := dial(t, NewService(&mock.APIServiceMock{
+		HistoricalFunc: func(_ context.Context, _ *anymind.HistoricalDataReq) ([]*anymind.HistoricalData, error) {
+			return []*anymind.HistoricalData{
+				{
+					DateTime: mustTime("2020-01-02T00:00:00Z"),
+					Amount:   mustApd("10"),
+				},
+			}, nil
+		},
+	}))
+
+	resp, err := client.Historical(context.Background(), &walletpb.HistoricalRequest{
+		StartDatetime: timestamppb.New(mustTime("2020-01-01T23:00:00Z")),
+		EndDatetime:   timestamppb.New(mustTime("2020-01-02T01:00:00Z")),
+	})
+
+	require.NoError(t, err)
+	require.Len(t, resp.Entries, 3)
+
+	expected := []string{"0", "10", "10"}
+	for i := range expected {
+		require.Equal(t, expected[i], resp.Entries[i].Amount)
+	}
+}
+
+func TestWatchBalance(t *testing.T) {
+	events := make(chan *anymind.DepositEvent, 1)
+	balance := "10"
+
+	client := dial(t, NewService(
+		&mock.APIServiceMock{
+			HistoricalFunc: func(_ context.Context, req *anymind.HistoricalDataReq) ([]*anymind.HistoricalData, error) {
+				return []*anymind.HistoricalData{
+					{
+						DateTime: req.End.Truncate(time.Hour),
+						Amount:   mustApd(balance),
+					},
+				}, nil
+			},
+		},
+		WithDepositFeed(&mock.DepositFeedMock{
+			SubscribeFunc: func(_ context.Context) <-chan *anymind.DepositEvent {
+				return events
+			},
+		})))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := client.WatchBalance(ctx, &walletpb.WatchBalanceRequest{})
+	require.NoError(t, err)
+
+	update, err := stream.Recv()
+	require.NoError(t, err)
+	require.Equal(t, int64(0), update.DepositId)
+	require.Equal(t, "10", update.Balance)
+
+	// deposit of other asset is not streamed.
+	events <- &anymind.DepositEvent{
+		ID:       2,
+		DateTime: mustTime("2020-01-01T00:00:00Z"),
+		Amount:   mustApd("7"),
+		Asset:    "ETH",
+	}
+
+	balance = "12.5"
+	events <- &anymind.DepositEvent{
+		ID:       3,
+		DateTime: mustTime("2020-01-01T00:00:00Z"),
+		Amount:   mustApd("2.5"),
+	}
+
+	update, err = stream.Recv()
+	require.NoError(t, err)
+	require.Equal(t, int64(3), update.DepositId)
+	require.Equal(t, "2.5", update.Amount)
+	require.Equal(t, "12.5", update.Balance)
+	require.Equal(t, anymind.DefaultAsset, update.Asset)
+}
+
+func TestWatchBalanceDisabled(t *testing.T) {
+	client := dial(t, NewService(&mock.APIServiceMock{}))
+
+	stream, err := client.WatchBalance(context.Background(), &walletpb.WatchBalanceRequest{})
+	require.NoError(t, err)
+
+	_, err = stream.Recv()
+	require.Equal(t, codes.Unimplemented, status.Code(err))
+}
+
+func TestAuthorization(t *testing.T) {
+	auth := &mock.AuthenticatorMock{
+		AuthenticateFunc: func(_ context.Context, credential string) (*anymind.Principal, error) {
+			if credential != "reader" {
+				return nil, anymind.UnauthorizedError(errors.New("invalid api key"))
+			}
+
+			return &anymind.Principal{Subject: "reader", Scopes: []string{anymind.ScopeHistoryRead}}, nil
+		},
+	}
+
+	client := dial(t, NewService(&mock.APIServiceMock{}, WithAuthenticator(auth)))
+	req := &walletpb.DepositRequest{Datetime: timestamppb.Now(), Amount: "1"}
+
+	_, err := client.Deposit(context.Background(), req)
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer unknown")
+	_, err = client.Deposit(ctx, req)
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx = metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "reader")
+	_, err = client.Deposit(ctx, req)
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+}
@@ -0,0 +1,91 @@
+package httpapi
+
+import (
+	"anymind"
+	"anymind/src/tracing"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"go.uber.org/zap"
+	"net"
+	"net/http"
+	"regexp"
+	"time"
+)
+
+// requestIDPattern limit id accepted from caller, so it cannot inject arbitrary content into logs.
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// requestID put id sent by caller, or a generated one, into request context and response header.
+// Client ip is put into context too, both are recorded in audit log.
+func requestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(anymind.RequestIDHeader)
+		if !requestIDPattern.MatchString(id) {
+			id = newRequestID()
+		}
+
+		clientIP, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			clientIP = r.RemoteAddr
+		}
+
+		w.Header().Set(anymind.RequestIDHeader, id)
+		ctx := anymind.WithClientIP(anymind.WithRequestID(r.Context(), id), clientIP)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func newRequestID() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+
+	return hex.EncodeToString(id)
+}
+
+// accessFields hold request detail only known to the endpoint, e.g. deposit amount, for the access log.
+type accessFields struct {
+	amount string
+}
+
+type accessFieldsKey struct{}
+
+func accessFieldsToContext(ctx context.Context, _ *http.Request) context.Context {
+	return context.WithValue(ctx, accessFieldsKey{}, &accessFields{})
+}
+
+// setAccessAmount record amount of the request in access log.
+func setAccessAmount(ctx context.Context, amount string) {
+	if fields, ok := ctx.Value(accessFieldsKey{}).(*accessFields); ok {
+		fields.amount = amount
+	}
+}
+
+// accessLog write one line per request once response is sent.
+func (s *Service) accessLog(ctx context.Context, code int, r *http.Request) {
+	fields := []zap.Field{
+		zap.String("method", r.Method),
+		zap.String("route", routeTemplate(r)),
+		zap.Int("status", code),
+		zap.String("remote_addr", r.RemoteAddr),
+	}
+
+	if begin, ok := ctx.Value(requestStartKey{}).(time.Time); ok {
+		fields = append(fields, zap.Duration("latency", time.Since(begin)))
+	}
+
+	if access, ok := ctx.Value(accessFieldsKey{}).(*accessFields); ok && access.amount != "" {
+		fields = append(fields, zap.String("amount", access.amount))
+	}
+
+	tracing.Logger(ctx, s.logger).Info("request", fields...)
+}
+
+// logResult log failure of action, success is only logged at debug level since access log already record it.
+func logResult(logger *zap.Logger, action string, err *error) {
+	if *err != nil {
+		logger.Error(action+" failed", zap.Error(*err))
+	} else {
+		logger.Debug(action + " succeeded")
+	}
+}
@@ -0,0 +1,165 @@
+package httpapi
+
+import (
+	"anymind"
+	"anymind/src/tracing"
+	"context"
+	"errors"
+	"github.com/cockroachdb/apd"
+	"github.com/go-kit/kit/endpoint"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const auditPath = "/audit"
+
+// defaultAuditLimit and maxAuditLimit bound page size of audit query.
+const defaultAuditLimit = 100
+const maxAuditLimit = 1000
+
+type auditEntry struct {
+	ID          int64     `json:"id"`
+	At          time.Time `json:"at"`
+	Actor       string    `json:"actor"`
+	ClientIP    string    `json:"clientIp"`
+	RequestID   string    `json:"requestId"`
+	Action      string    `json:"action"`
+	PayloadHash string    `json:"payloadHash"`
+	Outcome     string    `json:"outcome"`
+	PrevHash    string    `json:"prevHash"`
+	Hash        string    `json:"hash"`
+}
+
+func decodeAuditRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	query := r.URL.Query()
+	filter := &anymind.AuditFilter{
+		Actor:  query.Get("actor"),
+		Action: query.Get("action"),
+		Limit:  defaultAuditLimit,
+	}
+
+	var err error
+	if after := query.Get("after"); after != "" {
+		filter.AfterID, err = strconv.ParseInt(after, 10, 64)
+		if err != nil || filter.AfterID < 0 {
+			return nil, anymind.ParameterError(errors.New("after must be an entry id"))
+		}
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		filter.Limit, err = strconv.Atoi(limit)
+		if err != nil || filter.Limit < 1 || filter.Limit > maxAuditLimit {
+			return nil, anymind.ParameterError(errors.New("limit must be between 1 and 1000"))
+		}
+	}
+
+	return filter, nil
+}
+
+func listAuditEndpoint(logger *zap.Logger, s anymind.AuditService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (result interface{}, err error) {
+		defer logResult(tracing.Logger(ctx, logger), "list audit", &err)
+
+		entries, err := s.ListAudit(ctx, request.(*anymind.AuditFilter))
+		if err != nil {
+			return nil, err
+		}
+
+		res := make([]*auditEntry, len(entries))
+		for i, e := range entries {
+			res[i] = &auditEntry{
+				ID:          e.ID,
+				At:          e.At,
+				Actor:       e.Actor,
+				ClientIP:    e.ClientIP,
+				RequestID:   e.RequestID,
+				Action:      e.Action,
+				PayloadHash: e.PayloadHash,
+				Outcome:     e.Outcome,
+				PrevHash:    e.PrevHash,
+				Hash:        e.Hash,
+			}
+		}
+
+		return &APIResponse{JSONPayload: res}, nil
+	}
+}
+
+// auditFailure record action rejected or failed by endpoint, successful action is audited by persistence
+// in the transaction of the change. It must be outermost middleware, so authorization failure is recorded too.
+// Throttled request is not recorded, otherwise a flood would write as much to audit log as it is denied.
+func auditFailure(logger *zap.Logger, audits anymind.AuditService, action string) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		if audits == nil {
+			return next
+		}
+
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			res, err := next(ctx, request)
+			if err == nil {
+				return res, nil
+			}
+
+			outcome := anymind.AuditFailed
+			var anyerr *anymind.Error
+			if errors.As(err, &anyerr) {
+				if anyerr.Type == anymind.TooManyRequestsErr {
+					return res, err
+				}
+
+				if anyerr.Type != anymind.InternalErr {
+					outcome = anymind.AuditRejected
+				}
+			}
+
+			auditErr := audits.RecordAudit(ctx, action, auditPayload(request), outcome)
+			if auditErr != nil {
+				tracing.Logger(ctx, logger).Error("failed to record audit", zap.String("action", action), zap.Error(auditErr))
+			}
+
+			return res, err
+		}
+	}
+}
+
+// auditPayload leave out secrets of request, deposit is normalized like persistence does so equal deposits hash alike.
+func auditPayload(request interface{}) any {
+	switch req := request.(type) {
+	case *depositRequest:
+		var amount apd.Decimal
+		if _, _, err := amount.SetString(req.Amount.String()); err == nil {
+			return anymind.DepositAuditPayload(&anymind.DepositInput{
+				DateTime: req.DateTime, Amount: amount, Asset: req.Asset, Status: req.Status})
+		}
+
+		payload := map[string]string{
+			"datetime": req.DateTime.UTC().Truncate(time.Second).Format(time.RFC3339),
+			"amount":   req.Amount.String(),
+			"asset":    anymind.AssetCode(req.Asset),
+		}
+		if req.Status != "" && req.Status != anymind.DepositConfirmed {
+			payload["status"] = req.Status
+		}
+
+		return payload
+	case *anymind.DepositInput:
+		return anymind.DepositAuditPayload(req)
+	case *webhookRequest:
+		// secret is left out, see persistence.
+		return map[string]any{"url": req.URL, "events": req.Events, "thresholds": req.Thresholds, "asset": anymind.AssetCode(req.Asset)}
+	case *[]rateRequest:
+		return map[string]any{"rates": *req}
+	case *assetRequest:
+		return map[string]any{"code": req.Code, "scale": req.Scale, "displayPrecision": req.DisplayPrecision}
+	case *idRequest:
+		return map[string]any{"id": req.ID}
+	case *reviewAnomalyRequest:
+		return map[string]any{"id": req.ID, "status": req.Status}
+	case *depositStatusRequest:
+		return map[string]any{"id": req.ID, "status": req.Status}
+	default:
+		return request
+	}
+}
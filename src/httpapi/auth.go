@@ -0,0 +1,118 @@
+package httpapi
+
+import (
+	"anymind"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-kit/kit/endpoint"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const apiKeyHeader = "X-API-Key"
+
+// maxSignedBody is the largest signed request body read into memory for verification.
+const maxSignedBody = 1 << 20
+
+// credentialToContext store bearer token or api key sent with request, it is verified by authorize middleware.
+func credentialToContext(ctx context.Context, r *http.Request) context.Context {
+	credential := r.Header.Get(apiKeyHeader)
+
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if ok && strings.EqualFold(scheme, "Bearer") {
+		credential = strings.TrimSpace(token)
+	}
+
+	return anymind.WithCredential(ctx, credential)
+}
+
+// authorize authenticate request credential and require principal to have scope.
+// Authentication is disabled when auth is nil.
+func authorize(auth anymind.Authenticator, scope string) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		if auth == nil {
+			return next
+		}
+
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			credential := anymind.CredentialFromContext(ctx)
+			if credential == "" {
+				return nil, anymind.UnauthorizedError(errors.New("missing credential"))
+			}
+
+			principal, err := auth.Authenticate(ctx, credential)
+			if err != nil {
+				return nil, err
+			}
+
+			if !principal.HasScope(scope) {
+				return nil, anymind.ForbiddenError(fmt.Errorf("scope %s is required", scope))
+			}
+
+			return next(anymind.WithPrincipal(ctx, principal), request)
+		}
+	}
+}
+
+// unreadBody fail decoding of body signatureToContext could not read.
+type unreadBody struct {
+	err error
+}
+
+func (b unreadBody) Read([]byte) (int, error) {
+	return 0, b.err
+}
+
+// signatureToContext store request signature and the body it covers, they are verified by verifySignature
+// middleware. Body is left readable for the decoder, body over maxSignedBody fail decoding instead.
+func signatureToContext(ctx context.Context, r *http.Request) context.Context {
+	body, err := io.ReadAll(http.MaxBytesReader(nil, r.Body, maxSignedBody))
+	if err != nil {
+		r.Body = io.NopCloser(unreadBody{err: err})
+
+		return ctx
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	return anymind.WithSignedRequest(ctx, &anymind.SignedRequest{
+		KeyID:     r.Header.Get(anymind.SignatureKeyHeader),
+		Method:    r.Method,
+		Path:      r.URL.Path,
+		Timestamp: r.Header.Get(anymind.SignatureTimestampHeader),
+		Nonce:     r.Header.Get(anymind.SignatureNonceHeader),
+		Signature: r.Header.Get(anymind.SignatureHeader),
+		Body:      body,
+	})
+}
+
+// verifySignature check signature stored in context by transport, it is placed after IP rate limit so unsigned flood
+// does not reach verifier. Signature is not required when verifier is nil.
+func verifySignature(verifier anymind.RequestVerifier) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		if verifier == nil {
+			return next
+		}
+
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			_, span := tracer.Start(ctx, "verify signature")
+			defer span.End()
+
+			signed, ok := anymind.SignedRequestFromContext(ctx)
+			if !ok {
+				return nil, anymind.UnauthorizedError(errors.New("request is not signed"))
+			}
+
+			err := verifier.Verify(ctx, signed)
+			if err != nil {
+				span.RecordError(err)
+
+				return nil, err
+			}
+
+			return next(ctx, request)
+		}
+	}
+}
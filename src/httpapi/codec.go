@@ -2,6 +2,7 @@ package httpapi
 
 import (
 	"anymind"
+	"anymind/src/tracing"
 	"context"
 	"encoding/json"
 	transport "github.com/go-kit/kit/transport/http"
@@ -19,12 +20,16 @@ type APIErrorResponse struct {
 }
 
 func decoder[T any](logger *zap.Logger) func(context.Context, *http.Request) (interface{}, error) {
-	return func(_ context.Context, r *http.Request) (interface{}, error) {
+	return func(ctx context.Context, r *http.Request) (interface{}, error) {
+		_, span := tracer.Start(ctx, "decode request")
+		defer span.End()
+
 		var req T
 		err := json.NewDecoder(r.Body).Decode(&req)
 
 		if err != nil {
-			logger.Error("parsing error", zap.Error(err))
+			span.RecordError(err)
+			tracing.Logger(ctx, logger).Error("parsing error", zap.Error(err))
 
 			return nil, anymind.ParameterError(err)
 		}
@@ -46,6 +51,12 @@ func encodeAPIResponse(_ context.Context, w http.ResponseWriter, response interf
 
 var httpInternalServerCode = http.StatusInternalServerError
 var httpBadRequestCode = http.StatusBadRequest
+var httpNotFoundCode = http.StatusNotFound
+var httpUnauthorizedCode = http.StatusUnauthorized
+var httpForbiddenCode = http.StatusForbidden
+var httpTooManyRequestsCode = http.StatusTooManyRequests
+var httpCreatedCode = http.StatusCreated
+var httpAcceptedCode = http.StatusAccepted
 
 func errorHandler(logger *zap.Logger) transport.ErrorEncoder {
 	return func(ctx context.Context, err error, w http.ResponseWriter) {
@@ -55,11 +66,29 @@ func errorHandler(logger *zap.Logger) transport.ErrorEncoder {
 
 		anyerr, ok := (err).(*anymind.Error)
 		if ok {
+			// cause can carry extra response header, e.g. Retry-After of throttled request.
+			if headerer, ok := anyerr.Cause.(transport.Headerer); ok {
+				for k, values := range headerer.Headers() {
+					for _, v := range values {
+						w.Header().Add(k, v)
+					}
+				}
+			}
+
 			switch anyerr.Type {
 			case anymind.InternalErr:
 				resp.StatusCode = &httpInternalServerCode
 			case anymind.ParameterErr:
 				resp.StatusCode = &httpBadRequestCode
+			case anymind.NotFoundErr:
+				resp.StatusCode = &httpNotFoundCode
+			case anymind.UnauthorizedErr:
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				resp.StatusCode = &httpUnauthorizedCode
+			case anymind.ForbiddenErr:
+				resp.StatusCode = &httpForbiddenCode
+			case anymind.TooManyRequestsErr:
+				resp.StatusCode = &httpTooManyRequestsCode
 			default:
 				resp.StatusCode = &httpInternalServerCode
 			}
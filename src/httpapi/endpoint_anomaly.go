@@ -0,0 +1,126 @@
+package httpapi
+
+import (
+	"anymind"
+	"anymind/src/tracing"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/go-kit/kit/endpoint"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const anomaliesPath = "/anomalies"
+const reviewAnomalyPath = "/anomalies/{id:[0-9]+}/review"
+
+// defaultAnomalyLimit is number of anomalies listed when limit is not given.
+const defaultAnomalyLimit = 100
+
+type anomaliesRequest struct {
+	Status string
+	Limit  int
+}
+
+type reviewAnomalyRequest struct {
+	ID int64 `json:"-"`
+	// Status is released, crediting the deposit, or rejected.
+	Status string `json:"status"`
+}
+
+type anomalyEntry struct {
+	ID            int64      `json:"id"`
+	DateTime      time.Time  `json:"datetime"`
+	Amount        string     `json:"amount"`
+	Asset         string     `json:"asset"`
+	Reason        string     `json:"reason"`
+	Score         float64    `json:"score"`
+	Status        string     `json:"status"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	ReviewedAt    *time.Time `json:"reviewedAt"`
+	DepositStatus string     `json:"depositStatus"`
+	DepositID     int64      `json:"depositId,omitempty"`
+}
+
+func decodeAnomaliesRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	query := r.URL.Query()
+	req := &anomaliesRequest{Status: query.Get("status"), Limit: defaultAnomalyLimit}
+
+	if limit := query.Get("limit"); limit != "" {
+		var err error
+		req.Limit, err = strconv.Atoi(limit)
+		if err != nil {
+			return nil, anymind.ParameterError(errors.New("limit must be a number"))
+		}
+	}
+
+	return req, nil
+}
+
+func decodeReviewAnomalyRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		return nil, anymind.ParameterError(errors.New("invalid id"))
+	}
+
+	req := &reviewAnomalyRequest{ID: id}
+	err = json.NewDecoder(r.Body).Decode(req)
+	if err != nil {
+		return nil, anymind.ParameterError(err)
+	}
+
+	return req, nil
+}
+
+func listAnomaliesEndpoint(logger *zap.Logger, s anymind.AnomalyService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (result interface{}, err error) {
+		defer logResult(tracing.Logger(ctx, logger), "list anomalies", &err)
+
+		req := request.(*anomaliesRequest)
+		anomalies, err := s.ListAnomalies(ctx, req.Status, req.Limit)
+		if err != nil {
+			return nil, err
+		}
+
+		entries := make([]*anomalyEntry, 0, len(anomalies))
+		for _, anomaly := range anomalies {
+			entries = append(entries, toAnomalyEntry(anomaly))
+		}
+
+		return &APIResponse{JSONPayload: entries}, nil
+	}
+}
+
+func reviewAnomalyEndpoint(logger *zap.Logger, s anymind.AnomalyService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (result interface{}, err error) {
+		defer logResult(tracing.Logger(ctx, logger), "review anomaly", &err)
+
+		req := request.(*reviewAnomalyRequest)
+		anomaly, err := s.ReviewAnomaly(ctx, req.ID, req.Status)
+		if err != nil {
+			return nil, err
+		}
+
+		return &APIResponse{JSONPayload: toAnomalyEntry(anomaly)}, nil
+	}
+}
+
+func toAnomalyEntry(anomaly *anymind.Anomaly) *anomalyEntry {
+	return &anomalyEntry{
+		ID:            anomaly.ID,
+		DateTime:      anomaly.DateTime,
+		Amount:        fmt.Sprintf("%f", &anomaly.Amount),
+		Asset:         anomaly.Asset,
+		Reason:        anomaly.Reason,
+		Score:         anomaly.Score,
+		Status:        anomaly.Status,
+		CreatedAt:     anomaly.CreatedAt,
+		ReviewedAt:    anomaly.ReviewedAt,
+		DepositStatus: anomaly.DepositStatus,
+		DepositID:     anomaly.DepositID,
+	}
+}
@@ -0,0 +1,75 @@
+package httpapi
+
+import (
+	"anymind"
+	"anymind/src/tracing"
+	"context"
+	"github.com/go-kit/kit/endpoint"
+	"go.uber.org/zap"
+	"time"
+)
+
+const assetsPath = "/assets"
+
+type assetRequest struct {
+	Code             string `json:"code"`
+	Scale            int    `json:"scale"`
+	DisplayPrecision int    `json:"displayPrecision"`
+}
+
+type assetEntry struct {
+	Code             string    `json:"code"`
+	Scale            int       `json:"scale"`
+	DisplayPrecision int       `json:"displayPrecision"`
+	CreatedAt        time.Time `json:"createdAt"`
+}
+
+func createAssetEndpoint(logger *zap.Logger, s anymind.AssetService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (result interface{}, err error) {
+		defer logResult(tracing.Logger(ctx, logger), "create asset", &err)
+
+		req := request.(*assetRequest)
+		asset := &anymind.Asset{
+			Code:             req.Code,
+			Scale:            req.Scale,
+			DisplayPrecision: req.DisplayPrecision,
+		}
+
+		err = s.CreateAsset(ctx, asset)
+		if err != nil {
+			return nil, err
+		}
+
+		return &APIResponse{
+			JSONPayload: toAssetEntry(asset),
+			StatusCode:  &httpCreatedCode,
+		}, nil
+	}
+}
+
+func listAssetsEndpoint(logger *zap.Logger, s anymind.AssetService) endpoint.Endpoint {
+	return func(ctx context.Context, _ interface{}) (result interface{}, err error) {
+		defer logResult(tracing.Logger(ctx, logger), "list assets", &err)
+
+		assets, err := s.ListAssets(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		entries := make([]*assetEntry, 0, len(assets))
+		for _, asset := range assets {
+			entries = append(entries, toAssetEntry(asset))
+		}
+
+		return &APIResponse{JSONPayload: entries}, nil
+	}
+}
+
+func toAssetEntry(asset *anymind.Asset) *assetEntry {
+	return &assetEntry{
+		Code:             asset.Code,
+		Scale:            asset.Scale,
+		DisplayPrecision: asset.DisplayPrecision,
+		CreatedAt:        asset.CreatedAt,
+	}
+}
@@ -0,0 +1,67 @@
+package httpapi
+
+import (
+	"anymind"
+	"anymind/src/tracing"
+	"context"
+	"fmt"
+	"github.com/go-kit/kit/endpoint"
+	"go.uber.org/zap"
+	"time"
+)
+
+const candlesPath = "/candles"
+
+type candlesRequest struct {
+	Start time.Time `json:"startDatetime"`
+	End   time.Time `json:"endDateTime"`
+	Asset string    `json:"asset,omitempty"`
+	// Granularity is a go duration such as 1h, 4h or 24h, 1h when empty.
+	Granularity string `json:"granularity,omitempty"`
+}
+
+type candleEntry struct {
+	DateTime time.Time `json:"datetime"`
+	Open     string    `json:"open"`
+	High     string    `json:"high"`
+	Low      string    `json:"low"`
+	Close    string    `json:"close"`
+}
+
+func candlesEndpoint(logger *zap.Logger, s anymind.CandleService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (result interface{}, err error) {
+		defer logResult(tracing.Logger(ctx, logger), "candles", &err)
+
+		req := request.(*candlesRequest)
+		candlereq := &anymind.CandleReq{
+			Start: req.Start.UTC(),
+			End:   req.End.UTC(),
+			Asset: req.Asset,
+		}
+
+		if req.Granularity != "" {
+			candlereq.Granularity, err = time.ParseDuration(req.Granularity)
+			if err != nil {
+				return nil, anymind.ParameterError(fmt.Errorf("invalid granularity: %w", err))
+			}
+		}
+
+		res, err := s.Candles(ctx, candlereq)
+		if err != nil {
+			return nil, err
+		}
+
+		entries := make([]*candleEntry, 0, len(res))
+		for _, candle := range res {
+			entries = append(entries, &candleEntry{
+				DateTime: candle.DateTime,
+				Open:     fmt.Sprintf("%f", &candle.Open),
+				High:     fmt.Sprintf("%f", &candle.High),
+				Low:      fmt.Sprintf("%f", &candle.Low),
+				Close:    fmt.Sprintf("%f", &candle.Close),
+			})
+		}
+
+		return &APIResponse{JSONPayload: entries}, nil
+	}
+}
@@ -0,0 +1,54 @@
+package httpapi
+
+import (
+	"anymind"
+	"anymind/src/tracing"
+	"context"
+	"github.com/go-kit/kit/endpoint"
+	"go.uber.org/zap"
+)
+
+const adminChainPath = "/admin/chain/verify"
+
+type chainBreakEntry struct {
+	ID         int64  `json:"id"`
+	Checkpoint bool   `json:"checkpoint"`
+	Reason     string `json:"reason"`
+}
+
+type chainResponse struct {
+	Intact      bool             `json:"intact"`
+	Verified    int              `json:"verified"`
+	HeadID      int64            `json:"headId"`
+	HeadHash    string           `json:"headHash"`
+	Checkpoints int              `json:"checkpoints"`
+	Break       *chainBreakEntry `json:"break,omitempty"`
+}
+
+func verifyChainEndpoint(logger *zap.Logger, s anymind.ChainService) endpoint.Endpoint {
+	return func(ctx context.Context, _ interface{}) (result interface{}, err error) {
+		defer logResult(tracing.Logger(ctx, logger), "verify chain", &err)
+
+		report, err := s.VerifyChain(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		res := &chainResponse{
+			Intact:      report.Break == nil,
+			Verified:    report.Verified,
+			HeadID:      report.HeadID,
+			HeadHash:    report.HeadHash,
+			Checkpoints: report.Checkpoints,
+		}
+		if report.Break != nil {
+			res.Break = &chainBreakEntry{
+				ID:         report.Break.ID,
+				Checkpoint: report.Break.Checkpoint,
+				Reason:     report.Break.Reason,
+			}
+		}
+
+		return &APIResponse{JSONPayload: res}, nil
+	}
+}
@@ -0,0 +1,33 @@
+package httpapi
+
+import (
+	"anymind"
+	"anymind/src/tracing"
+	"context"
+	"github.com/go-kit/kit/endpoint"
+	"go.uber.org/zap"
+	"time"
+)
+
+type configResponse struct {
+	Version  int            `json:"version"`
+	LoadedAt time.Time      `json:"loadedAt"`
+	Settings map[string]any `json:"settings"`
+}
+
+func activeConfigEndpoint(logger *zap.Logger, s anymind.ConfigService) endpoint.Endpoint {
+	return func(ctx context.Context, _ interface{}) (result interface{}, err error) {
+		defer logResult(tracing.Logger(ctx, logger), "active config", &err)
+
+		cfg, err := s.ActiveConfig(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		return &APIResponse{JSONPayload: &configResponse{
+			Version:  cfg.Version,
+			LoadedAt: cfg.LoadedAt,
+			Settings: cfg.Settings,
+		}}, nil
+	}
+}
@@ -2,32 +2,62 @@ package httpapi
 
 import (
 	"anymind"
+	"anymind/src/tracing"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/cockroachdb/apd"
 	"github.com/go-kit/kit/endpoint"
+	"github.com/gorilla/mux"
 	"go.uber.org/zap"
+	"net/http"
+	"strconv"
 	"time"
 )
 
+const depositStatusPath = "/deposits/{id:[0-9]+}"
+
 type depositRequest struct {
 	DateTime time.Time   `json:"datetime"`
 	Amount   json.Number `json:"amount"`
+	// Asset is anymind.DefaultAsset when empty.
+	Asset string `json:"asset,omitempty"`
+	// Status is pending to store deposit without crediting it until it is confirmed, confirmed when empty.
+	Status string `json:"status,omitempty"`
+}
+
+type depositResponse struct {
+	// ID is used to settle pending deposit, it is not set for deposit held for review.
+	ID int64 `json:"id,omitempty"`
+	// AnomalyID is reference of deposit held for review, Status is then held.
+	AnomalyID int64       `json:"anomalyId,omitempty"`
+	DateTime  time.Time   `json:"datetime"`
+	Amount    json.Number `json:"amount"`
+	Asset     string      `json:"asset,omitempty"`
+	Status    string      `json:"status"`
 }
 
-type depositResponse depositRequest
+type depositStatusRequest struct {
+	ID int64 `json:"-"`
+	// Status is confirmed, crediting the deposit, or failed.
+	Status string `json:"status"`
+}
+
+type depositEventEntry struct {
+	ID       int64     `json:"id"`
+	DateTime time.Time `json:"datetime"`
+	Amount   string    `json:"amount"`
+	Asset    string    `json:"asset"`
+	Status   string    `json:"status"`
+}
 
 func depositEndpoint(logger *zap.Logger, s anymind.APIService) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (result interface{}, err error) {
-		defer func() {
-			if err != nil {
-				logger.Error("error deposit request", zap.Error(err))
-			} else {
-				logger.Info("success deposit request")
-			}
-		}()
+		defer logResult(tracing.Logger(ctx, logger), "deposit", &err)
 
 		req := request.(*depositRequest)
+		setAccessAmount(ctx, req.Amount.String())
 		amount, _, err := apd.NewFromString(req.Amount.String())
 		if err != nil {
 			return nil, anymind.InternalError(err)
@@ -36,14 +66,63 @@ func depositEndpoint(logger *zap.Logger, s anymind.APIService) endpoint.Endpoint
 		input := anymind.DepositInput{
 			DateTime: req.DateTime.UTC(),
 			Amount:   *amount,
+			Asset:    req.Asset,
+			Status:   req.Status,
 		}
 		err = s.Deposit(ctx, &input)
 		if err != nil {
 			return nil, err
 		}
 
+		res := &depositResponse{
+			ID:        input.ID,
+			AnomalyID: input.AnomalyID,
+			DateTime:  req.DateTime,
+			Amount:    req.Amount,
+			Asset:     input.Asset,
+			Status:    anymind.DepositStatus(req.Status),
+		}
+		if input.AnomalyID != 0 {
+			res.Status = anymind.AnomalyHeld
+		}
+
+		return &APIResponse{JSONPayload: res}, nil
+	}
+}
+
+func decodeDepositStatusRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		return nil, anymind.ParameterError(errors.New("invalid id"))
+	}
+
+	req := &depositStatusRequest{ID: id}
+	err = json.NewDecoder(r.Body).Decode(req)
+	if err != nil {
+		return nil, anymind.ParameterError(err)
+	}
+
+	return req, nil
+}
+
+func depositStatusEndpoint(logger *zap.Logger, s anymind.DepositStatusService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (result interface{}, err error) {
+		defer logResult(tracing.Logger(ctx, logger), "deposit status", &err)
+
+		req := request.(*depositStatusRequest)
+		event, err := s.SetDepositStatus(ctx, req.ID, req.Status)
+		if err != nil {
+			return nil, err
+		}
+
 		return &APIResponse{
-			JSONPayload: (*depositResponse)(req),
+			JSONPayload: &depositEventEntry{
+				ID:       event.ID,
+				DateTime: event.DateTime,
+				Amount:   fmt.Sprintf("%f", &event.Amount),
+				Asset:    event.Asset,
+				Status:   req.Status,
+			},
 		}, nil
 	}
 }
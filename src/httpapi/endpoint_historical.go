@@ -2,6 +2,7 @@ package httpapi
 
 import (
 	"anymind"
+	"anymind/src/tracing"
 	"context"
 	"fmt"
 	"github.com/cockroachdb/apd"
@@ -13,27 +14,32 @@ import (
 type historicalRequest struct {
 	Start time.Time `json:"startDatetime"`
 	End   time.Time `json:"endDateTime"`
+	Asset string    `json:"asset,omitempty"`
+	// Quote value each hour in quote asset, see anymind.ValueHourly.
+	Quote string `json:"quote,omitempty"`
+	// IncludePending add pending deposits to confirmed balance.
+	IncludePending bool `json:"includePending,omitempty"`
 }
 
 type historicalEntry struct {
 	DateTime time.Time `json:"datetime"`
 	Amount   string    `json:"amount"`
+	// Value and RateMissing are only set when quote is requested.
+	Value       *string `json:"value,omitempty"`
+	RateMissing bool    `json:"rateMissing,omitempty"`
 }
 
 func historicalEndpoint(logger *zap.Logger, s anymind.APIService) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (result interface{}, err error) {
-		defer func() {
-			if err != nil {
-				logger.Error("error deposit request", zap.Error(err))
-			} else {
-				logger.Info("success deposit request")
-			}
-		}()
+		defer logResult(tracing.Logger(ctx, logger), "historical", &err)
 
 		req := request.(*historicalRequest)
 		histreq := &anymind.HistoricalDataReq{
-			Start: req.Start.UTC(),
-			End:   req.End.UTC(),
+			Start:          req.Start.UTC(),
+			End:            req.End.UTC(),
+			Asset:          req.Asset,
+			Quote:          req.Quote,
+			IncludePending: req.IncludePending,
 		}
 
 		res, err := s.Historical(ctx, histreq)
@@ -49,27 +55,24 @@ func historicalEndpoint(logger *zap.Logger, s anymind.APIService) endpoint.Endpo
 
 func toHistoricalEntryArray(req *anymind.HistoricalDataReq, entries []*anymind.HistoricalData) []*historicalEntry {
 	var res []*historicalEntry
-
-	pos := 0
-	cur := req.Start.Truncate(time.Second).Add(-time.Second).Truncate(time.Hour).Add(time.Hour)
-	val := apd.New(0, 0)
-	for {
-		if cur.After(req.End) {
-			break
-		}
-
-		if pos < len(entries) && entries[pos].DateTime.Equal(cur) {
-			val = &entries[pos].Amount
-			pos++
-		}
-
+	for _, entry := range anymind.FillHourly(req, entries) {
 		res = append(res, &historicalEntry{
-			DateTime: cur,
-			Amount:   fmt.Sprintf("%f", val),
+			DateTime:    entry.DateTime,
+			Amount:      fmt.Sprintf("%f", &entry.Amount),
+			Value:       formatValue(entry.Value),
+			RateMissing: req.Quote != "" && entry.Value == nil,
 		})
-
-		cur = cur.Add(time.Hour)
 	}
 
 	return res
 }
+
+func formatValue(value *apd.Decimal) *string {
+	if value == nil {
+		return nil
+	}
+
+	res := fmt.Sprintf("%f", value)
+
+	return &res
+}
@@ -0,0 +1,49 @@
+package httpapi
+
+import (
+	"anymind"
+	"anymind/src/tracing"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/go-kit/kit/endpoint"
+	"go.uber.org/zap"
+	"time"
+)
+
+const adminRatesPath = "/admin/rates"
+
+type rateRequest struct {
+	Hour  time.Time   `json:"hour"`
+	Base  string      `json:"base"`
+	Quote string      `json:"quote"`
+	Rate  json.Number `json:"rate"`
+}
+
+type importRatesResponse struct {
+	Imported int `json:"imported"`
+}
+
+func importRatesEndpoint(logger *zap.Logger, s anymind.PriceService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (result interface{}, err error) {
+		defer logResult(tracing.Logger(ctx, logger), "import rates", &err)
+
+		req := *request.(*[]rateRequest)
+		rates := make([]*anymind.Rate, len(req))
+		for i := range req {
+			rates[i] = &anymind.Rate{Hour: req[i].Hour, Base: req[i].Base, Quote: req[i].Quote}
+
+			_, _, err = rates[i].Rate.SetString(req[i].Rate.String())
+			if err != nil {
+				return nil, anymind.ParameterError(fmt.Errorf("rate %d: %w", i, err))
+			}
+		}
+
+		err = s.ImportRates(ctx, rates)
+		if err != nil {
+			return nil, err
+		}
+
+		return &APIResponse{JSONPayload: &importRatesResponse{Imported: len(rates)}}, nil
+	}
+}
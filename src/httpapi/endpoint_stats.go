@@ -0,0 +1,63 @@
+package httpapi
+
+import (
+	"anymind"
+	"anymind/src/tracing"
+	"context"
+	"fmt"
+	"github.com/go-kit/kit/endpoint"
+	"go.uber.org/zap"
+	"time"
+)
+
+const statsPath = "/stats"
+
+type statsRequest struct {
+	Start time.Time `json:"startDatetime"`
+	End   time.Time `json:"endDateTime"`
+	Asset string    `json:"asset,omitempty"`
+}
+
+type statsEntry struct {
+	DateTime time.Time `json:"datetime"`
+	Count    int64     `json:"count"`
+	Delta    string    `json:"delta"`
+	// Min, Max and Average are null when there is no deposit in the hour.
+	Min     *string `json:"min"`
+	Max     *string `json:"max"`
+	Average *string `json:"average"`
+	Open    string  `json:"open"`
+	Close   string  `json:"close"`
+}
+
+func statsEndpoint(logger *zap.Logger, s anymind.StatsService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (result interface{}, err error) {
+		defer logResult(tracing.Logger(ctx, logger), "stats", &err)
+
+		req := request.(*statsRequest)
+		res, err := s.Stats(ctx, &anymind.HistoricalDataReq{
+			Start: req.Start.UTC(),
+			End:   req.End.UTC(),
+			Asset: req.Asset,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		entries := make([]*statsEntry, 0, len(res))
+		for _, bucket := range res {
+			entries = append(entries, &statsEntry{
+				DateTime: bucket.DateTime,
+				Count:    bucket.Count,
+				Delta:    fmt.Sprintf("%f", &bucket.Delta),
+				Min:      formatValue(bucket.Min),
+				Max:      formatValue(bucket.Max),
+				Average:  formatValue(bucket.Average),
+				Open:     fmt.Sprintf("%f", &bucket.Open),
+				Close:    fmt.Sprintf("%f", &bucket.Close),
+			})
+		}
+
+		return &APIResponse{JSONPayload: entries}, nil
+	}
+}
@@ -0,0 +1,212 @@
+package httpapi
+
+import (
+	"anymind"
+	"anymind/src/tracing"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/cockroachdb/apd"
+	"github.com/go-kit/kit/endpoint"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type webhookRequest struct {
+	URL        string        `json:"url"`
+	Events     []string      `json:"events"`
+	Secret     string        `json:"secret"`
+	Thresholds []json.Number `json:"thresholds"`
+	// Asset is asset whose balance thresholds are checked against, anymind.DefaultAsset when empty.
+	Asset string `json:"asset,omitempty"`
+}
+
+type webhookEntry struct {
+	ID         int64     `json:"id"`
+	URL        string    `json:"url"`
+	Events     []string  `json:"events"`
+	Thresholds []string  `json:"thresholds"`
+	Secret     string    `json:"secret,omitempty"`
+	Asset      string    `json:"asset"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+type deliveryEntry struct {
+	ID             int64           `json:"id"`
+	SubscriptionID int64           `json:"subscriptionId"`
+	URL            string          `json:"url"`
+	EventType      string          `json:"eventType"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  time.Time       `json:"nextAttemptAt"`
+	LastError      string          `json:"lastError"`
+	CreatedAt      time.Time       `json:"createdAt"`
+	DeliveredAt    *time.Time      `json:"deliveredAt"`
+}
+
+type idResponse struct {
+	ID int64 `json:"id"`
+}
+
+type redeliverResponse struct {
+	ID     int64  `json:"id"`
+	Status string `json:"status"`
+}
+
+type idRequest struct {
+	ID int64
+}
+
+type deliveriesRequest struct {
+	Status string
+}
+
+func decodeIDRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		return nil, anymind.ParameterError(errors.New("invalid id"))
+	}
+
+	return &idRequest{ID: id}, nil
+}
+
+func decodeDeliveriesRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	return &deliveriesRequest{Status: r.URL.Query().Get("status")}, nil
+}
+
+func createWebhookEndpoint(logger *zap.Logger, s anymind.WebhookService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (result interface{}, err error) {
+		defer logResult(tracing.Logger(ctx, logger), "create webhook", &err)
+
+		req := request.(*webhookRequest)
+		sub := &anymind.WebhookSubscription{
+			URL:        req.URL,
+			Events:     req.Events,
+			Secret:     req.Secret,
+			Thresholds: make([]apd.Decimal, len(req.Thresholds)),
+			Asset:      req.Asset,
+		}
+
+		for i := range req.Thresholds {
+			_, _, err = sub.Thresholds[i].SetString(req.Thresholds[i].String())
+			if err != nil {
+				return nil, anymind.ParameterError(err)
+			}
+		}
+
+		err = s.CreateWebhook(ctx, sub)
+		if err != nil {
+			return nil, err
+		}
+
+		entry := toWebhookEntry(sub)
+		// secret is only shown once on creation.
+		entry.Secret = sub.Secret
+
+		return &APIResponse{
+			JSONPayload: entry,
+			StatusCode:  &httpCreatedCode,
+		}, nil
+	}
+}
+
+func listWebhooksEndpoint(logger *zap.Logger, s anymind.WebhookService) endpoint.Endpoint {
+	return func(ctx context.Context, _ interface{}) (result interface{}, err error) {
+		defer logResult(tracing.Logger(ctx, logger), "list webhooks", &err)
+
+		subs, err := s.ListWebhooks(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		entries := make([]*webhookEntry, 0, len(subs))
+		for _, sub := range subs {
+			entries = append(entries, toWebhookEntry(sub))
+		}
+
+		return &APIResponse{JSONPayload: entries}, nil
+	}
+}
+
+func deleteWebhookEndpoint(logger *zap.Logger, s anymind.WebhookService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (result interface{}, err error) {
+		defer logResult(tracing.Logger(ctx, logger), "delete webhook", &err)
+
+		req := request.(*idRequest)
+		err = s.DeleteWebhook(ctx, req.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		return &APIResponse{JSONPayload: &idResponse{ID: req.ID}}, nil
+	}
+}
+
+func listDeliveriesEndpoint(logger *zap.Logger, s anymind.WebhookService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (result interface{}, err error) {
+		defer logResult(tracing.Logger(ctx, logger), "list webhook deliveries", &err)
+
+		req := request.(*deliveriesRequest)
+		deliveries, err := s.ListDeliveries(ctx, req.Status)
+		if err != nil {
+			return nil, err
+		}
+
+		entries := make([]*deliveryEntry, 0, len(deliveries))
+		for _, d := range deliveries {
+			entries = append(entries, &deliveryEntry{
+				ID:             d.ID,
+				SubscriptionID: d.SubscriptionID,
+				URL:            d.URL,
+				EventType:      d.EventType,
+				Payload:        d.Payload,
+				Status:         d.Status,
+				Attempts:       d.Attempts,
+				NextAttemptAt:  d.NextAttemptAt,
+				LastError:      d.LastError,
+				CreatedAt:      d.CreatedAt,
+				DeliveredAt:    d.DeliveredAt,
+			})
+		}
+
+		return &APIResponse{JSONPayload: entries}, nil
+	}
+}
+
+func redeliverEndpoint(logger *zap.Logger, s anymind.WebhookService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (result interface{}, err error) {
+		defer logResult(tracing.Logger(ctx, logger), "redeliver webhook", &err)
+
+		req := request.(*idRequest)
+		err = s.Redeliver(ctx, req.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		return &APIResponse{
+			JSONPayload: &redeliverResponse{ID: req.ID, Status: anymind.DeliveryPending},
+			StatusCode:  &httpAcceptedCode,
+		}, nil
+	}
+}
+
+func toWebhookEntry(sub *anymind.WebhookSubscription) *webhookEntry {
+	thresholds := make([]string, len(sub.Thresholds))
+	for i := range sub.Thresholds {
+		thresholds[i] = fmt.Sprintf("%f", &sub.Thresholds[i])
+	}
+
+	return &webhookEntry{
+		ID:         sub.ID,
+		URL:        sub.URL,
+		Events:     sub.Events,
+		Thresholds: thresholds,
+		Asset:      anymind.AssetCode(sub.Asset),
+		CreatedAt:  sub.CreatedAt,
+	}
+}
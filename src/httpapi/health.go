@@ -0,0 +1,96 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"go.uber.org/zap"
+	"net/http"
+	"sort"
+	"time"
+)
+
+const healthzPath = "/healthz"
+const readyzPath = "/readyz"
+
+// healthCheckTimeout bound readiness probe so slow dependency is reported instead of hanging the probe.
+const healthCheckTimeout = 2 * time.Second
+
+const (
+	statusOK          = "ok"
+	statusUnavailable = "unavailable"
+	statusDraining    = "draining"
+)
+
+type healthResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// healthzHandler report the process is alive, it does not depend on anything.
+func healthzHandler(w http.ResponseWriter, _ *http.Request) {
+	writeHealth(w, http.StatusOK, &healthResponse{Status: statusOK})
+}
+
+// readyzHandler report whether the service should receive traffic: it is not draining and every check pass.
+func (s *Service) readyzHandler(w http.ResponseWriter, r *http.Request) {
+	if s.draining.Load() {
+		writeHealth(w, http.StatusServiceUnavailable, &healthResponse{Status: statusDraining})
+
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), healthCheckTimeout)
+	defer cancel()
+
+	names := make([]string, 0, len(s.healthChecks))
+	for name := range s.healthChecks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	resp := &healthResponse{Status: statusOK, Checks: map[string]string{}}
+	for _, name := range names {
+		err := s.healthChecks[name].CheckHealth(ctx)
+		if err != nil {
+			s.logger.Warn("health check failed", zap.String("check", name), zap.Error(err))
+			resp.Status = statusUnavailable
+			resp.Checks[name] = err.Error()
+
+			continue
+		}
+
+		resp.Checks[name] = statusOK
+	}
+
+	if resp.Status != statusOK {
+		writeHealth(w, http.StatusServiceUnavailable, resp)
+
+		return
+	}
+
+	writeHealth(w, http.StatusOK, resp)
+}
+
+// rejectWhileDraining answer 503 to api request once shutdown begin, probes are still served.
+func (s *Service) rejectWhileDraining(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.draining.Load() && r.URL.Path != healthzPath && r.URL.Path != readyzPath {
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.Header().Set("Connection", "close")
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			json.NewEncoder(w).Encode(APIErrorResponse{Message: "service is shutting down"})
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func writeHealth(w http.ResponseWriter, statusCode int, resp *healthResponse) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(resp)
+}
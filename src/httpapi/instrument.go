@@ -0,0 +1,67 @@
+package httpapi
+
+import (
+	"context"
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var tracer = otel.Tracer("anymind/src/httpapi")
+
+type requestStartKey struct{}
+
+func requestStartToContext(ctx context.Context, _ *http.Request) context.Context {
+	return context.WithValue(ctx, requestStartKey{}, time.Now())
+}
+
+// routeTemplate return path template of matched route, e.g. /webhooks/{id}.
+func routeTemplate(r *http.Request) string {
+	if current := mux.CurrentRoute(r); current != nil {
+		if tpl, err := current.GetPathTemplate(); err == nil {
+			return pathVarPattern.ReplaceAllString(tpl, "{$1}")
+		}
+	}
+
+	return r.URL.Path
+}
+
+// observeRequest record latency of request labeled by route template, method and response status.
+func (s *Service) observeRequest(ctx context.Context, code int, r *http.Request) {
+	begin, ok := ctx.Value(requestStartKey{}).(time.Time)
+	if !ok {
+		return
+	}
+
+	s.requestDuration.
+		With("route", routeTemplate(r), "method", r.Method, "status", strconv.Itoa(code)).
+		Observe(time.Since(begin).Seconds())
+}
+
+// startSpan start server span of request, continuing trace of caller sent as w3c trace context.
+func startSpan(ctx context.Context, r *http.Request) context.Context {
+	ctx = otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(r.Header))
+
+	route := routeTemplate(r)
+	ctx, _ = tracer.Start(ctx, r.Method+" "+route,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(semconv.HTTPMethod(r.Method), semconv.HTTPRoute(route)))
+
+	return ctx
+}
+
+func endSpan(ctx context.Context, code int, _ *http.Request) {
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(semconv.HTTPStatusCode(code))
+	if code >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, http.StatusText(code))
+	}
+
+	span.End()
+}
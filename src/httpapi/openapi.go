@@ -0,0 +1,469 @@
+package httpapi
+
+import (
+	"anymind"
+	"encoding/json"
+	"fmt"
+	"github.com/gorilla/mux"
+	swaggerFiles "github.com/swaggo/files/v2"
+	"go.uber.org/zap"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const openAPIPath = "/openapi.json"
+const docsPath = "/docs/"
+
+// operation describe a route in openapi document.
+// Request and response are zero value of the go type that is decoded and encoded by the handler.
+type operation struct {
+	summary  string
+	scope    string
+	request  any
+	response any
+	status   int
+	query    []string
+	// signed is set when request signature is checked, see WithRequestVerifier.
+	signed bool
+}
+
+// operations must contain every route registered by NewRouter, see TestOpenAPIMatchRouter.
+var operations = map[string]*operation{
+	"POST " + depositPath: {
+		summary:  "Deposit amount at given datetime",
+		scope:    anymind.ScopeDepositWrite,
+		request:  depositRequest{},
+		response: depositResponse{},
+		status:   http.StatusOK,
+		signed:   true,
+	},
+	"POST " + historicalPath: {
+		summary:  "Hourly confirmed balance between start and end datetime, including pending deposits when includePending is set, valued in quote asset rounded half even when quote is set",
+		scope:    anymind.ScopeHistoryRead,
+		request:  historicalRequest{},
+		response: []historicalEntry{},
+		status:   http.StatusOK,
+	},
+	"POST " + statsPath: {
+		summary:  "Hourly deposit count, net delta, min, max, average and opening and closing balance between start and end datetime",
+		scope:    anymind.ScopeHistoryRead,
+		request:  statsRequest{},
+		response: []statsEntry{},
+		status:   http.StatusOK,
+	},
+	"POST " + candlesPath: {
+		summary:  "Open, high, low and close balance of every granularity bucket between start and end datetime",
+		scope:    anymind.ScopeHistoryRead,
+		request:  candlesRequest{},
+		response: []candleEntry{},
+		status:   http.StatusOK,
+	},
+	"POST " + webhooksPath: {
+		summary:  "Create webhook subscription, secret is only returned here",
+		scope:    anymind.ScopeAdmin,
+		request:  webhookRequest{},
+		response: webhookEntry{},
+		status:   http.StatusCreated,
+	},
+	"GET " + webhooksPath: {
+		summary:  "List webhook subscriptions",
+		scope:    anymind.ScopeAdmin,
+		response: []webhookEntry{},
+		status:   http.StatusOK,
+	},
+	"DELETE /webhooks/{id}": {
+		summary:  "Delete webhook subscription",
+		scope:    anymind.ScopeAdmin,
+		response: idResponse{},
+		status:   http.StatusOK,
+	},
+	"GET " + deliveriesPath: {
+		summary:  "List latest webhook deliveries",
+		scope:    anymind.ScopeAdmin,
+		response: []deliveryEntry{},
+		status:   http.StatusOK,
+		query:    []string{"status"},
+	},
+	"POST /webhooks/deliveries/{id}/redeliver": {
+		summary:  "Schedule webhook delivery to be sent again",
+		scope:    anymind.ScopeAdmin,
+		response: redeliverResponse{},
+		status:   http.StatusAccepted,
+	},
+	"GET " + assetsPath: {
+		summary:  "List registered assets",
+		scope:    anymind.ScopeHistoryRead,
+		response: []assetEntry{},
+		status:   http.StatusOK,
+	},
+	"POST " + assetsPath: {
+		summary:  "Register asset, its scale can not be changed afterwards",
+		scope:    anymind.ScopeAdmin,
+		request:  assetRequest{},
+		response: assetEntry{},
+		status:   http.StatusCreated,
+	},
+	"POST " + adminRatesPath: {
+		summary:  "Import hourly exchange rates, rate of an already imported pair and hour is replaced",
+		scope:    anymind.ScopeAdmin,
+		request:  []rateRequest{},
+		response: importRatesResponse{},
+		status:   http.StatusOK,
+	},
+	"GET " + anomaliesPath: {
+		summary:  "List latest deposits out of pattern",
+		scope:    anymind.ScopeAdmin,
+		response: []anomalyEntry{},
+		status:   http.StatusOK,
+		query:    []string{"status", "limit"},
+	},
+	"POST /anomalies/{id}/review": {
+		summary:  "Release held deposit, crediting it, or reject it",
+		scope:    anymind.ScopeAdmin,
+		request:  reviewAnomalyRequest{},
+		response: anomalyEntry{},
+		status:   http.StatusOK,
+	},
+	"PATCH /deposits/{id}": {
+		summary:  "Confirm pending deposit, crediting it, or fail it",
+		scope:    anymind.ScopeDepositWrite,
+		request:  depositStatusRequest{},
+		response: depositEventEntry{},
+		status:   http.StatusOK,
+	},
+	"GET " + adminConfigPath: {
+		summary:  "Active configuration and its version, secrets are redacted",
+		scope:    anymind.ScopeAdmin,
+		response: configResponse{},
+		status:   http.StatusOK,
+	},
+	"GET " + adminChainPath: {
+		summary:  "Verify deposit hash chain and its signed checkpoints, break locate the first broken link",
+		scope:    anymind.ScopeAdmin,
+		response: chainResponse{},
+		status:   http.StatusOK,
+	},
+	"GET " + auditPath: {
+		summary:  "Audit log entries ordered by id, page with after",
+		scope:    anymind.ScopeAdmin,
+		response: []auditEntry{},
+		status:   http.StatusOK,
+		query:    []string{"actor", "action", "after", "limit"},
+	},
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	numberType     = reflect.TypeOf(json.Number(""))
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+	pathVarPattern = regexp.MustCompile(`\{([^:}]+)(:[^}]*)?\}`)
+)
+
+// openAPI generate openapi 3 document from routes registered in root.
+// Route without operation is reported as error, but still included in the document.
+func openAPI(root *mux.Router) (map[string]any, error) {
+	gen := &schemaGen{schemas: map[string]any{}}
+	paths := map[string]map[string]any{}
+	var undocumented []string
+
+	err := root.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		tpl, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+
+		path := pathVarPattern.ReplaceAllString(tpl, "{$1}")
+		// operational endpoints are not part of the api.
+		if path == openAPIPath || path == healthzPath || path == readyzPath || strings.HasPrefix(path, docsPath) {
+			return nil
+		}
+
+		for _, method := range methods {
+			op, ok := operations[method+" "+path]
+			if !ok {
+				undocumented = append(undocumented, method+" "+path)
+				op = &operation{status: http.StatusOK}
+			}
+
+			if paths[path] == nil {
+				paths[path] = map[string]any{}
+			}
+
+			paths[path][strings.ToLower(method)] = gen.operation(path, op)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	doc := map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":   "anymind wallet API",
+			"version": "1.0.0",
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": gen.schemas,
+			"securitySchemes": map[string]any{
+				"bearerAuth": map[string]any{
+					"type":        "http",
+					"scheme":      "bearer",
+					"description": "API key, or JWT issued by identity provider when JWKS is configured.",
+				},
+				"apiKeyAuth": map[string]any{"type": "apiKey", "in": "header", "name": apiKeyHeader},
+			},
+		},
+	}
+
+	if len(undocumented) > 0 {
+		sort.Strings(undocumented)
+
+		return doc, fmt.Errorf("undocumented route: %s", strings.Join(undocumented, ", "))
+	}
+
+	return doc, nil
+}
+
+type schemaGen struct {
+	schemas map[string]any
+}
+
+func (g *schemaGen) operation(path string, op *operation) map[string]any {
+	res := map[string]any{
+		"responses": map[string]any{
+			fmt.Sprint(op.status): map[string]any{
+				"description": http.StatusText(op.status),
+				"content":     g.content(op.response),
+			},
+			"default": map[string]any{
+				"description": "Error",
+				"content":     g.content(APIErrorResponse{}),
+			},
+		},
+	}
+
+	if op.summary != "" {
+		res["summary"] = op.summary
+	}
+
+	if op.scope != "" {
+		res["description"] = fmt.Sprintf("Require `%s` scope when authentication is enabled.", op.scope)
+		res["security"] = []any{
+			map[string]any{"bearerAuth": []any{}},
+			map[string]any{"apiKeyAuth": []any{}},
+		}
+	}
+
+	if op.request != nil {
+		res["requestBody"] = map[string]any{
+			"required": true,
+			"content":  g.content(op.request),
+		}
+	}
+
+	var params []any
+	for _, match := range pathVarPattern.FindAllStringSubmatch(path, -1) {
+		params = append(params, map[string]any{
+			"name":     match[1],
+			"in":       "path",
+			"required": true,
+			"schema":   map[string]any{"type": "integer", "format": "int64"},
+		})
+	}
+
+	for _, name := range op.query {
+		params = append(params, map[string]any{
+			"name":   name,
+			"in":     "query",
+			"schema": map[string]any{"type": "string"},
+		})
+	}
+
+	if op.signed {
+		for _, name := range []string{
+			anymind.SignatureKeyHeader,
+			anymind.SignatureTimestampHeader,
+			anymind.SignatureNonceHeader,
+			anymind.SignatureHeader,
+		} {
+			params = append(params, map[string]any{
+				"name":        name,
+				"in":          "header",
+				"description": "Required when request signing is enabled.",
+				"schema":      map[string]any{"type": "string"},
+			})
+		}
+	}
+
+	if len(params) > 0 {
+		res["parameters"] = params
+	}
+
+	return res
+}
+
+func (g *schemaGen) content(v any) map[string]any {
+	if v == nil {
+		return map[string]any{}
+	}
+
+	return map[string]any{
+		"application/json": map[string]any{
+			"schema": g.schema(reflect.TypeOf(v)),
+		},
+	}
+}
+
+// schema convert go type into openapi schema following encoding/json rules,
+// named struct is registered once as component and referenced.
+func (g *schemaGen) schema(t reflect.Type) map[string]any {
+	switch t {
+	case timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case numberType:
+		return map[string]any{
+			"description": "decimal number, either as json number or string",
+			"oneOf":       []any{map[string]any{"type": "number"}, map[string]any{"type": "string"}},
+		}
+	case rawMessageType:
+		return map[string]any{}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		res := g.schema(t.Elem())
+		res["nullable"] = true
+
+		return res
+	case reflect.Struct:
+		name := []rune(t.Name())
+		name[0] = unicode.ToUpper(name[0])
+		ref := map[string]any{"$ref": "#/components/schemas/" + string(name)}
+
+		if _, ok := g.schemas[string(name)]; ok {
+			return ref
+		}
+
+		// register placeholder first to support recursive type.
+		g.schemas[string(name)] = nil
+		g.schemas[string(name)] = g.object(t)
+
+		return ref
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": g.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": g.schema(t.Elem())}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return map[string]any{"type": "integer", "format": "int64"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return map[string]any{"type": "integer", "format": "int32"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	default:
+		return map[string]any{}
+	}
+}
+
+func (g *schemaGen) object(t reflect.Type) map[string]any {
+	props := map[string]any{}
+	var required []string
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+
+		props[name] = g.schema(f.Type)
+		if !strings.Contains(opts, "omitempty") {
+			required = append(required, name)
+		}
+	}
+
+	res := map[string]any{
+		"type":       "object",
+		"properties": props,
+	}
+
+	if len(required) > 0 {
+		res["required"] = required
+	}
+
+	return res
+}
+
+func openAPIHandler(logger *zap.Logger, root *mux.Router) http.HandlerFunc {
+	doc, err := openAPI(root)
+	if err != nil {
+		logger.Error("incomplete openapi document", zap.Error(err))
+	}
+
+	payload, err := json.Marshal(doc)
+	if err != nil {
+		logger.Error("unable to encode openapi document", zap.Error(err))
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Write(payload)
+	}
+}
+
+const swaggerInitializer = `window.onload = function() {
+  window.ui = SwaggerUIBundle({
+    url: "` + openAPIPath + `",
+    dom_id: '#swagger-ui',
+    deepLinking: true,
+    presets: [
+      SwaggerUIBundle.presets.apis,
+      SwaggerUIStandalonePreset
+    ],
+    plugins: [
+      SwaggerUIBundle.plugins.DownloadUrl
+    ],
+    layout: "StandaloneLayout"
+  });
+};
+`
+
+// swaggerUIHandler serve embedded swagger ui pointing to openapi document.
+func swaggerUIHandler() http.Handler {
+	files := http.StripPrefix(docsPath, http.FileServer(http.FS(swaggerFiles.FS)))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == docsPath+"swagger-initializer.js" {
+			w.Header().Set("Content-Type", "application/javascript")
+			w.Write([]byte(swaggerInitializer))
+
+			return
+		}
+
+		files.ServeHTTP(w, r)
+	})
+}
@@ -1,6 +1,13 @@
 package httpapi
 
-import "go.uber.org/zap"
+import (
+	"anymind"
+	"fmt"
+	"github.com/go-kit/kit/metrics"
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+	"time"
+)
 
 type Option func(*Service)
 
@@ -10,8 +17,207 @@ func WithLogger(logger *zap.Logger) Option {
 	}
 }
 
+// WithListenPort listen on every interface at port, see WithListenAddr.
 func WithListenPort(port int) Option {
+	return WithListenAddr(fmt.Sprintf(":%d", port))
+}
+
+// WithListenAddr listen on host:port address, host can be empty to listen on every interface.
+func WithListenAddr(addr string) Option {
+	return func(svc *Service) {
+		svc.addr = addr
+	}
+}
+
+// WithMetricsAddr serve prometheus metrics on host:port address, apart from the api, see StartMetrics.
+func WithMetricsAddr(addr string) Option {
+	return func(svc *Service) {
+		svc.metricsAddr = addr
+	}
+}
+
+// WithWebhookService enable webhook management routes.
+func WithWebhookService(webhooks anymind.WebhookService) Option {
+	return func(svc *Service) {
+		svc.webhooks = webhooks
+	}
+}
+
+// WithConfigService serve active configuration at /admin/config.
+func WithConfigService(configs anymind.ConfigService) Option {
+	return func(svc *Service) {
+		svc.configs = configs
+	}
+}
+
+// WithAuditService serve audit log at /audit and record rejected or failed deposits and admin actions.
+func WithAuditService(audits anymind.AuditService) Option {
+	return func(svc *Service) {
+		svc.audits = audits
+	}
+}
+
+// WithAssetService serve asset registry at /assets.
+func WithAssetService(assets anymind.AssetService) Option {
+	return func(svc *Service) {
+		svc.assets = assets
+	}
+}
+
+// WithPriceService accept rates import at /admin/rates.
+func WithPriceService(prices anymind.PriceService) Option {
+	return func(svc *Service) {
+		svc.prices = prices
+	}
+}
+
+// WithStatsService serve hourly deposit stats at /stats.
+func WithStatsService(stats anymind.StatsService) Option {
+	return func(svc *Service) {
+		svc.stats = stats
+	}
+}
+
+// WithCandleService serve OHLC balance candles at /candles.
+func WithCandleService(candles anymind.CandleService) Option {
+	return func(svc *Service) {
+		svc.candles = candles
+	}
+}
+
+// WithAnomalyService serve listing and review of anomalous deposits at /anomalies.
+func WithAnomalyService(anomalies anymind.AnomalyService) Option {
+	return func(svc *Service) {
+		svc.anomalies = anomalies
+	}
+}
+
+// WithDepositStatusService settle pending deposits at PATCH /deposits/{id}.
+func WithDepositStatusService(depositStatus anymind.DepositStatusService) Option {
+	return func(svc *Service) {
+		svc.depositStatus = depositStatus
+	}
+}
+
+// WithChainService serve deposit chain verification at /admin/chain/verify.
+func WithChainService(chains anymind.ChainService) Option {
+	return func(svc *Service) {
+		svc.chains = chains
+	}
+}
+
+// WithSwaggerUI serve embedded swagger ui of the openapi document at /docs/.
+func WithSwaggerUI(enabled bool) Option {
+	return func(svc *Service) {
+		svc.swaggerUI = enabled
+	}
+}
+
+// WithAuthenticator require every api request to carry credential accepted by auth.
+// It can be given multiple times, credential is then checked against each authenticator in order.
+func WithAuthenticator(auth anymind.Authenticator) Option {
+	return func(svc *Service) {
+		switch {
+		case auth == nil:
+		case svc.auth == nil:
+			svc.auth = auth
+		default:
+			svc.auth = anymind.Authenticators{svc.auth, auth}
+		}
+	}
+}
+
+// WithRequestVerifier require deposit request to be signed, signature is verified once request passed IP rate limit.
+func WithRequestVerifier(verifier anymind.RequestVerifier) Option {
+	return func(svc *Service) {
+		svc.verifier = verifier
+	}
+}
+
+// WithRateLimit allow every api key, or client ip for anonymous request, perSecond requests with given burst.
+func WithRateLimit(perSecond float64, burst int) Option {
+	return func(svc *Service) {
+		svc.rateLimit = rate.Limit(perSecond)
+		svc.rateBurst = burst
+	}
+}
+
+// WithIPRateLimit allow every client ip perSecond requests with given burst, it is checked before credential
+// so request with bad credential is throttled too.
+func WithIPRateLimit(perSecond float64, burst int) Option {
+	return func(svc *Service) {
+		svc.ipRateLimit = rate.Limit(perSecond)
+		svc.ipRateBurst = burst
+	}
+}
+
+// WithMaxInFlightWrites cap number of deposit transactions processed at the same time.
+func WithMaxInFlightWrites(max int) Option {
+	return func(svc *Service) {
+		svc.maxInFlightWrites = max
+	}
+}
+
+// WithThrottledCounter count throttled request, labeled by route and reason.
+func WithThrottledCounter(counter metrics.Counter) Option {
+	return func(svc *Service) {
+		svc.throttled = counter
+	}
+}
+
+// WithRequestDuration observe latency of every api request, labeled by route, method and status.
+func WithRequestDuration(h metrics.Histogram) Option {
+	return func(svc *Service) {
+		svc.requestDuration = h
+	}
+}
+
+// WithHealthCheck add named dependency check to readiness probe.
+func WithHealthCheck(name string, check anymind.HealthChecker) Option {
+	return func(svc *Service) {
+		svc.healthChecks[name] = check
+	}
+}
+
+// WithDrainDelay keep the listener open for delay after shutdown begin, while readiness probe report draining
+// and api request is answered with 503.
+func WithDrainDelay(delay time.Duration) Option {
+	return func(svc *Service) {
+		svc.drainDelay = delay
+	}
+}
+
+// WithShutdownTimeout is grace period given to in-flight request once shutdown begin, default 30s.
+func WithShutdownTimeout(timeout time.Duration) Option {
+	return func(svc *Service) {
+		svc.shutdownTimeout = timeout
+	}
+}
+
+// WithReadTimeout bound reading whole request, including body, default 15s.
+func WithReadTimeout(timeout time.Duration) Option {
+	return func(svc *Service) {
+		svc.readTimeout = timeout
+	}
+}
+
+// WithReadHeaderTimeout bound reading request header, default 5s.
+func WithReadHeaderTimeout(timeout time.Duration) Option {
+	return func(svc *Service) {
+		svc.readHeaderTimeout = timeout
+	}
+}
+
+// WithWriteTimeout bound handling request and writing response, default 30s.
+func WithWriteTimeout(timeout time.Duration) Option {
+	return func(svc *Service) {
+		svc.writeTimeout = timeout
+	}
+}
+
+// WithIdleTimeout bound how long keep-alive connection wait for next request, default 60s.
+func WithIdleTimeout(timeout time.Duration) Option {
 	return func(svc *Service) {
-		svc.port = port
+		svc.idleTimeout = timeout
 	}
 }
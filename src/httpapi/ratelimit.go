@@ -0,0 +1,200 @@
+package httpapi
+
+import (
+	"anymind"
+	"context"
+	"fmt"
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/metrics"
+	"golang.org/x/time/rate"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Reasons request is throttled, used as label of throttled counter.
+const (
+	throttleRate     = "rate"
+	throttleIP       = "ip"
+	throttleInFlight = "in_flight"
+)
+
+// idleLimiterTTL is how long limiter of a client that stopped calling is kept.
+const idleLimiterTTL = 10 * time.Minute
+
+// throttledError tell client when to retry, via Retry-After header.
+type throttledError struct {
+	reason     string
+	retryAfter time.Duration
+}
+
+func (e *throttledError) Error() string {
+	return fmt.Sprintf("%s limit exceeded, retry after %s", e.reason, e.retryAfter)
+}
+
+func (e *throttledError) Headers() http.Header {
+	seconds := int(math.Ceil(e.retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+
+	return http.Header{"Retry-After": []string{strconv.Itoa(seconds)}}
+}
+
+type clientLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// rateLimiter keep token bucket for every client, client is identified by key.
+type rateLimiter struct {
+	limit     rate.Limit
+	burst     int
+	reason    string
+	key       func(context.Context) string
+	throttled metrics.Counter
+	now       func() time.Time
+
+	mu        sync.Mutex
+	clients   map[string]*clientLimiter
+	nextPrune time.Time
+}
+
+func newRateLimiter(limit rate.Limit, burst int, reason string, key func(context.Context) string, throttled metrics.Counter) *rateLimiter {
+	return &rateLimiter{
+		limit:     limit,
+		burst:     defaultBurst(limit, burst),
+		reason:    reason,
+		key:       key,
+		throttled: throttled,
+		now:       time.Now,
+		clients:   map[string]*clientLimiter{},
+	}
+}
+
+// defaultBurst allow one second worth of requests when burst is not set, bucket of zero size would reject every request.
+func defaultBurst(limit rate.Limit, burst int) int {
+	if burst < 1 {
+		return int(math.Max(1, math.Ceil(float64(limit))))
+	}
+
+	return burst
+}
+
+// middleware reject request of client that used up its bucket, it let every request through while limit is not set.
+// Limiter keyed by clientKey must run after authorize, so api key of the caller is known, limiter keyed by clientIP
+// run before it, so request with bad credential is throttled too.
+func (l *rateLimiter) middleware(route string) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			now := l.now()
+
+			limiter := l.client(l.key(ctx), now)
+			if limiter == nil {
+				return next(ctx, request)
+			}
+
+			reservation := limiter.ReserveN(now, 1)
+			if delay := reservation.DelayFrom(now); delay > 0 {
+				reservation.CancelAt(now)
+				l.throttled.With("route", route, "reason", l.reason).Add(1)
+
+				return nil, anymind.TooManyRequestsError(&throttledError{reason: l.reason, retryAfter: delay})
+			}
+
+			return next(ctx, request)
+		}
+	}
+}
+
+// client return bucket of the client, it is nil when limit is not set.
+func (l *rateLimiter) client(key string, now time.Time) *rate.Limiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.limit <= 0 {
+		return nil
+	}
+
+	if now.After(l.nextPrune) {
+		for k, c := range l.clients {
+			if now.Sub(c.lastSeen) > idleLimiterTTL {
+				delete(l.clients, k)
+			}
+		}
+
+		l.nextPrune = now.Add(idleLimiterTTL)
+	}
+
+	c, ok := l.clients[key]
+	if !ok {
+		c = &clientLimiter{limiter: rate.NewLimiter(l.limit, l.burst)}
+		l.clients[key] = c
+	}
+
+	c.lastSeen = now
+
+	return c.limiter
+}
+
+// setLimit change limit of every client, tokens already in bucket are kept.
+func (l *rateLimiter) setLimit(limit rate.Limit, burst int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.limit = limit
+	l.burst = defaultBurst(limit, burst)
+
+	now := l.now()
+	for _, c := range l.clients {
+		c.limiter.SetLimitAt(now, l.limit)
+		c.limiter.SetBurstAt(now, l.burst)
+	}
+}
+
+// clientKey identify caller by api key or subject of authenticated principal, otherwise by remote ip.
+func clientKey(ctx context.Context) string {
+	if p, ok := anymind.PrincipalFromContext(ctx); ok {
+		if p.KeyID != 0 {
+			return "key:" + strconv.FormatInt(p.KeyID, 10)
+		}
+
+		if p.Subject != "" {
+			return "sub:" + p.Subject
+		}
+	}
+
+	return clientIP(ctx)
+}
+
+// clientIP identify caller by remote ip, set by transport.
+func clientIP(ctx context.Context) string {
+	ip, _ := anymind.ClientIPFromContext(ctx)
+
+	return "ip:" + ip
+}
+
+// inFlightLimit cap number of concurrently running write transactions across all clients.
+// Request over the cap is rejected right away instead of queueing behind serializable transactions.
+func inFlightLimit(slots chan struct{}, throttled metrics.Counter, route string) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		if slots == nil {
+			return next
+		}
+
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+			default:
+				throttled.With("route", route, "reason", throttleInFlight).Add(1)
+
+				return nil, anymind.TooManyRequestsError(&throttledError{reason: throttleInFlight, retryAfter: time.Second})
+			}
+
+			return next(ctx, request)
+		}
+	}
+}
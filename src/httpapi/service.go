@@ -4,67 +4,448 @@ import (
 	"anymind"
 	"context"
 	"fmt"
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/discard"
 	transport "github.com/go-kit/kit/transport/http"
 	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+	"net"
 	"net/http"
+	"sync/atomic"
+	"time"
 )
 
 var _ anymind.HTTPService = &Service{}
 
 const depositPath = "/deposit"
 const historicalPath = "/historical"
+const webhooksPath = "/webhooks"
+const webhookPath = "/webhooks/{id:[0-9]+}"
+const deliveriesPath = "/webhooks/deliveries"
+const redeliverPath = "/webhooks/deliveries/{id:[0-9]+}/redeliver"
+const metricsPath = "/metrics"
+const adminConfigPath = "/admin/config"
 
 type Service struct {
-	api    anymind.APIService
-	port   int
-	logger *zap.Logger
+	api       anymind.APIService
+	webhooks  anymind.WebhookService
+	configs   anymind.ConfigService
+	audits    anymind.AuditService
+	chains    anymind.ChainService
+	assets    anymind.AssetService
+	prices    anymind.PriceService
+	stats     anymind.StatsService
+	candles   anymind.CandleService
+	anomalies anymind.AnomalyService
+	// depositStatus settle pending deposits at PATCH /deposits/{id}.
+	depositStatus anymind.DepositStatusService
+	auth          anymind.Authenticator
+	verifier      anymind.RequestVerifier
+	addr          string
+	metricsAddr   string
+	swaggerUI     bool
+	logger        *zap.Logger
+
+	rateLimit         rate.Limit
+	rateBurst         int
+	maxInFlightWrites int
+	ipRateLimit       rate.Limit
+	ipRateBurst       int
+	throttled         metrics.Counter
+	requestDuration   metrics.Histogram
+	limiter           *rateLimiter
+	ipLimiter         *rateLimiter
+	writeSlots        chan struct{}
+
+	healthChecks map[string]anymind.HealthChecker
+	drainDelay   time.Duration
+
+	readTimeout       time.Duration
+	readHeaderTimeout time.Duration
+	writeTimeout      time.Duration
+	idleTimeout       time.Duration
+	shutdownTimeout   time.Duration
+	// draining is set once shutdown begin, readiness probe then fail so traffic is moved away.
+	draining atomic.Bool
 }
 
 // NewRouter create new router with predefined path and method.
 func (s *Service) NewRouter() *mux.Router {
 	opt := []transport.ServerOption{
 		transport.ServerErrorEncoder(errorHandler(s.logger)),
+		transport.ServerBefore(transport.PopulateRequestContext, requestStartToContext, accessFieldsToContext, startSpan, credentialToContext),
+		transport.ServerFinalizer(s.observeRequest, s.accessLog, endSpan),
 	}
 
 	root := mux.NewRouter()
+	root.Use(requestID)
+
+	// signed body is read before it is decoded, signature is verified once request passed IP rate limit.
+	depositOpt := opt
+	if s.verifier != nil {
+		depositOpt = append(opt[:len(opt):len(opt)], transport.ServerBefore(signatureToContext))
+	}
 
 	root.Methods(http.MethodPost).Path(depositPath).Handler(transport.NewServer(
-		depositEndpoint(s.logger, s.api),
+		s.DepositMiddleware()(depositEndpoint(s.logger, s.api)),
 		decoder[depositRequest](s.logger),
 		encodeAPIResponse,
-		opt...,
+		depositOpt...,
 	))
 
 	root.Methods(http.MethodPost).Path(historicalPath).Handler(transport.NewServer(
-		historicalEndpoint(s.logger, s.api),
+		endpoint.Chain(
+			s.ipLimiter.middleware("historical"),
+			authorize(s.auth, anymind.ScopeHistoryRead),
+			s.limiter.middleware("historical"),
+		)(historicalEndpoint(s.logger, s.api)),
 		decoder[historicalRequest](s.logger),
 		encodeAPIResponse,
 		opt...,
 	))
 
+	if s.webhooks != nil {
+		s.webhookRoutes(root, opt)
+	}
+
+	if s.assets != nil {
+		root.Methods(http.MethodGet).Path(assetsPath).Handler(transport.NewServer(
+			endpoint.Chain(
+				s.ipLimiter.middleware("historical"),
+				authorize(s.auth, anymind.ScopeHistoryRead),
+				s.limiter.middleware("historical"),
+			)(listAssetsEndpoint(s.logger, s.assets)),
+			transport.NopRequestDecoder,
+			encodeAPIResponse,
+			opt...,
+		))
+
+		root.Methods(http.MethodPost).Path(assetsPath).Handler(transport.NewServer(
+			endpoint.Chain(
+				auditFailure(s.logger, s.audits, anymind.AuditAssetCreate),
+				s.ipLimiter.middleware("admin"),
+				authorize(s.auth, anymind.ScopeAdmin),
+				s.limiter.middleware("admin"),
+			)(createAssetEndpoint(s.logger, s.assets)),
+			decoder[assetRequest](s.logger),
+			encodeAPIResponse,
+			opt...,
+		))
+	}
+
+	if s.prices != nil {
+		root.Methods(http.MethodPost).Path(adminRatesPath).Handler(transport.NewServer(
+			endpoint.Chain(
+				auditFailure(s.logger, s.audits, anymind.AuditRatesImport),
+				s.ipLimiter.middleware("admin"),
+				authorize(s.auth, anymind.ScopeAdmin),
+				s.limiter.middleware("admin"),
+			)(importRatesEndpoint(s.logger, s.prices)),
+			decoder[[]rateRequest](s.logger),
+			encodeAPIResponse,
+			opt...,
+		))
+	}
+
+	if s.stats != nil {
+		root.Methods(http.MethodPost).Path(statsPath).Handler(transport.NewServer(
+			endpoint.Chain(
+				s.ipLimiter.middleware("historical"),
+				authorize(s.auth, anymind.ScopeHistoryRead),
+				s.limiter.middleware("historical"),
+			)(statsEndpoint(s.logger, s.stats)),
+			decoder[statsRequest](s.logger),
+			encodeAPIResponse,
+			opt...,
+		))
+	}
+
+	if s.candles != nil {
+		root.Methods(http.MethodPost).Path(candlesPath).Handler(transport.NewServer(
+			endpoint.Chain(
+				s.ipLimiter.middleware("historical"),
+				authorize(s.auth, anymind.ScopeHistoryRead),
+				s.limiter.middleware("historical"),
+			)(candlesEndpoint(s.logger, s.candles)),
+			decoder[candlesRequest](s.logger),
+			encodeAPIResponse,
+			opt...,
+		))
+	}
+
+	if s.anomalies != nil {
+		root.Methods(http.MethodGet).Path(anomaliesPath).Handler(transport.NewServer(
+			endpoint.Chain(
+				s.ipLimiter.middleware("admin"),
+				authorize(s.auth, anymind.ScopeAdmin),
+				s.limiter.middleware("admin"),
+			)(listAnomaliesEndpoint(s.logger, s.anomalies)),
+			decodeAnomaliesRequest,
+			encodeAPIResponse,
+			opt...,
+		))
+
+		root.Methods(http.MethodPost).Path(reviewAnomalyPath).Handler(transport.NewServer(
+			endpoint.Chain(
+				auditFailure(s.logger, s.audits, anymind.AuditAnomalyReview),
+				s.ipLimiter.middleware("admin"),
+				authorize(s.auth, anymind.ScopeAdmin),
+				s.limiter.middleware("admin"),
+			)(reviewAnomalyEndpoint(s.logger, s.anomalies)),
+			decodeReviewAnomalyRequest,
+			encodeAPIResponse,
+			opt...,
+		))
+	}
+
+	if s.depositStatus != nil {
+		root.Methods(http.MethodPatch).Path(depositStatusPath).Handler(transport.NewServer(
+			endpoint.Chain(
+				auditFailure(s.logger, s.audits, anymind.AuditDepositStatus),
+				s.ipLimiter.middleware("deposit"),
+				authorize(s.auth, anymind.ScopeDepositWrite),
+				s.limiter.middleware("deposit"),
+				inFlightLimit(s.writeSlots, s.throttled, "deposit"),
+			)(depositStatusEndpoint(s.logger, s.depositStatus)),
+			decodeDepositStatusRequest,
+			encodeAPIResponse,
+			opt...,
+		))
+	}
+
+	if s.configs != nil {
+		root.Methods(http.MethodGet).Path(adminConfigPath).Handler(transport.NewServer(
+			endpoint.Chain(
+				s.ipLimiter.middleware("admin"),
+				authorize(s.auth, anymind.ScopeAdmin),
+				s.limiter.middleware("admin"),
+			)(activeConfigEndpoint(s.logger, s.configs)),
+			transport.NopRequestDecoder,
+			encodeAPIResponse,
+			opt...,
+		))
+	}
+
+	if s.chains != nil {
+		root.Methods(http.MethodGet).Path(adminChainPath).Handler(transport.NewServer(
+			endpoint.Chain(
+				s.ipLimiter.middleware("admin"),
+				authorize(s.auth, anymind.ScopeAdmin),
+				s.limiter.middleware("admin"),
+			)(verifyChainEndpoint(s.logger, s.chains)),
+			transport.NopRequestDecoder,
+			encodeAPIResponse,
+			opt...,
+		))
+	}
+
+	if s.audits != nil {
+		root.Methods(http.MethodGet).Path(auditPath).Handler(transport.NewServer(
+			endpoint.Chain(
+				s.ipLimiter.middleware("admin"),
+				authorize(s.auth, anymind.ScopeAdmin),
+				s.limiter.middleware("admin"),
+			)(listAuditEndpoint(s.logger, s.audits)),
+			decodeAuditRequest,
+			encodeAPIResponse,
+			opt...,
+		))
+	}
+
+	root.Methods(http.MethodGet).Path(healthzPath).HandlerFunc(healthzHandler)
+	root.Methods(http.MethodGet).Path(readyzPath).HandlerFunc(s.readyzHandler)
+
+	// document is generated from routes above, so it must be registered after them.
+	root.Methods(http.MethodGet).Path(openAPIPath).Handler(openAPIHandler(s.logger, root))
+
+	if s.swaggerUI {
+		root.Methods(http.MethodGet).PathPrefix(docsPath).Handler(swaggerUIHandler())
+	}
+
 	return root
 }
 
+func (s *Service) webhookRoutes(root *mux.Router, opt []transport.ServerOption) {
+	admin := endpoint.Chain(
+		s.ipLimiter.middleware("webhooks"),
+		authorize(s.auth, anymind.ScopeAdmin),
+		s.limiter.middleware("webhooks"),
+	)
+
+	root.Methods(http.MethodPost).Path(webhooksPath).Handler(transport.NewServer(
+		endpoint.Chain(
+			auditFailure(s.logger, s.audits, anymind.AuditWebhookCreate),
+			admin,
+		)(createWebhookEndpoint(s.logger, s.webhooks)),
+		decoder[webhookRequest](s.logger),
+		encodeAPIResponse,
+		opt...,
+	))
+
+	root.Methods(http.MethodGet).Path(webhooksPath).Handler(transport.NewServer(
+		admin(listWebhooksEndpoint(s.logger, s.webhooks)),
+		transport.NopRequestDecoder,
+		encodeAPIResponse,
+		opt...,
+	))
+
+	root.Methods(http.MethodDelete).Path(webhookPath).Handler(transport.NewServer(
+		endpoint.Chain(
+			auditFailure(s.logger, s.audits, anymind.AuditWebhookDelete),
+			admin,
+		)(deleteWebhookEndpoint(s.logger, s.webhooks)),
+		decodeIDRequest,
+		encodeAPIResponse,
+		opt...,
+	))
+
+	root.Methods(http.MethodGet).Path(deliveriesPath).Handler(transport.NewServer(
+		admin(listDeliveriesEndpoint(s.logger, s.webhooks)),
+		decodeDeliveriesRequest,
+		encodeAPIResponse,
+		opt...,
+	))
+
+	root.Methods(http.MethodPost).Path(redeliverPath).Handler(transport.NewServer(
+		endpoint.Chain(
+			auditFailure(s.logger, s.audits, anymind.AuditWebhookRedeliver),
+			admin,
+		)(redeliverEndpoint(s.logger, s.webhooks)),
+		decodeIDRequest,
+		encodeAPIResponse,
+		opt...,
+	))
+}
+
 func (s *Service) Start(ctx context.Context) error {
-	root := s.NewRouter()
+	lis, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return err
+	}
+
+	webserver := &http.Server{
+		Handler:           s.rejectWhileDraining(s.NewRouter()),
+		ReadTimeout:       s.readTimeout,
+		ReadHeaderTimeout: s.readHeaderTimeout,
+		WriteTimeout:      s.writeTimeout,
+		IdleTimeout:       s.idleTimeout,
+	}
+
+	served := make(chan error, 1)
+	go func() {
+		served <- webserver.Serve(lis)
+	}()
+
+	select {
+	case err = <-served:
+		return err
+	case <-ctx.Done():
+	}
+
+	// keep answering while readiness report draining, so load balancer stop routing before listener close.
+	s.draining.Store(true)
+	time.Sleep(s.drainDelay)
+
+	// ctx is already done, in-flight request get their own grace period to complete.
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancel()
+
+	err = webserver.Shutdown(shutdownCtx)
+	if err != nil {
+		webserver.Close()
+
+		return fmt.Errorf("http shutdown: %w", err)
+	}
+
+	return nil
+}
+
+// NewMetricsRouter create router serving prometheus metrics, it is kept off the api router as metrics expose
+// balance of every asset.
+func (s *Service) NewMetricsRouter() *mux.Router {
+	root := mux.NewRouter()
+	root.Methods(http.MethodGet).Path(metricsPath).Handler(promhttp.Handler())
 
-	webserver := http.Server{
-		Handler: root,
-		Addr:    fmt.Sprintf(":%d", s.port),
+	return root
+}
+
+// StartMetrics serve NewMetricsRouter on metrics address until ctx is done, see WithMetricsAddr.
+func (s *Service) StartMetrics(ctx context.Context) error {
+	lis, err := net.Listen("tcp", s.metricsAddr)
+	if err != nil {
+		return err
+	}
+
+	webserver := &http.Server{
+		Handler:           s.NewMetricsRouter(),
+		ReadHeaderTimeout: s.readHeaderTimeout,
+		WriteTimeout:      s.writeTimeout,
+		IdleTimeout:       s.idleTimeout,
+	}
+
+	served := make(chan error, 1)
+	go func() {
+		served <- webserver.Serve(lis)
+	}()
+
+	select {
+	case err = <-served:
+		return err
+	case <-ctx.Done():
 	}
 
-	go webserver.ListenAndServe()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancel()
+
+	err = webserver.Shutdown(shutdownCtx)
+	if err != nil {
+		webserver.Close()
 
-	<-ctx.Done()
+		return fmt.Errorf("metrics shutdown: %w", err)
+	}
+
+	return nil
+}
 
-	return webserver.Shutdown(ctx)
+// DepositMiddleware return middleware chain deposit is served through, so deposit sent over another transport is
+// audited, signed, authorized and limited alike. Limits are shared with HTTP deposits. Transport must put caller ip,
+// credential and signed request into context, see anymind.WithClientIP, anymind.WithCredential and
+// anymind.WithSignedRequest, failure is audited with *anymind.DepositInput request.
+func (s *Service) DepositMiddleware() endpoint.Middleware {
+	return endpoint.Chain(
+		auditFailure(s.logger, s.audits, anymind.AuditDeposit),
+		s.ipLimiter.middleware("deposit"),
+		verifySignature(s.verifier),
+		authorize(s.auth, anymind.ScopeDepositWrite),
+		s.limiter.middleware("deposit"),
+		inFlightLimit(s.writeSlots, s.throttled, "deposit"),
+	)
+}
+
+// SetRateLimit change per client rate limit of running service, 0 disable the limit.
+func (s *Service) SetRateLimit(perSecond float64, burst int) {
+	s.limiter.setLimit(rate.Limit(perSecond), burst)
+}
+
+// SetIPRateLimit change per ip rate limit of running service, 0 disable the limit.
+func (s *Service) SetIPRateLimit(perSecond float64, burst int) {
+	s.ipLimiter.setLimit(rate.Limit(perSecond), burst)
 }
 
 func NewService(api anymind.APIService, opts ...Option) *Service {
 	s := &Service{
-		api:  api,
-		port: 80,
+		api:          api,
+		addr:         ":80",
+		healthChecks: map[string]anymind.HealthChecker{},
+
+		readTimeout:       15 * time.Second,
+		readHeaderTimeout: 5 * time.Second,
+		writeTimeout:      30 * time.Second,
+		idleTimeout:       60 * time.Second,
+		shutdownTimeout:   30 * time.Second,
 	}
 
 	for _, opt := range opts {
@@ -75,5 +456,19 @@ func NewService(api anymind.APIService, opts ...Option) *Service {
 		s.logger = zap.NewNop()
 	}
 
+	if s.throttled == nil {
+		s.throttled = discard.NewCounter()
+	}
+
+	if s.requestDuration == nil {
+		s.requestDuration = discard.NewHistogram()
+	}
+
+	s.limiter = newRateLimiter(s.rateLimit, s.rateBurst, throttleRate, clientKey, s.throttled)
+	s.ipLimiter = newRateLimiter(s.ipRateLimit, s.ipRateBurst, throttleIP, clientIP, s.throttled)
+	if s.maxInFlightWrites > 0 {
+		s.writeSlots = make(chan struct{}, s.maxInFlightWrites)
+	}
+
 	return s
 }
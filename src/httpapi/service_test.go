@@ -4,16 +4,34 @@ import (
 	"anymind"
 	"anymind/src/mock"
 	"context"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"github.com/cockroachdb/apd"
+	kitprometheus "github.com/go-kit/kit/metrics/prometheus"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"strings"
 	"testing"
 	"time"
 )
 
+var update = flag.Bool("update", false, "update golden files in testdata")
+
 func mustApd(number string) apd.Decimal {
 	val, _, err := apd.NewFromString(number)
 	if err != nil {
@@ -87,6 +105,48 @@ func TestDepositValidValue(t *testing.T) {
 	}
 }
 
+func TestDepositHeld(t *testing.T) {
+	svc := NewService(&mock.APIServiceMock{
+		DepositFunc: func(_ context.Context, input *anymind.DepositInput) error {
+			input.AnomalyID = 7
+
+			return nil
+		},
+	})
+
+	rec := httptest.NewRecorder()
+	req, err := http.NewRequest("POST", depositPath, strings.NewReader(`{"datetime": "2020-01-01T00:00:00Z", "amount": "1"}`))
+	require.NoError(t, err)
+
+	svc.NewRouter().ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `{"anomalyId": 7, "datetime": "2020-01-01T00:00:00Z", "amount": 1, "status": "held"}`,
+		rec.Body.String())
+}
+
+func TestDepositNormalizedAsset(t *testing.T) {
+	svc := NewService(&mock.APIServiceMock{
+		DepositFunc: func(_ context.Context, input *anymind.DepositInput) error {
+			require.Equal(t, " eth", input.Asset)
+			input.Asset, input.ID = "ETH", 3
+
+			return nil
+		},
+	})
+
+	rec := httptest.NewRecorder()
+	req, err := http.NewRequest("POST", depositPath,
+		strings.NewReader(`{"datetime": "2020-01-01T00:00:00Z", "amount": "1", "asset": " eth"}`))
+	require.NoError(t, err)
+
+	svc.NewRouter().ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `{"id": 3, "datetime": "2020-01-01T00:00:00Z", "amount": 1, "asset": "ETH", "status": "confirmed"}`,
+		rec.Body.String())
+}
+
 func TestDepositInvalidValue(t *testing.T) {
 	testCase := []struct {
 		name     string
@@ -393,3 +453,1324 @@ func TestHistoricalFillValue(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateWebhook(t *testing.T) {
+	svc := NewService(&mock.APIServiceMock{}, WithWebhookService(&mock.WebhookServiceMock{
+		CreateWebhookFunc: func(_ context.Context, sub *anymind.WebhookSubscription) error {
+			require.Equal(t, "https://example.com/hook", sub.URL)
+			require.Equal(t, "1000", fmt.Sprintf("%f", &sub.Thresholds[0]))
+
+			sub.ID = 7
+			sub.Secret = "generated"
+			sub.CreatedAt = mustTime("2020-01-01T00:00:00Z")
+
+			return nil
+		},
+	}))
+	router := svc.NewRouter()
+
+	rec := httptest.NewRecorder()
+	req, err := http.NewRequest("POST", webhooksPath, strings.NewReader(`
+		{
+			"url": "https://example.com/hook",
+			"events": ["balance.threshold"],
+			"thresholds": [1000]
+		}`))
+	require.NoError(t, err)
+
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusCreated, rec.Code)
+	require.JSONEq(t, `
+		{
+			"id": 7,
+			"url": "https://example.com/hook",
+			"events": ["balance.threshold"],
+			"thresholds": ["1000"],
+			"secret": "generated",
+			"asset": "BTC",
+			"createdAt": "2020-01-01T00:00:00Z"
+		}`, rec.Body.String())
+}
+
+func TestRedeliverWebhook(t *testing.T) {
+	testCases := []struct {
+		name     string
+		path     string
+		err      error
+		httpcode int
+	}{
+		{
+			name:     "accepted",
+			path:     "/webhooks/deliveries/3/redeliver",
+			httpcode: http.StatusAccepted,
+		},
+		{
+			name:     "not found",
+			path:     "/webhooks/deliveries/4/redeliver",
+			err:      anymind.NotFoundError(anymind.ErrNotFound),
+			httpcode: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			svc := NewService(&mock.APIServiceMock{}, WithWebhookService(&mock.WebhookServiceMock{
+				RedeliverFunc: func(_ context.Context, _ int64) error {
+					return tc.err
+				},
+			}))
+			router := svc.NewRouter()
+
+			rec := httptest.NewRecorder()
+			req, err := http.NewRequest("POST", tc.path, nil)
+			require.NoError(t, err)
+
+			router.ServeHTTP(rec, req)
+
+			require.Equal(t, tc.httpcode, rec.Code)
+		})
+	}
+}
+
+func TestOpenAPIMatchRouter(t *testing.T) {
+	svc := NewService(&mock.APIServiceMock{}, WithWebhookService(&mock.WebhookServiceMock{}), WithConfigService(&mock.ConfigServiceMock{}), WithAuditService(&mock.AuditServiceMock{}), WithChainService(&mock.ChainServiceMock{}), WithAssetService(&mock.AssetServiceMock{}), WithPriceService(&mock.PriceServiceMock{}), WithStatsService(&mock.StatsServiceMock{}),
+		WithCandleService(&mock.CandleServiceMock{}), WithAnomalyService(&mock.AnomalyServiceMock{}),
+		WithDepositStatusService(&mock.DepositStatusServiceMock{}))
+
+	doc, err := openAPI(svc.NewRouter())
+	require.NoError(t, err)
+
+	paths := doc["paths"].(map[string]map[string]any)
+	for key := range operations {
+		method, path, _ := strings.Cut(key, " ")
+		require.Contains(t, paths[path], strings.ToLower(method), "operation %s has no route", key)
+	}
+}
+
+func TestOpenAPIGolden(t *testing.T) {
+	svc := NewService(&mock.APIServiceMock{}, WithWebhookService(&mock.WebhookServiceMock{}), WithConfigService(&mock.ConfigServiceMock{}), WithAuditService(&mock.AuditServiceMock{}), WithChainService(&mock.ChainServiceMock{}), WithAssetService(&mock.AssetServiceMock{}), WithPriceService(&mock.PriceServiceMock{}), WithStatsService(&mock.StatsServiceMock{}),
+		WithCandleService(&mock.CandleServiceMock{}))
+
+	rec := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", openAPIPath, nil)
+	require.NoError(t, err)
+
+	svc.NewRouter().ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var doc any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &doc))
+	generated, err := json.MarshalIndent(doc, "", "  ")
+	require.NoError(t, err)
+
+	golden := "testdata/openapi.json"
+	if *update {
+		require.NoError(t, os.WriteFile(golden, append(generated, '\n'), 0o644))
+	}
+
+	expected, err := os.ReadFile(golden)
+	require.NoError(t, err)
+	require.JSONEq(t, string(expected), string(generated),
+		"openapi document changed, run go test ./src/httpapi -run TestOpenAPIGolden -update")
+}
+
+func TestSwaggerUI(t *testing.T) {
+	testCases := []struct {
+		enabled  bool
+		path     string
+		httpcode int
+	}{
+		{enabled: false, path: "/docs/", httpcode: http.StatusNotFound},
+		{enabled: true, path: "/docs/", httpcode: http.StatusOK},
+		{enabled: true, path: "/docs/swagger-initializer.js", httpcode: http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(fmt.Sprintf("%v %s", tc.enabled, tc.path), func(t *testing.T) {
+			svc := NewService(&mock.APIServiceMock{}, WithSwaggerUI(tc.enabled))
+
+			rec := httptest.NewRecorder()
+			req, err := http.NewRequest("GET", tc.path, nil)
+			require.NoError(t, err)
+
+			svc.NewRouter().ServeHTTP(rec, req)
+
+			require.Equal(t, tc.httpcode, rec.Code)
+			if strings.HasSuffix(tc.path, ".js") {
+				require.Contains(t, rec.Body.String(), openAPIPath)
+			}
+		})
+	}
+}
+
+func TestAuthorization(t *testing.T) {
+	auth := &mock.AuthenticatorMock{
+		AuthenticateFunc: func(_ context.Context, credential string) (*anymind.Principal, error) {
+			switch credential {
+			case "writer":
+				return &anymind.Principal{Subject: "writer", KeyID: 1, Scopes: []string{anymind.ScopeDepositWrite}}, nil
+			case "reader":
+				return &anymind.Principal{Subject: "reader", KeyID: 2, Scopes: []string{anymind.ScopeHistoryRead}}, nil
+			default:
+				return nil, anymind.UnauthorizedError(errors.New("invalid api key"))
+			}
+		},
+	}
+
+	testCases := []struct {
+		name     string
+		header   string
+		value    string
+		httpcode int
+	}{
+		{name: "missing credential", httpcode: http.StatusUnauthorized},
+		{name: "unknown key", header: "Authorization", value: "Bearer unknown", httpcode: http.StatusUnauthorized},
+		{name: "missing scope", header: "Authorization", value: "Bearer reader", httpcode: http.StatusForbidden},
+		{name: "bearer", header: "Authorization", value: "Bearer writer", httpcode: http.StatusOK},
+		{name: "api key header", header: apiKeyHeader, value: "writer", httpcode: http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			svc := NewService(&mock.APIServiceMock{
+				DepositFunc: func(ctx context.Context, _ *anymind.DepositInput) error {
+					principal, ok := anymind.PrincipalFromContext(ctx)
+					require.True(t, ok)
+					require.Equal(t, int64(1), principal.KeyID)
+
+					return nil
+				},
+			}, WithAuthenticator(auth))
+			router := svc.NewRouter()
+
+			rec := httptest.NewRecorder()
+			req, err := http.NewRequest("POST", depositPath, strings.NewReader(`
+				{
+					"datetime": "2020-01-01T00:00:00Z",
+					"amount": "1"
+				}`))
+			require.NoError(t, err)
+			if tc.header != "" {
+				req.Header.Set(tc.header, tc.value)
+			}
+
+			router.ServeHTTP(rec, req)
+
+			require.Equal(t, tc.httpcode, rec.Code)
+			if tc.httpcode == http.StatusUnauthorized {
+				require.Equal(t, "Bearer", rec.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+func TestAuthenticatorChain(t *testing.T) {
+	apiKeys := &mock.AuthenticatorMock{
+		AuthenticateFunc: func(_ context.Context, _ string) (*anymind.Principal, error) {
+			return nil, anymind.UnauthorizedError(errors.New("invalid api key"))
+		},
+	}
+	tokens := &mock.AuthenticatorMock{
+		AuthenticateFunc: func(_ context.Context, credential string) (*anymind.Principal, error) {
+			if credential != "jwt" {
+				return nil, anymind.UnauthorizedError(errors.New("token is malformed"))
+			}
+
+			return &anymind.Principal{Subject: "alice", Scopes: []string{anymind.ScopeHistoryRead}}, nil
+		},
+	}
+
+	svc := NewService(&mock.APIServiceMock{
+		HistoricalFunc: func(ctx context.Context, _ *anymind.HistoricalDataReq) ([]*anymind.HistoricalData, error) {
+			principal, ok := anymind.PrincipalFromContext(ctx)
+			require.True(t, ok)
+			require.Equal(t, "alice", principal.Subject)
+
+			return nil, nil
+		},
+	}, WithAuthenticator(apiKeys), WithAuthenticator(tokens))
+	router := svc.NewRouter()
+
+	for credential, code := range map[string]int{"jwt": http.StatusOK, "other": http.StatusUnauthorized} {
+		rec := httptest.NewRecorder()
+		req, err := http.NewRequest("POST", historicalPath, strings.NewReader(`
+			{
+				"startDatetime": "2020-01-01T00:00:00Z",
+				"endDatetime": "2020-01-01T01:00:00Z"
+			}`))
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+credential)
+
+		router.ServeHTTP(rec, req)
+
+		require.Equal(t, code, rec.Code, credential)
+	}
+
+	require.Len(t, tokens.AuthenticateCalls(), 2)
+}
+
+func TestDepositSignature(t *testing.T) {
+	verifier := &mock.RequestVerifierMock{
+		VerifyFunc: func(_ context.Context, req *anymind.SignedRequest) error {
+			if req.Signature != "valid" {
+				return anymind.UnauthorizedError(errors.New("invalid signature"))
+			}
+
+			require.Equal(t, "partner", req.KeyID)
+			require.Equal(t, "POST", req.Method)
+			require.Equal(t, depositPath, req.Path)
+			require.Equal(t, "1577836800", req.Timestamp)
+			require.Equal(t, "nonce", req.Nonce)
+
+			return nil
+		},
+	}
+
+	testCases := []struct {
+		name      string
+		signature string
+		body      string
+		httpcode  int
+	}{
+		{
+			name:      "valid",
+			signature: "valid",
+			body:      `{"datetime": "2020-01-01T00:00:00Z", "amount": "1"}`,
+			httpcode:  http.StatusOK,
+		},
+		{
+			name:      "invalid",
+			signature: "forged",
+			body:      `{"datetime": "2020-01-01T00:00:00Z", "amount": "1"}`,
+			httpcode:  http.StatusUnauthorized,
+		},
+		{
+			// malformed body is rejected when decoded, it does not reach verifier.
+			name:      "invalid malformed body",
+			signature: "forged",
+			body:      `{`,
+			httpcode:  http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			svc := NewService(&mock.APIServiceMock{
+				DepositFunc: func(_ context.Context, _ *anymind.DepositInput) error {
+					return nil
+				},
+			}, WithRequestVerifier(verifier))
+			router := svc.NewRouter()
+
+			rec := httptest.NewRecorder()
+			req, err := http.NewRequest("POST", depositPath, strings.NewReader(tc.body))
+			require.NoError(t, err)
+			req.Header.Set(anymind.SignatureKeyHeader, "partner")
+			req.Header.Set(anymind.SignatureTimestampHeader, "1577836800")
+			req.Header.Set(anymind.SignatureNonceHeader, "nonce")
+			req.Header.Set(anymind.SignatureHeader, tc.signature)
+
+			calls := len(verifier.VerifyCalls())
+			router.ServeHTTP(rec, req)
+
+			require.Equal(t, tc.httpcode, rec.Code)
+			if tc.httpcode == http.StatusBadRequest {
+				require.Len(t, verifier.VerifyCalls(), calls)
+
+				return
+			}
+			require.Equal(t, []byte(tc.body), verifier.VerifyCalls()[len(verifier.VerifyCalls())-1].Req.Body)
+		})
+	}
+}
+
+func TestDepositSignatureAfterIPRateLimit(t *testing.T) {
+	verifier := &mock.RequestVerifierMock{
+		VerifyFunc: func(_ context.Context, _ *anymind.SignedRequest) error {
+			return anymind.UnauthorizedError(errors.New("invalid signature"))
+		},
+	}
+	svc := NewService(&mock.APIServiceMock{}, WithRequestVerifier(verifier), WithIPRateLimit(1, 1))
+	router := svc.NewRouter()
+
+	codes := []int{}
+	for i := 0; i < 3; i++ {
+		rec := httptest.NewRecorder()
+		req, err := http.NewRequest("POST", depositPath, strings.NewReader(`{"datetime": "2020-01-01T00:00:00Z", "amount": "1"}`))
+		require.NoError(t, err)
+		req.RemoteAddr = "10.0.0.1:1234"
+		req.Header.Set(anymind.SignatureHeader, "forged")
+
+		router.ServeHTTP(rec, req)
+		codes = append(codes, rec.Code)
+	}
+
+	// throttled requests are not verified, so they can not load verifier and its nonce store.
+	require.Equal(t, []int{http.StatusUnauthorized, http.StatusTooManyRequests, http.StatusTooManyRequests}, codes)
+	require.Len(t, verifier.VerifyCalls(), 1)
+}
+
+func TestDepositSignatureBodyLimit(t *testing.T) {
+	verifier := &mock.RequestVerifierMock{
+		VerifyFunc: func(_ context.Context, _ *anymind.SignedRequest) error {
+			return nil
+		},
+	}
+	svc := NewService(&mock.APIServiceMock{}, WithRequestVerifier(verifier))
+	router := svc.NewRouter()
+
+	body := `{"datetime": "2020-01-01T00:00:00Z", "amount": "1", "padding": "` + strings.Repeat("x", maxSignedBody) + `"}`
+	rec := httptest.NewRecorder()
+	req, err := http.NewRequest("POST", depositPath, strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set(anymind.SignatureHeader, "valid")
+
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Empty(t, verifier.VerifyCalls())
+}
+
+func TestRateLimit(t *testing.T) {
+	throttled := stdprometheus.NewCounterVec(stdprometheus.CounterOpts{Name: "throttled"}, []string{"route", "reason"})
+	svc := NewService(&mock.APIServiceMock{
+		HistoricalFunc: func(_ context.Context, _ *anymind.HistoricalDataReq) ([]*anymind.HistoricalData, error) {
+			return nil, nil
+		},
+	}, WithRateLimit(1, 2), WithThrottledCounter(kitprometheus.NewCounter(throttled)))
+	router := svc.NewRouter()
+
+	call := func(remoteAddr string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req, err := http.NewRequest("POST", historicalPath, strings.NewReader(`
+			{
+				"startDatetime": "2020-01-01T00:00:00Z",
+				"endDatetime": "2020-01-01T01:00:00Z"
+			}`))
+		require.NoError(t, err)
+		req.RemoteAddr = remoteAddr
+
+		router.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	require.Equal(t, http.StatusOK, call("10.0.0.1:1000").Code)
+	require.Equal(t, http.StatusOK, call("10.0.0.1:1001").Code)
+
+	rec := call("10.0.0.1:1002")
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+	require.Equal(t, "1", rec.Header().Get("Retry-After"))
+	require.Equal(t, float64(1), testutil.ToFloat64(throttled.WithLabelValues("historical", throttleRate)))
+
+	// other client has its own bucket.
+	require.Equal(t, http.StatusOK, call("10.0.0.2:1000").Code)
+}
+
+func TestIPRateLimit(t *testing.T) {
+	throttled := stdprometheus.NewCounterVec(stdprometheus.CounterOpts{Name: "throttled"}, []string{"route", "reason"})
+	auth := &mock.AuthenticatorMock{
+		AuthenticateFunc: func(_ context.Context, _ string) (*anymind.Principal, error) {
+			return nil, anymind.UnauthorizedError(errors.New("invalid api key"))
+		},
+	}
+	svc := NewService(&mock.APIServiceMock{}, WithAuthenticator(auth), WithRateLimit(100, 100), WithIPRateLimit(1, 2),
+		WithThrottledCounter(kitprometheus.NewCounter(throttled)))
+	router := svc.NewRouter()
+
+	call := func(remoteAddr string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req, err := http.NewRequest("POST", historicalPath, strings.NewReader(`
+			{
+				"startDatetime": "2020-01-01T00:00:00Z",
+				"endDatetime": "2020-01-01T01:00:00Z"
+			}`))
+		require.NoError(t, err)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set(apiKeyHeader, "guess")
+
+		router.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	require.Equal(t, http.StatusUnauthorized, call("10.0.0.1:1000").Code)
+	require.Equal(t, http.StatusUnauthorized, call("10.0.0.1:1001").Code)
+
+	// bad credential is throttled before it is looked up.
+	rec := call("10.0.0.1:1002")
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+	require.Equal(t, "1", rec.Header().Get("Retry-After"))
+	require.Len(t, auth.AuthenticateCalls(), 2)
+	require.Equal(t, float64(1), testutil.ToFloat64(throttled.WithLabelValues("historical", throttleIP)))
+
+	require.Equal(t, http.StatusUnauthorized, call("10.0.0.2:1000").Code)
+}
+
+func TestInFlightLimit(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	svc := NewService(&mock.APIServiceMock{
+		DepositFunc: func(_ context.Context, _ *anymind.DepositInput) error {
+			started <- struct{}{}
+			<-release
+
+			return nil
+		},
+	}, WithMaxInFlightWrites(1))
+	router := svc.NewRouter()
+
+	call := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req, err := http.NewRequest("POST", depositPath, strings.NewReader(`
+			{
+				"datetime": "2020-01-01T00:00:00Z",
+				"amount": "1"
+			}`))
+		require.NoError(t, err)
+
+		router.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	done := make(chan int)
+	go func() {
+		done <- call().Code
+	}()
+	<-started
+
+	rec := call()
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+	require.NotEmpty(t, rec.Header().Get("Retry-After"))
+
+	close(release)
+	require.Equal(t, http.StatusOK, <-done)
+
+	go func() {
+		<-started
+	}()
+	require.Equal(t, http.StatusOK, call().Code)
+}
+
+func TestRequestDuration(t *testing.T) {
+	duration := stdprometheus.NewHistogramVec(stdprometheus.HistogramOpts{Name: "duration"}, []string{"route", "method", "status"})
+	svc := NewService(&mock.APIServiceMock{}, WithRequestDuration(kitprometheus.NewHistogram(duration)))
+	router := svc.NewRouter()
+
+	rec := httptest.NewRecorder()
+	req, err := http.NewRequest("POST", depositPath, strings.NewReader(`{`))
+	require.NoError(t, err)
+
+	router.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	m := &dto.Metric{}
+	require.NoError(t, duration.WithLabelValues(depositPath, "POST", "400").(stdprometheus.Metric).Write(m))
+	require.Equal(t, uint64(1), m.GetHistogram().GetSampleCount())
+
+}
+
+func TestMetricsRouter(t *testing.T) {
+	svc := NewService(&mock.APIServiceMock{})
+
+	// balance is exported by metrics, so they are not served next to the api.
+	rec := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", metricsPath, nil)
+	require.NoError(t, err)
+
+	svc.NewRouter().ServeHTTP(rec, req)
+	require.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = httptest.NewRecorder()
+	svc.NewMetricsRouter().ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestTracePropagation(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(trace.NewNoopTracerProvider())
+		otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+	})
+
+	svc := NewService(&mock.APIServiceMock{
+		DepositFunc: func(ctx context.Context, _ *anymind.DepositInput) error {
+			require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", trace.SpanContextFromContext(ctx).TraceID().String())
+
+			return nil
+		},
+	})
+	router := svc.NewRouter()
+
+	rec := httptest.NewRecorder()
+	req, err := http.NewRequest("POST", depositPath, strings.NewReader(`
+		{
+			"datetime": "2020-01-01T00:00:00Z",
+			"amount": "1"
+		}`))
+	require.NoError(t, err)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	router.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+
+	decode, server := spans[0], spans[1]
+	require.Equal(t, "decode request", decode.Name())
+	require.Equal(t, server.SpanContext().SpanID(), decode.Parent().SpanID())
+	require.Equal(t, "POST "+depositPath, server.Name())
+	require.Equal(t, "00f067aa0ba902b7", server.Parent().SpanID().String())
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext().TraceID().String())
+}
+
+func TestHealth(t *testing.T) {
+	dbErr := errors.New("schema version is 1, expected 2")
+
+	testCases := []struct {
+		name     string
+		path     string
+		dbErr    error
+		draining bool
+		httpcode int
+		body     string
+	}{
+		{name: "liveness", path: healthzPath, dbErr: dbErr, httpcode: http.StatusOK, body: `{"status":"ok"}`},
+		{name: "ready", path: readyzPath, httpcode: http.StatusOK, body: `{"status":"ok","checks":{"database":"ok"}}`},
+		{name: "unavailable", path: readyzPath, dbErr: dbErr, httpcode: http.StatusServiceUnavailable,
+			body: `{"status":"unavailable","checks":{"database":"schema version is 1, expected 2"}}`},
+		{name: "draining", path: readyzPath, draining: true, httpcode: http.StatusServiceUnavailable, body: `{"status":"draining"}`},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			svc := NewService(&mock.APIServiceMock{}, WithHealthCheck("database", &mock.HealthCheckerMock{
+				CheckHealthFunc: func(context.Context) error {
+					return tc.dbErr
+				},
+			}))
+			svc.draining.Store(tc.draining)
+
+			rec := httptest.NewRecorder()
+			req, err := http.NewRequest("GET", tc.path, nil)
+			require.NoError(t, err)
+
+			svc.NewRouter().ServeHTTP(rec, req)
+
+			require.Equal(t, tc.httpcode, rec.Code)
+			require.JSONEq(t, tc.body, rec.Body.String())
+		})
+	}
+}
+
+func TestStartBindError(t *testing.T) {
+	lis, err := net.Listen("tcp", ":0")
+	require.NoError(t, err)
+	defer lis.Close()
+
+	svc := NewService(&mock.APIServiceMock{}, WithListenPort(lis.Addr().(*net.TCPAddr).Port))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	err = svc.Start(ctx)
+	require.Error(t, err)
+	require.NoError(t, ctx.Err())
+}
+
+func TestGracefulShutdown(t *testing.T) {
+	lis, err := net.Listen("tcp", ":0")
+	require.NoError(t, err)
+	port := lis.Addr().(*net.TCPAddr).Port
+	lis.Close()
+
+	inFlight := make(chan struct{})
+	release := make(chan struct{})
+	svc := NewService(&mock.APIServiceMock{
+		DepositFunc: func(context.Context, *anymind.DepositInput) error {
+			close(inFlight)
+			<-release
+
+			return nil
+		},
+	}, WithListenPort(port), WithDrainDelay(100*time.Millisecond))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- svc.Start(ctx)
+	}()
+
+	baseURL := fmt.Sprintf("http://127.0.0.1:%d", port)
+	require.Eventually(t, func() bool {
+		resp, err := http.Get(baseURL + healthzPath)
+		if err != nil {
+			return false
+		}
+		resp.Body.Close()
+
+		return true
+	}, time.Second, 10*time.Millisecond)
+
+	deposited := make(chan int, 1)
+	go func() {
+		resp, err := http.Post(baseURL+depositPath, "application/json",
+			strings.NewReader(`{"datetime": "2020-01-01T00:00:00Z", "amount": "1"}`))
+		if err != nil {
+			deposited <- 0
+			return
+		}
+		resp.Body.Close()
+		deposited <- resp.StatusCode
+	}()
+
+	<-inFlight
+	cancel()
+
+	// new request is rejected while draining, in-flight deposit still complete.
+	require.Eventually(t, func() bool {
+		return svc.draining.Load()
+	}, time.Second, 5*time.Millisecond)
+
+	resp, err := http.Post(baseURL+historicalPath, "application/json", strings.NewReader(`{}`))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+
+	close(release)
+	require.Equal(t, http.StatusOK, <-deposited)
+	require.NoError(t, <-stopped)
+}
+
+func TestActiveConfig(t *testing.T) {
+	svc := NewService(&mock.APIServiceMock{},
+		WithAuthenticator(&mock.AuthenticatorMock{
+			AuthenticateFunc: func(_ context.Context, credential string) (*anymind.Principal, error) {
+				if credential != "admin" {
+					return &anymind.Principal{Scopes: []string{anymind.ScopeHistoryRead}}, nil
+				}
+
+				return &anymind.Principal{Scopes: []string{anymind.ScopeAdmin}}, nil
+			},
+		}),
+		WithConfigService(&mock.ConfigServiceMock{
+			ActiveConfigFunc: func(context.Context) (*anymind.ActiveConfig, error) {
+				return &anymind.ActiveConfig{
+					Version:  2,
+					LoadedAt: mustTime("2020-01-01T00:00:00Z"),
+					Settings: map[string]any{"http": map[string]any{"rate_limit": 20}},
+				}, nil
+			},
+		}))
+	router := svc.NewRouter()
+
+	for credential, httpcode := range map[string]int{"admin": http.StatusOK, "reader": http.StatusForbidden} {
+		rec := httptest.NewRecorder()
+		req, err := http.NewRequest("GET", adminConfigPath, nil)
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+credential)
+
+		router.ServeHTTP(rec, req)
+		require.Equal(t, httpcode, rec.Code)
+
+		if httpcode == http.StatusOK {
+			require.JSONEq(t, `{"version":2,"loadedAt":"2020-01-01T00:00:00Z","settings":{"http":{"rate_limit":20}}}`, rec.Body.String())
+		}
+	}
+}
+
+func TestSetRateLimit(t *testing.T) {
+	svc := NewService(&mock.APIServiceMock{
+		HistoricalFunc: func(context.Context, *anymind.HistoricalDataReq) ([]*anymind.HistoricalData, error) {
+			return nil, nil
+		},
+	})
+	router := svc.NewRouter()
+
+	historical := func() int {
+		rec := httptest.NewRecorder()
+		req, err := http.NewRequest("POST", historicalPath, strings.NewReader(
+			`{"startDatetime": "2020-01-01T00:00:00Z", "endDatetime": "2020-01-01T01:00:00Z"}`))
+		require.NoError(t, err)
+		req.RemoteAddr = "10.0.0.1:1234"
+
+		router.ServeHTTP(rec, req)
+
+		return rec.Code
+	}
+
+	require.Equal(t, http.StatusOK, historical())
+	require.Equal(t, http.StatusOK, historical())
+
+	svc.SetRateLimit(0.001, 1)
+	require.Equal(t, http.StatusOK, historical())
+	require.Equal(t, http.StatusTooManyRequests, historical())
+
+	svc.SetRateLimit(0, 0)
+	require.Equal(t, http.StatusOK, historical())
+}
+
+func TestRequestIDAndAccessLog(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+
+	var seen string
+	svc := NewService(&mock.APIServiceMock{
+		DepositFunc: func(ctx context.Context, _ *anymind.DepositInput) error {
+			seen, _ = anymind.RequestIDFromContext(ctx)
+
+			return nil
+		},
+	}, WithLogger(zap.New(core)))
+	router := svc.NewRouter()
+
+	testCases := []struct {
+		name      string
+		requestID string
+		generated bool
+	}{
+		{name: "from caller", requestID: "abc-123"},
+		{name: "generated", generated: true},
+		{name: "invalid", requestID: "bad id\nwith newline", generated: true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			logs.TakeAll()
+
+			rec := httptest.NewRecorder()
+			req, err := http.NewRequest("POST", depositPath, strings.NewReader(`
+				{
+					"datetime": "2020-01-01T00:00:00Z",
+					"amount": "12.5"
+				}`))
+			require.NoError(t, err)
+			if tc.requestID != "" {
+				req.Header.Set(anymind.RequestIDHeader, tc.requestID)
+			}
+
+			router.ServeHTTP(rec, req)
+			require.Equal(t, http.StatusOK, rec.Code)
+
+			id := rec.Header().Get(anymind.RequestIDHeader)
+			require.Equal(t, id, seen)
+			if tc.generated {
+				require.Len(t, id, 32)
+			} else {
+				require.Equal(t, tc.requestID, id)
+			}
+
+			access := logs.FilterMessage("request").All()
+			require.Len(t, access, 1)
+
+			fields := access[0].ContextMap()
+			require.Equal(t, id, fields["request_id"])
+			require.Equal(t, depositPath, fields["route"])
+			require.Equal(t, "POST", fields["method"])
+			require.EqualValues(t, http.StatusOK, fields["status"])
+			require.Equal(t, "12.5", fields["amount"])
+			require.Contains(t, fields, "latency")
+		})
+	}
+}
+
+func TestAuditFailure(t *testing.T) {
+	var recorded []string
+	audits := &mock.AuditServiceMock{
+		RecordAuditFunc: func(ctx context.Context, action string, payload any, outcome string) error {
+			requestID, _ := anymind.RequestIDFromContext(ctx)
+			clientIP, _ := anymind.ClientIPFromContext(ctx)
+			require.Equal(t, "req-1", requestID)
+			require.Equal(t, "192.0.2.1", clientIP)
+			require.Equal(t, map[string]string{"datetime": "2020-01-01T00:00:00Z", "amount": "1.5", "asset": "BTC"}, payload)
+
+			recorded = append(recorded, action+" "+outcome)
+
+			return nil
+		},
+	}
+
+	depositErr := error(anymind.ParameterError(errors.New("rejected")))
+	svc := NewService(&mock.APIServiceMock{
+		DepositFunc: func(_ context.Context, _ *anymind.DepositInput) error {
+			return depositErr
+		},
+	}, WithAuditService(audits))
+	router := svc.NewRouter()
+
+	deposit := func() int {
+		rec := httptest.NewRecorder()
+		req, err := http.NewRequest("POST", depositPath, strings.NewReader(`{"datetime": "2020-01-01T00:00:00Z", "amount": "1.50"}`))
+		require.NoError(t, err)
+		req.Header.Set(anymind.RequestIDHeader, "req-1")
+		req.RemoteAddr = "192.0.2.1:5000"
+
+		router.ServeHTTP(rec, req)
+
+		return rec.Code
+	}
+
+	require.Equal(t, http.StatusBadRequest, deposit())
+	depositErr = anymind.InternalError(errors.New("db down"))
+	require.Equal(t, http.StatusInternalServerError, deposit())
+	depositErr = anymind.TooManyRequestsError(errors.New("throttled"))
+	require.Equal(t, http.StatusTooManyRequests, deposit())
+	depositErr = nil
+	require.Equal(t, http.StatusOK, deposit())
+
+	require.Equal(t, []string{"deposit rejected", "deposit failed"}, recorded)
+}
+
+func TestListAudit(t *testing.T) {
+	svc := NewService(&mock.APIServiceMock{}, WithAuditService(&mock.AuditServiceMock{
+		ListAuditFunc: func(_ context.Context, filter *anymind.AuditFilter) ([]*anymind.AuditEntry, error) {
+			require.Equal(t, &anymind.AuditFilter{Actor: "key:7", AfterID: 10, Limit: defaultAuditLimit}, filter)
+
+			return []*anymind.AuditEntry{{
+				ID:          11,
+				At:          mustTime("2020-01-01T00:00:00Z"),
+				Actor:       "key:7",
+				ClientIP:    "192.0.2.1",
+				RequestID:   "req-1",
+				Action:      anymind.AuditDeposit,
+				PayloadHash: "payload",
+				Outcome:     anymind.AuditSuccess,
+				PrevHash:    "prev",
+				Hash:        "hash",
+			}}, nil
+		},
+	}))
+	router := svc.NewRouter()
+
+	rec := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", auditPath+"?actor=key:7&after=10", nil)
+	require.NoError(t, err)
+
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `
+		[{
+			"id": 11,
+			"at": "2020-01-01T00:00:00Z",
+			"actor": "key:7",
+			"clientIp": "192.0.2.1",
+			"requestId": "req-1",
+			"action": "deposit",
+			"payloadHash": "payload",
+			"outcome": "success",
+			"prevHash": "prev",
+			"hash": "hash"
+		}]`, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	req, err = http.NewRequest("GET", auditPath+"?limit=5000", nil)
+	require.NoError(t, err)
+
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestVerifyChain(t *testing.T) {
+	svc := NewService(&mock.APIServiceMock{}, WithChainService(&mock.ChainServiceMock{
+		VerifyChainFunc: func(_ context.Context) (*anymind.ChainReport, error) {
+			return &anymind.ChainReport{
+				Verified:    4,
+				HeadID:      4,
+				HeadHash:    "head",
+				Checkpoints: 2,
+				Break:       &anymind.ChainBreak{ID: 5, Reason: "content does not match hash"},
+			}, nil
+		},
+	}))
+
+	rec := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", adminChainPath, nil)
+	require.NoError(t, err)
+
+	svc.NewRouter().ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `
+		{
+			"intact": false,
+			"verified": 4,
+			"headId": 4,
+			"headHash": "head",
+			"checkpoints": 2,
+			"break": {"id": 5, "checkpoint": false, "reason": "content does not match hash"}
+		}`, rec.Body.String())
+}
+
+func TestAssets(t *testing.T) {
+	createdAt := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	var created *anymind.Asset
+	svc := NewService(&mock.APIServiceMock{}, WithAssetService(&mock.AssetServiceMock{
+		CreateAssetFunc: func(_ context.Context, asset *anymind.Asset) error {
+			if asset.Code == "BTC" {
+				return anymind.ParameterError(errors.New("asset BTC already exists"))
+			}
+
+			asset.CreatedAt = createdAt
+			created = asset
+
+			return nil
+		},
+		ListAssetsFunc: func(_ context.Context) ([]*anymind.Asset, error) {
+			return []*anymind.Asset{{Code: "BTC", Scale: 8, DisplayPrecision: 8, CreatedAt: createdAt}}, nil
+		},
+	}))
+	router := svc.NewRouter()
+
+	rec := httptest.NewRecorder()
+	req, err := http.NewRequest("POST", assetsPath, strings.NewReader(`{"code": "ETH", "scale": 18, "displayPrecision": 6}`))
+	require.NoError(t, err)
+
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusCreated, rec.Code)
+	require.Equal(t, &anymind.Asset{Code: "ETH", Scale: 18, DisplayPrecision: 6, CreatedAt: createdAt}, created)
+	require.JSONEq(t, `{"code": "ETH", "scale": 18, "displayPrecision": 6, "createdAt": "2022-01-01T00:00:00Z"}`, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	req, err = http.NewRequest("POST", assetsPath, strings.NewReader(`{"code": "BTC", "scale": 8, "displayPrecision": 8}`))
+	require.NoError(t, err)
+
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	req, err = http.NewRequest("GET", assetsPath, nil)
+	require.NoError(t, err)
+
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `[{"code": "BTC", "scale": 8, "displayPrecision": 8, "createdAt": "2022-01-01T00:00:00Z"}]`, rec.Body.String())
+}
+
+func TestHistoricalQuote(t *testing.T) {
+	value := mustApd("5000.50")
+	svc := NewService(&mock.APIServiceMock{
+		HistoricalFunc: func(_ context.Context, req *anymind.HistoricalDataReq) ([]*anymind.HistoricalData, error) {
+			require.Equal(t, "USD", req.Quote)
+
+			return []*anymind.HistoricalData{
+				{DateTime: mustTime("2020-01-01T00:00:00Z"), Amount: mustApd("0.1"), Value: &value},
+				{DateTime: mustTime("2020-01-01T01:00:00Z"), Amount: mustApd("0.1")},
+			}, nil
+		},
+	})
+
+	rec := httptest.NewRecorder()
+	req, err := http.NewRequest("POST", historicalPath, strings.NewReader(`
+		{
+			"startDatetime": "2020-01-01T00:00:00Z",
+			"endDateTime": "2020-01-01T01:00:00Z",
+			"quote": "USD"
+		}`))
+	require.NoError(t, err)
+
+	svc.NewRouter().ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `
+		[
+			{"datetime": "2020-01-01T00:00:00Z", "amount": "0.1", "value": "5000.50"},
+			{"datetime": "2020-01-01T01:00:00Z", "amount": "0.1", "rateMissing": true}
+		]`, rec.Body.String())
+}
+
+func TestImportRates(t *testing.T) {
+	var imported []*anymind.Rate
+	svc := NewService(&mock.APIServiceMock{}, WithPriceService(&mock.PriceServiceMock{
+		ImportRatesFunc: func(_ context.Context, rates []*anymind.Rate) error {
+			imported = rates
+
+			return nil
+		},
+	}))
+	router := svc.NewRouter()
+
+	rec := httptest.NewRecorder()
+	req, err := http.NewRequest("POST", adminRatesPath, strings.NewReader(`
+		[{"hour": "2020-01-01T00:00:00Z", "base": "BTC", "quote": "USD", "rate": 7200.5}]`))
+	require.NoError(t, err)
+
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `{"imported": 1}`, rec.Body.String())
+	require.Equal(t, []*anymind.Rate{
+		{Hour: mustTime("2020-01-01T00:00:00Z"), Base: "BTC", Quote: "USD", Rate: mustApd("7200.5")},
+	}, imported)
+
+	rec = httptest.NewRecorder()
+	req, err = http.NewRequest("POST", adminRatesPath, strings.NewReader(`[{"rate": "x"}]`))
+	require.NoError(t, err)
+
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestStats(t *testing.T) {
+	var got *anymind.HistoricalDataReq
+	avg := mustApd("1.5")
+	svc := NewService(&mock.APIServiceMock{}, WithStatsService(&mock.StatsServiceMock{
+		StatsFunc: func(_ context.Context, req *anymind.HistoricalDataReq) ([]*anymind.BucketStats, error) {
+			got = req
+
+			return []*anymind.BucketStats{
+				{
+					DateTime: mustTime("2020-01-01T00:00:00Z"),
+					Count:    2,
+					Delta:    mustApd("3"),
+					Min:      apd.New(1, 0),
+					Max:      apd.New(2, 0),
+					Average:  &avg,
+					Open:     mustApd("10"),
+					Close:    mustApd("13"),
+				},
+				{
+					DateTime: mustTime("2020-01-01T01:00:00Z"),
+					Delta:    mustApd("0"),
+					Open:     mustApd("13"),
+					Close:    mustApd("13"),
+				},
+			}, nil
+		},
+	}))
+
+	rec := httptest.NewRecorder()
+	req, err := http.NewRequest("POST", statsPath, strings.NewReader(`
+		{
+			"startDatetime": "2020-01-01T07:00:00+07:00",
+			"endDateTime": "2020-01-01T01:00:00Z",
+			"asset": "eth"
+		}`))
+	require.NoError(t, err)
+
+	svc.NewRouter().ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, &anymind.HistoricalDataReq{
+		Start: mustTime("2020-01-01T00:00:00Z"),
+		End:   mustTime("2020-01-01T01:00:00Z"),
+		Asset: "eth",
+	}, got)
+	require.JSONEq(t, `
+		[
+			{"datetime": "2020-01-01T00:00:00Z", "count": 2, "delta": "3", "min": "1", "max": "2", "average": "1.5",
+				"open": "10", "close": "13"},
+			{"datetime": "2020-01-01T01:00:00Z", "count": 0, "delta": "0", "min": null, "max": null, "average": null,
+				"open": "13", "close": "13"}
+		]`, rec.Body.String())
+}
+
+func TestCandles(t *testing.T) {
+	var got *anymind.CandleReq
+	svc := NewService(&mock.APIServiceMock{}, WithCandleService(&mock.CandleServiceMock{
+		CandlesFunc: func(_ context.Context, req *anymind.CandleReq) ([]*anymind.Candle, error) {
+			got = req
+
+			return []*anymind.Candle{
+				{
+					DateTime: mustTime("2020-01-01T04:00:00Z"),
+					Open:     mustApd("10"),
+					High:     mustApd("25.5"),
+					Low:      mustApd("4"),
+					Close:    mustApd("12"),
+				},
+			}, nil
+		},
+	}))
+	router := svc.NewRouter()
+
+	rec := httptest.NewRecorder()
+	req, err := http.NewRequest("POST", candlesPath, strings.NewReader(`
+		{
+			"startDatetime": "2020-01-01T00:00:00Z",
+			"endDateTime": "2020-01-01T04:00:00Z",
+			"granularity": "4h"
+		}`))
+	require.NoError(t, err)
+
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, 4*time.Hour, got.Granularity)
+	require.JSONEq(t, `
+		[{"datetime": "2020-01-01T04:00:00Z", "open": "10", "high": "25.5", "low": "4", "close": "12"}]`,
+		rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	req, err = http.NewRequest("POST", candlesPath, strings.NewReader(`{"granularity": "hourly"}`))
+	require.NoError(t, err)
+
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestAnomalies(t *testing.T) {
+	reviewed := mustTime("2020-01-02T00:00:00Z")
+	anomaly := &anymind.Anomaly{
+		ID:            7,
+		DateTime:      mustTime("2020-01-01T03:00:00Z"),
+		Amount:        mustApd("500"),
+		Asset:         "BTC",
+		Reason:        anymind.AnomalyReasonAmount,
+		Score:         12.5,
+		Status:        anymind.AnomalyHeld,
+		CreatedAt:     mustTime("2020-01-01T03:00:01Z"),
+		DepositStatus: anymind.DepositPending,
+	}
+
+	var audited []any
+	svc := NewService(&mock.APIServiceMock{}, WithAnomalyService(&mock.AnomalyServiceMock{
+		ListAnomaliesFunc: func(_ context.Context, status string, limit int) ([]*anymind.Anomaly, error) {
+			require.Equal(t, anymind.AnomalyHeld, status)
+			require.Equal(t, defaultAnomalyLimit, limit)
+
+			return []*anymind.Anomaly{anomaly}, nil
+		},
+		ReviewAnomalyFunc: func(_ context.Context, id int64, status string) (*anymind.Anomaly, error) {
+			if id != 7 {
+				return nil, anymind.NotFoundError(errors.New("no held anomaly"))
+			}
+
+			res := *anomaly
+			res.Status, res.ReviewedAt = status, &reviewed
+
+			return &res, nil
+		},
+	}), WithAuditService(&mock.AuditServiceMock{
+		RecordAuditFunc: func(_ context.Context, _ string, payload any, _ string) error {
+			audited = append(audited, payload)
+
+			return nil
+		},
+	}))
+	router := svc.NewRouter()
+
+	rec := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", anomaliesPath+"?status=held", nil)
+	require.NoError(t, err)
+
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `
+		[{"id": 7, "datetime": "2020-01-01T03:00:00Z", "amount": "500", "asset": "BTC", "reason": "amount",
+			"score": 12.5, "status": "held", "createdAt": "2020-01-01T03:00:01Z", "reviewedAt": null,
+			"depositStatus": "pending"}]`,
+		rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	req, err = http.NewRequest("POST", "/anomalies/7/review", strings.NewReader(`{"status": "released"}`))
+	require.NoError(t, err)
+
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Body.String(), `"status":"released","createdAt":"2020-01-01T03:00:01Z","reviewedAt":"2020-01-02T00:00:00Z"`)
+
+	rec = httptest.NewRecorder()
+	req, err = http.NewRequest("POST", "/anomalies/8/review", strings.NewReader(`{"status": "rejected"}`))
+	require.NoError(t, err)
+
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusNotFound, rec.Code)
+	require.Equal(t, []any{map[string]any{"id": int64(8), "status": "rejected"}}, audited)
+}
+
+func TestPendingDeposit(t *testing.T) {
+	var audited []any
+	svc := NewService(&mock.APIServiceMock{
+		DepositFunc: func(_ context.Context, input *anymind.DepositInput) error {
+			require.Equal(t, anymind.DepositPending, input.Status)
+			input.ID = 42
+
+			return nil
+		},
+		HistoricalFunc: func(_ context.Context, req *anymind.HistoricalDataReq) ([]*anymind.HistoricalData, error) {
+			require.True(t, req.IncludePending)
+
+			return nil, nil
+		},
+	}, WithDepositStatusService(&mock.DepositStatusServiceMock{
+		SetDepositStatusFunc: func(_ context.Context, id int64, status string) (*anymind.DepositEvent, error) {
+			if id != 42 {
+				return nil, anymind.NotFoundError(errors.New("no pending deposit"))
+			}
+
+			return &anymind.DepositEvent{ID: id, DateTime: mustTime("2020-01-01T03:00:00Z"), Amount: mustApd("1.5"), Asset: "BTC"}, nil
+		},
+	}), WithAuditService(&mock.AuditServiceMock{
+		RecordAuditFunc: func(_ context.Context, _ string, payload any, _ string) error {
+			audited = append(audited, payload)
+
+			return nil
+		},
+	}))
+	router := svc.NewRouter()
+
+	rec := httptest.NewRecorder()
+	req, err := http.NewRequest("POST", depositPath,
+		strings.NewReader(`{"datetime": "2020-01-01T03:00:00Z", "amount": "1.5", "status": "pending"}`))
+	require.NoError(t, err)
+
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `{"id": 42, "datetime": "2020-01-01T03:00:00Z", "amount": 1.5, "status": "pending"}`, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	req, err = http.NewRequest("PATCH", "/deposits/42", strings.NewReader(`{"status": "confirmed"}`))
+	require.NoError(t, err)
+
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `{"id": 42, "datetime": "2020-01-01T03:00:00Z", "amount": "1.5", "asset": "BTC", "status": "confirmed"}`,
+		rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	req, err = http.NewRequest("PATCH", "/deposits/43", strings.NewReader(`{"status": "failed"}`))
+	require.NoError(t, err)
+
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusNotFound, rec.Code)
+	require.Equal(t, []any{map[string]any{"id": int64(43), "status": "failed"}}, audited)
+
+	rec = httptest.NewRecorder()
+	req, err = http.NewRequest("POST", historicalPath, strings.NewReader(`
+		{"startDatetime": "2020-01-01T00:00:00Z", "endDateTime": "2020-01-01T01:00:00Z", "includePending": true}`))
+	require.NoError(t, err)
+
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+}
@@ -0,0 +1,26 @@
+package instrumenting
+
+import "github.com/go-kit/kit/metrics"
+
+type Option func(*instruments)
+
+// WithDuration observe latency of every call, labeled by method and error.
+func WithDuration(h metrics.Histogram) Option {
+	return func(i *instruments) {
+		i.duration = h
+	}
+}
+
+// WithDepositCounter count settled deposits, that is confirmed deposits credited to balance.
+func WithDepositCounter(c metrics.Counter) Option {
+	return func(i *instruments) {
+		i.deposits = c
+	}
+}
+
+// WithBalanceGauge track current balance by asset label, see APIService.RefreshBalance.
+func WithBalanceGauge(g metrics.Gauge) Option {
+	return func(i *instruments) {
+		i.balance = g
+	}
+}
@@ -0,0 +1,209 @@
+package instrumenting
+
+import (
+	"anymind"
+	"context"
+	"fmt"
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/discard"
+	"time"
+)
+
+var _ anymind.APIService = &APIService{}
+var _ anymind.PersistenceService = &PersistenceService{}
+var _ anymind.AnomalyService = &AnomalyService{}
+var _ anymind.DepositStatusService = &DepositStatusService{}
+
+type instruments struct {
+	duration metrics.Histogram
+	deposits metrics.Counter
+	balance  metrics.Gauge
+}
+
+func newInstruments(opts []Option) *instruments {
+	i := &instruments{
+		duration: discard.NewHistogram(),
+		deposits: discard.NewCounter(),
+		balance:  discard.NewGauge(),
+	}
+
+	for _, opt := range opts {
+		opt(i)
+	}
+
+	return i
+}
+
+func (i *instruments) observe(method string, begin time.Time, err error) {
+	i.duration.With("method", method, "error", fmt.Sprint(err != nil)).Observe(time.Since(begin).Seconds())
+}
+
+// APIService decorate api with metrics.
+type APIService struct {
+	next anymind.APIService
+	*instruments
+}
+
+func NewAPIService(next anymind.APIService, opts ...Option) *APIService {
+	return &APIService{
+		next:        next,
+		instruments: newInstruments(opts),
+	}
+}
+
+func (s *APIService) Deposit(ctx context.Context, input *anymind.DepositInput) (err error) {
+	defer func(begin time.Time) {
+		s.observe("deposit", begin, err)
+	}(time.Now())
+
+	err = s.next.Deposit(ctx, input)
+	// held deposit is not stored and pending deposit is not credited yet, they are counted once settled.
+	if err == nil && input.ID != 0 && anymind.DepositStatus(input.Status) == anymind.DepositConfirmed {
+		s.deposits.Add(1)
+	}
+
+	return err
+}
+
+func (s *APIService) Historical(
+	ctx context.Context,
+	req *anymind.HistoricalDataReq,
+) (res []*anymind.HistoricalData, err error) {
+	defer func(begin time.Time) {
+		s.observe("historical", begin, err)
+	}(time.Now())
+
+	return s.next.Historical(ctx, req)
+}
+
+// RefreshBalance set balance gauge of each asset to its current balance read from database, it is called periodically
+// so every instance report the same balance.
+func (s *APIService) RefreshBalance(ctx context.Context, assets []string) error {
+	now := time.Now().UTC()
+
+	for _, asset := range assets {
+		res, err := s.next.Historical(ctx, &anymind.HistoricalDataReq{Start: now, End: now, Asset: asset})
+		if err != nil {
+			return err
+		}
+
+		var balance float64
+		if len(res) > 0 {
+			balance, _ = res[0].Amount.Float64()
+		}
+
+		s.balance.With("asset", anymind.AssetCode(asset)).Set(balance)
+	}
+
+	return nil
+}
+
+// AnomalyService decorate anomaly review with metrics, released deposit is counted as it is credited then.
+type AnomalyService struct {
+	next anymind.AnomalyService
+	*instruments
+}
+
+func NewAnomalyService(next anymind.AnomalyService, opts ...Option) *AnomalyService {
+	return &AnomalyService{
+		next:        next,
+		instruments: newInstruments(opts),
+	}
+}
+
+func (s *AnomalyService) RecordAnomaly(ctx context.Context, anomaly *anymind.Anomaly) (err error) {
+	defer func(begin time.Time) {
+		s.observe("record_anomaly", begin, err)
+	}(time.Now())
+
+	return s.next.RecordAnomaly(ctx, anomaly)
+}
+
+func (s *AnomalyService) ListAnomalies(ctx context.Context, status string, limit int) (res []*anymind.Anomaly, err error) {
+	defer func(begin time.Time) {
+		s.observe("list_anomalies", begin, err)
+	}(time.Now())
+
+	return s.next.ListAnomalies(ctx, status, limit)
+}
+
+func (s *AnomalyService) ReviewAnomaly(ctx context.Context, id int64, status string) (res *anymind.Anomaly, err error) {
+	defer func(begin time.Time) {
+		s.observe("review_anomaly", begin, err)
+	}(time.Now())
+
+	res, err = s.next.ReviewAnomaly(ctx, id, status)
+	// released pending deposit is counted once it is confirmed.
+	if err == nil && res.Status == anymind.AnomalyReleased &&
+		anymind.DepositStatus(res.DepositStatus) == anymind.DepositConfirmed {
+		s.deposits.Add(1)
+	}
+
+	return res, err
+}
+
+// DepositStatusService decorate pending deposit settlement with metrics, confirmed deposit is counted.
+type DepositStatusService struct {
+	next anymind.DepositStatusService
+	*instruments
+}
+
+func NewDepositStatusService(next anymind.DepositStatusService, opts ...Option) *DepositStatusService {
+	return &DepositStatusService{
+		next:        next,
+		instruments: newInstruments(opts),
+	}
+}
+
+func (s *DepositStatusService) SetDepositStatus(ctx context.Context, id int64, status string) (res *anymind.DepositEvent, err error) {
+	defer func(begin time.Time) {
+		s.observe("set_deposit_status", begin, err)
+	}(time.Now())
+
+	res, err = s.next.SetDepositStatus(ctx, id, status)
+	if err == nil && status == anymind.DepositConfirmed {
+		s.deposits.Add(1)
+	}
+
+	return res, err
+}
+
+func (s *DepositStatusService) ExpirePendingDeposits(ctx context.Context, before time.Time) (n int64, err error) {
+	defer func(begin time.Time) {
+		s.observe("expire_pending_deposits", begin, err)
+	}(time.Now())
+
+	return s.next.ExpirePendingDeposits(ctx, before)
+}
+
+// PersistenceService decorate persistence with metrics.
+type PersistenceService struct {
+	next anymind.PersistenceService
+	*instruments
+}
+
+func NewPersistenceService(next anymind.PersistenceService, opts ...Option) *PersistenceService {
+	return &PersistenceService{
+		next:        next,
+		instruments: newInstruments(opts),
+	}
+}
+
+func (s *PersistenceService) Deposit(ctx context.Context, input *anymind.DepositInput) (err error) {
+	defer func(begin time.Time) {
+		s.observe("deposit", begin, err)
+	}(time.Now())
+
+	return s.next.Deposit(ctx, input)
+}
+
+func (s *PersistenceService) Historical(
+	ctx context.Context,
+	req *anymind.HistoricalDataReq,
+) (res []*anymind.HistoricalData, err error) {
+	defer func(begin time.Time) {
+		s.observe("historical", begin, err)
+	}(time.Now())
+
+	return s.next.Historical(ctx, req)
+}
@@ -0,0 +1,130 @@
+package instrumenting
+
+import (
+	"anymind"
+	"anymind/src/mock"
+	"context"
+	"errors"
+	"github.com/cockroachdb/apd"
+	kitprometheus "github.com/go-kit/kit/metrics/prometheus"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func mustApd(number string) apd.Decimal {
+	val, _, err := apd.NewFromString(number)
+	if err != nil {
+		panic(err)
+	}
+
+	return *val
+}
+
+func TestAPIService(t *testing.T) {
+	duration := stdprometheus.NewHistogramVec(stdprometheus.HistogramOpts{Name: "duration"}, []string{"method", "error"})
+	deposits := stdprometheus.NewCounterVec(stdprometheus.CounterOpts{Name: "deposits"}, nil)
+	balance := stdprometheus.NewGaugeVec(stdprometheus.GaugeOpts{Name: "balance"}, []string{"asset"})
+
+	fail := false
+	held := false
+	svc := NewAPIService(&mock.APIServiceMock{
+		DepositFunc: func(_ context.Context, input *anymind.DepositInput) error {
+			if fail {
+				return anymind.InternalError(errors.New("db down"))
+			}
+
+			// held deposit is answered as credited but not stored.
+			if !held {
+				input.ID = 1
+			}
+
+			return nil
+		},
+		HistoricalFunc: func(_ context.Context, req *anymind.HistoricalDataReq) ([]*anymind.HistoricalData, error) {
+			require.Equal(t, req.Start, req.End)
+
+			return []*anymind.HistoricalData{{DateTime: req.Start, Amount: mustApd("10.5")}}, nil
+		},
+	},
+		WithDuration(kitprometheus.NewHistogram(duration)),
+		WithDepositCounter(kitprometheus.NewCounter(deposits)),
+		WithBalanceGauge(kitprometheus.NewGauge(balance)),
+	)
+	ctx := context.Background()
+
+	require.NoError(t, svc.RefreshBalance(ctx, []string{anymind.DefaultAsset}))
+	require.Equal(t, 10.5, testutil.ToFloat64(balance.WithLabelValues(anymind.DefaultAsset)))
+
+	require.NoError(t, svc.Deposit(ctx, &anymind.DepositInput{DateTime: time.Now(), Amount: mustApd("1.25")}))
+	require.NoError(t, svc.Deposit(ctx, &anymind.DepositInput{DateTime: time.Now(), Amount: mustApd("1.25"), Status: anymind.DepositPending}))
+
+	held = true
+	require.NoError(t, svc.Deposit(ctx, &anymind.DepositInput{DateTime: time.Now(), Amount: mustApd("1.25")}))
+
+	fail = true
+	require.Error(t, svc.Deposit(ctx, &anymind.DepositInput{DateTime: time.Now(), Amount: mustApd("1.25")}))
+
+	// only the confirmed stored deposit is settled, balance is left to RefreshBalance.
+	require.Equal(t, float64(1), testutil.ToFloat64(deposits))
+	require.Equal(t, 10.5, testutil.ToFloat64(balance.WithLabelValues(anymind.DefaultAsset)))
+	// failed and successful deposit are observed separately.
+	require.Equal(t, 2, testutil.CollectAndCount(duration))
+}
+
+func TestSettlementCounted(t *testing.T) {
+	deposits := stdprometheus.NewCounterVec(stdprometheus.CounterOpts{Name: "deposits"}, nil)
+	ctx := context.Background()
+
+	anomalies := NewAnomalyService(&mock.AnomalyServiceMock{
+		ReviewAnomalyFunc: func(_ context.Context, id int64, status string) (*anymind.Anomaly, error) {
+			if id == 3 {
+				return &anymind.Anomaly{ID: id, Status: status, DepositStatus: anymind.DepositPending}, nil
+			}
+
+			return &anymind.Anomaly{ID: id, Status: status}, nil
+		},
+	}, WithDepositCounter(kitprometheus.NewCounter(deposits)))
+	statuses := NewDepositStatusService(&mock.DepositStatusServiceMock{
+		SetDepositStatusFunc: func(_ context.Context, id int64, _ string) (*anymind.DepositEvent, error) {
+			return &anymind.DepositEvent{ID: id}, nil
+		},
+	}, WithDepositCounter(kitprometheus.NewCounter(deposits)))
+
+	_, err := anomalies.ReviewAnomaly(ctx, 1, anymind.AnomalyRejected)
+	require.NoError(t, err)
+	_, err = statuses.SetDepositStatus(ctx, 2, anymind.DepositFailed)
+	require.NoError(t, err)
+	require.Equal(t, 0, testutil.CollectAndCount(deposits))
+
+	_, err = anomalies.ReviewAnomaly(ctx, 1, anymind.AnomalyReleased)
+	require.NoError(t, err)
+	_, err = statuses.SetDepositStatus(ctx, 2, anymind.DepositConfirmed)
+	require.NoError(t, err)
+	require.Equal(t, float64(2), testutil.ToFloat64(deposits))
+
+	// released pending deposit is only counted once it is confirmed.
+	_, err = anomalies.ReviewAnomaly(ctx, 3, anymind.AnomalyReleased)
+	require.NoError(t, err)
+	require.Equal(t, float64(2), testutil.ToFloat64(deposits))
+}
+
+func TestPersistenceService(t *testing.T) {
+	duration := stdprometheus.NewHistogramVec(stdprometheus.HistogramOpts{Name: "duration"}, []string{"method", "error"})
+
+	svc := NewPersistenceService(&mock.PersistenceServiceMock{
+		HistoricalFunc: func(_ context.Context, _ *anymind.HistoricalDataReq) ([]*anymind.HistoricalData, error) {
+			return nil, errors.New("db down")
+		},
+	}, WithDuration(kitprometheus.NewHistogram(duration)))
+
+	_, err := svc.Historical(context.Background(), &anymind.HistoricalDataReq{})
+	require.Error(t, err)
+
+	m := &dto.Metric{}
+	require.NoError(t, duration.WithLabelValues("historical", "true").(stdprometheus.Metric).Write(m))
+	require.Equal(t, uint64(1), m.GetHistogram().GetSampleCount())
+}
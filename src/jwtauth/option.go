@@ -0,0 +1,57 @@
+package jwtauth
+
+import (
+	"go.uber.org/zap"
+	"net/http"
+	"time"
+)
+
+type Option func(*Service)
+
+func WithLogger(logger *zap.Logger) Option {
+	return func(svc *Service) {
+		svc.logger = logger
+	}
+}
+
+// WithAudience require token aud claim to contain aud.
+func WithAudience(aud string) Option {
+	return func(svc *Service) {
+		svc.audience = aud
+	}
+}
+
+// WithIssuer require token iss claim to be iss.
+func WithIssuer(iss string) Option {
+	return func(svc *Service) {
+		svc.issuer = iss
+	}
+}
+
+// WithLeeway tolerate clock skew with identity provider when checking exp and nbf.
+func WithLeeway(leeway time.Duration) Option {
+	return func(svc *Service) {
+		svc.leeway = leeway
+	}
+}
+
+// WithScopeMapping map scope granted by identity provider into api scopes.
+// By default only scopes named the same as api scopes are accepted.
+func WithScopeMapping(mapping map[string][]string) Option {
+	return func(svc *Service) {
+		svc.scopeMapping = mapping
+	}
+}
+
+// WithRefreshInterval set how long keys are cached before jwks is loaded again.
+func WithRefreshInterval(interval time.Duration) Option {
+	return func(svc *Service) {
+		svc.keys.refreshInterval = interval
+	}
+}
+
+func WithHTTPClient(client *http.Client) Option {
+	return func(svc *Service) {
+		svc.keys.client = client
+	}
+}
@@ -0,0 +1,146 @@
+package jwtauth
+
+import (
+	"anymind"
+	"context"
+	"errors"
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var _ anymind.Authenticator = &Service{}
+
+var signingMethods = []string{
+	"RS256", "RS384", "RS512",
+	"PS256", "PS384", "PS512",
+	"ES256", "ES384", "ES512",
+	"EdDSA",
+}
+
+var apiScopes = []string{
+	anymind.ScopeDepositWrite,
+	anymind.ScopeHistoryRead,
+	anymind.ScopeAdmin,
+}
+
+// Service authenticate request using jwt issued by identity provider,
+// tokens are verified against public keys published as jwks.
+type Service struct {
+	keys         *keySet
+	audience     string
+	issuer       string
+	leeway       time.Duration
+	scopeMapping map[string][]string
+	logger       *zap.Logger
+	now          func() time.Time
+}
+
+// NewService create authenticator using jwks from source, which is either file path or http(s) url.
+// Keys are loaded on first use.
+func NewService(source string, opts ...Option) *Service {
+	s := &Service{
+		keys: &keySet{
+			source:          source,
+			client:          http.DefaultClient,
+			refreshInterval: time.Hour,
+		},
+		now: time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if s.logger == nil {
+		s.logger = zap.NewNop()
+	}
+
+	if s.scopeMapping == nil {
+		s.scopeMapping = make(map[string][]string, len(apiScopes))
+		for _, scope := range apiScopes {
+			s.scopeMapping[scope] = []string{scope}
+		}
+	}
+
+	s.keys.now = s.now
+
+	return s
+}
+
+func (s *Service) Authenticate(ctx context.Context, credential string) (*anymind.Principal, error) {
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(signingMethods),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(s.leeway),
+		jwt.WithTimeFunc(s.now),
+	}
+	if s.audience != "" {
+		opts = append(opts, jwt.WithAudience(s.audience))
+	}
+	if s.issuer != "" {
+		opts = append(opts, jwt.WithIssuer(s.issuer))
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(credential, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+
+		return s.keys.key(ctx, kid)
+	}, opts...)
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenUnverifiable) && !errors.Is(err, errUnknownKey) {
+			// jwks could not be loaded, it is not caller fault.
+			s.logger.Error("failed to load jwks", zap.Error(err))
+
+			return nil, anymind.InternalError(err)
+		}
+
+		return nil, anymind.UnauthorizedError(err)
+	}
+
+	sub, err := claims.GetSubject()
+	if err != nil || sub == "" {
+		return nil, anymind.UnauthorizedError(errors.New("token has no subject"))
+	}
+
+	return &anymind.Principal{
+		Subject: sub,
+		Scopes:  s.scopes(claims),
+	}, nil
+}
+
+// scopes map "scope" (space separated) or "scp" (list) claim into api scopes.
+func (s *Service) scopes(claims jwt.MapClaims) []string {
+	var granted []string
+
+	switch scp := claims["scp"].(type) {
+	case string:
+		granted = strings.Fields(scp)
+	case []interface{}:
+		for _, v := range scp {
+			if str, ok := v.(string); ok {
+				granted = append(granted, str)
+			}
+		}
+	}
+
+	if scope, ok := claims["scope"].(string); ok {
+		granted = append(granted, strings.Fields(scope)...)
+	}
+
+	seen := map[string]bool{}
+	scopes := []string{}
+	for _, g := range granted {
+		for _, scope := range s.scopeMapping[g] {
+			if !seen[scope] {
+				seen[scope] = true
+				scopes = append(scopes, scope)
+			}
+		}
+	}
+
+	return scopes
+}
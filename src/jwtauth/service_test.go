@@ -0,0 +1,254 @@
+package jwtauth
+
+import (
+	"anymind"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var now = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func rsaJWK(kid string, key *rsa.PrivateKey) map[string]string {
+	return map[string]string{
+		"kty": "RSA",
+		"kid": kid,
+		"use": "sig",
+		"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func ecJWK(kid string, key *ecdsa.PrivateKey) map[string]string {
+	return map[string]string{
+		"kty": "EC",
+		"kid": kid,
+		"crv": "P-256",
+		"x":   base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, 32))),
+		"y":   base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, 32))),
+	}
+}
+
+func jwks(t *testing.T, keys ...map[string]string) []byte {
+	data, err := json.Marshal(map[string]interface{}{"keys": keys})
+	require.NoError(t, err)
+
+	return data
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, kid string, key interface{}, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = kid
+
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+
+	return signed
+}
+
+func validClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub":   "alice",
+		"iss":   "https://idp.example.com",
+		"aud":   "anymind",
+		"exp":   now.Add(time.Hour).Unix(),
+		"nbf":   now.Add(-time.Minute).Unix(),
+		"scope": "history:read openid",
+	}
+}
+
+func requireErrType(t *testing.T, errType int, err error) {
+	anyErr := anymind.ParameterError(nil)
+	require.ErrorAs(t, err, &anyErr)
+	require.Equal(t, errType, anyErr.Type)
+}
+
+func TestAuthenticate(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, jwks(t, rsaJWK("rsa", rsaKey), ecJWK("ec", ecKey)), 0o600))
+
+	svc := NewService(path,
+		WithAudience("anymind"),
+		WithIssuer("https://idp.example.com"),
+		WithLeeway(time.Minute),
+	)
+	svc.now = func() time.Time { return now }
+	svc.keys.now = svc.now
+
+	withClaims := func(update func(jwt.MapClaims)) jwt.MapClaims {
+		claims := validClaims()
+		update(claims)
+
+		return claims
+	}
+
+	testCases := []struct {
+		name    string
+		token   string
+		errType int
+	}{
+		{name: "rsa", token: sign(t, jwt.SigningMethodRS256, "rsa", rsaKey, validClaims()), errType: -1},
+		{name: "ecdsa", token: sign(t, jwt.SigningMethodES256, "ec", ecKey, validClaims()), errType: -1},
+		{
+			name: "expired within leeway",
+			token: sign(t, jwt.SigningMethodRS256, "rsa", rsaKey, withClaims(func(c jwt.MapClaims) {
+				c["exp"] = now.Add(-30 * time.Second).Unix()
+			})),
+			errType: -1,
+		},
+		{
+			name: "expired",
+			token: sign(t, jwt.SigningMethodRS256, "rsa", rsaKey, withClaims(func(c jwt.MapClaims) {
+				c["exp"] = now.Add(-time.Hour).Unix()
+			})),
+			errType: anymind.UnauthorizedErr,
+		},
+		{
+			name: "missing exp",
+			token: sign(t, jwt.SigningMethodRS256, "rsa", rsaKey, withClaims(func(c jwt.MapClaims) {
+				delete(c, "exp")
+			})),
+			errType: anymind.UnauthorizedErr,
+		},
+		{
+			name: "not yet valid",
+			token: sign(t, jwt.SigningMethodRS256, "rsa", rsaKey, withClaims(func(c jwt.MapClaims) {
+				c["nbf"] = now.Add(time.Hour).Unix()
+			})),
+			errType: anymind.UnauthorizedErr,
+		},
+		{
+			name: "wrong audience",
+			token: sign(t, jwt.SigningMethodRS256, "rsa", rsaKey, withClaims(func(c jwt.MapClaims) {
+				c["aud"] = "other"
+			})),
+			errType: anymind.UnauthorizedErr,
+		},
+		{
+			name: "wrong issuer",
+			token: sign(t, jwt.SigningMethodRS256, "rsa", rsaKey, withClaims(func(c jwt.MapClaims) {
+				c["iss"] = "https://evil.example.com"
+			})),
+			errType: anymind.UnauthorizedErr,
+		},
+		{
+			name: "missing subject",
+			token: sign(t, jwt.SigningMethodRS256, "rsa", rsaKey, withClaims(func(c jwt.MapClaims) {
+				delete(c, "sub")
+			})),
+			errType: anymind.UnauthorizedErr,
+		},
+		{name: "unknown kid", token: sign(t, jwt.SigningMethodRS256, "other", rsaKey, validClaims()), errType: anymind.UnauthorizedErr},
+		{name: "key of other kid", token: sign(t, jwt.SigningMethodRS256, "ec", rsaKey, validClaims()), errType: anymind.UnauthorizedErr},
+		{name: "hmac", token: sign(t, jwt.SigningMethodHS256, "rsa", []byte("secret"), validClaims()), errType: anymind.UnauthorizedErr},
+		{name: "api key", token: "amk_abcdef", errType: anymind.UnauthorizedErr},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			principal, err := svc.Authenticate(context.Background(), tc.token)
+			if tc.errType >= 0 {
+				requireErrType(t, tc.errType, err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, "alice", principal.Subject)
+			require.Equal(t, []string{anymind.ScopeHistoryRead}, principal.Scopes)
+		})
+	}
+}
+
+func TestScopeMapping(t *testing.T) {
+	svc := NewService("", WithScopeMapping(map[string][]string{
+		"wallet.write": {anymind.ScopeDepositWrite},
+		"wallet.admin": {anymind.ScopeAdmin},
+	}))
+
+	require.Equal(t, []string{anymind.ScopeDepositWrite},
+		svc.scopes(jwt.MapClaims{"scp": []interface{}{"wallet.write", "history:read"}}))
+	require.Equal(t, []string{anymind.ScopeDepositWrite, anymind.ScopeAdmin},
+		svc.scopes(jwt.MapClaims{"scope": "wallet.write wallet.admin wallet.write"}))
+	require.Empty(t, svc.scopes(jwt.MapClaims{}))
+}
+
+func TestJWKSFromURL(t *testing.T) {
+	oldKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	newKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	var fetches int32
+	published := jwks(t, rsaJWK("old", oldKey))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&fetches, 1)
+		_, _ = w.Write(published)
+	}))
+	defer server.Close()
+
+	clock := now
+	svc := NewService(server.URL)
+	svc.now = func() time.Time { return clock }
+	svc.keys.now = svc.now
+	ctx := context.Background()
+
+	_, err = svc.Authenticate(ctx, sign(t, jwt.SigningMethodRS256, "old", oldKey, validClaims()))
+	require.NoError(t, err)
+	_, err = svc.Authenticate(ctx, sign(t, jwt.SigningMethodRS256, "old", oldKey, validClaims()))
+	require.NoError(t, err)
+	require.Equal(t, int32(1), atomic.LoadInt32(&fetches))
+
+	// identity provider rotate keys, unknown kid reload jwks but not more often than minRefreshInterval.
+	published = jwks(t, rsaJWK("old", oldKey), rsaJWK("new", newKey))
+	rotated := sign(t, jwt.SigningMethodRS256, "new", newKey, validClaims())
+
+	_, err = svc.Authenticate(ctx, rotated)
+	requireErrType(t, anymind.UnauthorizedErr, err)
+	require.Equal(t, int32(1), atomic.LoadInt32(&fetches))
+
+	clock = clock.Add(minRefreshInterval + time.Second)
+	_, err = svc.Authenticate(ctx, rotated)
+	require.NoError(t, err)
+	require.Equal(t, int32(2), atomic.LoadInt32(&fetches))
+
+	// cached keys are used when jwks is unavailable.
+	server.Close()
+	clock = clock.Add(2 * time.Hour)
+	claims := validClaims()
+	claims["exp"] = clock.Add(time.Hour).Unix()
+	_, err = svc.Authenticate(ctx, sign(t, jwt.SigningMethodRS256, "new", newKey, claims))
+	require.NoError(t, err)
+}
+
+func TestJWKSUnavailable(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	svc := NewService(filepath.Join(t.TempDir(), "missing.json"))
+	svc.now = func() time.Time { return now }
+
+	_, err = svc.Authenticate(context.Background(), sign(t, jwt.SigningMethodRS256, "rsa", key, validClaims()))
+	requireErrType(t, anymind.InternalErr, err)
+}
@@ -0,0 +1,132 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mock
+
+import (
+	"anymind"
+	"context"
+	"sync"
+)
+
+// Ensure, that AnomalyDetectorMock does implement anymind.AnomalyDetector.
+// If this is not the case, regenerate this file with moq.
+var _ anymind.AnomalyDetector = &AnomalyDetectorMock{}
+
+// AnomalyDetectorMock is a mock implementation of anymind.AnomalyDetector.
+//
+//	func TestSomethingThatUsesAnomalyDetector(t *testing.T) {
+//
+//		// make and configure a mocked anymind.AnomalyDetector
+//		mockedAnomalyDetector := &AnomalyDetectorMock{
+//			InspectFunc: func(ctx context.Context, input *anymind.DepositInput) (*anymind.Anomaly, error) {
+//				panic("mock out the Inspect method")
+//			},
+//			ObserveFunc: func(ctx context.Context, input *anymind.DepositInput) error {
+//				panic("mock out the Observe method")
+//			},
+//		}
+//
+//		// use mockedAnomalyDetector in code that requires anymind.AnomalyDetector
+//		// and then make assertions.
+//
+//	}
+type AnomalyDetectorMock struct {
+	// InspectFunc mocks the Inspect method.
+	InspectFunc func(ctx context.Context, input *anymind.DepositInput) (*anymind.Anomaly, error)
+
+	// ObserveFunc mocks the Observe method.
+	ObserveFunc func(ctx context.Context, input *anymind.DepositInput) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Inspect holds details about calls to the Inspect method.
+		Inspect []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Input is the input argument value.
+			Input *anymind.DepositInput
+		}
+		// Observe holds details about calls to the Observe method.
+		Observe []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Input is the input argument value.
+			Input *anymind.DepositInput
+		}
+	}
+	lockInspect sync.RWMutex
+	lockObserve sync.RWMutex
+}
+
+// Inspect calls InspectFunc.
+func (mock *AnomalyDetectorMock) Inspect(ctx context.Context, input *anymind.DepositInput) (*anymind.Anomaly, error) {
+	if mock.InspectFunc == nil {
+		panic("AnomalyDetectorMock.InspectFunc: method is nil but AnomalyDetector.Inspect was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Input *anymind.DepositInput
+	}{
+		Ctx:   ctx,
+		Input: input,
+	}
+	mock.lockInspect.Lock()
+	mock.calls.Inspect = append(mock.calls.Inspect, callInfo)
+	mock.lockInspect.Unlock()
+	return mock.InspectFunc(ctx, input)
+}
+
+// InspectCalls gets all the calls that were made to Inspect.
+// Check the length with:
+//
+//	len(mockedAnomalyDetector.InspectCalls())
+func (mock *AnomalyDetectorMock) InspectCalls() []struct {
+	Ctx   context.Context
+	Input *anymind.DepositInput
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Input *anymind.DepositInput
+	}
+	mock.lockInspect.RLock()
+	calls = mock.calls.Inspect
+	mock.lockInspect.RUnlock()
+	return calls
+}
+
+// Observe calls ObserveFunc.
+func (mock *AnomalyDetectorMock) Observe(ctx context.Context, input *anymind.DepositInput) error {
+	if mock.ObserveFunc == nil {
+		panic("AnomalyDetectorMock.ObserveFunc: method is nil but AnomalyDetector.Observe was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Input *anymind.DepositInput
+	}{
+		Ctx:   ctx,
+		Input: input,
+	}
+	mock.lockObserve.Lock()
+	mock.calls.Observe = append(mock.calls.Observe, callInfo)
+	mock.lockObserve.Unlock()
+	return mock.ObserveFunc(ctx, input)
+}
+
+// ObserveCalls gets all the calls that were made to Observe.
+// Check the length with:
+//
+//	len(mockedAnomalyDetector.ObserveCalls())
+func (mock *AnomalyDetectorMock) ObserveCalls() []struct {
+	Ctx   context.Context
+	Input *anymind.DepositInput
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Input *anymind.DepositInput
+	}
+	mock.lockObserve.RLock()
+	calls = mock.calls.Observe
+	mock.lockObserve.RUnlock()
+	return calls
+}
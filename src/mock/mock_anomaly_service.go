@@ -0,0 +1,194 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mock
+
+import (
+	"anymind"
+	"context"
+	"sync"
+)
+
+// Ensure, that AnomalyServiceMock does implement anymind.AnomalyService.
+// If this is not the case, regenerate this file with moq.
+var _ anymind.AnomalyService = &AnomalyServiceMock{}
+
+// AnomalyServiceMock is a mock implementation of anymind.AnomalyService.
+//
+//	func TestSomethingThatUsesAnomalyService(t *testing.T) {
+//
+//		// make and configure a mocked anymind.AnomalyService
+//		mockedAnomalyService := &AnomalyServiceMock{
+//			ListAnomaliesFunc: func(ctx context.Context, status string, limit int) ([]*anymind.Anomaly, error) {
+//				panic("mock out the ListAnomalies method")
+//			},
+//			RecordAnomalyFunc: func(ctx context.Context, anomaly *anymind.Anomaly) error {
+//				panic("mock out the RecordAnomaly method")
+//			},
+//			ReviewAnomalyFunc: func(ctx context.Context, id int64, status string) (*anymind.Anomaly, error) {
+//				panic("mock out the ReviewAnomaly method")
+//			},
+//		}
+//
+//		// use mockedAnomalyService in code that requires anymind.AnomalyService
+//		// and then make assertions.
+//
+//	}
+type AnomalyServiceMock struct {
+	// ListAnomaliesFunc mocks the ListAnomalies method.
+	ListAnomaliesFunc func(ctx context.Context, status string, limit int) ([]*anymind.Anomaly, error)
+
+	// RecordAnomalyFunc mocks the RecordAnomaly method.
+	RecordAnomalyFunc func(ctx context.Context, anomaly *anymind.Anomaly) error
+
+	// ReviewAnomalyFunc mocks the ReviewAnomaly method.
+	ReviewAnomalyFunc func(ctx context.Context, id int64, status string) (*anymind.Anomaly, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// ListAnomalies holds details about calls to the ListAnomalies method.
+		ListAnomalies []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Status is the status argument value.
+			Status string
+			// Limit is the limit argument value.
+			Limit int
+		}
+		// RecordAnomaly holds details about calls to the RecordAnomaly method.
+		RecordAnomaly []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Anomaly is the anomaly argument value.
+			Anomaly *anymind.Anomaly
+		}
+		// ReviewAnomaly holds details about calls to the ReviewAnomaly method.
+		ReviewAnomaly []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID int64
+			// Status is the status argument value.
+			Status string
+		}
+	}
+	lockListAnomalies sync.RWMutex
+	lockRecordAnomaly sync.RWMutex
+	lockReviewAnomaly sync.RWMutex
+}
+
+// ListAnomalies calls ListAnomaliesFunc.
+func (mock *AnomalyServiceMock) ListAnomalies(ctx context.Context, status string, limit int) ([]*anymind.Anomaly, error) {
+	if mock.ListAnomaliesFunc == nil {
+		panic("AnomalyServiceMock.ListAnomaliesFunc: method is nil but AnomalyService.ListAnomalies was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Status string
+		Limit  int
+	}{
+		Ctx:    ctx,
+		Status: status,
+		Limit:  limit,
+	}
+	mock.lockListAnomalies.Lock()
+	mock.calls.ListAnomalies = append(mock.calls.ListAnomalies, callInfo)
+	mock.lockListAnomalies.Unlock()
+	return mock.ListAnomaliesFunc(ctx, status, limit)
+}
+
+// ListAnomaliesCalls gets all the calls that were made to ListAnomalies.
+// Check the length with:
+//
+//	len(mockedAnomalyService.ListAnomaliesCalls())
+func (mock *AnomalyServiceMock) ListAnomaliesCalls() []struct {
+	Ctx    context.Context
+	Status string
+	Limit  int
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Status string
+		Limit  int
+	}
+	mock.lockListAnomalies.RLock()
+	calls = mock.calls.ListAnomalies
+	mock.lockListAnomalies.RUnlock()
+	return calls
+}
+
+// RecordAnomaly calls RecordAnomalyFunc.
+func (mock *AnomalyServiceMock) RecordAnomaly(ctx context.Context, anomaly *anymind.Anomaly) error {
+	if mock.RecordAnomalyFunc == nil {
+		panic("AnomalyServiceMock.RecordAnomalyFunc: method is nil but AnomalyService.RecordAnomaly was just called")
+	}
+	callInfo := struct {
+		Ctx     context.Context
+		Anomaly *anymind.Anomaly
+	}{
+		Ctx:     ctx,
+		Anomaly: anomaly,
+	}
+	mock.lockRecordAnomaly.Lock()
+	mock.calls.RecordAnomaly = append(mock.calls.RecordAnomaly, callInfo)
+	mock.lockRecordAnomaly.Unlock()
+	return mock.RecordAnomalyFunc(ctx, anomaly)
+}
+
+// RecordAnomalyCalls gets all the calls that were made to RecordAnomaly.
+// Check the length with:
+//
+//	len(mockedAnomalyService.RecordAnomalyCalls())
+func (mock *AnomalyServiceMock) RecordAnomalyCalls() []struct {
+	Ctx     context.Context
+	Anomaly *anymind.Anomaly
+} {
+	var calls []struct {
+		Ctx     context.Context
+		Anomaly *anymind.Anomaly
+	}
+	mock.lockRecordAnomaly.RLock()
+	calls = mock.calls.RecordAnomaly
+	mock.lockRecordAnomaly.RUnlock()
+	return calls
+}
+
+// ReviewAnomaly calls ReviewAnomalyFunc.
+func (mock *AnomalyServiceMock) ReviewAnomaly(ctx context.Context, id int64, status string) (*anymind.Anomaly, error) {
+	if mock.ReviewAnomalyFunc == nil {
+		panic("AnomalyServiceMock.ReviewAnomalyFunc: method is nil but AnomalyService.ReviewAnomaly was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		ID     int64
+		Status string
+	}{
+		Ctx:    ctx,
+		ID:     id,
+		Status: status,
+	}
+	mock.lockReviewAnomaly.Lock()
+	mock.calls.ReviewAnomaly = append(mock.calls.ReviewAnomaly, callInfo)
+	mock.lockReviewAnomaly.Unlock()
+	return mock.ReviewAnomalyFunc(ctx, id, status)
+}
+
+// ReviewAnomalyCalls gets all the calls that were made to ReviewAnomaly.
+// Check the length with:
+//
+//	len(mockedAnomalyService.ReviewAnomalyCalls())
+func (mock *AnomalyServiceMock) ReviewAnomalyCalls() []struct {
+	Ctx    context.Context
+	ID     int64
+	Status string
+} {
+	var calls []struct {
+		Ctx    context.Context
+		ID     int64
+		Status string
+	}
+	mock.lockReviewAnomaly.RLock()
+	calls = mock.calls.ReviewAnomaly
+	mock.lockReviewAnomaly.RUnlock()
+	return calls
+}
@@ -0,0 +1,233 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mock
+
+import (
+	"anymind"
+	"context"
+	"sync"
+	"time"
+)
+
+// Ensure, that APIKeyPersistenceServiceMock does implement anymind.APIKeyPersistenceService.
+// If this is not the case, regenerate this file with moq.
+var _ anymind.APIKeyPersistenceService = &APIKeyPersistenceServiceMock{}
+
+// APIKeyPersistenceServiceMock is a mock implementation of anymind.APIKeyPersistenceService.
+//
+//	func TestSomethingThatUsesAPIKeyPersistenceService(t *testing.T) {
+//
+//		// make and configure a mocked anymind.APIKeyPersistenceService
+//		mockedAPIKeyPersistenceService := &APIKeyPersistenceServiceMock{
+//			CreateAPIKeyFunc: func(ctx context.Context, key *anymind.APIKey) error {
+//				panic("mock out the CreateAPIKey method")
+//			},
+//			FindAPIKeyFunc: func(ctx context.Context, hash string) (*anymind.APIKey, error) {
+//				panic("mock out the FindAPIKey method")
+//			},
+//			ListAPIKeysFunc: func(ctx context.Context) ([]*anymind.APIKey, error) {
+//				panic("mock out the ListAPIKeys method")
+//			},
+//			RevokeAPIKeyFunc: func(ctx context.Context, id int64, at time.Time) error {
+//				panic("mock out the RevokeAPIKey method")
+//			},
+//		}
+//
+//		// use mockedAPIKeyPersistenceService in code that requires anymind.APIKeyPersistenceService
+//		// and then make assertions.
+//
+//	}
+type APIKeyPersistenceServiceMock struct {
+	// CreateAPIKeyFunc mocks the CreateAPIKey method.
+	CreateAPIKeyFunc func(ctx context.Context, key *anymind.APIKey) error
+
+	// FindAPIKeyFunc mocks the FindAPIKey method.
+	FindAPIKeyFunc func(ctx context.Context, hash string) (*anymind.APIKey, error)
+
+	// ListAPIKeysFunc mocks the ListAPIKeys method.
+	ListAPIKeysFunc func(ctx context.Context) ([]*anymind.APIKey, error)
+
+	// RevokeAPIKeyFunc mocks the RevokeAPIKey method.
+	RevokeAPIKeyFunc func(ctx context.Context, id int64, at time.Time) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// CreateAPIKey holds details about calls to the CreateAPIKey method.
+		CreateAPIKey []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Key is the key argument value.
+			Key *anymind.APIKey
+		}
+		// FindAPIKey holds details about calls to the FindAPIKey method.
+		FindAPIKey []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Hash is the hash argument value.
+			Hash string
+		}
+		// ListAPIKeys holds details about calls to the ListAPIKeys method.
+		ListAPIKeys []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+		}
+		// RevokeAPIKey holds details about calls to the RevokeAPIKey method.
+		RevokeAPIKey []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID int64
+			// At is the at argument value.
+			At time.Time
+		}
+	}
+	lockCreateAPIKey sync.RWMutex
+	lockFindAPIKey   sync.RWMutex
+	lockListAPIKeys  sync.RWMutex
+	lockRevokeAPIKey sync.RWMutex
+}
+
+// CreateAPIKey calls CreateAPIKeyFunc.
+func (mock *APIKeyPersistenceServiceMock) CreateAPIKey(ctx context.Context, key *anymind.APIKey) error {
+	if mock.CreateAPIKeyFunc == nil {
+		panic("APIKeyPersistenceServiceMock.CreateAPIKeyFunc: method is nil but APIKeyPersistenceService.CreateAPIKey was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		Key *anymind.APIKey
+	}{
+		Ctx: ctx,
+		Key: key,
+	}
+	mock.lockCreateAPIKey.Lock()
+	mock.calls.CreateAPIKey = append(mock.calls.CreateAPIKey, callInfo)
+	mock.lockCreateAPIKey.Unlock()
+	return mock.CreateAPIKeyFunc(ctx, key)
+}
+
+// CreateAPIKeyCalls gets all the calls that were made to CreateAPIKey.
+// Check the length with:
+//
+//	len(mockedAPIKeyPersistenceService.CreateAPIKeyCalls())
+func (mock *APIKeyPersistenceServiceMock) CreateAPIKeyCalls() []struct {
+	Ctx context.Context
+	Key *anymind.APIKey
+} {
+	var calls []struct {
+		Ctx context.Context
+		Key *anymind.APIKey
+	}
+	mock.lockCreateAPIKey.RLock()
+	calls = mock.calls.CreateAPIKey
+	mock.lockCreateAPIKey.RUnlock()
+	return calls
+}
+
+// FindAPIKey calls FindAPIKeyFunc.
+func (mock *APIKeyPersistenceServiceMock) FindAPIKey(ctx context.Context, hash string) (*anymind.APIKey, error) {
+	if mock.FindAPIKeyFunc == nil {
+		panic("APIKeyPersistenceServiceMock.FindAPIKeyFunc: method is nil but APIKeyPersistenceService.FindAPIKey was just called")
+	}
+	callInfo := struct {
+		Ctx  context.Context
+		Hash string
+	}{
+		Ctx:  ctx,
+		Hash: hash,
+	}
+	mock.lockFindAPIKey.Lock()
+	mock.calls.FindAPIKey = append(mock.calls.FindAPIKey, callInfo)
+	mock.lockFindAPIKey.Unlock()
+	return mock.FindAPIKeyFunc(ctx, hash)
+}
+
+// FindAPIKeyCalls gets all the calls that were made to FindAPIKey.
+// Check the length with:
+//
+//	len(mockedAPIKeyPersistenceService.FindAPIKeyCalls())
+func (mock *APIKeyPersistenceServiceMock) FindAPIKeyCalls() []struct {
+	Ctx  context.Context
+	Hash string
+} {
+	var calls []struct {
+		Ctx  context.Context
+		Hash string
+	}
+	mock.lockFindAPIKey.RLock()
+	calls = mock.calls.FindAPIKey
+	mock.lockFindAPIKey.RUnlock()
+	return calls
+}
+
+// ListAPIKeys calls ListAPIKeysFunc.
+func (mock *APIKeyPersistenceServiceMock) ListAPIKeys(ctx context.Context) ([]*anymind.APIKey, error) {
+	if mock.ListAPIKeysFunc == nil {
+		panic("APIKeyPersistenceServiceMock.ListAPIKeysFunc: method is nil but APIKeyPersistenceService.ListAPIKeys was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+	}{
+		Ctx: ctx,
+	}
+	mock.lockListAPIKeys.Lock()
+	mock.calls.ListAPIKeys = append(mock.calls.ListAPIKeys, callInfo)
+	mock.lockListAPIKeys.Unlock()
+	return mock.ListAPIKeysFunc(ctx)
+}
+
+// ListAPIKeysCalls gets all the calls that were made to ListAPIKeys.
+// Check the length with:
+//
+//	len(mockedAPIKeyPersistenceService.ListAPIKeysCalls())
+func (mock *APIKeyPersistenceServiceMock) ListAPIKeysCalls() []struct {
+	Ctx context.Context
+} {
+	var calls []struct {
+		Ctx context.Context
+	}
+	mock.lockListAPIKeys.RLock()
+	calls = mock.calls.ListAPIKeys
+	mock.lockListAPIKeys.RUnlock()
+	return calls
+}
+
+// RevokeAPIKey calls RevokeAPIKeyFunc.
+func (mock *APIKeyPersistenceServiceMock) RevokeAPIKey(ctx context.Context, id int64, at time.Time) error {
+	if mock.RevokeAPIKeyFunc == nil {
+		panic("APIKeyPersistenceServiceMock.RevokeAPIKeyFunc: method is nil but APIKeyPersistenceService.RevokeAPIKey was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		ID  int64
+		At  time.Time
+	}{
+		Ctx: ctx,
+		ID:  id,
+		At:  at,
+	}
+	mock.lockRevokeAPIKey.Lock()
+	mock.calls.RevokeAPIKey = append(mock.calls.RevokeAPIKey, callInfo)
+	mock.lockRevokeAPIKey.Unlock()
+	return mock.RevokeAPIKeyFunc(ctx, id, at)
+}
+
+// RevokeAPIKeyCalls gets all the calls that were made to RevokeAPIKey.
+// Check the length with:
+//
+//	len(mockedAPIKeyPersistenceService.RevokeAPIKeyCalls())
+func (mock *APIKeyPersistenceServiceMock) RevokeAPIKeyCalls() []struct {
+	Ctx context.Context
+	ID  int64
+	At  time.Time
+} {
+	var calls []struct {
+		Ctx context.Context
+		ID  int64
+		At  time.Time
+	}
+	mock.lockRevokeAPIKey.RLock()
+	calls = mock.calls.RevokeAPIKey
+	mock.lockRevokeAPIKey.RUnlock()
+	return calls
+}
@@ -0,0 +1,176 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mock
+
+import (
+	"anymind"
+	"context"
+	"sync"
+)
+
+// Ensure, that AssetServiceMock does implement anymind.AssetService.
+// If this is not the case, regenerate this file with moq.
+var _ anymind.AssetService = &AssetServiceMock{}
+
+// AssetServiceMock is a mock implementation of anymind.AssetService.
+//
+//	func TestSomethingThatUsesAssetService(t *testing.T) {
+//
+//		// make and configure a mocked anymind.AssetService
+//		mockedAssetService := &AssetServiceMock{
+//			AssetFunc: func(ctx context.Context, code string) (*anymind.Asset, error) {
+//				panic("mock out the Asset method")
+//			},
+//			CreateAssetFunc: func(ctx context.Context, asset *anymind.Asset) error {
+//				panic("mock out the CreateAsset method")
+//			},
+//			ListAssetsFunc: func(ctx context.Context) ([]*anymind.Asset, error) {
+//				panic("mock out the ListAssets method")
+//			},
+//		}
+//
+//		// use mockedAssetService in code that requires anymind.AssetService
+//		// and then make assertions.
+//
+//	}
+type AssetServiceMock struct {
+	// AssetFunc mocks the Asset method.
+	AssetFunc func(ctx context.Context, code string) (*anymind.Asset, error)
+
+	// CreateAssetFunc mocks the CreateAsset method.
+	CreateAssetFunc func(ctx context.Context, asset *anymind.Asset) error
+
+	// ListAssetsFunc mocks the ListAssets method.
+	ListAssetsFunc func(ctx context.Context) ([]*anymind.Asset, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Asset holds details about calls to the Asset method.
+		Asset []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Code is the code argument value.
+			Code string
+		}
+		// CreateAsset holds details about calls to the CreateAsset method.
+		CreateAsset []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Asset is the asset argument value.
+			Asset *anymind.Asset
+		}
+		// ListAssets holds details about calls to the ListAssets method.
+		ListAssets []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+		}
+	}
+	lockAsset       sync.RWMutex
+	lockCreateAsset sync.RWMutex
+	lockListAssets  sync.RWMutex
+}
+
+// Asset calls AssetFunc.
+func (mock *AssetServiceMock) Asset(ctx context.Context, code string) (*anymind.Asset, error) {
+	if mock.AssetFunc == nil {
+		panic("AssetServiceMock.AssetFunc: method is nil but AssetService.Asset was just called")
+	}
+	callInfo := struct {
+		Ctx  context.Context
+		Code string
+	}{
+		Ctx:  ctx,
+		Code: code,
+	}
+	mock.lockAsset.Lock()
+	mock.calls.Asset = append(mock.calls.Asset, callInfo)
+	mock.lockAsset.Unlock()
+	return mock.AssetFunc(ctx, code)
+}
+
+// AssetCalls gets all the calls that were made to Asset.
+// Check the length with:
+//
+//	len(mockedAssetService.AssetCalls())
+func (mock *AssetServiceMock) AssetCalls() []struct {
+	Ctx  context.Context
+	Code string
+} {
+	var calls []struct {
+		Ctx  context.Context
+		Code string
+	}
+	mock.lockAsset.RLock()
+	calls = mock.calls.Asset
+	mock.lockAsset.RUnlock()
+	return calls
+}
+
+// CreateAsset calls CreateAssetFunc.
+func (mock *AssetServiceMock) CreateAsset(ctx context.Context, asset *anymind.Asset) error {
+	if mock.CreateAssetFunc == nil {
+		panic("AssetServiceMock.CreateAssetFunc: method is nil but AssetService.CreateAsset was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Asset *anymind.Asset
+	}{
+		Ctx:   ctx,
+		Asset: asset,
+	}
+	mock.lockCreateAsset.Lock()
+	mock.calls.CreateAsset = append(mock.calls.CreateAsset, callInfo)
+	mock.lockCreateAsset.Unlock()
+	return mock.CreateAssetFunc(ctx, asset)
+}
+
+// CreateAssetCalls gets all the calls that were made to CreateAsset.
+// Check the length with:
+//
+//	len(mockedAssetService.CreateAssetCalls())
+func (mock *AssetServiceMock) CreateAssetCalls() []struct {
+	Ctx   context.Context
+	Asset *anymind.Asset
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Asset *anymind.Asset
+	}
+	mock.lockCreateAsset.RLock()
+	calls = mock.calls.CreateAsset
+	mock.lockCreateAsset.RUnlock()
+	return calls
+}
+
+// ListAssets calls ListAssetsFunc.
+func (mock *AssetServiceMock) ListAssets(ctx context.Context) ([]*anymind.Asset, error) {
+	if mock.ListAssetsFunc == nil {
+		panic("AssetServiceMock.ListAssetsFunc: method is nil but AssetService.ListAssets was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+	}{
+		Ctx: ctx,
+	}
+	mock.lockListAssets.Lock()
+	mock.calls.ListAssets = append(mock.calls.ListAssets, callInfo)
+	mock.lockListAssets.Unlock()
+	return mock.ListAssetsFunc(ctx)
+}
+
+// ListAssetsCalls gets all the calls that were made to ListAssets.
+// Check the length with:
+//
+//	len(mockedAssetService.ListAssetsCalls())
+func (mock *AssetServiceMock) ListAssetsCalls() []struct {
+	Ctx context.Context
+} {
+	var calls []struct {
+		Ctx context.Context
+	}
+	mock.lockListAssets.RLock()
+	calls = mock.calls.ListAssets
+	mock.lockListAssets.RUnlock()
+	return calls
+}
@@ -0,0 +1,144 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mock
+
+import (
+	"anymind"
+	"context"
+	"sync"
+)
+
+// Ensure, that AuditServiceMock does implement anymind.AuditService.
+// If this is not the case, regenerate this file with moq.
+var _ anymind.AuditService = &AuditServiceMock{}
+
+// AuditServiceMock is a mock implementation of anymind.AuditService.
+//
+//	func TestSomethingThatUsesAuditService(t *testing.T) {
+//
+//		// make and configure a mocked anymind.AuditService
+//		mockedAuditService := &AuditServiceMock{
+//			ListAuditFunc: func(ctx context.Context, filter *anymind.AuditFilter) ([]*anymind.AuditEntry, error) {
+//				panic("mock out the ListAudit method")
+//			},
+//			RecordAuditFunc: func(ctx context.Context, action string, payload any, outcome string) error {
+//				panic("mock out the RecordAudit method")
+//			},
+//		}
+//
+//		// use mockedAuditService in code that requires anymind.AuditService
+//		// and then make assertions.
+//
+//	}
+type AuditServiceMock struct {
+	// ListAuditFunc mocks the ListAudit method.
+	ListAuditFunc func(ctx context.Context, filter *anymind.AuditFilter) ([]*anymind.AuditEntry, error)
+
+	// RecordAuditFunc mocks the RecordAudit method.
+	RecordAuditFunc func(ctx context.Context, action string, payload any, outcome string) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// ListAudit holds details about calls to the ListAudit method.
+		ListAudit []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Filter is the filter argument value.
+			Filter *anymind.AuditFilter
+		}
+		// RecordAudit holds details about calls to the RecordAudit method.
+		RecordAudit []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Action is the action argument value.
+			Action string
+			// Payload is the payload argument value.
+			Payload any
+			// Outcome is the outcome argument value.
+			Outcome string
+		}
+	}
+	lockListAudit   sync.RWMutex
+	lockRecordAudit sync.RWMutex
+}
+
+// ListAudit calls ListAuditFunc.
+func (mock *AuditServiceMock) ListAudit(ctx context.Context, filter *anymind.AuditFilter) ([]*anymind.AuditEntry, error) {
+	if mock.ListAuditFunc == nil {
+		panic("AuditServiceMock.ListAuditFunc: method is nil but AuditService.ListAudit was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Filter *anymind.AuditFilter
+	}{
+		Ctx:    ctx,
+		Filter: filter,
+	}
+	mock.lockListAudit.Lock()
+	mock.calls.ListAudit = append(mock.calls.ListAudit, callInfo)
+	mock.lockListAudit.Unlock()
+	return mock.ListAuditFunc(ctx, filter)
+}
+
+// ListAuditCalls gets all the calls that were made to ListAudit.
+// Check the length with:
+//
+//	len(mockedAuditService.ListAuditCalls())
+func (mock *AuditServiceMock) ListAuditCalls() []struct {
+	Ctx    context.Context
+	Filter *anymind.AuditFilter
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Filter *anymind.AuditFilter
+	}
+	mock.lockListAudit.RLock()
+	calls = mock.calls.ListAudit
+	mock.lockListAudit.RUnlock()
+	return calls
+}
+
+// RecordAudit calls RecordAuditFunc.
+func (mock *AuditServiceMock) RecordAudit(ctx context.Context, action string, payload any, outcome string) error {
+	if mock.RecordAuditFunc == nil {
+		panic("AuditServiceMock.RecordAuditFunc: method is nil but AuditService.RecordAudit was just called")
+	}
+	callInfo := struct {
+		Ctx     context.Context
+		Action  string
+		Payload any
+		Outcome string
+	}{
+		Ctx:     ctx,
+		Action:  action,
+		Payload: payload,
+		Outcome: outcome,
+	}
+	mock.lockRecordAudit.Lock()
+	mock.calls.RecordAudit = append(mock.calls.RecordAudit, callInfo)
+	mock.lockRecordAudit.Unlock()
+	return mock.RecordAuditFunc(ctx, action, payload, outcome)
+}
+
+// RecordAuditCalls gets all the calls that were made to RecordAudit.
+// Check the length with:
+//
+//	len(mockedAuditService.RecordAuditCalls())
+func (mock *AuditServiceMock) RecordAuditCalls() []struct {
+	Ctx     context.Context
+	Action  string
+	Payload any
+	Outcome string
+} {
+	var calls []struct {
+		Ctx     context.Context
+		Action  string
+		Payload any
+		Outcome string
+	}
+	mock.lockRecordAudit.RLock()
+	calls = mock.calls.RecordAudit
+	mock.lockRecordAudit.RUnlock()
+	return calls
+}
@@ -0,0 +1,82 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mock
+
+import (
+	"anymind"
+	"context"
+	"sync"
+)
+
+// Ensure, that AuthenticatorMock does implement anymind.Authenticator.
+// If this is not the case, regenerate this file with moq.
+var _ anymind.Authenticator = &AuthenticatorMock{}
+
+// AuthenticatorMock is a mock implementation of anymind.Authenticator.
+//
+//	func TestSomethingThatUsesAuthenticator(t *testing.T) {
+//
+//		// make and configure a mocked anymind.Authenticator
+//		mockedAuthenticator := &AuthenticatorMock{
+//			AuthenticateFunc: func(ctx context.Context, credential string) (*anymind.Principal, error) {
+//				panic("mock out the Authenticate method")
+//			},
+//		}
+//
+//		// use mockedAuthenticator in code that requires anymind.Authenticator
+//		// and then make assertions.
+//
+//	}
+type AuthenticatorMock struct {
+	// AuthenticateFunc mocks the Authenticate method.
+	AuthenticateFunc func(ctx context.Context, credential string) (*anymind.Principal, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Authenticate holds details about calls to the Authenticate method.
+		Authenticate []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Credential is the credential argument value.
+			Credential string
+		}
+	}
+	lockAuthenticate sync.RWMutex
+}
+
+// Authenticate calls AuthenticateFunc.
+func (mock *AuthenticatorMock) Authenticate(ctx context.Context, credential string) (*anymind.Principal, error) {
+	if mock.AuthenticateFunc == nil {
+		panic("AuthenticatorMock.AuthenticateFunc: method is nil but Authenticator.Authenticate was just called")
+	}
+	callInfo := struct {
+		Ctx        context.Context
+		Credential string
+	}{
+		Ctx:        ctx,
+		Credential: credential,
+	}
+	mock.lockAuthenticate.Lock()
+	mock.calls.Authenticate = append(mock.calls.Authenticate, callInfo)
+	mock.lockAuthenticate.Unlock()
+	return mock.AuthenticateFunc(ctx, credential)
+}
+
+// AuthenticateCalls gets all the calls that were made to Authenticate.
+// Check the length with:
+//
+//	len(mockedAuthenticator.AuthenticateCalls())
+func (mock *AuthenticatorMock) AuthenticateCalls() []struct {
+	Ctx        context.Context
+	Credential string
+} {
+	var calls []struct {
+		Ctx        context.Context
+		Credential string
+	}
+	mock.lockAuthenticate.RLock()
+	calls = mock.calls.Authenticate
+	mock.lockAuthenticate.RUnlock()
+	return calls
+}
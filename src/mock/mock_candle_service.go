@@ -0,0 +1,82 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mock
+
+import (
+	"anymind"
+	"context"
+	"sync"
+)
+
+// Ensure, that CandleServiceMock does implement anymind.CandleService.
+// If this is not the case, regenerate this file with moq.
+var _ anymind.CandleService = &CandleServiceMock{}
+
+// CandleServiceMock is a mock implementation of anymind.CandleService.
+//
+//	func TestSomethingThatUsesCandleService(t *testing.T) {
+//
+//		// make and configure a mocked anymind.CandleService
+//		mockedCandleService := &CandleServiceMock{
+//			CandlesFunc: func(ctx context.Context, req *anymind.CandleReq) ([]*anymind.Candle, error) {
+//				panic("mock out the Candles method")
+//			},
+//		}
+//
+//		// use mockedCandleService in code that requires anymind.CandleService
+//		// and then make assertions.
+//
+//	}
+type CandleServiceMock struct {
+	// CandlesFunc mocks the Candles method.
+	CandlesFunc func(ctx context.Context, req *anymind.CandleReq) ([]*anymind.Candle, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Candles holds details about calls to the Candles method.
+		Candles []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Req is the req argument value.
+			Req *anymind.CandleReq
+		}
+	}
+	lockCandles sync.RWMutex
+}
+
+// Candles calls CandlesFunc.
+func (mock *CandleServiceMock) Candles(ctx context.Context, req *anymind.CandleReq) ([]*anymind.Candle, error) {
+	if mock.CandlesFunc == nil {
+		panic("CandleServiceMock.CandlesFunc: method is nil but CandleService.Candles was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		Req *anymind.CandleReq
+	}{
+		Ctx: ctx,
+		Req: req,
+	}
+	mock.lockCandles.Lock()
+	mock.calls.Candles = append(mock.calls.Candles, callInfo)
+	mock.lockCandles.Unlock()
+	return mock.CandlesFunc(ctx, req)
+}
+
+// CandlesCalls gets all the calls that were made to Candles.
+// Check the length with:
+//
+//	len(mockedCandleService.CandlesCalls())
+func (mock *CandleServiceMock) CandlesCalls() []struct {
+	Ctx context.Context
+	Req *anymind.CandleReq
+} {
+	var calls []struct {
+		Ctx context.Context
+		Req *anymind.CandleReq
+	}
+	mock.lockCandles.RLock()
+	calls = mock.calls.Candles
+	mock.lockCandles.RUnlock()
+	return calls
+}
@@ -0,0 +1,220 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mock
+
+import (
+	"anymind"
+	"context"
+	"sync"
+)
+
+// Ensure, that ChainPersistenceServiceMock does implement anymind.ChainPersistenceService.
+// If this is not the case, regenerate this file with moq.
+var _ anymind.ChainPersistenceService = &ChainPersistenceServiceMock{}
+
+// ChainPersistenceServiceMock is a mock implementation of anymind.ChainPersistenceService.
+//
+//	func TestSomethingThatUsesChainPersistenceService(t *testing.T) {
+//
+//		// make and configure a mocked anymind.ChainPersistenceService
+//		mockedChainPersistenceService := &ChainPersistenceServiceMock{
+//			DepositChainHeadFunc: func(ctx context.Context) (int64, string, error) {
+//				panic("mock out the DepositChainHead method")
+//			},
+//			ListChainCheckpointsFunc: func(ctx context.Context) ([]*anymind.ChainCheckpoint, error) {
+//				panic("mock out the ListChainCheckpoints method")
+//			},
+//			SaveChainCheckpointFunc: func(ctx context.Context, checkpoint *anymind.ChainCheckpoint) error {
+//				panic("mock out the SaveChainCheckpoint method")
+//			},
+//			VerifyDepositChainFunc: func(ctx context.Context, checkpoints []*anymind.ChainCheckpoint) (*anymind.ChainReport, error) {
+//				panic("mock out the VerifyDepositChain method")
+//			},
+//		}
+//
+//		// use mockedChainPersistenceService in code that requires anymind.ChainPersistenceService
+//		// and then make assertions.
+//
+//	}
+type ChainPersistenceServiceMock struct {
+	// DepositChainHeadFunc mocks the DepositChainHead method.
+	DepositChainHeadFunc func(ctx context.Context) (int64, string, error)
+
+	// ListChainCheckpointsFunc mocks the ListChainCheckpoints method.
+	ListChainCheckpointsFunc func(ctx context.Context) ([]*anymind.ChainCheckpoint, error)
+
+	// SaveChainCheckpointFunc mocks the SaveChainCheckpoint method.
+	SaveChainCheckpointFunc func(ctx context.Context, checkpoint *anymind.ChainCheckpoint) error
+
+	// VerifyDepositChainFunc mocks the VerifyDepositChain method.
+	VerifyDepositChainFunc func(ctx context.Context, checkpoints []*anymind.ChainCheckpoint) (*anymind.ChainReport, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// DepositChainHead holds details about calls to the DepositChainHead method.
+		DepositChainHead []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+		}
+		// ListChainCheckpoints holds details about calls to the ListChainCheckpoints method.
+		ListChainCheckpoints []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+		}
+		// SaveChainCheckpoint holds details about calls to the SaveChainCheckpoint method.
+		SaveChainCheckpoint []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Checkpoint is the checkpoint argument value.
+			Checkpoint *anymind.ChainCheckpoint
+		}
+		// VerifyDepositChain holds details about calls to the VerifyDepositChain method.
+		VerifyDepositChain []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Checkpoints is the checkpoints argument value.
+			Checkpoints []*anymind.ChainCheckpoint
+		}
+	}
+	lockDepositChainHead     sync.RWMutex
+	lockListChainCheckpoints sync.RWMutex
+	lockSaveChainCheckpoint  sync.RWMutex
+	lockVerifyDepositChain   sync.RWMutex
+}
+
+// DepositChainHead calls DepositChainHeadFunc.
+func (mock *ChainPersistenceServiceMock) DepositChainHead(ctx context.Context) (int64, string, error) {
+	if mock.DepositChainHeadFunc == nil {
+		panic("ChainPersistenceServiceMock.DepositChainHeadFunc: method is nil but ChainPersistenceService.DepositChainHead was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+	}{
+		Ctx: ctx,
+	}
+	mock.lockDepositChainHead.Lock()
+	mock.calls.DepositChainHead = append(mock.calls.DepositChainHead, callInfo)
+	mock.lockDepositChainHead.Unlock()
+	return mock.DepositChainHeadFunc(ctx)
+}
+
+// DepositChainHeadCalls gets all the calls that were made to DepositChainHead.
+// Check the length with:
+//
+//	len(mockedChainPersistenceService.DepositChainHeadCalls())
+func (mock *ChainPersistenceServiceMock) DepositChainHeadCalls() []struct {
+	Ctx context.Context
+} {
+	var calls []struct {
+		Ctx context.Context
+	}
+	mock.lockDepositChainHead.RLock()
+	calls = mock.calls.DepositChainHead
+	mock.lockDepositChainHead.RUnlock()
+	return calls
+}
+
+// ListChainCheckpoints calls ListChainCheckpointsFunc.
+func (mock *ChainPersistenceServiceMock) ListChainCheckpoints(ctx context.Context) ([]*anymind.ChainCheckpoint, error) {
+	if mock.ListChainCheckpointsFunc == nil {
+		panic("ChainPersistenceServiceMock.ListChainCheckpointsFunc: method is nil but ChainPersistenceService.ListChainCheckpoints was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+	}{
+		Ctx: ctx,
+	}
+	mock.lockListChainCheckpoints.Lock()
+	mock.calls.ListChainCheckpoints = append(mock.calls.ListChainCheckpoints, callInfo)
+	mock.lockListChainCheckpoints.Unlock()
+	return mock.ListChainCheckpointsFunc(ctx)
+}
+
+// ListChainCheckpointsCalls gets all the calls that were made to ListChainCheckpoints.
+// Check the length with:
+//
+//	len(mockedChainPersistenceService.ListChainCheckpointsCalls())
+func (mock *ChainPersistenceServiceMock) ListChainCheckpointsCalls() []struct {
+	Ctx context.Context
+} {
+	var calls []struct {
+		Ctx context.Context
+	}
+	mock.lockListChainCheckpoints.RLock()
+	calls = mock.calls.ListChainCheckpoints
+	mock.lockListChainCheckpoints.RUnlock()
+	return calls
+}
+
+// SaveChainCheckpoint calls SaveChainCheckpointFunc.
+func (mock *ChainPersistenceServiceMock) SaveChainCheckpoint(ctx context.Context, checkpoint *anymind.ChainCheckpoint) error {
+	if mock.SaveChainCheckpointFunc == nil {
+		panic("ChainPersistenceServiceMock.SaveChainCheckpointFunc: method is nil but ChainPersistenceService.SaveChainCheckpoint was just called")
+	}
+	callInfo := struct {
+		Ctx        context.Context
+		Checkpoint *anymind.ChainCheckpoint
+	}{
+		Ctx:        ctx,
+		Checkpoint: checkpoint,
+	}
+	mock.lockSaveChainCheckpoint.Lock()
+	mock.calls.SaveChainCheckpoint = append(mock.calls.SaveChainCheckpoint, callInfo)
+	mock.lockSaveChainCheckpoint.Unlock()
+	return mock.SaveChainCheckpointFunc(ctx, checkpoint)
+}
+
+// SaveChainCheckpointCalls gets all the calls that were made to SaveChainCheckpoint.
+// Check the length with:
+//
+//	len(mockedChainPersistenceService.SaveChainCheckpointCalls())
+func (mock *ChainPersistenceServiceMock) SaveChainCheckpointCalls() []struct {
+	Ctx        context.Context
+	Checkpoint *anymind.ChainCheckpoint
+} {
+	var calls []struct {
+		Ctx        context.Context
+		Checkpoint *anymind.ChainCheckpoint
+	}
+	mock.lockSaveChainCheckpoint.RLock()
+	calls = mock.calls.SaveChainCheckpoint
+	mock.lockSaveChainCheckpoint.RUnlock()
+	return calls
+}
+
+// VerifyDepositChain calls VerifyDepositChainFunc.
+func (mock *ChainPersistenceServiceMock) VerifyDepositChain(ctx context.Context, checkpoints []*anymind.ChainCheckpoint) (*anymind.ChainReport, error) {
+	if mock.VerifyDepositChainFunc == nil {
+		panic("ChainPersistenceServiceMock.VerifyDepositChainFunc: method is nil but ChainPersistenceService.VerifyDepositChain was just called")
+	}
+	callInfo := struct {
+		Ctx         context.Context
+		Checkpoints []*anymind.ChainCheckpoint
+	}{
+		Ctx:         ctx,
+		Checkpoints: checkpoints,
+	}
+	mock.lockVerifyDepositChain.Lock()
+	mock.calls.VerifyDepositChain = append(mock.calls.VerifyDepositChain, callInfo)
+	mock.lockVerifyDepositChain.Unlock()
+	return mock.VerifyDepositChainFunc(ctx, checkpoints)
+}
+
+// VerifyDepositChainCalls gets all the calls that were made to VerifyDepositChain.
+// Check the length with:
+//
+//	len(mockedChainPersistenceService.VerifyDepositChainCalls())
+func (mock *ChainPersistenceServiceMock) VerifyDepositChainCalls() []struct {
+	Ctx         context.Context
+	Checkpoints []*anymind.ChainCheckpoint
+} {
+	var calls []struct {
+		Ctx         context.Context
+		Checkpoints []*anymind.ChainCheckpoint
+	}
+	mock.lockVerifyDepositChain.RLock()
+	calls = mock.calls.VerifyDepositChain
+	mock.lockVerifyDepositChain.RUnlock()
+	return calls
+}
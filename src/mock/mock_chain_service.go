@@ -0,0 +1,76 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mock
+
+import (
+	"anymind"
+	"context"
+	"sync"
+)
+
+// Ensure, that ChainServiceMock does implement anymind.ChainService.
+// If this is not the case, regenerate this file with moq.
+var _ anymind.ChainService = &ChainServiceMock{}
+
+// ChainServiceMock is a mock implementation of anymind.ChainService.
+//
+//	func TestSomethingThatUsesChainService(t *testing.T) {
+//
+//		// make and configure a mocked anymind.ChainService
+//		mockedChainService := &ChainServiceMock{
+//			VerifyChainFunc: func(ctx context.Context) (*anymind.ChainReport, error) {
+//				panic("mock out the VerifyChain method")
+//			},
+//		}
+//
+//		// use mockedChainService in code that requires anymind.ChainService
+//		// and then make assertions.
+//
+//	}
+type ChainServiceMock struct {
+	// VerifyChainFunc mocks the VerifyChain method.
+	VerifyChainFunc func(ctx context.Context) (*anymind.ChainReport, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// VerifyChain holds details about calls to the VerifyChain method.
+		VerifyChain []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+		}
+	}
+	lockVerifyChain sync.RWMutex
+}
+
+// VerifyChain calls VerifyChainFunc.
+func (mock *ChainServiceMock) VerifyChain(ctx context.Context) (*anymind.ChainReport, error) {
+	if mock.VerifyChainFunc == nil {
+		panic("ChainServiceMock.VerifyChainFunc: method is nil but ChainService.VerifyChain was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+	}{
+		Ctx: ctx,
+	}
+	mock.lockVerifyChain.Lock()
+	mock.calls.VerifyChain = append(mock.calls.VerifyChain, callInfo)
+	mock.lockVerifyChain.Unlock()
+	return mock.VerifyChainFunc(ctx)
+}
+
+// VerifyChainCalls gets all the calls that were made to VerifyChain.
+// Check the length with:
+//
+//	len(mockedChainService.VerifyChainCalls())
+func (mock *ChainServiceMock) VerifyChainCalls() []struct {
+	Ctx context.Context
+} {
+	var calls []struct {
+		Ctx context.Context
+	}
+	mock.lockVerifyChain.RLock()
+	calls = mock.calls.VerifyChain
+	mock.lockVerifyChain.RUnlock()
+	return calls
+}
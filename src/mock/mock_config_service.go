@@ -0,0 +1,76 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mock
+
+import (
+	"anymind"
+	"context"
+	"sync"
+)
+
+// Ensure, that ConfigServiceMock does implement anymind.ConfigService.
+// If this is not the case, regenerate this file with moq.
+var _ anymind.ConfigService = &ConfigServiceMock{}
+
+// ConfigServiceMock is a mock implementation of anymind.ConfigService.
+//
+//	func TestSomethingThatUsesConfigService(t *testing.T) {
+//
+//		// make and configure a mocked anymind.ConfigService
+//		mockedConfigService := &ConfigServiceMock{
+//			ActiveConfigFunc: func(ctx context.Context) (*anymind.ActiveConfig, error) {
+//				panic("mock out the ActiveConfig method")
+//			},
+//		}
+//
+//		// use mockedConfigService in code that requires anymind.ConfigService
+//		// and then make assertions.
+//
+//	}
+type ConfigServiceMock struct {
+	// ActiveConfigFunc mocks the ActiveConfig method.
+	ActiveConfigFunc func(ctx context.Context) (*anymind.ActiveConfig, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// ActiveConfig holds details about calls to the ActiveConfig method.
+		ActiveConfig []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+		}
+	}
+	lockActiveConfig sync.RWMutex
+}
+
+// ActiveConfig calls ActiveConfigFunc.
+func (mock *ConfigServiceMock) ActiveConfig(ctx context.Context) (*anymind.ActiveConfig, error) {
+	if mock.ActiveConfigFunc == nil {
+		panic("ConfigServiceMock.ActiveConfigFunc: method is nil but ConfigService.ActiveConfig was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+	}{
+		Ctx: ctx,
+	}
+	mock.lockActiveConfig.Lock()
+	mock.calls.ActiveConfig = append(mock.calls.ActiveConfig, callInfo)
+	mock.lockActiveConfig.Unlock()
+	return mock.ActiveConfigFunc(ctx)
+}
+
+// ActiveConfigCalls gets all the calls that were made to ActiveConfig.
+// Check the length with:
+//
+//	len(mockedConfigService.ActiveConfigCalls())
+func (mock *ConfigServiceMock) ActiveConfigCalls() []struct {
+	Ctx context.Context
+} {
+	var calls []struct {
+		Ctx context.Context
+	}
+	mock.lockActiveConfig.RLock()
+	calls = mock.calls.ActiveConfig
+	mock.lockActiveConfig.RUnlock()
+	return calls
+}
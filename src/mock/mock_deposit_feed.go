@@ -0,0 +1,76 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mock
+
+import (
+	"anymind"
+	"context"
+	"sync"
+)
+
+// Ensure, that DepositFeedMock does implement anymind.DepositFeed.
+// If this is not the case, regenerate this file with moq.
+var _ anymind.DepositFeed = &DepositFeedMock{}
+
+// DepositFeedMock is a mock implementation of anymind.DepositFeed.
+//
+//	func TestSomethingThatUsesDepositFeed(t *testing.T) {
+//
+//		// make and configure a mocked anymind.DepositFeed
+//		mockedDepositFeed := &DepositFeedMock{
+//			SubscribeFunc: func(ctx context.Context) <-chan *anymind.DepositEvent {
+//				panic("mock out the Subscribe method")
+//			},
+//		}
+//
+//		// use mockedDepositFeed in code that requires anymind.DepositFeed
+//		// and then make assertions.
+//
+//	}
+type DepositFeedMock struct {
+	// SubscribeFunc mocks the Subscribe method.
+	SubscribeFunc func(ctx context.Context) <-chan *anymind.DepositEvent
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Subscribe holds details about calls to the Subscribe method.
+		Subscribe []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+		}
+	}
+	lockSubscribe sync.RWMutex
+}
+
+// Subscribe calls SubscribeFunc.
+func (mock *DepositFeedMock) Subscribe(ctx context.Context) <-chan *anymind.DepositEvent {
+	if mock.SubscribeFunc == nil {
+		panic("DepositFeedMock.SubscribeFunc: method is nil but DepositFeed.Subscribe was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+	}{
+		Ctx: ctx,
+	}
+	mock.lockSubscribe.Lock()
+	mock.calls.Subscribe = append(mock.calls.Subscribe, callInfo)
+	mock.lockSubscribe.Unlock()
+	return mock.SubscribeFunc(ctx)
+}
+
+// SubscribeCalls gets all the calls that were made to Subscribe.
+// Check the length with:
+//
+//	len(mockedDepositFeed.SubscribeCalls())
+func (mock *DepositFeedMock) SubscribeCalls() []struct {
+	Ctx context.Context
+} {
+	var calls []struct {
+		Ctx context.Context
+	}
+	mock.lockSubscribe.RLock()
+	calls = mock.calls.Subscribe
+	mock.lockSubscribe.RUnlock()
+	return calls
+}
@@ -0,0 +1,139 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mock
+
+import (
+	"anymind"
+	"context"
+	"sync"
+	"time"
+)
+
+// Ensure, that DepositStatusServiceMock does implement anymind.DepositStatusService.
+// If this is not the case, regenerate this file with moq.
+var _ anymind.DepositStatusService = &DepositStatusServiceMock{}
+
+// DepositStatusServiceMock is a mock implementation of anymind.DepositStatusService.
+//
+//	func TestSomethingThatUsesDepositStatusService(t *testing.T) {
+//
+//		// make and configure a mocked anymind.DepositStatusService
+//		mockedDepositStatusService := &DepositStatusServiceMock{
+//			ExpirePendingDepositsFunc: func(ctx context.Context, before time.Time) (int64, error) {
+//				panic("mock out the ExpirePendingDeposits method")
+//			},
+//			SetDepositStatusFunc: func(ctx context.Context, id int64, status string) (*anymind.DepositEvent, error) {
+//				panic("mock out the SetDepositStatus method")
+//			},
+//		}
+//
+//		// use mockedDepositStatusService in code that requires anymind.DepositStatusService
+//		// and then make assertions.
+//
+//	}
+type DepositStatusServiceMock struct {
+	// ExpirePendingDepositsFunc mocks the ExpirePendingDeposits method.
+	ExpirePendingDepositsFunc func(ctx context.Context, before time.Time) (int64, error)
+
+	// SetDepositStatusFunc mocks the SetDepositStatus method.
+	SetDepositStatusFunc func(ctx context.Context, id int64, status string) (*anymind.DepositEvent, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// ExpirePendingDeposits holds details about calls to the ExpirePendingDeposits method.
+		ExpirePendingDeposits []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Before is the before argument value.
+			Before time.Time
+		}
+		// SetDepositStatus holds details about calls to the SetDepositStatus method.
+		SetDepositStatus []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID int64
+			// Status is the status argument value.
+			Status string
+		}
+	}
+	lockExpirePendingDeposits sync.RWMutex
+	lockSetDepositStatus      sync.RWMutex
+}
+
+// ExpirePendingDeposits calls ExpirePendingDepositsFunc.
+func (mock *DepositStatusServiceMock) ExpirePendingDeposits(ctx context.Context, before time.Time) (int64, error) {
+	if mock.ExpirePendingDepositsFunc == nil {
+		panic("DepositStatusServiceMock.ExpirePendingDepositsFunc: method is nil but DepositStatusService.ExpirePendingDeposits was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Before time.Time
+	}{
+		Ctx:    ctx,
+		Before: before,
+	}
+	mock.lockExpirePendingDeposits.Lock()
+	mock.calls.ExpirePendingDeposits = append(mock.calls.ExpirePendingDeposits, callInfo)
+	mock.lockExpirePendingDeposits.Unlock()
+	return mock.ExpirePendingDepositsFunc(ctx, before)
+}
+
+// ExpirePendingDepositsCalls gets all the calls that were made to ExpirePendingDeposits.
+// Check the length with:
+//
+//	len(mockedDepositStatusService.ExpirePendingDepositsCalls())
+func (mock *DepositStatusServiceMock) ExpirePendingDepositsCalls() []struct {
+	Ctx    context.Context
+	Before time.Time
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Before time.Time
+	}
+	mock.lockExpirePendingDeposits.RLock()
+	calls = mock.calls.ExpirePendingDeposits
+	mock.lockExpirePendingDeposits.RUnlock()
+	return calls
+}
+
+// SetDepositStatus calls SetDepositStatusFunc.
+func (mock *DepositStatusServiceMock) SetDepositStatus(ctx context.Context, id int64, status string) (*anymind.DepositEvent, error) {
+	if mock.SetDepositStatusFunc == nil {
+		panic("DepositStatusServiceMock.SetDepositStatusFunc: method is nil but DepositStatusService.SetDepositStatus was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		ID     int64
+		Status string
+	}{
+		Ctx:    ctx,
+		ID:     id,
+		Status: status,
+	}
+	mock.lockSetDepositStatus.Lock()
+	mock.calls.SetDepositStatus = append(mock.calls.SetDepositStatus, callInfo)
+	mock.lockSetDepositStatus.Unlock()
+	return mock.SetDepositStatusFunc(ctx, id, status)
+}
+
+// SetDepositStatusCalls gets all the calls that were made to SetDepositStatus.
+// Check the length with:
+//
+//	len(mockedDepositStatusService.SetDepositStatusCalls())
+func (mock *DepositStatusServiceMock) SetDepositStatusCalls() []struct {
+	Ctx    context.Context
+	ID     int64
+	Status string
+} {
+	var calls []struct {
+		Ctx    context.Context
+		ID     int64
+		Status string
+	}
+	mock.lockSetDepositStatus.RLock()
+	calls = mock.calls.SetDepositStatus
+	mock.lockSetDepositStatus.RUnlock()
+	return calls
+}
@@ -0,0 +1,76 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mock
+
+import (
+	"anymind"
+	"context"
+	"sync"
+)
+
+// Ensure, that HealthCheckerMock does implement anymind.HealthChecker.
+// If this is not the case, regenerate this file with moq.
+var _ anymind.HealthChecker = &HealthCheckerMock{}
+
+// HealthCheckerMock is a mock implementation of anymind.HealthChecker.
+//
+//	func TestSomethingThatUsesHealthChecker(t *testing.T) {
+//
+//		// make and configure a mocked anymind.HealthChecker
+//		mockedHealthChecker := &HealthCheckerMock{
+//			CheckHealthFunc: func(ctx context.Context) error {
+//				panic("mock out the CheckHealth method")
+//			},
+//		}
+//
+//		// use mockedHealthChecker in code that requires anymind.HealthChecker
+//		// and then make assertions.
+//
+//	}
+type HealthCheckerMock struct {
+	// CheckHealthFunc mocks the CheckHealth method.
+	CheckHealthFunc func(ctx context.Context) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// CheckHealth holds details about calls to the CheckHealth method.
+		CheckHealth []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+		}
+	}
+	lockCheckHealth sync.RWMutex
+}
+
+// CheckHealth calls CheckHealthFunc.
+func (mock *HealthCheckerMock) CheckHealth(ctx context.Context) error {
+	if mock.CheckHealthFunc == nil {
+		panic("HealthCheckerMock.CheckHealthFunc: method is nil but HealthChecker.CheckHealth was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+	}{
+		Ctx: ctx,
+	}
+	mock.lockCheckHealth.Lock()
+	mock.calls.CheckHealth = append(mock.calls.CheckHealth, callInfo)
+	mock.lockCheckHealth.Unlock()
+	return mock.CheckHealthFunc(ctx)
+}
+
+// CheckHealthCalls gets all the calls that were made to CheckHealth.
+// Check the length with:
+//
+//	len(mockedHealthChecker.CheckHealthCalls())
+func (mock *HealthCheckerMock) CheckHealthCalls() []struct {
+	Ctx context.Context
+} {
+	var calls []struct {
+		Ctx context.Context
+	}
+	mock.lockCheckHealth.RLock()
+	calls = mock.calls.CheckHealth
+	mock.lockCheckHealth.RUnlock()
+	return calls
+}
@@ -0,0 +1,151 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mock
+
+import (
+	"anymind"
+	"context"
+	"sync"
+	"time"
+)
+
+// Ensure, that NonceStoreMock does implement anymind.NonceStore.
+// If this is not the case, regenerate this file with moq.
+var _ anymind.NonceStore = &NonceStoreMock{}
+
+// NonceStoreMock is a mock implementation of anymind.NonceStore.
+//
+//	func TestSomethingThatUsesNonceStore(t *testing.T) {
+//
+//		// make and configure a mocked anymind.NonceStore
+//		mockedNonceStore := &NonceStoreMock{
+//			ClaimNonceFunc: func(ctx context.Context, keyID string, nonce string, now time.Time, expiresAt time.Time) (bool, error) {
+//				panic("mock out the ClaimNonce method")
+//			},
+//			PruneNoncesFunc: func(ctx context.Context, before time.Time) error {
+//				panic("mock out the PruneNonces method")
+//			},
+//		}
+//
+//		// use mockedNonceStore in code that requires anymind.NonceStore
+//		// and then make assertions.
+//
+//	}
+type NonceStoreMock struct {
+	// ClaimNonceFunc mocks the ClaimNonce method.
+	ClaimNonceFunc func(ctx context.Context, keyID string, nonce string, now time.Time, expiresAt time.Time) (bool, error)
+
+	// PruneNoncesFunc mocks the PruneNonces method.
+	PruneNoncesFunc func(ctx context.Context, before time.Time) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// ClaimNonce holds details about calls to the ClaimNonce method.
+		ClaimNonce []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// KeyID is the keyID argument value.
+			KeyID string
+			// Nonce is the nonce argument value.
+			Nonce string
+			// Now is the now argument value.
+			Now time.Time
+			// ExpiresAt is the expiresAt argument value.
+			ExpiresAt time.Time
+		}
+		// PruneNonces holds details about calls to the PruneNonces method.
+		PruneNonces []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Before is the before argument value.
+			Before time.Time
+		}
+	}
+	lockClaimNonce  sync.RWMutex
+	lockPruneNonces sync.RWMutex
+}
+
+// ClaimNonce calls ClaimNonceFunc.
+func (mock *NonceStoreMock) ClaimNonce(ctx context.Context, keyID string, nonce string, now time.Time, expiresAt time.Time) (bool, error) {
+	if mock.ClaimNonceFunc == nil {
+		panic("NonceStoreMock.ClaimNonceFunc: method is nil but NonceStore.ClaimNonce was just called")
+	}
+	callInfo := struct {
+		Ctx       context.Context
+		KeyID     string
+		Nonce     string
+		Now       time.Time
+		ExpiresAt time.Time
+	}{
+		Ctx:       ctx,
+		KeyID:     keyID,
+		Nonce:     nonce,
+		Now:       now,
+		ExpiresAt: expiresAt,
+	}
+	mock.lockClaimNonce.Lock()
+	mock.calls.ClaimNonce = append(mock.calls.ClaimNonce, callInfo)
+	mock.lockClaimNonce.Unlock()
+	return mock.ClaimNonceFunc(ctx, keyID, nonce, now, expiresAt)
+}
+
+// ClaimNonceCalls gets all the calls that were made to ClaimNonce.
+// Check the length with:
+//
+//	len(mockedNonceStore.ClaimNonceCalls())
+func (mock *NonceStoreMock) ClaimNonceCalls() []struct {
+	Ctx       context.Context
+	KeyID     string
+	Nonce     string
+	Now       time.Time
+	ExpiresAt time.Time
+} {
+	var calls []struct {
+		Ctx       context.Context
+		KeyID     string
+		Nonce     string
+		Now       time.Time
+		ExpiresAt time.Time
+	}
+	mock.lockClaimNonce.RLock()
+	calls = mock.calls.ClaimNonce
+	mock.lockClaimNonce.RUnlock()
+	return calls
+}
+
+// PruneNonces calls PruneNoncesFunc.
+func (mock *NonceStoreMock) PruneNonces(ctx context.Context, before time.Time) error {
+	if mock.PruneNoncesFunc == nil {
+		panic("NonceStoreMock.PruneNoncesFunc: method is nil but NonceStore.PruneNonces was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Before time.Time
+	}{
+		Ctx:    ctx,
+		Before: before,
+	}
+	mock.lockPruneNonces.Lock()
+	mock.calls.PruneNonces = append(mock.calls.PruneNonces, callInfo)
+	mock.lockPruneNonces.Unlock()
+	return mock.PruneNoncesFunc(ctx, before)
+}
+
+// PruneNoncesCalls gets all the calls that were made to PruneNonces.
+// Check the length with:
+//
+//	len(mockedNonceStore.PruneNoncesCalls())
+func (mock *NonceStoreMock) PruneNoncesCalls() []struct {
+	Ctx    context.Context
+	Before time.Time
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Before time.Time
+	}
+	mock.lockPruneNonces.RLock()
+	calls = mock.calls.PruneNonces
+	mock.lockPruneNonces.RUnlock()
+	return calls
+}
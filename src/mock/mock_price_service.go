@@ -0,0 +1,151 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mock
+
+import (
+	"anymind"
+	"context"
+	"sync"
+	"time"
+)
+
+// Ensure, that PriceServiceMock does implement anymind.PriceService.
+// If this is not the case, regenerate this file with moq.
+var _ anymind.PriceService = &PriceServiceMock{}
+
+// PriceServiceMock is a mock implementation of anymind.PriceService.
+//
+//	func TestSomethingThatUsesPriceService(t *testing.T) {
+//
+//		// make and configure a mocked anymind.PriceService
+//		mockedPriceService := &PriceServiceMock{
+//			ImportRatesFunc: func(ctx context.Context, rates []*anymind.Rate) error {
+//				panic("mock out the ImportRates method")
+//			},
+//			RatesFunc: func(ctx context.Context, base string, quote string, start time.Time, end time.Time) ([]*anymind.Rate, error) {
+//				panic("mock out the Rates method")
+//			},
+//		}
+//
+//		// use mockedPriceService in code that requires anymind.PriceService
+//		// and then make assertions.
+//
+//	}
+type PriceServiceMock struct {
+	// ImportRatesFunc mocks the ImportRates method.
+	ImportRatesFunc func(ctx context.Context, rates []*anymind.Rate) error
+
+	// RatesFunc mocks the Rates method.
+	RatesFunc func(ctx context.Context, base string, quote string, start time.Time, end time.Time) ([]*anymind.Rate, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// ImportRates holds details about calls to the ImportRates method.
+		ImportRates []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Rates is the rates argument value.
+			Rates []*anymind.Rate
+		}
+		// Rates holds details about calls to the Rates method.
+		Rates []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Base is the base argument value.
+			Base string
+			// Quote is the quote argument value.
+			Quote string
+			// Start is the start argument value.
+			Start time.Time
+			// End is the end argument value.
+			End time.Time
+		}
+	}
+	lockImportRates sync.RWMutex
+	lockRates       sync.RWMutex
+}
+
+// ImportRates calls ImportRatesFunc.
+func (mock *PriceServiceMock) ImportRates(ctx context.Context, rates []*anymind.Rate) error {
+	if mock.ImportRatesFunc == nil {
+		panic("PriceServiceMock.ImportRatesFunc: method is nil but PriceService.ImportRates was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Rates []*anymind.Rate
+	}{
+		Ctx:   ctx,
+		Rates: rates,
+	}
+	mock.lockImportRates.Lock()
+	mock.calls.ImportRates = append(mock.calls.ImportRates, callInfo)
+	mock.lockImportRates.Unlock()
+	return mock.ImportRatesFunc(ctx, rates)
+}
+
+// ImportRatesCalls gets all the calls that were made to ImportRates.
+// Check the length with:
+//
+//	len(mockedPriceService.ImportRatesCalls())
+func (mock *PriceServiceMock) ImportRatesCalls() []struct {
+	Ctx   context.Context
+	Rates []*anymind.Rate
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Rates []*anymind.Rate
+	}
+	mock.lockImportRates.RLock()
+	calls = mock.calls.ImportRates
+	mock.lockImportRates.RUnlock()
+	return calls
+}
+
+// Rates calls RatesFunc.
+func (mock *PriceServiceMock) Rates(ctx context.Context, base string, quote string, start time.Time, end time.Time) ([]*anymind.Rate, error) {
+	if mock.RatesFunc == nil {
+		panic("PriceServiceMock.RatesFunc: method is nil but PriceService.Rates was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Base  string
+		Quote string
+		Start time.Time
+		End   time.Time
+	}{
+		Ctx:   ctx,
+		Base:  base,
+		Quote: quote,
+		Start: start,
+		End:   end,
+	}
+	mock.lockRates.Lock()
+	mock.calls.Rates = append(mock.calls.Rates, callInfo)
+	mock.lockRates.Unlock()
+	return mock.RatesFunc(ctx, base, quote, start, end)
+}
+
+// RatesCalls gets all the calls that were made to Rates.
+// Check the length with:
+//
+//	len(mockedPriceService.RatesCalls())
+func (mock *PriceServiceMock) RatesCalls() []struct {
+	Ctx   context.Context
+	Base  string
+	Quote string
+	Start time.Time
+	End   time.Time
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Base  string
+		Quote string
+		Start time.Time
+		End   time.Time
+	}
+	mock.lockRates.RLock()
+	calls = mock.calls.Rates
+	mock.lockRates.RUnlock()
+	return calls
+}
@@ -0,0 +1,82 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mock
+
+import (
+	"anymind"
+	"context"
+	"sync"
+)
+
+// Ensure, that RequestVerifierMock does implement anymind.RequestVerifier.
+// If this is not the case, regenerate this file with moq.
+var _ anymind.RequestVerifier = &RequestVerifierMock{}
+
+// RequestVerifierMock is a mock implementation of anymind.RequestVerifier.
+//
+//	func TestSomethingThatUsesRequestVerifier(t *testing.T) {
+//
+//		// make and configure a mocked anymind.RequestVerifier
+//		mockedRequestVerifier := &RequestVerifierMock{
+//			VerifyFunc: func(ctx context.Context, req *anymind.SignedRequest) error {
+//				panic("mock out the Verify method")
+//			},
+//		}
+//
+//		// use mockedRequestVerifier in code that requires anymind.RequestVerifier
+//		// and then make assertions.
+//
+//	}
+type RequestVerifierMock struct {
+	// VerifyFunc mocks the Verify method.
+	VerifyFunc func(ctx context.Context, req *anymind.SignedRequest) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Verify holds details about calls to the Verify method.
+		Verify []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Req is the req argument value.
+			Req *anymind.SignedRequest
+		}
+	}
+	lockVerify sync.RWMutex
+}
+
+// Verify calls VerifyFunc.
+func (mock *RequestVerifierMock) Verify(ctx context.Context, req *anymind.SignedRequest) error {
+	if mock.VerifyFunc == nil {
+		panic("RequestVerifierMock.VerifyFunc: method is nil but RequestVerifier.Verify was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		Req *anymind.SignedRequest
+	}{
+		Ctx: ctx,
+		Req: req,
+	}
+	mock.lockVerify.Lock()
+	mock.calls.Verify = append(mock.calls.Verify, callInfo)
+	mock.lockVerify.Unlock()
+	return mock.VerifyFunc(ctx, req)
+}
+
+// VerifyCalls gets all the calls that were made to Verify.
+// Check the length with:
+//
+//	len(mockedRequestVerifier.VerifyCalls())
+func (mock *RequestVerifierMock) VerifyCalls() []struct {
+	Ctx context.Context
+	Req *anymind.SignedRequest
+} {
+	var calls []struct {
+		Ctx context.Context
+		Req *anymind.SignedRequest
+	}
+	mock.lockVerify.RLock()
+	calls = mock.calls.Verify
+	mock.lockVerify.RUnlock()
+	return calls
+}
@@ -0,0 +1,82 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mock
+
+import (
+	"anymind"
+	"context"
+	"sync"
+)
+
+// Ensure, that StatsServiceMock does implement anymind.StatsService.
+// If this is not the case, regenerate this file with moq.
+var _ anymind.StatsService = &StatsServiceMock{}
+
+// StatsServiceMock is a mock implementation of anymind.StatsService.
+//
+//	func TestSomethingThatUsesStatsService(t *testing.T) {
+//
+//		// make and configure a mocked anymind.StatsService
+//		mockedStatsService := &StatsServiceMock{
+//			StatsFunc: func(ctx context.Context, req *anymind.HistoricalDataReq) ([]*anymind.BucketStats, error) {
+//				panic("mock out the Stats method")
+//			},
+//		}
+//
+//		// use mockedStatsService in code that requires anymind.StatsService
+//		// and then make assertions.
+//
+//	}
+type StatsServiceMock struct {
+	// StatsFunc mocks the Stats method.
+	StatsFunc func(ctx context.Context, req *anymind.HistoricalDataReq) ([]*anymind.BucketStats, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Stats holds details about calls to the Stats method.
+		Stats []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Req is the req argument value.
+			Req *anymind.HistoricalDataReq
+		}
+	}
+	lockStats sync.RWMutex
+}
+
+// Stats calls StatsFunc.
+func (mock *StatsServiceMock) Stats(ctx context.Context, req *anymind.HistoricalDataReq) ([]*anymind.BucketStats, error) {
+	if mock.StatsFunc == nil {
+		panic("StatsServiceMock.StatsFunc: method is nil but StatsService.Stats was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		Req *anymind.HistoricalDataReq
+	}{
+		Ctx: ctx,
+		Req: req,
+	}
+	mock.lockStats.Lock()
+	mock.calls.Stats = append(mock.calls.Stats, callInfo)
+	mock.lockStats.Unlock()
+	return mock.StatsFunc(ctx, req)
+}
+
+// StatsCalls gets all the calls that were made to Stats.
+// Check the length with:
+//
+//	len(mockedStatsService.StatsCalls())
+func (mock *StatsServiceMock) StatsCalls() []struct {
+	Ctx context.Context
+	Req *anymind.HistoricalDataReq
+} {
+	var calls []struct {
+		Ctx context.Context
+		Req *anymind.HistoricalDataReq
+	}
+	mock.lockStats.RLock()
+	calls = mock.calls.Stats
+	mock.lockStats.RUnlock()
+	return calls
+}
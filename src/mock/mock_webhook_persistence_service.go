@@ -0,0 +1,401 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mock
+
+import (
+	"anymind"
+	"context"
+	"sync"
+	"time"
+)
+
+// Ensure, that WebhookPersistenceServiceMock does implement anymind.WebhookPersistenceService.
+// If this is not the case, regenerate this file with moq.
+var _ anymind.WebhookPersistenceService = &WebhookPersistenceServiceMock{}
+
+// WebhookPersistenceServiceMock is a mock implementation of anymind.WebhookPersistenceService.
+//
+//	func TestSomethingThatUsesWebhookPersistenceService(t *testing.T) {
+//
+//		// make and configure a mocked anymind.WebhookPersistenceService
+//		mockedWebhookPersistenceService := &WebhookPersistenceServiceMock{
+//			ClaimDeliveriesFunc: func(ctx context.Context, now time.Time, leaseUntil time.Time, limit int) ([]*anymind.WebhookDelivery, error) {
+//				panic("mock out the ClaimDeliveries method")
+//			},
+//			CreateWebhookFunc: func(ctx context.Context, sub *anymind.WebhookSubscription) error {
+//				panic("mock out the CreateWebhook method")
+//			},
+//			DeleteWebhookFunc: func(ctx context.Context, id int64) error {
+//				panic("mock out the DeleteWebhook method")
+//			},
+//			ListDeliveriesFunc: func(ctx context.Context, status string, limit int) ([]*anymind.WebhookDelivery, error) {
+//				panic("mock out the ListDeliveries method")
+//			},
+//			ListWebhooksFunc: func(ctx context.Context) ([]*anymind.WebhookSubscription, error) {
+//				panic("mock out the ListWebhooks method")
+//			},
+//			RedeliverWebhookFunc: func(ctx context.Context, id int64, at time.Time) error {
+//				panic("mock out the RedeliverWebhook method")
+//			},
+//			UpdateDeliveryFunc: func(ctx context.Context, delivery *anymind.WebhookDelivery) error {
+//				panic("mock out the UpdateDelivery method")
+//			},
+//		}
+//
+//		// use mockedWebhookPersistenceService in code that requires anymind.WebhookPersistenceService
+//		// and then make assertions.
+//
+//	}
+type WebhookPersistenceServiceMock struct {
+	// ClaimDeliveriesFunc mocks the ClaimDeliveries method.
+	ClaimDeliveriesFunc func(ctx context.Context, now time.Time, leaseUntil time.Time, limit int) ([]*anymind.WebhookDelivery, error)
+
+	// CreateWebhookFunc mocks the CreateWebhook method.
+	CreateWebhookFunc func(ctx context.Context, sub *anymind.WebhookSubscription) error
+
+	// DeleteWebhookFunc mocks the DeleteWebhook method.
+	DeleteWebhookFunc func(ctx context.Context, id int64) error
+
+	// ListDeliveriesFunc mocks the ListDeliveries method.
+	ListDeliveriesFunc func(ctx context.Context, status string, limit int) ([]*anymind.WebhookDelivery, error)
+
+	// ListWebhooksFunc mocks the ListWebhooks method.
+	ListWebhooksFunc func(ctx context.Context) ([]*anymind.WebhookSubscription, error)
+
+	// RedeliverWebhookFunc mocks the RedeliverWebhook method.
+	RedeliverWebhookFunc func(ctx context.Context, id int64, at time.Time) error
+
+	// UpdateDeliveryFunc mocks the UpdateDelivery method.
+	UpdateDeliveryFunc func(ctx context.Context, delivery *anymind.WebhookDelivery) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// ClaimDeliveries holds details about calls to the ClaimDeliveries method.
+		ClaimDeliveries []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Now is the now argument value.
+			Now time.Time
+			// LeaseUntil is the leaseUntil argument value.
+			LeaseUntil time.Time
+			// Limit is the limit argument value.
+			Limit int
+		}
+		// CreateWebhook holds details about calls to the CreateWebhook method.
+		CreateWebhook []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Sub is the sub argument value.
+			Sub *anymind.WebhookSubscription
+		}
+		// DeleteWebhook holds details about calls to the DeleteWebhook method.
+		DeleteWebhook []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID int64
+		}
+		// ListDeliveries holds details about calls to the ListDeliveries method.
+		ListDeliveries []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Status is the status argument value.
+			Status string
+			// Limit is the limit argument value.
+			Limit int
+		}
+		// ListWebhooks holds details about calls to the ListWebhooks method.
+		ListWebhooks []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+		}
+		// RedeliverWebhook holds details about calls to the RedeliverWebhook method.
+		RedeliverWebhook []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID int64
+			// At is the at argument value.
+			At time.Time
+		}
+		// UpdateDelivery holds details about calls to the UpdateDelivery method.
+		UpdateDelivery []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Delivery is the delivery argument value.
+			Delivery *anymind.WebhookDelivery
+		}
+	}
+	lockClaimDeliveries  sync.RWMutex
+	lockCreateWebhook    sync.RWMutex
+	lockDeleteWebhook    sync.RWMutex
+	lockListDeliveries   sync.RWMutex
+	lockListWebhooks     sync.RWMutex
+	lockRedeliverWebhook sync.RWMutex
+	lockUpdateDelivery   sync.RWMutex
+}
+
+// ClaimDeliveries calls ClaimDeliveriesFunc.
+func (mock *WebhookPersistenceServiceMock) ClaimDeliveries(ctx context.Context, now time.Time, leaseUntil time.Time, limit int) ([]*anymind.WebhookDelivery, error) {
+	if mock.ClaimDeliveriesFunc == nil {
+		panic("WebhookPersistenceServiceMock.ClaimDeliveriesFunc: method is nil but WebhookPersistenceService.ClaimDeliveries was just called")
+	}
+	callInfo := struct {
+		Ctx        context.Context
+		Now        time.Time
+		LeaseUntil time.Time
+		Limit      int
+	}{
+		Ctx:        ctx,
+		Now:        now,
+		LeaseUntil: leaseUntil,
+		Limit:      limit,
+	}
+	mock.lockClaimDeliveries.Lock()
+	mock.calls.ClaimDeliveries = append(mock.calls.ClaimDeliveries, callInfo)
+	mock.lockClaimDeliveries.Unlock()
+	return mock.ClaimDeliveriesFunc(ctx, now, leaseUntil, limit)
+}
+
+// ClaimDeliveriesCalls gets all the calls that were made to ClaimDeliveries.
+// Check the length with:
+//
+//	len(mockedWebhookPersistenceService.ClaimDeliveriesCalls())
+func (mock *WebhookPersistenceServiceMock) ClaimDeliveriesCalls() []struct {
+	Ctx        context.Context
+	Now        time.Time
+	LeaseUntil time.Time
+	Limit      int
+} {
+	var calls []struct {
+		Ctx        context.Context
+		Now        time.Time
+		LeaseUntil time.Time
+		Limit      int
+	}
+	mock.lockClaimDeliveries.RLock()
+	calls = mock.calls.ClaimDeliveries
+	mock.lockClaimDeliveries.RUnlock()
+	return calls
+}
+
+// CreateWebhook calls CreateWebhookFunc.
+func (mock *WebhookPersistenceServiceMock) CreateWebhook(ctx context.Context, sub *anymind.WebhookSubscription) error {
+	if mock.CreateWebhookFunc == nil {
+		panic("WebhookPersistenceServiceMock.CreateWebhookFunc: method is nil but WebhookPersistenceService.CreateWebhook was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		Sub *anymind.WebhookSubscription
+	}{
+		Ctx: ctx,
+		Sub: sub,
+	}
+	mock.lockCreateWebhook.Lock()
+	mock.calls.CreateWebhook = append(mock.calls.CreateWebhook, callInfo)
+	mock.lockCreateWebhook.Unlock()
+	return mock.CreateWebhookFunc(ctx, sub)
+}
+
+// CreateWebhookCalls gets all the calls that were made to CreateWebhook.
+// Check the length with:
+//
+//	len(mockedWebhookPersistenceService.CreateWebhookCalls())
+func (mock *WebhookPersistenceServiceMock) CreateWebhookCalls() []struct {
+	Ctx context.Context
+	Sub *anymind.WebhookSubscription
+} {
+	var calls []struct {
+		Ctx context.Context
+		Sub *anymind.WebhookSubscription
+	}
+	mock.lockCreateWebhook.RLock()
+	calls = mock.calls.CreateWebhook
+	mock.lockCreateWebhook.RUnlock()
+	return calls
+}
+
+// DeleteWebhook calls DeleteWebhookFunc.
+func (mock *WebhookPersistenceServiceMock) DeleteWebhook(ctx context.Context, id int64) error {
+	if mock.DeleteWebhookFunc == nil {
+		panic("WebhookPersistenceServiceMock.DeleteWebhookFunc: method is nil but WebhookPersistenceService.DeleteWebhook was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		ID  int64
+	}{
+		Ctx: ctx,
+		ID:  id,
+	}
+	mock.lockDeleteWebhook.Lock()
+	mock.calls.DeleteWebhook = append(mock.calls.DeleteWebhook, callInfo)
+	mock.lockDeleteWebhook.Unlock()
+	return mock.DeleteWebhookFunc(ctx, id)
+}
+
+// DeleteWebhookCalls gets all the calls that were made to DeleteWebhook.
+// Check the length with:
+//
+//	len(mockedWebhookPersistenceService.DeleteWebhookCalls())
+func (mock *WebhookPersistenceServiceMock) DeleteWebhookCalls() []struct {
+	Ctx context.Context
+	ID  int64
+} {
+	var calls []struct {
+		Ctx context.Context
+		ID  int64
+	}
+	mock.lockDeleteWebhook.RLock()
+	calls = mock.calls.DeleteWebhook
+	mock.lockDeleteWebhook.RUnlock()
+	return calls
+}
+
+// ListDeliveries calls ListDeliveriesFunc.
+func (mock *WebhookPersistenceServiceMock) ListDeliveries(ctx context.Context, status string, limit int) ([]*anymind.WebhookDelivery, error) {
+	if mock.ListDeliveriesFunc == nil {
+		panic("WebhookPersistenceServiceMock.ListDeliveriesFunc: method is nil but WebhookPersistenceService.ListDeliveries was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Status string
+		Limit  int
+	}{
+		Ctx:    ctx,
+		Status: status,
+		Limit:  limit,
+	}
+	mock.lockListDeliveries.Lock()
+	mock.calls.ListDeliveries = append(mock.calls.ListDeliveries, callInfo)
+	mock.lockListDeliveries.Unlock()
+	return mock.ListDeliveriesFunc(ctx, status, limit)
+}
+
+// ListDeliveriesCalls gets all the calls that were made to ListDeliveries.
+// Check the length with:
+//
+//	len(mockedWebhookPersistenceService.ListDeliveriesCalls())
+func (mock *WebhookPersistenceServiceMock) ListDeliveriesCalls() []struct {
+	Ctx    context.Context
+	Status string
+	Limit  int
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Status string
+		Limit  int
+	}
+	mock.lockListDeliveries.RLock()
+	calls = mock.calls.ListDeliveries
+	mock.lockListDeliveries.RUnlock()
+	return calls
+}
+
+// ListWebhooks calls ListWebhooksFunc.
+func (mock *WebhookPersistenceServiceMock) ListWebhooks(ctx context.Context) ([]*anymind.WebhookSubscription, error) {
+	if mock.ListWebhooksFunc == nil {
+		panic("WebhookPersistenceServiceMock.ListWebhooksFunc: method is nil but WebhookPersistenceService.ListWebhooks was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+	}{
+		Ctx: ctx,
+	}
+	mock.lockListWebhooks.Lock()
+	mock.calls.ListWebhooks = append(mock.calls.ListWebhooks, callInfo)
+	mock.lockListWebhooks.Unlock()
+	return mock.ListWebhooksFunc(ctx)
+}
+
+// ListWebhooksCalls gets all the calls that were made to ListWebhooks.
+// Check the length with:
+//
+//	len(mockedWebhookPersistenceService.ListWebhooksCalls())
+func (mock *WebhookPersistenceServiceMock) ListWebhooksCalls() []struct {
+	Ctx context.Context
+} {
+	var calls []struct {
+		Ctx context.Context
+	}
+	mock.lockListWebhooks.RLock()
+	calls = mock.calls.ListWebhooks
+	mock.lockListWebhooks.RUnlock()
+	return calls
+}
+
+// RedeliverWebhook calls RedeliverWebhookFunc.
+func (mock *WebhookPersistenceServiceMock) RedeliverWebhook(ctx context.Context, id int64, at time.Time) error {
+	if mock.RedeliverWebhookFunc == nil {
+		panic("WebhookPersistenceServiceMock.RedeliverWebhookFunc: method is nil but WebhookPersistenceService.RedeliverWebhook was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		ID  int64
+		At  time.Time
+	}{
+		Ctx: ctx,
+		ID:  id,
+		At:  at,
+	}
+	mock.lockRedeliverWebhook.Lock()
+	mock.calls.RedeliverWebhook = append(mock.calls.RedeliverWebhook, callInfo)
+	mock.lockRedeliverWebhook.Unlock()
+	return mock.RedeliverWebhookFunc(ctx, id, at)
+}
+
+// RedeliverWebhookCalls gets all the calls that were made to RedeliverWebhook.
+// Check the length with:
+//
+//	len(mockedWebhookPersistenceService.RedeliverWebhookCalls())
+func (mock *WebhookPersistenceServiceMock) RedeliverWebhookCalls() []struct {
+	Ctx context.Context
+	ID  int64
+	At  time.Time
+} {
+	var calls []struct {
+		Ctx context.Context
+		ID  int64
+		At  time.Time
+	}
+	mock.lockRedeliverWebhook.RLock()
+	calls = mock.calls.RedeliverWebhook
+	mock.lockRedeliverWebhook.RUnlock()
+	return calls
+}
+
+// UpdateDelivery calls UpdateDeliveryFunc.
+func (mock *WebhookPersistenceServiceMock) UpdateDelivery(ctx context.Context, delivery *anymind.WebhookDelivery) error {
+	if mock.UpdateDeliveryFunc == nil {
+		panic("WebhookPersistenceServiceMock.UpdateDeliveryFunc: method is nil but WebhookPersistenceService.UpdateDelivery was just called")
+	}
+	callInfo := struct {
+		Ctx      context.Context
+		Delivery *anymind.WebhookDelivery
+	}{
+		Ctx:      ctx,
+		Delivery: delivery,
+	}
+	mock.lockUpdateDelivery.Lock()
+	mock.calls.UpdateDelivery = append(mock.calls.UpdateDelivery, callInfo)
+	mock.lockUpdateDelivery.Unlock()
+	return mock.UpdateDeliveryFunc(ctx, delivery)
+}
+
+// UpdateDeliveryCalls gets all the calls that were made to UpdateDelivery.
+// Check the length with:
+//
+//	len(mockedWebhookPersistenceService.UpdateDeliveryCalls())
+func (mock *WebhookPersistenceServiceMock) UpdateDeliveryCalls() []struct {
+	Ctx      context.Context
+	Delivery *anymind.WebhookDelivery
+} {
+	var calls []struct {
+		Ctx      context.Context
+		Delivery *anymind.WebhookDelivery
+	}
+	mock.lockUpdateDelivery.RLock()
+	calls = mock.calls.UpdateDelivery
+	mock.lockUpdateDelivery.RUnlock()
+	return calls
+}
@@ -0,0 +1,276 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mock
+
+import (
+	"anymind"
+	"context"
+	"sync"
+)
+
+// Ensure, that WebhookServiceMock does implement anymind.WebhookService.
+// If this is not the case, regenerate this file with moq.
+var _ anymind.WebhookService = &WebhookServiceMock{}
+
+// WebhookServiceMock is a mock implementation of anymind.WebhookService.
+//
+//	func TestSomethingThatUsesWebhookService(t *testing.T) {
+//
+//		// make and configure a mocked anymind.WebhookService
+//		mockedWebhookService := &WebhookServiceMock{
+//			CreateWebhookFunc: func(ctx context.Context, sub *anymind.WebhookSubscription) error {
+//				panic("mock out the CreateWebhook method")
+//			},
+//			DeleteWebhookFunc: func(ctx context.Context, id int64) error {
+//				panic("mock out the DeleteWebhook method")
+//			},
+//			ListDeliveriesFunc: func(ctx context.Context, status string) ([]*anymind.WebhookDelivery, error) {
+//				panic("mock out the ListDeliveries method")
+//			},
+//			ListWebhooksFunc: func(ctx context.Context) ([]*anymind.WebhookSubscription, error) {
+//				panic("mock out the ListWebhooks method")
+//			},
+//			RedeliverFunc: func(ctx context.Context, id int64) error {
+//				panic("mock out the Redeliver method")
+//			},
+//		}
+//
+//		// use mockedWebhookService in code that requires anymind.WebhookService
+//		// and then make assertions.
+//
+//	}
+type WebhookServiceMock struct {
+	// CreateWebhookFunc mocks the CreateWebhook method.
+	CreateWebhookFunc func(ctx context.Context, sub *anymind.WebhookSubscription) error
+
+	// DeleteWebhookFunc mocks the DeleteWebhook method.
+	DeleteWebhookFunc func(ctx context.Context, id int64) error
+
+	// ListDeliveriesFunc mocks the ListDeliveries method.
+	ListDeliveriesFunc func(ctx context.Context, status string) ([]*anymind.WebhookDelivery, error)
+
+	// ListWebhooksFunc mocks the ListWebhooks method.
+	ListWebhooksFunc func(ctx context.Context) ([]*anymind.WebhookSubscription, error)
+
+	// RedeliverFunc mocks the Redeliver method.
+	RedeliverFunc func(ctx context.Context, id int64) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// CreateWebhook holds details about calls to the CreateWebhook method.
+		CreateWebhook []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Sub is the sub argument value.
+			Sub *anymind.WebhookSubscription
+		}
+		// DeleteWebhook holds details about calls to the DeleteWebhook method.
+		DeleteWebhook []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID int64
+		}
+		// ListDeliveries holds details about calls to the ListDeliveries method.
+		ListDeliveries []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Status is the status argument value.
+			Status string
+		}
+		// ListWebhooks holds details about calls to the ListWebhooks method.
+		ListWebhooks []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+		}
+		// Redeliver holds details about calls to the Redeliver method.
+		Redeliver []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID int64
+		}
+	}
+	lockCreateWebhook  sync.RWMutex
+	lockDeleteWebhook  sync.RWMutex
+	lockListDeliveries sync.RWMutex
+	lockListWebhooks   sync.RWMutex
+	lockRedeliver      sync.RWMutex
+}
+
+// CreateWebhook calls CreateWebhookFunc.
+func (mock *WebhookServiceMock) CreateWebhook(ctx context.Context, sub *anymind.WebhookSubscription) error {
+	if mock.CreateWebhookFunc == nil {
+		panic("WebhookServiceMock.CreateWebhookFunc: method is nil but WebhookService.CreateWebhook was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		Sub *anymind.WebhookSubscription
+	}{
+		Ctx: ctx,
+		Sub: sub,
+	}
+	mock.lockCreateWebhook.Lock()
+	mock.calls.CreateWebhook = append(mock.calls.CreateWebhook, callInfo)
+	mock.lockCreateWebhook.Unlock()
+	return mock.CreateWebhookFunc(ctx, sub)
+}
+
+// CreateWebhookCalls gets all the calls that were made to CreateWebhook.
+// Check the length with:
+//
+//	len(mockedWebhookService.CreateWebhookCalls())
+func (mock *WebhookServiceMock) CreateWebhookCalls() []struct {
+	Ctx context.Context
+	Sub *anymind.WebhookSubscription
+} {
+	var calls []struct {
+		Ctx context.Context
+		Sub *anymind.WebhookSubscription
+	}
+	mock.lockCreateWebhook.RLock()
+	calls = mock.calls.CreateWebhook
+	mock.lockCreateWebhook.RUnlock()
+	return calls
+}
+
+// DeleteWebhook calls DeleteWebhookFunc.
+func (mock *WebhookServiceMock) DeleteWebhook(ctx context.Context, id int64) error {
+	if mock.DeleteWebhookFunc == nil {
+		panic("WebhookServiceMock.DeleteWebhookFunc: method is nil but WebhookService.DeleteWebhook was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		ID  int64
+	}{
+		Ctx: ctx,
+		ID:  id,
+	}
+	mock.lockDeleteWebhook.Lock()
+	mock.calls.DeleteWebhook = append(mock.calls.DeleteWebhook, callInfo)
+	mock.lockDeleteWebhook.Unlock()
+	return mock.DeleteWebhookFunc(ctx, id)
+}
+
+// DeleteWebhookCalls gets all the calls that were made to DeleteWebhook.
+// Check the length with:
+//
+//	len(mockedWebhookService.DeleteWebhookCalls())
+func (mock *WebhookServiceMock) DeleteWebhookCalls() []struct {
+	Ctx context.Context
+	ID  int64
+} {
+	var calls []struct {
+		Ctx context.Context
+		ID  int64
+	}
+	mock.lockDeleteWebhook.RLock()
+	calls = mock.calls.DeleteWebhook
+	mock.lockDeleteWebhook.RUnlock()
+	return calls
+}
+
+// ListDeliveries calls ListDeliveriesFunc.
+func (mock *WebhookServiceMock) ListDeliveries(ctx context.Context, status string) ([]*anymind.WebhookDelivery, error) {
+	if mock.ListDeliveriesFunc == nil {
+		panic("WebhookServiceMock.ListDeliveriesFunc: method is nil but WebhookService.ListDeliveries was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Status string
+	}{
+		Ctx:    ctx,
+		Status: status,
+	}
+	mock.lockListDeliveries.Lock()
+	mock.calls.ListDeliveries = append(mock.calls.ListDeliveries, callInfo)
+	mock.lockListDeliveries.Unlock()
+	return mock.ListDeliveriesFunc(ctx, status)
+}
+
+// ListDeliveriesCalls gets all the calls that were made to ListDeliveries.
+// Check the length with:
+//
+//	len(mockedWebhookService.ListDeliveriesCalls())
+func (mock *WebhookServiceMock) ListDeliveriesCalls() []struct {
+	Ctx    context.Context
+	Status string
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Status string
+	}
+	mock.lockListDeliveries.RLock()
+	calls = mock.calls.ListDeliveries
+	mock.lockListDeliveries.RUnlock()
+	return calls
+}
+
+// ListWebhooks calls ListWebhooksFunc.
+func (mock *WebhookServiceMock) ListWebhooks(ctx context.Context) ([]*anymind.WebhookSubscription, error) {
+	if mock.ListWebhooksFunc == nil {
+		panic("WebhookServiceMock.ListWebhooksFunc: method is nil but WebhookService.ListWebhooks was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+	}{
+		Ctx: ctx,
+	}
+	mock.lockListWebhooks.Lock()
+	mock.calls.ListWebhooks = append(mock.calls.ListWebhooks, callInfo)
+	mock.lockListWebhooks.Unlock()
+	return mock.ListWebhooksFunc(ctx)
+}
+
+// ListWebhooksCalls gets all the calls that were made to ListWebhooks.
+// Check the length with:
+//
+//	len(mockedWebhookService.ListWebhooksCalls())
+func (mock *WebhookServiceMock) ListWebhooksCalls() []struct {
+	Ctx context.Context
+} {
+	var calls []struct {
+		Ctx context.Context
+	}
+	mock.lockListWebhooks.RLock()
+	calls = mock.calls.ListWebhooks
+	mock.lockListWebhooks.RUnlock()
+	return calls
+}
+
+// Redeliver calls RedeliverFunc.
+func (mock *WebhookServiceMock) Redeliver(ctx context.Context, id int64) error {
+	if mock.RedeliverFunc == nil {
+		panic("WebhookServiceMock.RedeliverFunc: method is nil but WebhookService.Redeliver was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		ID  int64
+	}{
+		Ctx: ctx,
+		ID:  id,
+	}
+	mock.lockRedeliver.Lock()
+	mock.calls.Redeliver = append(mock.calls.Redeliver, callInfo)
+	mock.lockRedeliver.Unlock()
+	return mock.RedeliverFunc(ctx, id)
+}
+
+// RedeliverCalls gets all the calls that were made to Redeliver.
+// Check the length with:
+//
+//	len(mockedWebhookService.RedeliverCalls())
+func (mock *WebhookServiceMock) RedeliverCalls() []struct {
+	Ctx context.Context
+	ID  int64
+} {
+	var calls []struct {
+		Ctx context.Context
+		ID  int64
+	}
+	mock.lockRedeliver.RLock()
+	calls = mock.calls.Redeliver
+	mock.lockRedeliver.RUnlock()
+	return calls
+}
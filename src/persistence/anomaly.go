@@ -0,0 +1,193 @@
+package persistence
+
+import (
+	"anymind"
+	"anymind/src/tracing"
+	"context"
+	"database/sql"
+	"errors"
+	"go.uber.org/zap"
+	"time"
+)
+
+var _ anymind.AnomalyService = &Service{}
+
+// RecordAnomaly store anomaly on behalf of ctx principal, so held deposit is credited to it once released.
+func (s Service) RecordAnomaly(ctx context.Context, anomaly *anymind.Anomaly) error {
+	keyID, subject := principalColumns(ctx)
+	anomaly.KeyID, anomaly.Subject = keyID.Int64, subject.String
+	anomaly.DateTime = anomaly.DateTime.Truncate(time.Second)
+	anomaly.Asset = anymind.AssetCode(anomaly.Asset)
+	anomaly.DepositStatus = anymind.DepositStatus(anomaly.DepositStatus)
+	anomaly.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+
+	qctx, done := s.query(ctx, "insertAnomalyQuery")
+	err := s.db.QueryRowContext(qctx, insertAnomalyQuery,
+		anomaly.DateTime,
+		anomaly.Amount,
+		anomaly.Asset,
+		anomaly.Reason,
+		anomaly.Score,
+		anomaly.Status,
+		keyID,
+		subject,
+		anomaly.CreatedAt,
+		anomaly.DepositStatus,
+		sql.NullInt64{Int64: anomaly.DepositID, Valid: anomaly.DepositID != 0},
+	).Scan(&anomaly.ID)
+	done(err)
+	if err != nil {
+		tracing.Logger(ctx, s.logger).Error("failed to execute insertAnomalyQuery", zap.Error(err))
+
+		return err
+	}
+
+	return nil
+}
+
+func (s Service) ListAnomalies(ctx context.Context, status string, limit int) ([]*anymind.Anomaly, error) {
+	qctx, done := s.query(ctx, "selectAnomaliesQuery")
+	rows, err := s.db.QueryContext(qctx, selectAnomaliesQuery, status, limit)
+	done(err)
+	if err != nil {
+		tracing.Logger(ctx, s.logger).Error("failed to execute selectAnomaliesQuery", zap.Error(err))
+
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []*anymind.Anomaly
+	for rows.Next() {
+		anomaly, err := scanAnomaly(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, anomaly)
+	}
+
+	return res, rows.Err()
+}
+
+// ReviewAnomaly credit released deposit in the transaction changing its status, so it is credited exactly once.
+func (s Service) ReviewAnomaly(ctx context.Context, id int64, status string) (res *anymind.Anomaly, err error) {
+	err = s.retrySerializable(ctx, "anomaly_review", func() error {
+		res, err = s.reviewAnomaly(ctx, id, status)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (s Service) reviewAnomaly(ctx context.Context, id int64, status string) (*anymind.Anomaly, error) {
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	qctx, done := s.query(ctx, "reviewAnomalyQuery")
+	anomaly, err := scanAnomaly(tx.QueryRowContext(qctx, reviewAnomalyQuery, id, status, time.Now().UTC()))
+	done(err)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, anymind.ErrNotFound
+	}
+	if err != nil {
+		tracing.Logger(ctx, s.logger).Error("failed to execute reviewAnomalyQuery", zap.Error(err))
+
+		return nil, err
+	}
+
+	err = s.appendAudit(ctx, tx, anymind.AuditAnomalyReview, map[string]any{"id": id, "status": status}, anymind.AuditSuccess)
+	if err != nil {
+		return nil, err
+	}
+
+	if status == anymind.AnomalyReleased {
+		input := anomaly.DepositInput()
+		err = s.credit(ctx, tx, input,
+			sql.NullInt64{Int64: anomaly.KeyID, Valid: anomaly.KeyID != 0},
+			sql.NullString{String: anomaly.Subject, Valid: anomaly.Subject != ""})
+		if err != nil {
+			return nil, err
+		}
+
+		qctx, done = s.query(ctx, "updateAnomalyDepositQuery")
+		_, err = tx.ExecContext(qctx, updateAnomalyDepositQuery, anomaly.ID, input.ID)
+		done(err)
+		if err != nil {
+			tracing.Logger(ctx, s.logger).Error("failed to execute updateAnomalyDepositQuery", zap.Error(err))
+
+			return nil, err
+		}
+		anomaly.DepositID = input.ID
+	}
+
+	return anomaly, tx.Commit()
+}
+
+// RecentDeposits return up to limit latest confirmed deposits of every asset, oldest first, to seed anomaly
+// detector with. Held deposits are not stored until released, so they are never returned.
+func (s Service) RecentDeposits(ctx context.Context, limit int) ([]*anymind.DepositInput, error) {
+	qctx, done := s.query(ctx, "selectRecentDepositsQuery")
+	rows, err := s.db.QueryContext(qctx, selectRecentDepositsQuery, limit)
+	done(err)
+	if err != nil {
+		tracing.Logger(ctx, s.logger).Error("failed to execute selectRecentDepositsQuery", zap.Error(err))
+
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []*anymind.DepositInput
+	for rows.Next() {
+		var row anymind.DepositInput
+		err = rows.Scan(&row.DateTime, &row.Amount, &row.Asset)
+		if err != nil {
+			tracing.Logger(ctx, s.logger).Error("failed to scan selectRecentDepositsQuery", zap.Error(err))
+
+			return nil, err
+		}
+
+		res = append(res, &row)
+	}
+
+	return res, rows.Err()
+}
+
+func scanAnomaly(row interface{ Scan(...any) error }) (*anymind.Anomaly, error) {
+	var anomaly anymind.Anomaly
+	var keyID sql.NullInt64
+	var subject sql.NullString
+	var reviewedAt sql.NullTime
+	var depositID sql.NullInt64
+	err := row.Scan(
+		&anomaly.ID,
+		&anomaly.DateTime,
+		&anomaly.Amount,
+		&anomaly.Asset,
+		&anomaly.Reason,
+		&anomaly.Score,
+		&anomaly.Status,
+		&keyID,
+		&subject,
+		&anomaly.CreatedAt,
+		&reviewedAt,
+		&anomaly.DepositStatus,
+		&depositID)
+	if err != nil {
+		return nil, err
+	}
+
+	anomaly.KeyID, anomaly.Subject = keyID.Int64, subject.String
+	anomaly.DepositID = depositID.Int64
+	if reviewedAt.Valid {
+		anomaly.ReviewedAt = &reviewedAt.Time
+	}
+
+	return &anomaly, nil
+}
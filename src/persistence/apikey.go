@@ -0,0 +1,124 @@
+package persistence
+
+import (
+	"anymind"
+	"anymind/src/tracing"
+	"context"
+	"database/sql"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+	"time"
+)
+
+var _ anymind.APIKeyPersistenceService = &Service{}
+
+func (s Service) CreateAPIKey(ctx context.Context, key *anymind.APIKey) error {
+	createdAt := time.Now().UTC().Truncate(time.Microsecond)
+	err := s.audited(ctx, anymind.AuditAPIKeyCreate, func(tx *sql.Tx) (any, error) {
+		err := tx.QueryRowContext(ctx, insertAPIKeyQuery, key.Name, key.Prefix, key.Hash, key.Scopes, createdAt).
+			Scan(&key.ID)
+		if err != nil {
+			tracing.Logger(ctx, s.logger).Error("failed to execute insertAPIKeyQuery", zap.Error(err))
+
+			return nil, err
+		}
+
+		// key hash is left out, audit log is readable by every admin.
+		return map[string]any{"id": key.ID, "name": key.Name, "prefix": key.Prefix, "scopes": key.Scopes}, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	key.CreatedAt = createdAt
+
+	return nil
+}
+
+func (s Service) FindAPIKey(ctx context.Context, hash string) (*anymind.APIKey, error) {
+	rows, err := s.db.QueryContext(ctx, selectAPIKeyByHashQuery, hash)
+	if err != nil {
+		tracing.Logger(ctx, s.logger).Error("failed to execute selectAPIKeyByHashQuery", zap.Error(err))
+
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys, err := scanAPIKeys(rows)
+	if err != nil {
+		tracing.Logger(ctx, s.logger).Error("failed to scan selectAPIKeyByHashQuery", zap.Error(err))
+
+		return nil, err
+	}
+
+	if len(keys) == 0 {
+		return nil, anymind.ErrNotFound
+	}
+
+	return keys[0], nil
+}
+
+func (s Service) ListAPIKeys(ctx context.Context) ([]*anymind.APIKey, error) {
+	rows, err := s.db.QueryContext(ctx, selectAPIKeysQuery)
+	if err != nil {
+		tracing.Logger(ctx, s.logger).Error("failed to execute selectAPIKeysQuery", zap.Error(err))
+
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys, err := scanAPIKeys(rows)
+	if err != nil {
+		tracing.Logger(ctx, s.logger).Error("failed to scan selectAPIKeysQuery", zap.Error(err))
+
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+func (s Service) RevokeAPIKey(ctx context.Context, id int64, at time.Time) error {
+	return s.audited(ctx, anymind.AuditAPIKeyRevoke, func(tx *sql.Tx) (any, error) {
+		res, err := tx.ExecContext(ctx, revokeAPIKeyQuery, id, at.UTC())
+		if err != nil {
+			tracing.Logger(ctx, s.logger).Error("failed to execute revokeAPIKeyQuery", zap.Error(err))
+
+			return nil, err
+		}
+
+		return map[string]any{"id": id}, requireAffected(res)
+	})
+}
+
+func scanAPIKeys(rows *sql.Rows) ([]*anymind.APIKey, error) {
+	types := pgtype.NewMap()
+
+	var res []*anymind.APIKey
+	for rows.Next() {
+		var row anymind.APIKey
+		var revokedAt sql.NullTime
+		err := rows.Scan(
+			&row.ID,
+			&row.Name,
+			&row.Prefix,
+			&row.Hash,
+			types.SQLScanner(&row.Scopes),
+			&row.CreatedAt,
+			&revokedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		if revokedAt.Valid {
+			row.RevokedAt = &revokedAt.Time
+		}
+
+		res = append(res, &row)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
@@ -0,0 +1,83 @@
+package persistence
+
+import (
+	"anymind"
+	"anymind/src/tracing"
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+	"time"
+)
+
+var _ anymind.AssetService = &Service{}
+
+// sqlstate returned when insert conflict with unique constraint, or reference unknown row.
+const (
+	uniqueViolationCode     = "23505"
+	foreignKeyViolationCode = "23503"
+)
+
+func (s Service) Asset(ctx context.Context, code string) (*anymind.Asset, error) {
+	var res anymind.Asset
+	err := s.db.QueryRowContext(ctx, selectAssetQuery, code).Scan(&res.Code, &res.Scale, &res.DisplayPrecision, &res.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, anymind.ErrNotFound
+	}
+	if err != nil {
+		tracing.Logger(ctx, s.logger).Error("failed to execute selectAssetQuery", zap.Error(err))
+
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+func (s Service) ListAssets(ctx context.Context) ([]*anymind.Asset, error) {
+	rows, err := s.db.QueryContext(ctx, selectAssetsQuery)
+	if err != nil {
+		tracing.Logger(ctx, s.logger).Error("failed to execute selectAssetsQuery", zap.Error(err))
+
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []*anymind.Asset
+	for rows.Next() {
+		var row anymind.Asset
+		err = rows.Scan(&row.Code, &row.Scale, &row.DisplayPrecision, &row.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, &row)
+	}
+
+	return res, rows.Err()
+}
+
+func (s Service) CreateAsset(ctx context.Context, asset *anymind.Asset) error {
+	createdAt := time.Now().UTC().Truncate(time.Microsecond)
+	err := s.audited(ctx, anymind.AuditAssetCreate, func(tx *sql.Tx) (any, error) {
+		_, err := tx.ExecContext(ctx, insertAssetQuery, asset.Code, asset.Scale, asset.DisplayPrecision, createdAt)
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode {
+			return nil, anymind.ErrAlreadyExists
+		}
+		if err != nil {
+			tracing.Logger(ctx, s.logger).Error("failed to execute insertAssetQuery", zap.Error(err))
+
+			return nil, err
+		}
+
+		return map[string]any{"code": asset.Code, "scale": asset.Scale, "displayPrecision": asset.DisplayPrecision}, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	asset.CreatedAt = createdAt
+
+	return nil
+}
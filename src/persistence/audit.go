@@ -0,0 +1,185 @@
+package persistence
+
+import (
+	"anymind"
+	"anymind/src/tracing"
+	"context"
+	"database/sql"
+	"fmt"
+	"go.uber.org/zap"
+	"time"
+)
+
+var _ anymind.AuditService = &Service{}
+
+// auditPageSize is number of entries read at once by VerifyAudit.
+const auditPageSize = 1000
+
+// appendAudit chain entry to audit log within tx, so it is only kept when the change it describe is committed.
+func (s Service) appendAudit(ctx context.Context, tx *sql.Tx, action string, payload any, outcome string) error {
+	entry, err := anymind.NewAuditEntry(ctx, action, payload, outcome, time.Now())
+	if err != nil {
+		return err
+	}
+
+	qctx, done := s.query(ctx, "selectAuditHeadQuery")
+	err = tx.QueryRowContext(qctx, selectAuditHeadQuery).Scan(&entry.ID, &entry.PrevHash)
+	done(err)
+	if err != nil {
+		tracing.Logger(ctx, s.logger).Error("failed to execute selectAuditHeadQuery", zap.Error(err))
+
+		return err
+	}
+
+	entry.ID++
+	entry.Hash = entry.ChainHash()
+
+	qctx, done = s.query(ctx, "insertAuditQuery")
+	_, err = tx.ExecContext(qctx, insertAuditQuery,
+		entry.ID,
+		entry.At,
+		entry.Actor,
+		entry.ClientIP,
+		entry.RequestID,
+		entry.Action,
+		entry.PayloadHash,
+		entry.Outcome,
+		entry.PrevHash,
+		entry.Hash)
+	done(err)
+	if err != nil {
+		tracing.Logger(ctx, s.logger).Error("failed to execute insertAuditQuery", zap.Error(err))
+
+		return err
+	}
+
+	qctx, done = s.query(ctx, "updateAuditHeadQuery")
+	_, err = tx.ExecContext(qctx, updateAuditHeadQuery, entry.ID, entry.Hash)
+	done(err)
+	if err != nil {
+		tracing.Logger(ctx, s.logger).Error("failed to execute updateAuditHeadQuery", zap.Error(err))
+
+		return err
+	}
+
+	return nil
+}
+
+// audited run change and its audit entry in one transaction, change return the payload to audit.
+func (s Service) audited(ctx context.Context, action string, change func(tx *sql.Tx) (any, error)) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	payload, err := change(tx)
+	if err != nil {
+		return err
+	}
+
+	err = s.appendAudit(ctx, tx, action, payload, anymind.AuditSuccess)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s Service) RecordAudit(ctx context.Context, action string, payload any, outcome string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = s.appendAudit(ctx, tx, action, payload, outcome)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s Service) ListAudit(ctx context.Context, filter *anymind.AuditFilter) ([]*anymind.AuditEntry, error) {
+	// LIMIT NULL return every entry.
+	limit := sql.NullInt64{Int64: int64(filter.Limit), Valid: filter.Limit > 0}
+	rows, err := s.db.QueryContext(ctx, selectAuditQuery, filter.AfterID, filter.Actor, filter.Action, limit)
+	if err != nil {
+		tracing.Logger(ctx, s.logger).Error("failed to execute selectAuditQuery", zap.Error(err))
+
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []*anymind.AuditEntry
+	for rows.Next() {
+		var row anymind.AuditEntry
+		err = rows.Scan(
+			&row.ID,
+			&row.At,
+			&row.Actor,
+			&row.ClientIP,
+			&row.RequestID,
+			&row.Action,
+			&row.PayloadHash,
+			&row.Outcome,
+			&row.PrevHash,
+			&row.Hash)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, &row)
+	}
+
+	return res, rows.Err()
+}
+
+// VerifyAudit walk the whole chain and report first entry that was altered, removed or inserted.
+// It return number of verified entries.
+func (s Service) VerifyAudit(ctx context.Context) (int, error) {
+	var verified int
+	var prev anymind.AuditEntry
+	for {
+		page, err := s.ListAudit(ctx, &anymind.AuditFilter{AfterID: prev.ID, Limit: auditPageSize})
+		if err != nil {
+			return verified, err
+		}
+
+		for _, entry := range page {
+			switch {
+			case entry.ID != prev.ID+1:
+				return verified, fmt.Errorf("audit entry %d is missing", prev.ID+1)
+			case entry.PrevHash != prev.Hash:
+				return verified, fmt.Errorf("audit entry %d is not linked to entry %d", entry.ID, prev.ID)
+			case entry.Hash != entry.ChainHash():
+				return verified, fmt.Errorf("audit entry %d was altered", entry.ID)
+			}
+
+			prev = *entry
+			verified++
+		}
+
+		if len(page) < auditPageSize {
+			break
+		}
+	}
+
+	var headID int64
+	var headHash string
+	qctx, done := s.query(ctx, "selectAuditTipQuery")
+	err := s.db.QueryRowContext(qctx, selectAuditTipQuery).Scan(&headID, &headHash)
+	done(err)
+	if err != nil {
+		tracing.Logger(ctx, s.logger).Error("failed to execute selectAuditTipQuery", zap.Error(err))
+
+		return verified, err
+	}
+
+	if headID != prev.ID || headHash != prev.Hash {
+		return verified, fmt.Errorf("audit log end at entry %d but head is entry %d", prev.ID, headID)
+	}
+
+	return verified, nil
+}
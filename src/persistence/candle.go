@@ -0,0 +1,83 @@
+package persistence
+
+import (
+	"anymind"
+	"anymind/src/tracing"
+	"context"
+	"database/sql"
+	"github.com/cockroachdb/apd"
+	"go.uber.org/zap"
+)
+
+var _ anymind.CandleService = &Service{}
+
+// Candles derive candles from running sum over deposit_histories within range, so high and low hold for negative
+// amounts too. Opening balance is read from deposit_hourly, candle granularity is whole hours.
+func (s Service) Candles(ctx context.Context, req *anymind.CandleReq) ([]*anymind.Candle, error) {
+	// opening balance and movements are read from the same snapshot.
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable, ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Commit()
+
+	asset := anymind.AssetCode(req.Asset)
+	from := anymind.CandleOf(req.Start, req.Granularity).Add(-req.Granularity)
+	last := anymind.CandleOf(req.End, req.Granularity)
+	if last.After(req.End) {
+		last = last.Add(-req.Granularity)
+	}
+
+	var open apd.Decimal
+	err = s.balanceAt(ctx, tx, from, asset, &open)
+	if err != nil {
+		return nil, err
+	}
+
+	qctx, done := s.query(ctx, "selectCandlesQuery")
+	rows, err := tx.QueryContext(qctx, selectCandlesQuery, from, last, req.Granularity.Seconds(), asset)
+	done(err)
+	if err != nil {
+		tracing.Logger(ctx, s.logger).Error("failed to execute selectCandlesQuery", zap.Error(err))
+
+		return nil, err
+	}
+	defer rows.Close()
+
+	// running sum of the query start from zero, candles are shifted by opening balance.
+	var candles []*anymind.Candle
+	var sum apd.Decimal
+	for rows.Next() {
+		var delta, low, high apd.Decimal
+		candle := &anymind.Candle{}
+		err = rows.Scan(&candle.DateTime, &delta, &low, &high)
+		if err != nil {
+			tracing.Logger(ctx, s.logger).Error("failed to scan selectCandlesQuery", zap.Error(err))
+
+			return nil, err
+		}
+
+		// precision of base context is unlimited, sums are exact.
+		_, err = apd.BaseContext.Add(&sum, &sum, &delta)
+		if err == nil {
+			_, err = apd.BaseContext.Add(&candle.Close, &open, &sum)
+		}
+		if err == nil {
+			_, err = apd.BaseContext.Add(&candle.Low, &open, &low)
+		}
+		if err == nil {
+			_, err = apd.BaseContext.Add(&candle.High, &open, &high)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		candles = append(candles, candle)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return anymind.FillCandles(req, open, candles), nil
+}
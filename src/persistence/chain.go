@@ -0,0 +1,296 @@
+package persistence
+
+import (
+	"anymind"
+	"anymind/src/tracing"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"time"
+)
+
+var _ anymind.ChainPersistenceService = &Service{}
+
+// chainPageSize is number of deposits read at once when walking the chain.
+const chainPageSize = 1000
+
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// depositRecords return deposits read by query, query select every DepositRecord field but Hash last.
+func depositRecords(ctx context.Context, db queryer, query string, args ...any) ([]*anymind.DepositRecord, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []*anymind.DepositRecord
+	for rows.Next() {
+		var row anymind.DepositRecord
+		err = rows.Scan(&row.Seq, &row.StatusChange, &row.ID, &row.DateTime, &row.Amount, &row.Asset, &row.KeyID, &row.Subject, &row.Status,
+			&row.PrevHash, &row.Hash)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, &row)
+	}
+
+	return res, rows.Err()
+}
+
+// sealDepositChain chain deposits made before deposits were hashed.
+func sealDepositChain(ctx context.Context, tx *sql.Tx) error {
+	var prevHash string
+	var afterID int64
+	for {
+		// page is read whole before update, connection can not run query while rows are open.
+		page, err := depositRecords(ctx, tx, selectUnsealedDepositsQuery, afterID, chainPageSize)
+		if err != nil {
+			return err
+		}
+
+		for _, row := range page {
+			row.PrevHash = prevHash
+			row.Hash = row.ChainHash()
+			_, err = tx.ExecContext(ctx, updateSealedDepositQuery, row.ID, row.PrevHash, row.Hash)
+			if err != nil {
+				return err
+			}
+
+			prevHash, afterID = row.Hash, row.ID
+		}
+
+		if len(page) < chainPageSize {
+			return nil
+		}
+	}
+}
+
+// linkDeposit link stored deposit or status change to chain head and make it the head. Head row is locked, so
+// concurrent serializable transactions linking to the same head conflict and one of them is retried, chain never fork.
+func linkDeposit(ctx context.Context, tx *sql.Tx, row *anymind.DepositRecord) error {
+	var head anymind.DepositRecord
+	err := tx.QueryRowContext(ctx, selectDepositChainHeadQuery).Scan(&head.Seq, &head.ID, &head.Hash)
+	if err != nil {
+		return err
+	}
+
+	row.Seq, row.PrevHash = head.Seq+1, head.Hash
+	row.Hash = row.ChainHash()
+
+	if row.StatusChange {
+		_, err = tx.ExecContext(ctx, insertDepositStatusChangeQuery, row.ID, row.Status, row.DateTime, row.KeyID,
+			row.Subject, row.Seq, row.PrevHash, row.Hash)
+	} else {
+		_, err = tx.ExecContext(ctx, updateDepositHashQuery, row.ID, row.Seq, row.PrevHash, row.Hash)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, updateDepositChainHeadQuery, row.Seq, row.ID, row.Hash)
+
+	return err
+}
+
+// chainDeposit link deposit id stored in tx to chain, hash cover deposit as stored.
+func (s Service) chainDeposit(ctx context.Context, tx *sql.Tx, id int64) error {
+	qctx, done := s.query(ctx, "linkDeposit")
+	rows, err := depositRecords(qctx, tx, selectDepositRecordQuery, id)
+	if err == nil && len(rows) != 1 {
+		err = fmt.Errorf("deposit %d is not stored", id)
+	}
+	if err == nil {
+		err = linkDeposit(qctx, tx, rows[0])
+	}
+	done(err)
+	if err != nil {
+		tracing.Logger(ctx, s.logger).Error("failed to link deposit to chain", zap.Error(err))
+
+		return err
+	}
+
+	return nil
+}
+
+// chainStatusChange link status change of deposit id made in tx by principal of ctx to chain.
+func (s Service) chainStatusChange(ctx context.Context, tx *sql.Tx, id int64, status string) error {
+	keyID, subject := principalColumns(ctx)
+	row := &anymind.DepositRecord{
+		StatusChange: true,
+		ID:           id,
+		DateTime:     time.Now().UTC().Truncate(time.Microsecond),
+		KeyID:        keyID.Int64,
+		Subject:      subject.String,
+		Status:       status,
+	}
+
+	qctx, done := s.query(ctx, "linkStatusChange")
+	err := linkDeposit(qctx, tx, row)
+	done(err)
+	if err != nil {
+		tracing.Logger(ctx, s.logger).Error("failed to link status change to chain", zap.Error(err))
+
+		return err
+	}
+
+	return nil
+}
+
+// chainLeftoverDeposits chain deposits earlier versions left unchained, pending ones or ones committed right before a
+// crash, in id order.
+func chainLeftoverDeposits(ctx context.Context, tx *sql.Tx) error {
+	for {
+		page, err := depositRecords(ctx, tx, selectUnchainedDepositsQuery, chainPageSize)
+		if err != nil {
+			return err
+		}
+
+		for _, row := range page {
+			err = linkDeposit(ctx, tx, row)
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(page) < chainPageSize {
+			return nil
+		}
+	}
+}
+
+func (s Service) DepositChainHead(ctx context.Context) (int64, string, error) {
+	var id int64
+	var hash string
+	qctx, done := s.query(ctx, "selectDepositChainTipQuery")
+	err := s.db.QueryRowContext(qctx, selectDepositChainTipQuery).Scan(&id, &hash)
+	done(err)
+	if err != nil {
+		tracing.Logger(ctx, s.logger).Error("failed to execute selectDepositChainTipQuery", zap.Error(err))
+
+		return 0, "", err
+	}
+
+	return id, hash, nil
+}
+
+func (s Service) SaveChainCheckpoint(ctx context.Context, checkpoint *anymind.ChainCheckpoint) error {
+	err := s.db.QueryRowContext(ctx, insertChainCheckpointQuery,
+		checkpoint.At.UTC(),
+		checkpoint.LastID,
+		checkpoint.HeadHash,
+		checkpoint.Signature).Scan(&checkpoint.ID)
+	if err != nil {
+		tracing.Logger(ctx, s.logger).Error("failed to execute insertChainCheckpointQuery", zap.Error(err))
+
+		return err
+	}
+
+	return nil
+}
+
+func (s Service) ListChainCheckpoints(ctx context.Context) ([]*anymind.ChainCheckpoint, error) {
+	rows, err := s.db.QueryContext(ctx, selectChainCheckpointsQuery)
+	if err != nil {
+		tracing.Logger(ctx, s.logger).Error("failed to execute selectChainCheckpointsQuery", zap.Error(err))
+
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []*anymind.ChainCheckpoint
+	for rows.Next() {
+		var row anymind.ChainCheckpoint
+		err = rows.Scan(&row.ID, &row.At, &row.LastID, &row.HeadHash, &row.Signature)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, &row)
+	}
+
+	return res, rows.Err()
+}
+
+// VerifyDepositChain stop at first broken link. Deposit removed at the end of chain is only detected by checkpoint,
+// status changed outside of chain is detected once every link is verified.
+func (s Service) VerifyDepositChain(ctx context.Context, checkpoints []*anymind.ChainCheckpoint) (*anymind.ChainReport, error) {
+	report := &anymind.ChainReport{Checkpoints: len(checkpoints)}
+	// chain is walked by seq, checkpoint match a link of its deposit by hash as deposit may have several links.
+	type link struct {
+		id   int64
+		hash string
+	}
+	unmatched := map[link]bool{}
+	for _, checkpoint := range checkpoints {
+		unmatched[link{checkpoint.LastID, checkpoint.HeadHash}] = true
+	}
+	seen := map[int64]bool{}
+
+	var prev anymind.DepositRecord
+	for {
+		page, err := depositRecords(ctx, s.db, selectDepositChainQuery, prev.Seq, chainPageSize)
+		if err != nil {
+			tracing.Logger(ctx, s.logger).Error("failed to execute selectDepositChainQuery", zap.Error(err))
+
+			return nil, err
+		}
+
+		for _, row := range page {
+			switch {
+			case row.PrevHash != prev.Hash:
+				report.Break = &anymind.ChainBreak{ID: row.ID, Reason: fmt.Sprintf("not linked to deposit %d", prev.ID)}
+			case row.Hash != row.ChainHash():
+				report.Break = &anymind.ChainBreak{ID: row.ID, Reason: "content does not match hash"}
+			}
+			if report.Break != nil {
+				return report, nil
+			}
+
+			delete(unmatched, link{row.ID, row.Hash})
+			seen[row.ID] = true
+
+			prev = *row
+			report.Verified++
+			report.HeadID, report.HeadHash = row.ID, row.Hash
+		}
+
+		if len(page) < chainPageSize {
+			break
+		}
+	}
+
+	// checkpoint of deposit that was rewritten or removed.
+	for _, checkpoint := range checkpoints {
+		if !unmatched[link{checkpoint.LastID, checkpoint.HeadHash}] {
+			continue
+		}
+
+		reason := fmt.Sprintf("deposit %d of checkpoint is missing", checkpoint.LastID)
+		if seen[checkpoint.LastID] {
+			reason = fmt.Sprintf("deposit %d differ from checkpoint", checkpoint.LastID)
+		}
+		report.Break = &anymind.ChainBreak{ID: checkpoint.ID, Checkpoint: true, Reason: reason}
+
+		return report, nil
+	}
+
+	var id int64
+	err := s.db.QueryRowContext(ctx, selectUnchainedStatusQuery).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return report, nil
+	}
+	if err != nil {
+		tracing.Logger(ctx, s.logger).Error("failed to execute selectUnchainedStatusQuery", zap.Error(err))
+
+		return nil, err
+	}
+	report.Break = &anymind.ChainBreak{ID: id, Reason: "status does not match chain"}
+
+	return report, nil
+}
@@ -0,0 +1,111 @@
+package persistence
+
+import (
+	"anymind"
+	"anymind/src/tracing"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/cockroachdb/apd"
+	"go.uber.org/zap"
+	"time"
+)
+
+// DepositChannel is postgres notification channel used to publish committed deposit.
+const DepositChannel = "deposit_events"
+
+// feedLockKey is advisory lock key serializing feed positions, see Service.apply.
+const feedLockKey = 7209418
+
+type depositEventPayload struct {
+	ID       int64     `json:"id"`
+	DateTime time.Time `json:"datetime"`
+	Amount   string    `json:"amount"`
+	Asset    string    `json:"asset"`
+	Seq      int64     `json:"seq,omitempty"`
+}
+
+func encodeDepositEvent(ev *anymind.DepositEvent) (string, error) {
+	payload, err := json.Marshal(&depositEventPayload{
+		ID:       ev.ID,
+		DateTime: ev.DateTime.UTC(),
+		Amount:   fmt.Sprintf("%f", &ev.Amount),
+		Asset:    anymind.AssetCode(ev.Asset),
+		Seq:      ev.Seq,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return string(payload), nil
+}
+
+// DecodeDepositEvent parse notification payload sent on DepositChannel.
+func DecodeDepositEvent(payload string) (*anymind.DepositEvent, error) {
+	var p depositEventPayload
+	err := json.Unmarshal([]byte(payload), &p)
+	if err != nil {
+		return nil, err
+	}
+
+	amount, _, err := apd.NewFromString(p.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	return &anymind.DepositEvent{
+		ID:       p.ID,
+		DateTime: p.DateTime,
+		Amount:   *amount,
+		// payload published before assets were introduced has no asset, nor seq before feed_seq.
+		Asset: anymind.AssetCode(p.Asset),
+		Seq:   p.Seq,
+	}, nil
+}
+
+// LatestDepositSeq return seq of the last confirmed deposit, or 0 when there is none.
+func (s Service) LatestDepositSeq(ctx context.Context) (int64, error) {
+	var seq int64
+	err := s.db.QueryRowContext(ctx, selectLatestFeedSeqQuery).Scan(&seq)
+	if err != nil {
+		tracing.Logger(ctx, s.logger).Error("failed to execute selectLatestFeedSeqQuery", zap.Error(err))
+
+		return 0, err
+	}
+
+	return seq, nil
+}
+
+// DepositsAfter return at most limit confirmed deposits with seq greater than afterSeq in confirmation order, so
+// a pending deposit confirmed after newer ones is not skipped.
+func (s Service) DepositsAfter(ctx context.Context, afterSeq int64, limit int) ([]*anymind.DepositEvent, error) {
+	rows, err := s.db.QueryContext(ctx, selectHistoriesAfterQuery, afterSeq, limit)
+	if err != nil {
+		tracing.Logger(ctx, s.logger).Error("failed to execute selectHistoriesAfterQuery", zap.Error(err))
+
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []*anymind.DepositEvent
+	for rows.Next() {
+		var row anymind.DepositEvent
+		err = rows.Scan(&row.ID, &row.DateTime, &row.Amount, &row.Asset, &row.Seq)
+		if err != nil {
+			tracing.Logger(ctx, s.logger).Error("failed to scan selectHistoriesAfterQuery", zap.Error(err))
+
+			return nil, err
+		}
+
+		res = append(res, &row)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		tracing.Logger(ctx, s.logger).Error("error on next selectHistoriesAfterQuery", zap.Error(err))
+
+		return nil, err
+	}
+
+	return res, nil
+}
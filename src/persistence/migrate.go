@@ -0,0 +1,153 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// SchemaVersion is version of schema expected by this build, it is number of applied SchemaUp statements.
+var SchemaVersion = len(SchemaUp)
+
+const undefinedTableCode = "42P01"
+
+const createSchemaVersionQuery = `
+  CREATE TABLE IF NOT EXISTS schema_version (
+    version INT NOT NULL
+  )`
+
+// lockSchemaVersionQuery serialize migration run by several replicas at once.
+const lockSchemaVersionQuery = `
+  LOCK TABLE schema_version IN EXCLUSIVE MODE`
+
+const selectSchemaVersionQuery = `
+  SELECT version FROM schema_version`
+
+const insertSchemaVersionQuery = `
+  INSERT INTO schema_version (version) VALUES ($1)`
+
+const updateSchemaVersionQuery = `
+  UPDATE schema_version SET version = $1`
+
+// migrationHooks update data that can not be updated in sql, they run right after migration of their version.
+var migrationHooks = map[int]func(ctx context.Context, tx *sql.Tx) error{
+	16: sealDepositChain,
+	45: chainLeftoverDeposits,
+}
+
+// Migrate apply SchemaUp statements that are not applied yet, in a single transaction.
+// It return version before migration.
+func Migrate(ctx context.Context, db *sql.DB) (int, error) {
+	return setVersion(ctx, db, func(tx *sql.Tx, version int) (int, error) {
+		if version > SchemaVersion {
+			return 0, fmt.Errorf("database schema version %d is newer than %d known by this build", version, SchemaVersion)
+		}
+
+		for i := version; i < SchemaVersion; i++ {
+			_, err := tx.ExecContext(ctx, SchemaUp[i])
+			if err != nil {
+				return 0, fmt.Errorf("migration %d: %w", i+1, err)
+			}
+
+			if hook, ok := migrationHooks[i+1]; ok {
+				err = hook(ctx, tx)
+				if err != nil {
+					return 0, fmt.Errorf("migration %d: %w", i+1, err)
+				}
+			}
+		}
+
+		return SchemaVersion, nil
+	})
+}
+
+// Baseline record schema as migrated up to version without running any statement,
+// it is meant for database whose schema was created before versioning.
+func Baseline(ctx context.Context, db *sql.DB, version int) error {
+	if version < 0 || version > SchemaVersion {
+		return fmt.Errorf("baseline version must be between 0 and %d", SchemaVersion)
+	}
+
+	_, err := setVersion(ctx, db, func(_ *sql.Tx, _ int) (int, error) {
+		return version, nil
+	})
+
+	return err
+}
+
+func setVersion(ctx context.Context, db *sql.DB, apply func(tx *sql.Tx, version int) (int, error)) (int, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, createSchemaVersionQuery)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.ExecContext(ctx, lockSchemaVersionQuery)
+	if err != nil {
+		return 0, err
+	}
+
+	var version int
+	exists := true
+	err = tx.QueryRowContext(ctx, selectSchemaVersionQuery).Scan(&version)
+	if errors.Is(err, sql.ErrNoRows) {
+		exists = false
+	} else if err != nil {
+		return 0, err
+	}
+
+	next, err := apply(tx, version)
+	if err != nil {
+		return 0, err
+	}
+
+	if exists {
+		_, err = tx.ExecContext(ctx, updateSchemaVersionQuery, next)
+	} else {
+		_, err = tx.ExecContext(ctx, insertSchemaVersionQuery, next)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return version, tx.Commit()
+}
+
+// CurrentSchemaVersion return version of database schema, it is 0 when schema was never migrated.
+func (s Service) CurrentSchemaVersion(ctx context.Context) (int, error) {
+	var version int
+	err := s.db.QueryRowContext(ctx, selectSchemaVersionQuery).Scan(&version)
+
+	var pgErr *pgconn.PgError
+	if errors.Is(err, sql.ErrNoRows) || (errors.As(err, &pgErr) && pgErr.Code == undefinedTableCode) {
+		return 0, nil
+	}
+
+	return version, err
+}
+
+// CheckHealth verify database is reachable and its schema match this build.
+func (s Service) CheckHealth(ctx context.Context) error {
+	err := s.db.PingContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	version, err := s.CurrentSchemaVersion(ctx)
+	if err != nil {
+		return err
+	}
+
+	if version != SchemaVersion {
+		return fmt.Errorf("schema version is %d, expected %d", version, SchemaVersion)
+	}
+
+	return nil
+}
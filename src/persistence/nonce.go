@@ -0,0 +1,44 @@
+package persistence
+
+import (
+	"anymind"
+	"anymind/src/tracing"
+	"context"
+	"database/sql"
+	"errors"
+	"go.uber.org/zap"
+	"time"
+)
+
+var _ anymind.NonceStore = &Service{}
+
+// ClaimNonce record nonce with a single statement, so only one of concurrent requests with the same nonce claim it.
+func (s Service) ClaimNonce(ctx context.Context, keyID, nonce string, now, expiresAt time.Time) (bool, error) {
+	var claimed string
+	qctx, done := s.query(ctx, "claimNonceQuery")
+	err := s.db.QueryRowContext(qctx, claimNonceQuery, keyID, nonce, expiresAt.UTC(), now.UTC()).Scan(&claimed)
+	done(err)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		tracing.Logger(ctx, s.logger).Error("failed to execute claimNonceQuery", zap.Error(err))
+
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (s Service) PruneNonces(ctx context.Context, before time.Time) error {
+	qctx, done := s.query(ctx, "pruneNoncesQuery")
+	_, err := s.db.ExecContext(qctx, pruneNoncesQuery, before.UTC())
+	done(err)
+	if err != nil {
+		tracing.Logger(ctx, s.logger).Error("failed to execute pruneNoncesQuery", zap.Error(err))
+
+		return err
+	}
+
+	return nil
+}
@@ -1,6 +1,9 @@
 package persistence
 
-import "go.uber.org/zap"
+import (
+	"github.com/go-kit/kit/metrics"
+	"go.uber.org/zap"
+)
 
 type Option func(*Service)
 
@@ -9,3 +12,24 @@ func WithLogger(logger *zap.Logger) Option {
 		svc.logger = logger
 	}
 }
+
+// WithSerializationRetries set how many times deposit is retried after serialization failure.
+func WithSerializationRetries(retries int) Option {
+	return func(svc *Service) {
+		svc.SetSerializationRetries(retries)
+	}
+}
+
+// WithQueryDuration observe latency of every query, labeled by query name.
+func WithQueryDuration(h metrics.Histogram) Option {
+	return func(svc *Service) {
+		svc.queryDuration = h
+	}
+}
+
+// WithRetryCounter count transaction retried after serialization failure, labeled by operation.
+func WithRetryCounter(c metrics.Counter) Option {
+	return func(svc *Service) {
+		svc.retries = c
+	}
+}
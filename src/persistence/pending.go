@@ -0,0 +1,171 @@
+package persistence
+
+import (
+	"anymind"
+	"anymind/src/tracing"
+	"context"
+	"database/sql"
+	"errors"
+	"go.uber.org/zap"
+	"time"
+)
+
+var _ anymind.DepositStatusService = &Service{}
+
+// SetDepositStatus settle pending deposit, confirmed deposit is applied to balance at its original datetime like
+// any back-dated deposit.
+func (s Service) SetDepositStatus(ctx context.Context, id int64, status string) (res *anymind.DepositEvent, err error) {
+	err = s.retrySerializable(ctx, "deposit_status", func() error {
+		res, err = s.setDepositStatus(ctx, id, status)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (s Service) setDepositStatus(ctx context.Context, id int64, status string) (*anymind.DepositEvent, error) {
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// deposit is settled by the principal that made it, or by an admin.
+	keyID, subject := principalColumns(ctx)
+	p, ok := anymind.PrincipalFromContext(ctx)
+	admin := ok && p.HasScope(anymind.ScopeAdmin)
+
+	var event anymind.DepositEvent
+	qctx, done := s.query(ctx, "updateDepositStatusQuery")
+	err = tx.QueryRowContext(qctx, updateDepositStatusQuery, id, status, admin, keyID, subject).
+		Scan(&event.ID, &event.DateTime, &event.Amount, &event.Asset)
+	done(err)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, anymind.ErrNotFound
+	}
+	if err != nil {
+		tracing.Logger(ctx, s.logger).Error("failed to execute updateDepositStatusQuery", zap.Error(err))
+
+		return nil, err
+	}
+
+	err = s.chainStatusChange(ctx, tx, id, status)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.appendAudit(ctx, tx, anymind.AuditDepositStatus, map[string]any{"id": id, "status": status}, anymind.AuditSuccess)
+	if err != nil {
+		return nil, err
+	}
+
+	if status == anymind.DepositConfirmed {
+		err = s.apply(ctx, tx, &event)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	_, done = s.query(ctx, "commit")
+	err = tx.Commit()
+	done(err)
+	if err != nil {
+		return nil, err
+	}
+
+	return &event, nil
+}
+
+// ExpirePendingDeposits fail deposits pending since before, expiry is audited only when a deposit was failed.
+func (s Service) ExpirePendingDeposits(ctx context.Context, before time.Time) (int64, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	qctx, done := s.query(ctx, "expirePendingQuery")
+	rows, err := tx.QueryContext(qctx, expirePendingQuery, before.UTC())
+	done(err)
+	if err != nil {
+		tracing.Logger(ctx, s.logger).Error("failed to execute expirePendingQuery", zap.Error(err))
+
+		return 0, err
+	}
+	defer rows.Close()
+
+	ids := []int64{}
+	for rows.Next() {
+		var id int64
+		err = rows.Scan(&id)
+		if err != nil {
+			tracing.Logger(ctx, s.logger).Error("failed to scan expirePendingQuery", zap.Error(err))
+
+			return 0, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	if err = rows.Err(); err != nil {
+		tracing.Logger(ctx, s.logger).Error("error on next expirePendingQuery", zap.Error(err))
+
+		return 0, err
+	}
+
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	for _, id := range ids {
+		err = s.chainStatusChange(ctx, tx, id, anymind.DepositFailed)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	err = s.appendAudit(ctx, tx, anymind.AuditDepositExpire, map[string]any{"ids": ids}, anymind.AuditSuccess)
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(len(ids)), tx.Commit()
+}
+
+// pendingHourly return net pending amount per hourly bucket up to req.End, see anymind.AddPending.
+func (s Service) pendingHourly(ctx context.Context, tx *sql.Tx, req *anymind.HistoricalDataReq) ([]*anymind.HistoricalData, error) {
+	qctx, done := s.query(ctx, "selectPendingHourlyQuery")
+	rows, err := tx.QueryContext(qctx, selectPendingHourlyQuery, req.End, anymind.AssetCode(req.Asset))
+	done(err)
+	if err != nil {
+		tracing.Logger(ctx, s.logger).Error("failed to execute selectPendingHourlyQuery", zap.Error(err))
+
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []*anymind.HistoricalData
+	for rows.Next() {
+		var row anymind.HistoricalData
+		err = rows.Scan(&row.DateTime, &row.Amount)
+		if err != nil {
+			tracing.Logger(ctx, s.logger).Error("failed to scan selectPendingHourlyQuery", zap.Error(err))
+
+			return nil, err
+		}
+
+		res = append(res, &row)
+	}
+
+	if err = rows.Err(); err != nil {
+		tracing.Logger(ctx, s.logger).Error("error on next selectPendingHourlyQuery", zap.Error(err))
+
+		return nil, err
+	}
+
+	return res, nil
+}
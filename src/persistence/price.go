@@ -0,0 +1,70 @@
+package persistence
+
+import (
+	"anymind"
+	"anymind/src/tracing"
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+	"time"
+)
+
+var _ anymind.PriceService = &Service{}
+
+func (s Service) ImportRates(ctx context.Context, rates []*anymind.Rate) error {
+	return s.audited(ctx, anymind.AuditRatesImport, func(tx *sql.Tx) (any, error) {
+		for _, rate := range rates {
+			qctx, done := s.query(ctx, "upsertRateQuery")
+			_, err := tx.ExecContext(qctx, upsertRateQuery, rate.Base, rate.Quote, rate.Hour.UTC(), &rate.Rate)
+			done(err)
+
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolationCode {
+				return nil, anymind.ErrNotFound
+			}
+			if err != nil {
+				tracing.Logger(ctx, s.logger).Error("failed to execute upsertRateQuery", zap.Error(err))
+
+				return nil, err
+			}
+		}
+
+		return anymind.RatesAuditPayload(rates), nil
+	})
+}
+
+func (s Service) Rates(
+	ctx context.Context,
+	base string,
+	quote string,
+	start time.Time,
+	end time.Time,
+) ([]*anymind.Rate, error) {
+	qctx, done := s.query(ctx, "selectRatesQuery")
+	rows, err := s.db.QueryContext(qctx, selectRatesQuery, base, quote, start.UTC(), end.UTC())
+	done(err)
+	if err != nil {
+		tracing.Logger(ctx, s.logger).Error("failed to execute selectRatesQuery", zap.Error(err))
+
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []*anymind.Rate
+	for rows.Next() {
+		var row anymind.Rate
+		err = rows.Scan(&row.Base, &row.Quote, &row.Hour, &row.Rate)
+		if err != nil {
+			tracing.Logger(ctx, s.logger).Error("failed to scan selectRatesQuery", zap.Error(err))
+
+			return nil, err
+		}
+
+		row.Hour = row.Hour.UTC()
+		res = append(res, &row)
+	}
+
+	return res, rows.Err()
+}
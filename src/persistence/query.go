@@ -1,35 +1,404 @@
 package persistence
 
 const insertHistoriesQuery = `
-  INSERT INTO deposit_histories (ts, amount)
-    VALUES ($1, $2)`
+  INSERT INTO deposit_histories (ts, amount, api_key_id, subject, asset, status, initial_status, created_at)
+    VALUES ($1, $2, $3, $4, $5, $6, $6, $7)
+    RETURNING id`
 
 const updatePostHourlyQuery = `
   UPDATE deposit_hourly
     SET amount = amount + $2
-    WHERE ts > date_trunc('hour', $1::timestamp - interval '1 second') + interval '1 hour'`
+    WHERE asset = $3
+      AND ts > date_trunc('hour', $1::timestamp - interval '1 second') + interval '1 hour'`
 
 const insertHourlyQuery = `
-  INSERT INTO deposit_hourly (ts, amount)
+  INSERT INTO deposit_hourly (asset, ts, amount)
     SELECT
+      $3,
       date_trunc('hour', $1::timestamp - interval '1 second') + interval '1 hour' AS ts,
       COALESCE((
         SELECT amount + $2
           FROM deposit_hourly
-          WHERE ts < date_trunc('hour', $1::timestamp - interval '1 second') + interval '1 hour'
+          WHERE asset = $3
+            AND ts < date_trunc('hour', $1::timestamp - interval '1 second') + interval '1 hour'
           ORDER BY ts DESC LIMIT 1
         ), $2)
-    ON CONFLICT (ts) DO UPDATE SET amount = deposit_hourly.amount + $2`
+    ON CONFLICT (asset, ts) DO UPDATE SET amount = deposit_hourly.amount + $2`
 
 const selectHourlyQuery = `
   (
     SELECT date_trunc('hour', $1::timestamp - interval '1 second') + interval '1 hour' AS ts, amount
 	FROM deposit_hourly
-	WHERE ts <= date_trunc('hour', $1::timestamp - interval '1 second') + interval '1 hour'
+	WHERE asset = $3
+	  AND ts <= date_trunc('hour', $1::timestamp - interval '1 second') + interval '1 hour'
 	ORDER BY ts DESC LIMIT 1
   ) UNION (
     SELECT ts, amount
     FROM deposit_hourly
-    WHERE ts > date_trunc('hour', $1::timestamp - interval '1 second') + interval '1 hour'
+    WHERE asset = $3
+      AND ts > date_trunc('hour', $1::timestamp - interval '1 second') + interval '1 hour'
       AND ts <= $2
   ) ORDER BY ts`
+
+const notifyDepositQuery = `
+  SELECT pg_notify($1, $2)`
+
+const selectLatestFeedSeqQuery = `
+  SELECT COALESCE(MAX(feed_seq), 0)
+    FROM deposit_histories`
+
+// selectHistoriesAfterQuery return deposits in confirmation order, only confirmed deposits have feed_seq.
+const selectHistoriesAfterQuery = `
+  SELECT id, ts, amount, asset, feed_seq
+    FROM deposit_histories
+    WHERE feed_seq > $1
+    ORDER BY feed_seq
+    LIMIT $2`
+
+// lockFeedQuery hold feed lock until transaction end, see Service.apply.
+const lockFeedQuery = `
+  SELECT pg_advisory_xact_lock($1)`
+
+const updateFeedSeqQuery = `
+  UPDATE deposit_histories
+    SET feed_seq = nextval('deposit_feed_seq')
+    WHERE id = $1
+    RETURNING feed_seq`
+
+const insertDepositOutboxQuery = `
+  INSERT INTO webhook_outbox (subscription_id, event_type, payload, next_attempt_at, created_at)
+    SELECT id, $1::text, $2::jsonb, $3, $3
+      FROM webhook_subscriptions
+      WHERE $1::text = ANY(events)`
+
+const insertThresholdOutboxQuery = `
+  INSERT INTO webhook_outbox (subscription_id, event_type, payload, next_attempt_at, created_at)
+    SELECT
+        s.id,
+        $1::text,
+        jsonb_build_object(
+          'depositId', $3::bigint,
+          'asset', $5::text,
+          'threshold', t.threshold::text,
+          'previousBalance', (b.balance - $2::numeric)::text,
+          'balance', b.balance::text),
+        $4,
+        $4
+      FROM webhook_subscriptions s
+        CROSS JOIN LATERAL unnest(s.thresholds) AS t(threshold)
+        CROSS JOIN (SELECT amount AS balance FROM deposit_hourly WHERE asset = $5 ORDER BY ts DESC LIMIT 1) b
+      WHERE $1::text = ANY(s.events)
+        AND s.asset = $5
+        AND t.threshold > b.balance - $2::numeric
+        AND t.threshold <= b.balance`
+
+const insertWebhookQuery = `
+  INSERT INTO webhook_subscriptions (url, events, secret, thresholds, created_at, asset)
+    VALUES ($1, $2::text[], $3, $4::text[]::numeric[], $5, $6)
+    RETURNING id`
+
+const selectWebhooksQuery = `
+  SELECT id, url, events, secret, thresholds::text[], created_at, asset
+    FROM webhook_subscriptions
+    ORDER BY id`
+
+const deleteWebhookQuery = `
+  DELETE FROM webhook_subscriptions
+    WHERE id = $1`
+
+const claimDeliveriesQuery = `
+  UPDATE webhook_outbox o
+    SET next_attempt_at = $2, attempts = o.attempts + 1
+    FROM webhook_subscriptions s
+    WHERE o.id IN (
+        SELECT id
+          FROM webhook_outbox
+          WHERE status = 'pending' AND next_attempt_at <= $1
+          ORDER BY next_attempt_at
+          LIMIT $3
+          FOR UPDATE SKIP LOCKED
+      )
+      AND s.id = o.subscription_id
+    RETURNING o.id, o.subscription_id, s.url, s.secret, o.event_type, o.payload::text, o.status,
+      o.attempts, o.next_attempt_at, o.last_error, o.created_at, o.delivered_at`
+
+const updateDeliveryQuery = `
+  UPDATE webhook_outbox
+    SET status = $2, next_attempt_at = $3, last_error = $4, delivered_at = $5
+    WHERE id = $1`
+
+const selectDeliveriesQuery = `
+  SELECT o.id, o.subscription_id, s.url, '', o.event_type, o.payload::text, o.status,
+      o.attempts, o.next_attempt_at, o.last_error, o.created_at, o.delivered_at
+    FROM webhook_outbox o
+      JOIN webhook_subscriptions s ON s.id = o.subscription_id
+    WHERE $1::text = '' OR o.status = $1::text
+    ORDER BY o.id DESC
+    LIMIT $2`
+
+const redeliverQuery = `
+  UPDATE webhook_outbox
+    SET status = 'pending', attempts = 0, next_attempt_at = $2, last_error = '', delivered_at = NULL
+    WHERE id = $1`
+
+const insertAPIKeyQuery = `
+  INSERT INTO api_keys (name, prefix, hash, scopes, created_at)
+    VALUES ($1, $2, $3, $4::text[], $5)
+    RETURNING id`
+
+const selectAPIKeyByHashQuery = `
+  SELECT id, name, prefix, hash, scopes, created_at, revoked_at
+    FROM api_keys
+    WHERE hash = $1`
+
+const selectAPIKeysQuery = `
+  SELECT id, name, prefix, hash, scopes, created_at, revoked_at
+    FROM api_keys
+    ORDER BY id`
+
+const revokeAPIKeyQuery = `
+  UPDATE api_keys
+    SET revoked_at = COALESCE(revoked_at, $2)
+    WHERE id = $1`
+
+const selectAuditHeadQuery = `
+  SELECT last_id, last_hash FROM audit_head FOR UPDATE`
+
+const selectAuditTipQuery = `
+  SELECT last_id, last_hash FROM audit_head`
+
+const updateAuditHeadQuery = `
+  UPDATE audit_head SET last_id = $1, last_hash = $2`
+
+const insertAuditQuery = `
+  INSERT INTO audit_log (id, at, actor, client_ip, request_id, action, payload_hash, outcome, prev_hash, hash)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+
+const selectAuditQuery = `
+  SELECT id, at, actor, client_ip, request_id, action, payload_hash, outcome, prev_hash, hash
+    FROM audit_log
+    WHERE id > $1
+      AND ($2::text = '' OR actor = $2::text)
+      AND ($3::text = '' OR action = $3::text)
+    ORDER BY id
+    LIMIT $4`
+
+const selectDepositChainHeadQuery = `
+  SELECT last_seq, last_id, last_hash FROM deposit_chain_head FOR UPDATE`
+
+const selectDepositChainTipQuery = `
+  SELECT last_id, last_hash FROM deposit_chain_head`
+
+const updateDepositChainHeadQuery = `
+  UPDATE deposit_chain_head SET last_seq = $1, last_id = $2, last_hash = $3`
+
+const updateDepositHashQuery = `
+  UPDATE deposit_histories SET chain_seq = $2, prev_hash = $3, hash = $4 WHERE id = $1`
+
+const insertDepositStatusChangeQuery = `
+  INSERT INTO deposit_status_changes (deposit_id, status, at, api_key_id, subject, chain_seq, prev_hash, hash)
+    VALUES ($1, $2, $3, NULLIF($4::bigint, 0), NULLIF($5::text, ''), $6, $7, $8)`
+
+const selectDepositRecordQuery = `
+  SELECT 0, FALSE, id, ts, amount, asset, COALESCE(api_key_id, 0), COALESCE(subject, ''), initial_status, '', ''
+    FROM deposit_histories
+    WHERE id = $1`
+
+const selectUnchainedDepositsQuery = `
+  SELECT 0, FALSE, id, ts, amount, asset, COALESCE(api_key_id, 0), COALESCE(subject, ''), initial_status, '', ''
+    FROM deposit_histories
+    WHERE chain_seq IS NULL
+    ORDER BY id
+    LIMIT $1`
+
+const selectDepositChainQuery = `
+  SELECT chain_seq, FALSE, id, ts, amount, asset, COALESCE(api_key_id, 0), COALESCE(subject, ''), initial_status,
+      prev_hash, hash
+    FROM deposit_histories
+    WHERE chain_seq > $1
+  UNION ALL
+  SELECT chain_seq, TRUE, deposit_id, at, 0, '', COALESCE(api_key_id, 0), COALESCE(subject, ''), status, prev_hash, hash
+    FROM deposit_status_changes
+    WHERE chain_seq > $1
+  ORDER BY chain_seq
+  LIMIT $2`
+
+// selectUnchainedStatusQuery return first chained deposit whose status is not the one its chain links end with.
+const selectUnchainedStatusQuery = `
+  SELECT h.id
+    FROM deposit_histories h
+    LEFT JOIN LATERAL (
+      SELECT c.status FROM deposit_status_changes c WHERE c.deposit_id = h.id ORDER BY c.chain_seq DESC LIMIT 1
+    ) c ON TRUE
+    WHERE h.chain_seq IS NOT NULL
+      AND h.status <> COALESCE(c.status, h.initial_status)
+    ORDER BY h.chain_seq
+    LIMIT 1`
+
+// selectUnsealedDepositsQuery and updateSealedDepositQuery run at schema version 16, see sealDepositChain,
+// they must only use columns existing then.
+const selectUnsealedDepositsQuery = `
+  SELECT 0, FALSE, id, ts, amount, 'BTC', COALESCE(api_key_id, 0), COALESCE(subject, ''), 'confirmed', '', ''
+    FROM deposit_histories
+    WHERE id > $1
+    ORDER BY id
+    LIMIT $2`
+
+const updateSealedDepositQuery = `
+  UPDATE deposit_histories SET prev_hash = $2, hash = $3 WHERE id = $1`
+
+const insertChainCheckpointQuery = `
+  INSERT INTO deposit_chain_checkpoints (at, last_id, head_hash, signature)
+    VALUES ($1, $2, $3, $4)
+    RETURNING id`
+
+const selectChainCheckpointsQuery = `
+  SELECT id, at, last_id, head_hash, signature
+    FROM deposit_chain_checkpoints
+    ORDER BY last_id, id`
+
+const selectAssetQuery = `
+  SELECT code, scale, display_precision, created_at
+    FROM assets
+    WHERE code = $1`
+
+const selectAssetsQuery = `
+  SELECT code, scale, display_precision, created_at
+    FROM assets
+    ORDER BY code`
+
+const insertAssetQuery = `
+  INSERT INTO assets (code, scale, display_precision, created_at)
+    VALUES ($1, $2, $3, $4)`
+
+const upsertRateQuery = `
+  INSERT INTO exchange_rates (base, quote, ts, rate)
+    VALUES ($1, $2, $3, $4)
+    ON CONFLICT (base, quote, ts) DO UPDATE SET rate = EXCLUDED.rate`
+
+const selectRatesQuery = `
+  SELECT base, quote, ts, rate
+    FROM exchange_rates
+    WHERE base = $1 AND quote = $2 AND ts BETWEEN $3 AND $4
+    ORDER BY ts`
+
+// selectBalanceAtQuery return balance at hour $1, deposit_hourly hold balance of every hour a deposit was made.
+const selectBalanceAtQuery = `
+  SELECT COALESCE((
+      SELECT amount
+        FROM deposit_hourly
+        WHERE asset = $2
+          AND ts <= $1
+        ORDER BY ts DESC LIMIT 1
+    ), 0)`
+
+const selectStatsQuery = `
+  SELECT
+      date_trunc('hour', ts - interval '1 second') + interval '1 hour' AS bucket,
+      COUNT(*),
+      SUM(amount),
+      MIN(amount),
+      MAX(amount),
+      ROUND(SUM(amount) / COUNT(*), (SELECT scale FROM assets WHERE code = $3))
+    FROM deposit_histories
+    WHERE asset = $3
+      AND status = 'confirmed'
+      AND ts > $1::timestamp - interval '1 hour'
+      AND ts <= $2
+    GROUP BY bucket
+    ORDER BY bucket`
+
+// selectCandlesQuery return net amount and lowest and highest running sum of every bucket of $3 seconds,
+// running sum start from zero at $1 and follow deposit order so intra bucket movement is seen.
+const selectCandlesQuery = `
+  SELECT bucket, SUM(amount), MIN(running), MAX(running)
+    FROM (
+      SELECT
+          to_timestamp(ceil(extract(epoch FROM ts)::float8 / $3::float8) * $3::float8) AT TIME ZONE 'UTC' AS bucket,
+          amount,
+          SUM(amount) OVER (ORDER BY ts, id) AS running
+        FROM deposit_histories
+        WHERE asset = $4
+          AND status = 'confirmed'
+          AND ts > $1
+          AND ts <= $2
+    ) moves
+    GROUP BY bucket
+    ORDER BY bucket`
+
+const insertAnomalyQuery = `
+  INSERT INTO deposit_anomalies (ts, amount, asset, reason, score, status, api_key_id, subject, created_at,
+      deposit_status, deposit_id)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+    RETURNING id`
+
+const selectAnomaliesQuery = `
+  SELECT id, ts, amount, asset, reason, score, status, api_key_id, subject, created_at, reviewed_at, deposit_status,
+      deposit_id
+    FROM deposit_anomalies
+    WHERE $1::text = '' OR status = $1::text
+    ORDER BY id DESC
+    LIMIT $2`
+
+const reviewAnomalyQuery = `
+  UPDATE deposit_anomalies
+    SET status = $2, reviewed_at = $3
+    WHERE id = $1
+      AND status = 'held'
+    RETURNING id, ts, amount, asset, reason, score, status, api_key_id, subject, created_at, reviewed_at, deposit_status,
+      deposit_id`
+
+const updateAnomalyDepositQuery = `
+  UPDATE deposit_anomalies SET deposit_id = $2 WHERE id = $1`
+
+// selectRecentDepositsQuery return up to $1 latest confirmed deposits of every asset, oldest first.
+const selectRecentDepositsQuery = `
+  SELECT recent.ts, recent.amount, recent.asset
+    FROM assets
+    CROSS JOIN LATERAL (
+      SELECT ts, amount, asset
+        FROM deposit_histories
+        WHERE asset = assets.code
+          AND status = 'confirmed'
+        ORDER BY ts DESC
+        LIMIT $1
+    ) recent
+    ORDER BY recent.asset, recent.ts`
+
+// selectPendingHourlyQuery return net pending amount of every hourly bucket up to bucket $1.
+const selectPendingHourlyQuery = `
+  SELECT date_trunc('hour', ts - interval '1 second') + interval '1 hour' AS bucket, SUM(amount)
+    FROM deposit_histories
+    WHERE asset = $2
+      AND status = 'pending'
+      AND ts <= date_trunc('hour', $1::timestamp)
+    GROUP BY bucket
+    ORDER BY bucket`
+
+// updateDepositStatusQuery only settle deposit made by principal $4 $5, any deposit when $3 is true.
+const updateDepositStatusQuery = `
+  UPDATE deposit_histories
+    SET status = $2
+    WHERE id = $1
+      AND status = 'pending'
+      AND ($3 OR (api_key_id IS NOT DISTINCT FROM $4 AND subject IS NOT DISTINCT FROM $5))
+    RETURNING id, ts, amount, asset`
+
+const expirePendingQuery = `
+  UPDATE deposit_histories
+    SET status = 'failed'
+    WHERE status = 'pending'
+      AND created_at < $1
+    RETURNING id`
+
+// claimNonceQuery return no row when nonce is already recorded and not expired at $4.
+const claimNonceQuery = `
+  INSERT INTO request_nonces (key_id, nonce, expires_at)
+    VALUES ($1, $2, $3)
+    ON CONFLICT (key_id, nonce) DO UPDATE
+      SET expires_at = EXCLUDED.expires_at
+      WHERE request_nonces.expires_at < $4
+    RETURNING key_id`
+
+const pruneNoncesQuery = `
+  DELETE FROM request_nonces WHERE expires_at < $1`
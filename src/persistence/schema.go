@@ -1,5 +1,6 @@
 package persistence
 
+// SchemaUp is ordered list of schema migration, new statement must be appended at the end.
 var SchemaUp = []string{
 	`CREATE TABLE deposit_histories (
 	    ts TIMESTAMP,
@@ -9,4 +10,193 @@ var SchemaUp = []string{
 	    ts TIMESTAMP PRIMARY KEY,
 	    amount DECIMAL(20,8)
 	);`,
+	`ALTER TABLE deposit_histories ADD COLUMN id BIGSERIAL PRIMARY KEY;`,
+	`CREATE TABLE webhook_subscriptions (
+	    id BIGSERIAL PRIMARY KEY,
+	    url TEXT NOT NULL,
+	    events TEXT[] NOT NULL,
+	    secret TEXT NOT NULL,
+	    thresholds DECIMAL(20,8)[] NOT NULL DEFAULT '{}',
+	    created_at TIMESTAMP NOT NULL
+	);`,
+	`CREATE TABLE webhook_outbox (
+	    id BIGSERIAL PRIMARY KEY,
+	    subscription_id BIGINT NOT NULL REFERENCES webhook_subscriptions (id) ON DELETE CASCADE,
+	    event_type TEXT NOT NULL,
+	    payload JSONB NOT NULL,
+	    status TEXT NOT NULL DEFAULT 'pending',
+	    attempts INT NOT NULL DEFAULT 0,
+	    next_attempt_at TIMESTAMP NOT NULL,
+	    last_error TEXT NOT NULL DEFAULT '',
+	    created_at TIMESTAMP NOT NULL,
+	    delivered_at TIMESTAMP
+	);`,
+	`CREATE INDEX webhook_outbox_pending_idx ON webhook_outbox (next_attempt_at) WHERE status = 'pending';`,
+	`CREATE TABLE api_keys (
+	    id BIGSERIAL PRIMARY KEY,
+	    name TEXT NOT NULL,
+	    prefix TEXT NOT NULL,
+	    hash TEXT NOT NULL UNIQUE,
+	    scopes TEXT[] NOT NULL,
+	    created_at TIMESTAMP NOT NULL,
+	    revoked_at TIMESTAMP
+	);`,
+	`ALTER TABLE deposit_histories ADD COLUMN api_key_id BIGINT REFERENCES api_keys (id);`,
+	`ALTER TABLE deposit_histories ADD COLUMN subject TEXT;`,
+	`CREATE TABLE audit_log (
+	    id BIGINT PRIMARY KEY,
+	    at TIMESTAMP NOT NULL,
+	    actor TEXT NOT NULL,
+	    client_ip TEXT NOT NULL,
+	    request_id TEXT NOT NULL,
+	    action TEXT NOT NULL,
+	    payload_hash TEXT NOT NULL,
+	    outcome TEXT NOT NULL,
+	    prev_hash TEXT NOT NULL,
+	    hash TEXT NOT NULL
+	);`,
+	`CREATE INDEX audit_log_actor_idx ON audit_log (actor, id);`,
+	// audit_head hold the chain tip, its row lock serialize appends so entries are chained in commit order.
+	`CREATE TABLE audit_head (
+	    singleton BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (singleton),
+	    last_id BIGINT NOT NULL,
+	    last_hash TEXT NOT NULL
+	);`,
+	`INSERT INTO audit_head (last_id, last_hash) VALUES (0, '');`,
+	`CREATE FUNCTION reject_audit_change() RETURNS trigger AS $$
+	BEGIN
+	    RAISE EXCEPTION 'audit_log is append-only';
+	END;
+	$$ LANGUAGE plpgsql;`,
+	`CREATE TRIGGER audit_log_append_only
+	    BEFORE UPDATE OR DELETE OR TRUNCATE ON audit_log
+	    FOR EACH STATEMENT EXECUTE FUNCTION reject_audit_change();`,
+	// existing deposits are chained by sealDepositChain once this is applied, see migrationHooks.
+	`ALTER TABLE deposit_histories ADD COLUMN prev_hash TEXT, ADD COLUMN hash TEXT;`,
+	`CREATE TABLE deposit_chain_checkpoints (
+	    id BIGSERIAL PRIMARY KEY,
+	    at TIMESTAMP NOT NULL,
+	    last_id BIGINT NOT NULL,
+	    head_hash TEXT NOT NULL,
+	    signature BYTEA NOT NULL
+	);`,
+	`CREATE TABLE assets (
+	    code TEXT PRIMARY KEY,
+	    scale INT NOT NULL,
+	    display_precision INT NOT NULL,
+	    created_at TIMESTAMP NOT NULL,
+	    CHECK (scale BETWEEN 0 AND 36 AND display_precision BETWEEN 0 AND scale)
+	);`,
+	// deposits made before assets were introduced are in BTC, see anymind.DefaultAsset.
+	`INSERT INTO assets (code, scale, display_precision, created_at) VALUES ('BTC', 8, 8, now() AT TIME ZONE 'UTC');`,
+	// amount precision is checked against asset scale, column keep amount as given.
+	`ALTER TABLE deposit_histories
+	    ALTER COLUMN amount TYPE NUMERIC,
+	    ADD COLUMN asset TEXT NOT NULL DEFAULT 'BTC' REFERENCES assets (code);`,
+	`ALTER TABLE deposit_histories ALTER COLUMN asset DROP DEFAULT;`,
+	`ALTER TABLE deposit_hourly
+	    ALTER COLUMN amount TYPE NUMERIC,
+	    ADD COLUMN asset TEXT NOT NULL DEFAULT 'BTC' REFERENCES assets (code),
+	    DROP CONSTRAINT deposit_hourly_pkey,
+	    ADD PRIMARY KEY (asset, ts);`,
+	`ALTER TABLE deposit_hourly ALTER COLUMN asset DROP DEFAULT;`,
+	`ALTER TABLE webhook_subscriptions
+	    ALTER COLUMN thresholds TYPE NUMERIC[],
+	    ADD COLUMN asset TEXT NOT NULL DEFAULT 'BTC' REFERENCES assets (code);`,
+	// rate value the historical bucket at ts, see anymind.Rate.
+	`CREATE TABLE exchange_rates (
+	    base TEXT NOT NULL REFERENCES assets (code),
+	    quote TEXT NOT NULL REFERENCES assets (code),
+	    ts TIMESTAMP NOT NULL,
+	    rate NUMERIC NOT NULL CHECK (rate > 0),
+	    PRIMARY KEY (base, quote, ts)
+	);`,
+	// deposit out of pattern, held deposit is only in deposit_histories once released, see anymind.Anomaly.
+	`CREATE TABLE deposit_anomalies (
+	    id BIGSERIAL PRIMARY KEY,
+	    ts TIMESTAMP NOT NULL,
+	    amount NUMERIC NOT NULL,
+	    asset TEXT NOT NULL REFERENCES assets (code),
+	    reason TEXT NOT NULL,
+	    score DOUBLE PRECISION NOT NULL,
+	    status TEXT NOT NULL,
+	    api_key_id BIGINT REFERENCES api_keys (id),
+	    subject TEXT,
+	    created_at TIMESTAMP NOT NULL,
+	    reviewed_at TIMESTAMP
+	);`,
+	`CREATE INDEX deposit_anomalies_status_idx ON deposit_anomalies (status, id);`,
+	// pending deposit is only applied to deposit_hourly once confirmed, see anymind.DepositPending.
+	`ALTER TABLE deposit_histories
+	    ADD COLUMN status TEXT NOT NULL DEFAULT 'confirmed' CHECK (status IN ('pending', 'confirmed', 'failed')),
+	    ADD COLUMN created_at TIMESTAMP;`,
+	`CREATE INDEX deposit_histories_pending_idx ON deposit_histories (asset, ts) WHERE status = 'pending';`,
+	// chain_seq is position of deposit in chain, deposits chained so far were chained in id order.
+	`ALTER TABLE deposit_histories ADD COLUMN chain_seq BIGINT UNIQUE;`,
+	`UPDATE deposit_histories SET chain_seq = id WHERE hash IS NOT NULL;`,
+	`CREATE INDEX deposit_histories_unchained_idx ON deposit_histories (id) WHERE chain_seq IS NULL;`,
+	// deposit_chain_head hold the chain tip, its row lock serialize chaining like audit_head.
+	`CREATE TABLE deposit_chain_head (
+	    singleton BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (singleton),
+	    last_seq BIGINT NOT NULL,
+	    last_id BIGINT NOT NULL,
+	    last_hash TEXT NOT NULL
+	);`,
+	`INSERT INTO deposit_chain_head (last_seq, last_id, last_hash)
+	    SELECT COALESCE(MAX(id), 0), COALESCE(MAX(id), 0), COALESCE((
+	        SELECT hash FROM deposit_histories WHERE chain_seq IS NOT NULL ORDER BY id DESC LIMIT 1
+	      ), '')
+	      FROM deposit_histories
+	      WHERE chain_seq IS NOT NULL;`,
+	`CREATE FUNCTION reject_append_only_change() RETURNS trigger AS $$
+	BEGIN
+	    RAISE EXCEPTION '% is append-only', TG_TABLE_NAME;
+	END;
+	$$ LANGUAGE plpgsql;`,
+	`CREATE TRIGGER deposit_chain_checkpoints_append_only
+	    BEFORE UPDATE OR DELETE OR TRUNCATE ON deposit_chain_checkpoints
+	    FOR EACH STATEMENT EXECUTE FUNCTION reject_append_only_change();`,
+	// stats and candles scan deposits of an asset within a range.
+	`CREATE INDEX deposit_histories_asset_ts_idx ON deposit_histories (asset, ts);`,
+	// held deposit is credited with the status it was made with once released.
+	`ALTER TABLE deposit_anomalies
+	    ADD COLUMN deposit_status TEXT NOT NULL DEFAULT 'confirmed' CHECK (deposit_status IN ('pending', 'confirmed'));`,
+	// change feed is backfilled in confirmation order, feed_seq is taken when deposit is confirmed, see Service.apply.
+	// Deposits confirmed so far keep their id as position.
+	`ALTER TABLE deposit_histories ADD COLUMN feed_seq BIGINT UNIQUE;`,
+	`UPDATE deposit_histories SET feed_seq = id WHERE status = 'confirmed';`,
+	`CREATE SEQUENCE deposit_feed_seq;`,
+	`SELECT setval('deposit_feed_seq', COALESCE(MAX(id), 0) + 1, false) FROM deposit_histories;`,
+	// deposit is chained in its own transaction with the status it was stored with, initial_status keep it once the
+	// deposit is settled. Deposits left unchained so far are chained by chainLeftoverDeposits.
+	`ALTER TABLE deposit_histories ADD COLUMN initial_status TEXT;`,
+	`UPDATE deposit_histories SET initial_status = status;`,
+	`ALTER TABLE deposit_histories ALTER COLUMN initial_status SET NOT NULL;`,
+	// settlement of pending deposit is chained as its own link in the transaction that settle it, see
+	// anymind.DepositRecord.
+	`CREATE TABLE deposit_status_changes (
+	    id BIGSERIAL PRIMARY KEY,
+	    deposit_id BIGINT NOT NULL REFERENCES deposit_histories (id),
+	    status TEXT NOT NULL,
+	    at TIMESTAMP NOT NULL,
+	    api_key_id BIGINT REFERENCES api_keys (id),
+	    subject TEXT,
+	    chain_seq BIGINT NOT NULL UNIQUE,
+	    prev_hash TEXT NOT NULL,
+	    hash TEXT NOT NULL
+	);`,
+	`CREATE INDEX deposit_status_changes_deposit_idx ON deposit_status_changes (deposit_id, chain_seq);`,
+	`CREATE TRIGGER deposit_status_changes_append_only
+	    BEFORE UPDATE OR DELETE OR TRUNCATE ON deposit_status_changes
+	    FOR EACH STATEMENT EXECUTE FUNCTION reject_append_only_change();`,
+	// deposit_id link anomaly to its credited deposit, flagged one once recorded and held one once released.
+	`ALTER TABLE deposit_anomalies ADD COLUMN deposit_id BIGINT REFERENCES deposit_histories (id);`,
+	// nonces of signed requests are shared by every instance, see reqsign.Service.
+	`CREATE TABLE request_nonces (
+	    key_id TEXT NOT NULL,
+	    nonce TEXT NOT NULL,
+	    expires_at TIMESTAMP NOT NULL,
+	    PRIMARY KEY (key_id, nonce)
+	);`,
+	`CREATE INDEX request_nonces_expires_at_idx ON request_nonces (expires_at);`,
 }
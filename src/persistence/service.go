@@ -2,23 +2,52 @@ package persistence
 
 import (
 	"anymind"
+	"anymind/src/tracing"
 	"context"
 	"database/sql"
+	"errors"
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/discard"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
+	"sync/atomic"
 	"time"
 )
 
 var _ anymind.PersistenceService = &Service{}
+var _ anymind.HealthChecker = &Service{}
+
+var tracer = otel.Tracer("anymind/src/persistence")
+
+// serializationFailureCode is sqlstate returned when serializable transaction conflict with concurrent one.
+const serializationFailureCode = "40001"
 
 type Service struct {
 	db     *sql.DB
 	logger *zap.Logger
+
+	// serializationRetries is shared by copies of the service, since it can change while serving.
+	serializationRetries *atomic.Int64
+	queryDuration        metrics.Histogram
+	retries              metrics.Counter
+}
+
+// SetSerializationRetries change how many times deposit is retried after serialization failure.
+func (s Service) SetSerializationRetries(retries int) {
+	s.serializationRetries.Store(int64(retries))
 }
 
 func NewService(db *sql.DB, opts ...Option) *Service {
 	s := &Service{
-		db: db,
+		db:                   db,
+		serializationRetries: &atomic.Int64{},
 	}
+	s.serializationRetries.Store(3)
 
 	for _, opt := range opts {
 		opt(s)
@@ -28,43 +57,216 @@ func NewService(db *sql.DB, opts ...Option) *Service {
 		s.logger = zap.NewNop()
 	}
 
+	if s.queryDuration == nil {
+		s.queryDuration = discard.NewHistogram()
+	}
+
+	if s.retries == nil {
+		s.retries = discard.NewCounter()
+	}
+
 	return s
 }
-func (s Service) Deposit(ctx context.Context, input *anymind.DepositInput) error {
+
+// query start span of named query, returned func end the span and record query latency.
+func (s Service) query(ctx context.Context, name string) (context.Context, func(error)) {
+	begin := time.Now()
+	ctx, span := tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemPostgreSQL, semconv.DBOperation(name)))
+
+	return ctx, func(err error) {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+
+		span.End()
+		s.queryDuration.With("query", name).Observe(time.Since(begin).Seconds())
+	}
+}
+
+func isSerializationFailure(err error) bool {
+	var pgErr *pgconn.PgError
+
+	return errors.As(err, &pgErr) && pgErr.Code == serializationFailureCode
+}
+
+// Deposit run deposit transaction, it is retried when it conflict with concurrent deposit.
+func (s Service) Deposit(ctx context.Context, input *anymind.DepositInput) (err error) {
+	ctx, span := tracer.Start(ctx, "persistence.Deposit")
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+
+		span.End()
+	}()
+
+	return s.retrySerializable(ctx, "deposit", func() error {
+		return s.deposit(ctx, input)
+	})
+}
+
+// retrySerializable run transaction until it does not conflict with concurrent one, or retries are exhausted.
+func (s Service) retrySerializable(ctx context.Context, operation string, run func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := run()
+		if err == nil || !isSerializationFailure(err) || attempt >= int(s.serializationRetries.Load()) {
+			return err
+		}
+
+		s.retries.With("operation", operation).Add(1)
+		trace.SpanFromContext(ctx).AddEvent("serialization failure retry",
+			trace.WithAttributes(attribute.Int("attempt", attempt+1)))
+		tracing.Logger(ctx, s.logger).Warn("retrying "+operation+" after serialization failure", zap.Int("attempt", attempt+1))
+	}
+}
+
+func (s Service) deposit(ctx context.Context, input *anymind.DepositInput) error {
 	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
+	keyID, subject := principalColumns(ctx)
+	err = s.credit(ctx, tx, input, keyID, subject)
+	if err != nil {
+		return err
+	}
+
+	_, done := s.query(ctx, "commit")
+	err = tx.Commit()
+	done(err)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// principalColumns return api key and subject of ctx principal as stored with deposit.
+func principalColumns(ctx context.Context) (sql.NullInt64, sql.NullString) {
+	var keyID sql.NullInt64
+	var subject sql.NullString
+	if p, ok := anymind.PrincipalFromContext(ctx); ok {
+		keyID = sql.NullInt64{Int64: p.KeyID, Valid: p.KeyID != 0}
+		subject = sql.NullString{String: p.Subject, Valid: p.Subject != ""}
+	}
+
+	return keyID, subject
+}
+
+// credit store, chain and audit deposit made by keyID and subject in tx, which must be serializable, so deposit is
+// only kept with its chain link and audit entry.
+func (s Service) credit(
+	ctx context.Context,
+	tx *sql.Tx,
+	input *anymind.DepositInput,
+	keyID sql.NullInt64,
+	subject sql.NullString,
+) error {
 	adjtime := input.DateTime.Truncate(time.Second)
+	asset := anymind.AssetCode(input.Asset)
+	status := anymind.DepositStatus(input.Status)
 
-	_, err = tx.ExecContext(ctx, insertHistoriesQuery, adjtime, input.Amount)
+	qctx, done := s.query(ctx, "insertHistoriesQuery")
+	err := tx.QueryRowContext(qctx, insertHistoriesQuery, adjtime, input.Amount, keyID, subject, asset, status,
+		time.Now().UTC()).
+		Scan(&input.ID)
+	done(err)
 	if err != nil {
-		s.logger.Error("failed to execute insertHistoriesQuery", zap.Error(err))
+		tracing.Logger(ctx, s.logger).Error("failed to execute insertHistoriesQuery", zap.Error(err))
 
 		return err
 	}
 
-	_, err = tx.ExecContext(ctx, insertHourlyQuery, adjtime, input.Amount)
+	// pending deposit is chained too, its settlement can not go unnoticed.
+	err = s.chainDeposit(ctx, tx, input.ID)
 	if err != nil {
-		s.logger.Error("failed to execute insertHourlyQuery", zap.Error(err))
+		return err
+	}
 
+	err = s.appendAudit(ctx, tx, anymind.AuditDeposit, anymind.DepositAuditPayload(input), anymind.AuditSuccess)
+	if err != nil {
 		return err
 	}
 
-	_, err = tx.ExecContext(ctx, updatePostHourlyQuery, adjtime, input.Amount)
+	// pending deposit is applied to balance when it is confirmed, see SetDepositStatus.
+	if status != anymind.DepositConfirmed {
+		return nil
+	}
+
+	return s.apply(ctx, tx, &anymind.DepositEvent{
+		ID:       input.ID,
+		DateTime: adjtime,
+		Amount:   input.Amount,
+		Asset:    asset,
+	})
+}
+
+// apply add confirmed deposit event to hourly balance, then deliver it to webhooks and listeners.
+func (s Service) apply(ctx context.Context, tx *sql.Tx, event *anymind.DepositEvent) error {
+	qctx, done := s.query(ctx, "insertHourlyQuery")
+	_, err := tx.ExecContext(qctx, insertHourlyQuery, event.DateTime, event.Amount, event.Asset)
+	done(err)
 	if err != nil {
-		s.logger.Error("failed to execute updatePostHourlyQuery", zap.Error(err))
+		tracing.Logger(ctx, s.logger).Error("failed to execute insertHourlyQuery", zap.Error(err))
 
 		return err
 	}
 
-	err = tx.Commit()
+	qctx, done = s.query(ctx, "updatePostHourlyQuery")
+	_, err = tx.ExecContext(qctx, updatePostHourlyQuery, event.DateTime, event.Amount, event.Asset)
+	done(err)
+	if err != nil {
+		tracing.Logger(ctx, s.logger).Error("failed to execute updatePostHourlyQuery", zap.Error(err))
+
+		return err
+	}
+
+	// sequence is not transactional, deposit taking the next position could commit first and be passed by a listener
+	// backfilling. Feed position is taken under a lock held until commit, so positions are committed in order.
+	qctx, done = s.query(ctx, "lockFeedQuery")
+	_, err = tx.ExecContext(qctx, lockFeedQuery, feedLockKey)
+	done(err)
+	if err != nil {
+		tracing.Logger(ctx, s.logger).Error("failed to execute lockFeedQuery", zap.Error(err))
+
+		return err
+	}
+
+	qctx, done = s.query(ctx, "updateFeedSeqQuery")
+	err = tx.QueryRowContext(qctx, updateFeedSeqQuery, event.ID).Scan(&event.Seq)
+	done(err)
+	if err != nil {
+		tracing.Logger(ctx, s.logger).Error("failed to execute updateFeedSeqQuery", zap.Error(err))
+
+		return err
+	}
+
+	payload, err := encodeDepositEvent(event)
 	if err != nil {
 		return err
 	}
 
+	err = s.enqueueWebhooks(ctx, tx, event, payload)
+	if err != nil {
+		return err
+	}
+
+	// notification is only delivered to listeners when transaction is committed.
+	qctx, done = s.query(ctx, "notifyDepositQuery")
+	_, err = tx.ExecContext(qctx, notifyDepositQuery, DepositChannel, payload)
+	done(err)
+	if err != nil {
+		tracing.Logger(ctx, s.logger).Error("failed to execute notifyDepositQuery", zap.Error(err))
+
+		return err
+	}
+
 	return nil
 }
 
@@ -76,9 +278,11 @@ func (s Service) Historical(ctx context.Context, req *anymind.HistoricalDataReq)
 	defer tx.Commit()
 
 	var res []*anymind.HistoricalData
-	rows, err := tx.QueryContext(ctx, selectHourlyQuery, req.Start, req.End)
+	qctx, done := s.query(ctx, "selectHourlyQuery")
+	rows, err := tx.QueryContext(qctx, selectHourlyQuery, req.Start, req.End, anymind.AssetCode(req.Asset))
+	done(err)
 	if err != nil {
-		s.logger.Error("failed to execute selectHourlyQuery", zap.Error(err))
+		tracing.Logger(ctx, s.logger).Error("failed to execute selectHourlyQuery", zap.Error(err))
 
 		return nil, err
 	}
@@ -88,7 +292,7 @@ func (s Service) Historical(ctx context.Context, req *anymind.HistoricalDataReq)
 		var row anymind.HistoricalData
 		err = rows.Scan(&row.DateTime, &row.Amount)
 		if err != nil {
-			s.logger.Error("failed to scan selectHourlyQuery", zap.Error(err))
+			tracing.Logger(ctx, s.logger).Error("failed to scan selectHourlyQuery", zap.Error(err))
 
 			return nil, err
 		}
@@ -97,10 +301,20 @@ func (s Service) Historical(ctx context.Context, req *anymind.HistoricalDataReq)
 	}
 
 	if rows.Err() != nil {
-		s.logger.Error("error on next selectHourlyQuery", zap.Error(err))
+		tracing.Logger(ctx, s.logger).Error("error on next selectHourlyQuery", zap.Error(err))
+
+		return nil, err
+	}
 
+	if !req.IncludePending {
+		return res, nil
+	}
+
+	// pending is read in the same transaction, so it is consistent with confirmed balance.
+	pending, err := s.pendingHourly(ctx, tx, req)
+	if err != nil {
 		return nil, err
 	}
 
-	return res, nil
+	return anymind.AddPending(req, res, pending)
 }
@@ -6,6 +6,7 @@ import (
 	"database/sql"
 	"fmt"
 	"github.com/cockroachdb/apd"
+	"github.com/jackc/pgx/v5/pgconn"
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/stretchr/testify/require"
 	"os"
@@ -256,3 +257,739 @@ func TestEmpty(t *testing.T) {
 	require.NoError(t, err)
 	require.Len(t, res, 0)
 }
+
+func TestDepositsAfter(t *testing.T) {
+	db := connTestDB(SchemaUp)
+	defer db.Close()
+
+	svc := NewService(db)
+	ctx := context.Background()
+
+	latest, err := svc.LatestDepositSeq(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(0), latest)
+
+	pending := &anymind.DepositInput{
+		DateTime: mustTime("2020-01-01T14:00:00Z"),
+		Amount:   mustApd("9"),
+		Status:   anymind.DepositPending,
+	}
+	require.NoError(t, svc.Deposit(ctx, pending))
+
+	for i := 0; i < 3; i++ {
+		err := svc.Deposit(ctx, &anymind.DepositInput{
+			DateTime: mustTime("2020-01-01T15:00:00Z").Add(time.Duration(i) * time.Minute),
+			Amount:   mustApd(fmt.Sprint(i + 1)),
+		})
+		require.NoError(t, err)
+	}
+
+	latest, err = svc.LatestDepositSeq(ctx)
+	require.NoError(t, err)
+
+	res, err := svc.DepositsAfter(ctx, latest-2, 10)
+	require.NoError(t, err)
+	require.Len(t, res, 2)
+	require.Equal(t, latest-1, res[0].Seq)
+	require.Equal(t, "3", fmt.Sprintf("%f", &res[1].Amount))
+
+	// pending deposit is after every deposit confirmed before it, whatever its id.
+	_, err = svc.SetDepositStatus(ctx, pending.ID, anymind.DepositConfirmed)
+	require.NoError(t, err)
+
+	res, err = svc.DepositsAfter(ctx, latest, 10)
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	require.Equal(t, pending.ID, res[0].ID)
+	require.Equal(t, latest+1, res[0].Seq)
+}
+
+func TestDepositEventPayload(t *testing.T) {
+	payload, err := encodeDepositEvent(&anymind.DepositEvent{
+		ID:       7,
+		DateTime: mustTime("2020-01-01T15:00:00Z"),
+		Amount:   mustApd("1.50000000"),
+		Seq:      9,
+	})
+	require.NoError(t, err)
+
+	ev, err := DecodeDepositEvent(payload)
+	require.NoError(t, err)
+	require.Equal(t, int64(7), ev.ID)
+	require.Equal(t, int64(9), ev.Seq)
+	require.Equal(t, mustTime("2020-01-01T15:00:00Z"), ev.DateTime)
+	require.Equal(t, "1.50000000", fmt.Sprintf("%f", &ev.Amount))
+}
+
+func TestDepositWebhookOutbox(t *testing.T) {
+	db := connTestDB(SchemaUp)
+	defer db.Close()
+
+	svc := NewService(db)
+	ctx := context.Background()
+
+	deposits := &anymind.WebhookSubscription{
+		URL:    "http://localhost/deposit",
+		Events: []string{anymind.WebhookEventDeposit},
+		Secret: "secret",
+	}
+	thresholds := &anymind.WebhookSubscription{
+		URL:        "http://localhost/threshold",
+		Events:     []string{anymind.WebhookEventBalanceThreshold},
+		Secret:     "secret",
+		Thresholds: []apd.Decimal{mustApd("5"), mustApd("100")},
+	}
+	require.NoError(t, svc.CreateWebhook(ctx, deposits))
+	require.NoError(t, svc.CreateWebhook(ctx, thresholds))
+
+	subs, err := svc.ListWebhooks(ctx)
+	require.NoError(t, err)
+	require.Len(t, subs, 2)
+	require.Equal(t, []string{anymind.WebhookEventBalanceThreshold}, subs[1].Events)
+	require.Len(t, subs[1].Thresholds, 2)
+
+	for _, amount := range []string{"3", "4"} {
+		err = svc.Deposit(ctx, &anymind.DepositInput{
+			DateTime: mustTime("2020-01-01T15:00:00Z"),
+			Amount:   mustApd(amount),
+		})
+		require.NoError(t, err)
+	}
+
+	now := time.Now()
+	claimed, err := svc.ClaimDeliveries(ctx, now, now.Add(time.Minute), 10)
+	require.NoError(t, err)
+	// two deposit events and a single crossing of threshold 5.
+	require.Len(t, claimed, 3)
+
+	again, err := svc.ClaimDeliveries(ctx, now, now.Add(time.Minute), 10)
+	require.NoError(t, err)
+	require.Len(t, again, 0)
+
+	for _, d := range claimed {
+		require.Equal(t, 1, d.Attempts)
+		d.Status = anymind.DeliveryDead
+		require.NoError(t, svc.UpdateDelivery(ctx, d))
+	}
+
+	dead, err := svc.ListDeliveries(ctx, anymind.DeliveryDead, 10)
+	require.NoError(t, err)
+	require.Len(t, dead, 3)
+
+	require.NoError(t, svc.RedeliverWebhook(ctx, dead[0].ID, now))
+	require.ErrorIs(t, svc.RedeliverWebhook(ctx, -1, now), anymind.ErrNotFound)
+}
+
+func TestAPIKey(t *testing.T) {
+	db := connTestDB(SchemaUp)
+	defer db.Close()
+
+	svc := NewService(db)
+	ctx := context.Background()
+
+	key := &anymind.APIKey{
+		Name:   "partner",
+		Prefix: "amk_abcdef",
+		Hash:   "hash",
+		Scopes: []string{anymind.ScopeDepositWrite},
+	}
+	require.NoError(t, svc.CreateAPIKey(ctx, key))
+
+	found, err := svc.FindAPIKey(ctx, "hash")
+	require.NoError(t, err)
+	require.Equal(t, key.ID, found.ID)
+	require.Equal(t, []string{anymind.ScopeDepositWrite}, found.Scopes)
+	require.Nil(t, found.RevokedAt)
+
+	_, err = svc.FindAPIKey(ctx, "other")
+	require.ErrorIs(t, err, anymind.ErrNotFound)
+
+	// deposit record key id and subject of authenticated principal.
+	principal := &anymind.Principal{Subject: "apikey:partner", KeyID: key.ID}
+	err = svc.Deposit(anymind.WithPrincipal(ctx, principal), &anymind.DepositInput{
+		DateTime: mustTime("2020-01-01T15:00:00Z"),
+		Amount:   mustApd("1"),
+	})
+	require.NoError(t, err)
+
+	var keyID int64
+	var subject string
+	row := db.QueryRowContext(ctx, "SELECT api_key_id, subject FROM deposit_histories")
+	require.NoError(t, row.Scan(&keyID, &subject))
+	require.Equal(t, key.ID, keyID)
+	require.Equal(t, principal.Subject, subject)
+
+	require.NoError(t, svc.RevokeAPIKey(ctx, key.ID, time.Now()))
+	keys, err := svc.ListAPIKeys(ctx)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	require.NotNil(t, keys[0].RevokedAt)
+}
+
+func TestIsSerializationFailure(t *testing.T) {
+	require.True(t, isSerializationFailure(fmt.Errorf("commit: %w", &pgconn.PgError{Code: "40001"})))
+	require.False(t, isSerializationFailure(&pgconn.PgError{Code: "23505"}))
+	require.False(t, isSerializationFailure(sql.ErrTxDone))
+}
+
+func TestMigrate(t *testing.T) {
+	ctx := context.Background()
+	db := connTestDB(SchemaUp[:2])
+	defer db.Close()
+
+	svc := NewService(db)
+
+	version, err := svc.CurrentSchemaVersion(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 0, version)
+	require.Error(t, svc.CheckHealth(ctx))
+
+	err = Baseline(ctx, db, 2)
+	require.NoError(t, err)
+
+	from, err := Migrate(ctx, db)
+	require.NoError(t, err)
+	require.Equal(t, 2, from)
+
+	version, err = svc.CurrentSchemaVersion(ctx)
+	require.NoError(t, err)
+	require.Equal(t, SchemaVersion, version)
+	require.NoError(t, svc.CheckHealth(ctx))
+
+	from, err = Migrate(ctx, db)
+	require.NoError(t, err)
+	require.Equal(t, SchemaVersion, from)
+}
+
+func TestAudit(t *testing.T) {
+	db := connTestDB(SchemaUp)
+	defer db.Close()
+
+	svc := NewService(db)
+	ctx := anymind.WithRequestID(context.Background(), "req-1")
+	ctx = anymind.WithClientIP(ctx, "10.0.0.1")
+	ctx = anymind.WithPrincipal(ctx, &anymind.Principal{KeyID: 7})
+
+	input := &anymind.DepositInput{DateTime: mustTime("2020-01-01T15:00:00Z"), Amount: mustApd("1.5")}
+	require.NoError(t, svc.Deposit(ctx, input))
+	require.NoError(t, svc.RecordAudit(ctx, anymind.AuditDeposit, anymind.DepositAuditPayload(input), anymind.AuditRejected))
+	require.ErrorIs(t, svc.DeleteWebhook(ctx, 42), anymind.ErrNotFound)
+
+	entries, err := svc.ListAudit(ctx, &anymind.AuditFilter{})
+	require.NoError(t, err)
+	require.Len(t, entries, 2, "failed change leave no success entry")
+
+	hash, err := anymind.AuditPayloadHash(anymind.DepositAuditPayload(input))
+	require.NoError(t, err)
+	require.Equal(t, int64(1), entries[0].ID)
+	require.Equal(t, "key:7", entries[0].Actor)
+	require.Equal(t, "10.0.0.1", entries[0].ClientIP)
+	require.Equal(t, "req-1", entries[0].RequestID)
+	require.Equal(t, hash, entries[0].PayloadHash)
+	require.Equal(t, anymind.AuditSuccess, entries[0].Outcome)
+	require.Equal(t, entries[0].Hash, entries[1].PrevHash)
+	require.Equal(t, anymind.AuditRejected, entries[1].Outcome)
+
+	entries, err = svc.ListAudit(ctx, &anymind.AuditFilter{AfterID: 1, Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, int64(2), entries[0].ID)
+
+	verified, err := svc.VerifyAudit(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 2, verified)
+
+	_, err = db.ExecContext(ctx, "UPDATE audit_log SET actor = 'anonymous'")
+	require.Error(t, err, "audit log is append-only")
+
+	// trigger can only be bypassed by table owner disabling it, verify must still catch the change.
+	_, err = db.ExecContext(ctx, "ALTER TABLE audit_log DISABLE TRIGGER audit_log_append_only")
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, "UPDATE audit_log SET actor = 'anonymous' WHERE id = 1")
+	require.NoError(t, err)
+
+	verified, err = svc.VerifyAudit(ctx)
+	require.ErrorContains(t, err, "audit entry 1 was altered")
+	require.Equal(t, 0, verified)
+}
+
+func TestDepositChain(t *testing.T) {
+	ctx := context.Background()
+
+	// deposit made before deposits were hashed is sealed by migration.
+	db := connTestDB(SchemaUp[:15])
+	defer db.Close()
+	_, err := db.ExecContext(ctx, "INSERT INTO deposit_histories (ts, amount) VALUES ('2020-01-01T10:00:00', 1)")
+	require.NoError(t, err)
+	require.NoError(t, Baseline(ctx, db, 15))
+	_, err = Migrate(ctx, db)
+	require.NoError(t, err)
+
+	svc := NewService(db)
+	for _, amount := range []string{"1.5", "2.123456789", "3"} {
+		err = svc.Deposit(ctx, &anymind.DepositInput{DateTime: mustTime("2020-01-01T15:00:00Z"), Amount: mustApd(amount)})
+		require.NoError(t, err)
+	}
+
+	headID, _, err := svc.DepositChainHead(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(4), headID)
+
+	// pending deposit is chained in its transaction too.
+	pending := &anymind.DepositInput{DateTime: mustTime("2020-01-01T16:00:00Z"), Amount: mustApd("4"), Status: anymind.DepositPending}
+	require.NoError(t, svc.Deposit(ctx, pending))
+
+	headID, pendingHash, err := svc.DepositChainHead(ctx)
+	require.NoError(t, err)
+	require.Equal(t, pending.ID, headID)
+	require.Equal(t, int64(5), headID)
+
+	// settlement is chained as its own link, head move without a new deposit.
+	_, err = svc.SetDepositStatus(ctx, pending.ID, anymind.DepositConfirmed)
+	require.NoError(t, err)
+
+	headID, headHash, err := svc.DepositChainHead(ctx)
+	require.NoError(t, err)
+	require.Equal(t, pending.ID, headID)
+	require.NotEqual(t, pendingHash, headHash)
+
+	checkpoint := &anymind.ChainCheckpoint{At: time.Now().UTC().Truncate(time.Microsecond), LastID: 3, Signature: []byte("sig")}
+	row := db.QueryRowContext(ctx, "SELECT hash FROM deposit_histories WHERE id = 3")
+	require.NoError(t, row.Scan(&checkpoint.HeadHash))
+	require.NoError(t, svc.SaveChainCheckpoint(ctx, checkpoint))
+
+	checkpoints, err := svc.ListChainCheckpoints(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []*anymind.ChainCheckpoint{checkpoint}, checkpoints)
+
+	_, err = db.ExecContext(ctx, "DELETE FROM deposit_chain_checkpoints")
+	require.ErrorContains(t, err, "deposit_chain_checkpoints is append-only")
+
+	report, err := svc.VerifyDepositChain(ctx, checkpoints)
+	require.NoError(t, err)
+	require.Equal(t, &anymind.ChainReport{Verified: 6, HeadID: 5, HeadHash: headHash, Checkpoints: 1}, report)
+
+	// checkpoint of a link that was since followed by settlement still match.
+	report, err = svc.VerifyDepositChain(ctx, []*anymind.ChainCheckpoint{{ID: 8, LastID: 5, HeadHash: pendingHash}})
+	require.NoError(t, err)
+	require.Nil(t, report.Break)
+
+	// status can not be changed outside of chain.
+	_, err = db.ExecContext(ctx, "UPDATE deposit_histories SET status = 'failed' WHERE id = $1", pending.ID)
+	require.NoError(t, err)
+	report, err = svc.VerifyDepositChain(ctx, checkpoints)
+	require.NoError(t, err)
+	require.Equal(t, &anymind.ChainBreak{ID: pending.ID, Reason: "status does not match chain"}, report.Break)
+
+	_, err = db.ExecContext(ctx, "DELETE FROM deposit_status_changes")
+	require.ErrorContains(t, err, "deposit_status_changes is append-only")
+
+	// checkpoint beyond head, e.g. last deposits removed.
+	report, err = svc.VerifyDepositChain(ctx, []*anymind.ChainCheckpoint{{ID: 9, LastID: 6}})
+	require.NoError(t, err)
+	require.Equal(t, &anymind.ChainBreak{ID: 9, Checkpoint: true, Reason: "deposit 6 of checkpoint is missing"}, report.Break)
+
+	// pending deposit can not pass for a confirmed one.
+	_, err = db.ExecContext(ctx, "UPDATE deposit_histories SET initial_status = 'confirmed' WHERE id = $1", pending.ID)
+	require.NoError(t, err)
+	report, err = svc.VerifyDepositChain(ctx, checkpoints)
+	require.NoError(t, err)
+	require.Equal(t, &anymind.ChainBreak{ID: pending.ID, Reason: "content does not match hash"}, report.Break)
+
+	_, err = db.ExecContext(ctx, "UPDATE deposit_histories SET amount = 30 WHERE id = 3")
+	require.NoError(t, err)
+	report, err = svc.VerifyDepositChain(ctx, checkpoints)
+	require.NoError(t, err)
+	require.Equal(t, 2, report.Verified)
+	require.Equal(t, &anymind.ChainBreak{ID: 3, Reason: "content does not match hash"}, report.Break)
+
+	_, err = db.ExecContext(ctx, "DELETE FROM deposit_histories WHERE id = 3")
+	require.NoError(t, err)
+	report, err = svc.VerifyDepositChain(ctx, nil)
+	require.NoError(t, err)
+	require.Equal(t, &anymind.ChainBreak{ID: 4, Reason: "not linked to deposit 2"}, report.Break)
+}
+
+func TestAssets(t *testing.T) {
+	db := connTestDB(SchemaUp)
+	defer db.Close()
+
+	svc := NewService(db)
+	ctx := context.Background()
+
+	btc, err := svc.Asset(ctx, anymind.DefaultAsset)
+	require.NoError(t, err)
+	require.Equal(t, 8, btc.Scale)
+
+	_, err = svc.Asset(ctx, "ETH")
+	require.ErrorIs(t, err, anymind.ErrNotFound)
+
+	eth := &anymind.Asset{Code: "ETH", Scale: 18, DisplayPrecision: 6}
+	require.NoError(t, svc.CreateAsset(ctx, eth))
+	require.ErrorIs(t, svc.CreateAsset(ctx, &anymind.Asset{Code: "ETH", Scale: 8}), anymind.ErrAlreadyExists)
+
+	assets, err := svc.ListAssets(ctx)
+	require.NoError(t, err)
+	require.Len(t, assets, 2)
+
+	err = svc.Deposit(ctx, &anymind.DepositInput{DateTime: mustTime("2020-01-01T15:00:00Z"), Amount: mustApd("1.5")})
+	require.NoError(t, err)
+	err = svc.Deposit(ctx, &anymind.DepositInput{
+		DateTime: mustTime("2020-01-01T15:30:00Z"),
+		Amount:   mustApd("0.000000000000000001"),
+		Asset:    "ETH",
+	})
+	require.NoError(t, err)
+
+	// hourly rollup is kept per asset.
+	res, err := svc.Historical(ctx, &anymind.HistoricalDataReq{
+		Start: mustTime("2020-01-01T16:00:00Z"),
+		End:   mustTime("2020-01-01T16:00:00Z"),
+		Asset: "ETH",
+	})
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	require.Equal(t, "0.000000000000000001", fmt.Sprintf("%f", &res[0].Amount))
+
+	res, err = svc.Historical(ctx, &anymind.HistoricalDataReq{
+		Start: mustTime("2020-01-01T16:00:00Z"),
+		End:   mustTime("2020-01-01T16:00:00Z"),
+	})
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	require.Equal(t, "1.5", fmt.Sprintf("%f", &res[0].Amount))
+
+	err = svc.Deposit(ctx, &anymind.DepositInput{DateTime: mustTime("2020-01-01T15:00:00Z"), Amount: mustApd("1"), Asset: "DOGE"})
+	require.Error(t, err)
+}
+
+func TestRates(t *testing.T) {
+	db := connTestDB(SchemaUp)
+	defer db.Close()
+
+	svc := NewService(db)
+	ctx := context.Background()
+
+	require.NoError(t, svc.CreateAsset(ctx, &anymind.Asset{Code: "USD", Scale: 2, DisplayPrecision: 2}))
+
+	rates := []*anymind.Rate{
+		{Base: "BTC", Quote: "USD", Hour: mustTime("2020-01-01T10:00:00Z"), Rate: mustApd("7200.5")},
+		{Base: "BTC", Quote: "USD", Hour: mustTime("2020-01-01T11:00:00Z"), Rate: mustApd("7300")},
+	}
+	require.NoError(t, svc.ImportRates(ctx, rates))
+
+	// import again replace rate of the same hour.
+	require.NoError(t, svc.ImportRates(ctx, []*anymind.Rate{
+		{Base: "BTC", Quote: "USD", Hour: mustTime("2020-01-01T11:00:00Z"), Rate: mustApd("7350")},
+	}))
+
+	res, err := svc.Rates(ctx, "BTC", "USD", mustTime("2020-01-01T00:00:00Z"), mustTime("2020-01-01T23:00:00Z"))
+	require.NoError(t, err)
+	require.Len(t, res, 2)
+	require.Equal(t, "7200.5", fmt.Sprintf("%f", &res[0].Rate))
+	require.Equal(t, "7350", fmt.Sprintf("%f", &res[1].Rate))
+	require.Equal(t, mustTime("2020-01-01T11:00:00Z"), res[1].Hour)
+
+	err = svc.ImportRates(ctx, []*anymind.Rate{
+		{Base: "DOGE", Quote: "USD", Hour: mustTime("2020-01-01T10:00:00Z"), Rate: mustApd("1")},
+	})
+	require.ErrorIs(t, err, anymind.ErrNotFound)
+}
+
+func TestStats(t *testing.T) {
+	db := connTestDB(SchemaUp)
+	defer db.Close()
+
+	svc := NewService(db)
+	ctx := context.Background()
+
+	inputs := []*anymind.DepositInput{
+		// before range, it only count toward opening balance.
+		{DateTime: mustTime("2020-01-01T07:10:00Z"), Amount: mustApd("4")},
+		{DateTime: mustTime("2020-01-01T09:30:00Z"), Amount: mustApd("5")},
+		{DateTime: mustTime("2020-01-01T10:00:00Z"), Amount: mustApd("1")},
+		{DateTime: mustTime("2020-01-01T11:15:00Z"), Amount: mustApd("0.00000001")},
+		{DateTime: mustTime("2020-01-01T11:45:00Z"), Amount: mustApd("2")},
+	}
+	for _, input := range inputs {
+		require.NoError(t, svc.Deposit(ctx, input))
+	}
+
+	res, err := svc.Stats(ctx, &anymind.HistoricalDataReq{
+		Start: mustTime("2020-01-01T10:00:00Z"),
+		End:   mustTime("2020-01-01T12:30:00Z"),
+	})
+	require.NoError(t, err)
+	require.Len(t, res, 3)
+
+	// deposit at 10:00 belong to bucket 10:00, together with 09:30.
+	require.Equal(t, mustTime("2020-01-01T10:00:00Z"), res[0].DateTime)
+	require.Equal(t, int64(2), res[0].Count)
+	require.Equal(t, "4", fmt.Sprintf("%f", &res[0].Open))
+	require.Equal(t, "10", fmt.Sprintf("%f", &res[0].Close))
+	require.Equal(t, "3.00000000", fmt.Sprintf("%f", res[0].Average))
+
+	require.Equal(t, int64(0), res[1].Count)
+	require.Nil(t, res[1].Min)
+	require.Equal(t, "10", fmt.Sprintf("%f", &res[1].Close))
+
+	require.Equal(t, int64(2), res[2].Count)
+	require.Equal(t, "0.00000001", fmt.Sprintf("%f", res[2].Min))
+	require.Equal(t, "2", fmt.Sprintf("%f", res[2].Max))
+	require.Equal(t, "1.00000001", fmt.Sprintf("%f", res[2].Average))
+	require.Equal(t, "12.00000001", fmt.Sprintf("%f", &res[2].Close))
+}
+
+func TestCandles(t *testing.T) {
+	db := connTestDB(SchemaUp)
+	defer db.Close()
+
+	svc := NewService(db)
+	ctx := context.Background()
+
+	inputs := []*anymind.DepositInput{
+		// before range, it only count toward opening balance.
+		{DateTime: mustTime("2019-12-31T23:00:00Z"), Amount: mustApd("100")},
+		{DateTime: mustTime("2020-01-01T00:30:00Z"), Amount: mustApd("10")},
+		{DateTime: mustTime("2020-01-01T01:30:00Z"), Amount: mustApd("20")},
+		// negative amount stand for a withdrawal, intra bucket high is above both open and close.
+		{DateTime: mustTime("2020-01-01T02:00:00Z"), Amount: mustApd("-25")},
+		{DateTime: mustTime("2020-01-01T03:00:00Z"), Amount: mustApd("3")},
+	}
+	for _, input := range inputs {
+		require.NoError(t, svc.Deposit(ctx, input))
+	}
+
+	res, err := svc.Candles(ctx, &anymind.CandleReq{
+		Start:       mustTime("2020-01-01T02:00:00Z"),
+		End:         mustTime("2020-01-01T06:00:00Z"),
+		Granularity: 2 * time.Hour,
+	})
+	require.NoError(t, err)
+	require.Len(t, res, 3)
+
+	format := func(c *anymind.Candle) []string {
+		return []string{
+			c.DateTime.Format(time.RFC3339),
+			fmt.Sprintf("%f", &c.Open), fmt.Sprintf("%f", &c.High), fmt.Sprintf("%f", &c.Low), fmt.Sprintf("%f", &c.Close),
+		}
+	}
+	require.Equal(t, []string{"2020-01-01T02:00:00Z", "100", "130", "100", "105"}, format(res[0]))
+	require.Equal(t, []string{"2020-01-01T04:00:00Z", "105", "108", "105", "108"}, format(res[1]))
+	require.Equal(t, []string{"2020-01-01T06:00:00Z", "108", "108", "108", "108"}, format(res[2]))
+}
+
+func TestAnomalies(t *testing.T) {
+	db := connTestDB(SchemaUp)
+	defer db.Close()
+
+	svc := NewService(db)
+	ctx := anymind.WithPrincipal(context.Background(), &anymind.Principal{Subject: "alice"})
+
+	held := &anymind.Anomaly{
+		DateTime: mustTime("2020-01-01T10:00:00Z"),
+		Amount:   mustApd("500"),
+		Reason:   anymind.AnomalyReasonAmount,
+		Score:    12.5,
+		Status:   anymind.AnomalyHeld,
+	}
+	require.NoError(t, svc.RecordAnomaly(ctx, held))
+	require.NoError(t, svc.RecordAnomaly(ctx, &anymind.Anomaly{
+		DateTime: mustTime("2020-01-01T11:00:00Z"),
+		Amount:   mustApd("1"),
+		Reason:   anymind.AnomalyReasonHour,
+		Status:   anymind.AnomalyFlagged,
+	}))
+
+	res, err := svc.ListAnomalies(ctx, anymind.AnomalyHeld, 10)
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	require.Equal(t, held.ID, res[0].ID)
+	require.Equal(t, "BTC", res[0].Asset)
+	require.Equal(t, "alice", res[0].Subject)
+	require.Equal(t, int64(0), res[0].DepositID)
+
+	res, err = svc.ListAnomalies(ctx, "", 10)
+	require.NoError(t, err)
+	require.Len(t, res, 2)
+
+	// held deposit is not credited before release.
+	balance, err := svc.Historical(ctx, &anymind.HistoricalDataReq{
+		Start: mustTime("2020-01-01T10:00:00Z"),
+		End:   mustTime("2020-01-01T10:00:00Z"),
+	})
+	require.NoError(t, err)
+	require.Empty(t, balance)
+
+	reviewed, err := svc.ReviewAnomaly(ctx, held.ID, anymind.AnomalyReleased)
+	require.NoError(t, err)
+	require.Equal(t, anymind.AnomalyReleased, reviewed.Status)
+	require.NotNil(t, reviewed.ReviewedAt)
+	require.NotZero(t, reviewed.DepositID)
+
+	// released anomaly is linked to the deposit it credited.
+	released, err := svc.ListAnomalies(ctx, anymind.AnomalyReleased, 10)
+	require.NoError(t, err)
+	require.Len(t, released, 1)
+	require.Equal(t, reviewed.DepositID, released[0].DepositID)
+
+	balance, err = svc.Historical(ctx, &anymind.HistoricalDataReq{
+		Start: mustTime("2020-01-01T10:00:00Z"),
+		End:   mustTime("2020-01-01T10:00:00Z"),
+	})
+	require.NoError(t, err)
+	require.Len(t, balance, 1)
+	require.Equal(t, "500", fmt.Sprintf("%f", &balance[0].Amount))
+
+	// anomaly is reviewed once, flagged anomaly is not reviewable.
+	_, err = svc.ReviewAnomaly(ctx, held.ID, anymind.AnomalyRejected)
+	require.ErrorIs(t, err, anymind.ErrNotFound)
+	_, err = svc.ReviewAnomaly(ctx, res[0].ID, anymind.AnomalyReleased)
+	require.ErrorIs(t, err, anymind.ErrNotFound)
+
+	// held pending deposit is credited as pending once released.
+	pending := &anymind.Anomaly{
+		DateTime:      mustTime("2020-01-01T12:00:00Z"),
+		Amount:        mustApd("7"),
+		Reason:        anymind.AnomalyReasonAmount,
+		Status:        anymind.AnomalyHeld,
+		DepositStatus: anymind.DepositPending,
+	}
+	require.NoError(t, svc.RecordAnomaly(ctx, pending))
+
+	reviewed, err = svc.ReviewAnomaly(ctx, pending.ID, anymind.AnomalyReleased)
+	require.NoError(t, err)
+	require.Equal(t, anymind.DepositPending, reviewed.DepositStatus)
+
+	req := &anymind.HistoricalDataReq{
+		Start: mustTime("2020-01-01T13:00:00Z"),
+		End:   mustTime("2020-01-01T13:00:00Z"),
+	}
+	balance, err = svc.Historical(ctx, req)
+	require.NoError(t, err)
+	require.Len(t, balance, 1)
+	require.Equal(t, "500", fmt.Sprintf("%f", &balance[0].Amount))
+
+	req.IncludePending = true
+	balance, err = svc.Historical(ctx, req)
+	require.NoError(t, err)
+	require.Len(t, balance, 1)
+	require.Equal(t, "507", fmt.Sprintf("%f", &balance[0].Amount))
+}
+
+func TestPendingDeposits(t *testing.T) {
+	db := connTestDB(SchemaUp)
+	defer db.Close()
+
+	svc := NewService(db)
+	ctx := context.Background()
+
+	deposit := func(ts string, amount string, status string) *anymind.DepositInput {
+		input := &anymind.DepositInput{DateTime: mustTime(ts), Amount: mustApd(amount), Status: status}
+		require.NoError(t, svc.Deposit(ctx, input))
+		require.NotZero(t, input.ID)
+
+		return input
+	}
+
+	deposit("2020-01-01T10:30:00Z", "10", "")
+	early := deposit("2020-01-01T09:30:00Z", "5", anymind.DepositPending)
+	late := deposit("2020-01-01T12:30:00Z", "2", anymind.DepositPending)
+
+	req := &anymind.HistoricalDataReq{
+		Start: mustTime("2020-01-01T11:00:00Z"),
+		End:   mustTime("2020-01-01T13:00:00Z"),
+	}
+	balance := func(includePending bool) []string {
+		req.IncludePending = includePending
+		res, err := svc.Historical(ctx, req)
+		require.NoError(t, err)
+
+		var entries []string
+		for _, entry := range res {
+			amount, _ := entry.Amount.Reduce(&entry.Amount)
+			entries = append(entries, fmt.Sprintf("%s %f", entry.DateTime.Format("15:04"), amount))
+		}
+
+		return entries
+	}
+
+	// pending deposit before start count toward the first hour.
+	require.Equal(t, []string{"11:00 10"}, balance(false))
+	require.Equal(t, []string{"11:00 15", "13:00 17"}, balance(true))
+
+	event, err := svc.SetDepositStatus(ctx, late.ID, anymind.DepositConfirmed)
+	require.NoError(t, err)
+	require.Equal(t, late.ID, event.ID)
+	require.Equal(t, 0, event.Amount.Cmp(&late.Amount))
+	require.Equal(t, []string{"11:00 10", "13:00 12"}, balance(false))
+
+	_, err = svc.SetDepositStatus(ctx, early.ID, anymind.DepositFailed)
+	require.NoError(t, err)
+	require.Equal(t, []string{"11:00 10", "13:00 12"}, balance(true))
+
+	// settled deposit is not pending anymore.
+	_, err = svc.SetDepositStatus(ctx, late.ID, anymind.DepositFailed)
+	require.ErrorIs(t, err, anymind.ErrNotFound)
+
+	deposit("2020-01-01T12:45:00Z", "3", anymind.DepositPending)
+	n, err := svc.ExpirePendingDeposits(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	n, err = svc.ExpirePendingDeposits(ctx, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, int64(1), n)
+	require.Equal(t, []string{"11:00 10", "13:00 12"}, balance(true))
+
+	// deposit is settled by the principal that made it or by an admin.
+	alice := anymind.WithPrincipal(ctx, &anymind.Principal{Subject: "alice", Scopes: []string{anymind.ScopeDepositWrite}})
+	bob := anymind.WithPrincipal(ctx, &anymind.Principal{Subject: "bob", Scopes: []string{anymind.ScopeDepositWrite}})
+	admin := anymind.WithPrincipal(ctx, &anymind.Principal{Subject: "carol", Scopes: []string{anymind.ScopeAdmin}})
+
+	owned := &anymind.DepositInput{DateTime: mustTime("2020-01-01T12:50:00Z"), Amount: mustApd("1"), Status: anymind.DepositPending}
+	require.NoError(t, svc.Deposit(alice, owned))
+	_, err = svc.SetDepositStatus(bob, owned.ID, anymind.DepositConfirmed)
+	require.ErrorIs(t, err, anymind.ErrNotFound)
+	_, err = svc.SetDepositStatus(ctx, owned.ID, anymind.DepositConfirmed)
+	require.ErrorIs(t, err, anymind.ErrNotFound)
+	_, err = svc.SetDepositStatus(alice, owned.ID, anymind.DepositConfirmed)
+	require.NoError(t, err)
+
+	owned = &anymind.DepositInput{DateTime: mustTime("2020-01-01T12:55:00Z"), Amount: mustApd("1"), Status: anymind.DepositPending}
+	require.NoError(t, svc.Deposit(alice, owned))
+	_, err = svc.SetDepositStatus(admin, owned.ID, anymind.DepositFailed)
+	require.NoError(t, err)
+}
+
+func TestNonces(t *testing.T) {
+	db := connTestDB(SchemaUp)
+	defer db.Close()
+
+	svc := NewService(db)
+	ctx := context.Background()
+	now := mustTime("2020-01-01T10:00:00Z")
+
+	claimed, err := svc.ClaimNonce(ctx, "partner", "nonce", now, now.Add(time.Minute))
+	require.NoError(t, err)
+	require.True(t, claimed)
+
+	// nonce is claimed once until it expires, nonce of other partner is not a replay.
+	claimed, err = svc.ClaimNonce(ctx, "partner", "nonce", now, now.Add(time.Minute))
+	require.NoError(t, err)
+	require.False(t, claimed)
+
+	claimed, err = svc.ClaimNonce(ctx, "other", "nonce", now, now.Add(time.Minute))
+	require.NoError(t, err)
+	require.True(t, claimed)
+
+	later := now.Add(2 * time.Minute)
+	claimed, err = svc.ClaimNonce(ctx, "partner", "nonce", later, later.Add(time.Minute))
+	require.NoError(t, err)
+	require.True(t, claimed)
+
+	require.NoError(t, svc.PruneNonces(ctx, later))
+	var count int
+	require.NoError(t, db.QueryRowContext(ctx, "SELECT COUNT(*) FROM request_nonces").Scan(&count))
+	require.Equal(t, 1, count)
+}
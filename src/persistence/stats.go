@@ -0,0 +1,79 @@
+package persistence
+
+import (
+	"anymind"
+	"anymind/src/tracing"
+	"context"
+	"database/sql"
+	"github.com/cockroachdb/apd"
+	"go.uber.org/zap"
+	"time"
+)
+
+var _ anymind.StatsService = &Service{}
+
+func (s Service) Stats(ctx context.Context, req *anymind.HistoricalDataReq) ([]*anymind.BucketStats, error) {
+	// opening balance and buckets are read from the same snapshot.
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable, ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Commit()
+
+	asset := anymind.AssetCode(req.Asset)
+	first := anymind.BucketOf(req.Start)
+	last := req.End.Truncate(time.Hour)
+
+	var open apd.Decimal
+	err = s.balanceAt(ctx, tx, first.Add(-time.Hour), asset, &open)
+	if err != nil {
+		return nil, err
+	}
+
+	qctx, done := s.query(ctx, "selectStatsQuery")
+	rows, err := tx.QueryContext(qctx, selectStatsQuery, first, last, asset)
+	done(err)
+	if err != nil {
+		tracing.Logger(ctx, s.logger).Error("failed to execute selectStatsQuery", zap.Error(err))
+
+		return nil, err
+	}
+	defer rows.Close()
+
+	var buckets []*anymind.BucketStats
+	for rows.Next() {
+		row := &anymind.BucketStats{
+			Min:     new(apd.Decimal),
+			Max:     new(apd.Decimal),
+			Average: new(apd.Decimal),
+		}
+		err = rows.Scan(&row.DateTime, &row.Count, &row.Delta, row.Min, row.Max, row.Average)
+		if err != nil {
+			tracing.Logger(ctx, s.logger).Error("failed to scan selectStatsQuery", zap.Error(err))
+
+			return nil, err
+		}
+
+		buckets = append(buckets, row)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return anymind.FillStats(req, open, buckets)
+}
+
+// balanceAt scan balance of asset including deposits at t, t must be on an hour.
+func (s Service) balanceAt(ctx context.Context, tx *sql.Tx, t time.Time, asset string, balance *apd.Decimal) error {
+	qctx, done := s.query(ctx, "selectBalanceAtQuery")
+	err := tx.QueryRowContext(qctx, selectBalanceAtQuery, t, asset).Scan(balance)
+	done(err)
+	if err != nil {
+		tracing.Logger(ctx, s.logger).Error("failed to execute selectBalanceAtQuery", zap.Error(err))
+
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,243 @@
+package persistence
+
+import (
+	"anymind"
+	"anymind/src/tracing"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/cockroachdb/apd"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+	"time"
+)
+
+var _ anymind.WebhookPersistenceService = &Service{}
+
+// enqueueWebhooks write outbox deliveries for committed deposit within deposit transaction.
+func (s Service) enqueueWebhooks(ctx context.Context, tx *sql.Tx, ev *anymind.DepositEvent, payload string) error {
+	now := time.Now().UTC()
+
+	qctx, done := s.query(ctx, "insertDepositOutboxQuery")
+	_, err := tx.ExecContext(qctx, insertDepositOutboxQuery, anymind.WebhookEventDeposit, payload, now)
+	done(err)
+	if err != nil {
+		tracing.Logger(ctx, s.logger).Error("failed to execute insertDepositOutboxQuery", zap.Error(err))
+
+		return err
+	}
+
+	qctx, done = s.query(ctx, "insertThresholdOutboxQuery")
+	_, err = tx.ExecContext(qctx, insertThresholdOutboxQuery, anymind.WebhookEventBalanceThreshold, ev.Amount, ev.ID, now, ev.Asset)
+	done(err)
+	if err != nil {
+		tracing.Logger(ctx, s.logger).Error("failed to execute insertThresholdOutboxQuery", zap.Error(err))
+
+		return err
+	}
+
+	return nil
+}
+
+func (s Service) CreateWebhook(ctx context.Context, sub *anymind.WebhookSubscription) error {
+	thresholds := make([]string, len(sub.Thresholds))
+	for i := range sub.Thresholds {
+		thresholds[i] = fmt.Sprintf("%f", &sub.Thresholds[i])
+	}
+
+	createdAt := time.Now().UTC().Truncate(time.Microsecond)
+	err := s.audited(ctx, anymind.AuditWebhookCreate, func(tx *sql.Tx) (any, error) {
+		sub.Asset = anymind.AssetCode(sub.Asset)
+		err := tx.QueryRowContext(ctx, insertWebhookQuery, sub.URL, sub.Events, sub.Secret, thresholds, createdAt, sub.Asset).
+			Scan(&sub.ID)
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolationCode {
+			return nil, anymind.ErrNotFound
+		}
+		if err != nil {
+			tracing.Logger(ctx, s.logger).Error("failed to execute insertWebhookQuery", zap.Error(err))
+
+			return nil, err
+		}
+
+		// secret is left out, audit log is readable by every admin.
+		return map[string]any{"id": sub.ID, "url": sub.URL, "events": sub.Events, "thresholds": thresholds, "asset": sub.Asset}, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	sub.CreatedAt = createdAt
+
+	return nil
+}
+
+func (s Service) ListWebhooks(ctx context.Context) ([]*anymind.WebhookSubscription, error) {
+	rows, err := s.db.QueryContext(ctx, selectWebhooksQuery)
+	if err != nil {
+		tracing.Logger(ctx, s.logger).Error("failed to execute selectWebhooksQuery", zap.Error(err))
+
+		return nil, err
+	}
+	defer rows.Close()
+
+	types := pgtype.NewMap()
+
+	var res []*anymind.WebhookSubscription
+	for rows.Next() {
+		var row anymind.WebhookSubscription
+		var thresholds []string
+		err = rows.Scan(
+			&row.ID,
+			&row.URL,
+			types.SQLScanner(&row.Events),
+			&row.Secret,
+			types.SQLScanner(&thresholds),
+			&row.CreatedAt,
+			&row.Asset)
+		if err != nil {
+			tracing.Logger(ctx, s.logger).Error("failed to scan selectWebhooksQuery", zap.Error(err))
+
+			return nil, err
+		}
+
+		row.Thresholds = make([]apd.Decimal, len(thresholds))
+		for i := range thresholds {
+			_, _, err = row.Thresholds[i].SetString(thresholds[i])
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		res = append(res, &row)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		tracing.Logger(ctx, s.logger).Error("error on next selectWebhooksQuery", zap.Error(err))
+
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (s Service) DeleteWebhook(ctx context.Context, id int64) error {
+	return s.audited(ctx, anymind.AuditWebhookDelete, func(tx *sql.Tx) (any, error) {
+		res, err := tx.ExecContext(ctx, deleteWebhookQuery, id)
+		if err != nil {
+			tracing.Logger(ctx, s.logger).Error("failed to execute deleteWebhookQuery", zap.Error(err))
+
+			return nil, err
+		}
+
+		return map[string]any{"id": id}, requireAffected(res)
+	})
+}
+
+func (s Service) ClaimDeliveries(
+	ctx context.Context,
+	now time.Time,
+	leaseUntil time.Time,
+	limit int,
+) ([]*anymind.WebhookDelivery, error) {
+	rows, err := s.db.QueryContext(ctx, claimDeliveriesQuery, now.UTC(), leaseUntil.UTC(), limit)
+	if err != nil {
+		tracing.Logger(ctx, s.logger).Error("failed to execute claimDeliveriesQuery", zap.Error(err))
+
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanDeliveries(rows)
+}
+
+func (s Service) UpdateDelivery(ctx context.Context, d *anymind.WebhookDelivery) error {
+	var deliveredAt sql.NullTime
+	if d.DeliveredAt != nil {
+		deliveredAt = sql.NullTime{Time: d.DeliveredAt.UTC(), Valid: true}
+	}
+
+	res, err := s.db.ExecContext(ctx, updateDeliveryQuery, d.ID, d.Status, d.NextAttemptAt.UTC(), d.LastError, deliveredAt)
+	if err != nil {
+		tracing.Logger(ctx, s.logger).Error("failed to execute updateDeliveryQuery", zap.Error(err))
+
+		return err
+	}
+
+	return requireAffected(res)
+}
+
+func (s Service) ListDeliveries(ctx context.Context, status string, limit int) ([]*anymind.WebhookDelivery, error) {
+	rows, err := s.db.QueryContext(ctx, selectDeliveriesQuery, status, limit)
+	if err != nil {
+		tracing.Logger(ctx, s.logger).Error("failed to execute selectDeliveriesQuery", zap.Error(err))
+
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanDeliveries(rows)
+}
+
+func (s Service) RedeliverWebhook(ctx context.Context, id int64, at time.Time) error {
+	return s.audited(ctx, anymind.AuditWebhookRedeliver, func(tx *sql.Tx) (any, error) {
+		res, err := tx.ExecContext(ctx, redeliverQuery, id, at.UTC())
+		if err != nil {
+			tracing.Logger(ctx, s.logger).Error("failed to execute redeliverQuery", zap.Error(err))
+
+			return nil, err
+		}
+
+		return map[string]any{"id": id}, requireAffected(res)
+	})
+}
+
+func scanDeliveries(rows *sql.Rows) ([]*anymind.WebhookDelivery, error) {
+	var res []*anymind.WebhookDelivery
+	for rows.Next() {
+		var row anymind.WebhookDelivery
+		var payload string
+		var deliveredAt sql.NullTime
+		err := rows.Scan(
+			&row.ID,
+			&row.SubscriptionID,
+			&row.URL,
+			&row.Secret,
+			&row.EventType,
+			&payload,
+			&row.Status,
+			&row.Attempts,
+			&row.NextAttemptAt,
+			&row.LastError,
+			&row.CreatedAt,
+			&deliveredAt)
+		if err != nil {
+			return nil, err
+		}
+
+		row.Payload = []byte(payload)
+		if deliveredAt.Valid {
+			row.DeliveredAt = &deliveredAt.Time
+		}
+
+		res = append(res, &row)
+	}
+
+	return res, rows.Err()
+}
+
+func requireAffected(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return anymind.ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,128 @@
+package pricefeed
+
+import (
+	"anymind"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"io"
+	"strings"
+	"time"
+)
+
+var _ anymind.PriceService = &Service{}
+
+// csvHeader is header of rates csv file, see ParseCSV.
+var csvHeader = []string{"hour", "base", "quote", "rate"}
+
+// Service validate imported hourly exchange rates and read them back for valuation.
+type Service struct {
+	store  anymind.PriceService
+	logger *zap.Logger
+}
+
+func NewService(store anymind.PriceService, opts ...Option) *Service {
+	s := &Service{
+		store: store,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if s.logger == nil {
+		s.logger = zap.NewNop()
+	}
+
+	return s
+}
+
+// ImportRates import every rate or none, rate of an already imported pair and hour is replaced.
+func (s *Service) ImportRates(ctx context.Context, rates []*anymind.Rate) error {
+	if len(rates) == 0 {
+		return anymind.ParameterError(errors.New("no rate to import"))
+	}
+
+	for i, rate := range rates {
+		err := rate.Validate()
+		if err != nil {
+			return anymind.ParameterError(fmt.Errorf("rate %d: %w", i, err))
+		}
+
+		rate.Hour = rate.Hour.UTC()
+	}
+
+	err := s.store.ImportRates(ctx, rates)
+	if errors.Is(err, anymind.ErrNotFound) {
+		return anymind.ParameterError(errors.New("rates refer to unknown asset"))
+	}
+	if err != nil {
+		return anymind.InternalError(err)
+	}
+
+	s.logger.Info("rates imported", zap.Int("count", len(rates)))
+
+	return nil
+}
+
+func (s *Service) Rates(
+	ctx context.Context,
+	base string,
+	quote string,
+	start time.Time,
+	end time.Time,
+) ([]*anymind.Rate, error) {
+	res, err := s.store.Rates(ctx, base, quote, start, end)
+	if err != nil {
+		return nil, anymind.InternalError(err)
+	}
+
+	return res, nil
+}
+
+// ParseCSV read rates from csv with hour,base,quote,rate header, hour is RFC3339 and rate is decimal, e.g.
+//
+//	hour,base,quote,rate
+//	2022-01-01T10:00:00Z,BTC,USD,46000.25
+func ParseCSV(r io.Reader) ([]*anymind.Rate, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = len(csvHeader)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("rates header: %w", err)
+	}
+
+	if strings.Join(header, ",") != strings.Join(csvHeader, ",") {
+		return nil, fmt.Errorf("rates header must be %s", strings.Join(csvHeader, ","))
+	}
+
+	var res []*anymind.Rate
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return res, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		rate := &anymind.Rate{Base: record[1], Quote: record[2]}
+
+		rate.Hour, err = time.Parse(time.RFC3339, record[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid hour: %w", line, err)
+		}
+
+		_, _, err = rate.Rate.SetString(record[3])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid rate: %w", line, err)
+		}
+
+		res = append(res, rate)
+	}
+}
@@ -0,0 +1,89 @@
+package pricefeed
+
+import (
+	"anymind"
+	"anymind/src/mock"
+	"context"
+	"github.com/cockroachdb/apd"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+	"time"
+)
+
+func mustApd(number string) apd.Decimal {
+	val, _, err := apd.NewFromString(number)
+	if err != nil {
+		panic(err)
+	}
+
+	return *val
+}
+
+func TestImportRates(t *testing.T) {
+	hour := time.Date(2022, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name string
+		rate *anymind.Rate
+		err  string
+	}{
+		{name: "valid", rate: &anymind.Rate{Base: "BTC", Quote: "USD", Hour: hour, Rate: mustApd("46000.25")}},
+		{name: "same asset", rate: &anymind.Rate{Base: "BTC", Quote: "BTC", Hour: hour, Rate: mustApd("1")},
+			err: "base and quote asset must differ"},
+		{name: "not on the hour", rate: &anymind.Rate{Base: "BTC", Quote: "USD", Hour: hour.Add(time.Minute), Rate: mustApd("1")},
+			err: "rate hour must be on the hour"},
+		{name: "zero", rate: &anymind.Rate{Base: "BTC", Quote: "USD", Hour: hour, Rate: mustApd("0")},
+			err: "rate must be positive"},
+		{name: "invalid code", rate: &anymind.Rate{Base: "btc", Quote: "USD", Hour: hour, Rate: mustApd("1")},
+			err: "invalid asset pair"},
+		{name: "unknown asset", rate: &anymind.Rate{Base: "DOGE", Quote: "USD", Hour: hour, Rate: mustApd("1")},
+			err: "rates refer to unknown asset"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			svc := NewService(&mock.PriceServiceMock{
+				ImportRatesFunc: func(_ context.Context, rates []*anymind.Rate) error {
+					if rates[0].Base == "DOGE" {
+						return anymind.ErrNotFound
+					}
+
+					return nil
+				},
+			})
+
+			err := svc.ImportRates(context.Background(), []*anymind.Rate{tc.rate})
+			if tc.err == "" {
+				require.NoError(t, err)
+
+				return
+			}
+
+			anyErr := anymind.ParameterError(nil)
+			require.ErrorAs(t, err, &anyErr)
+			require.Equal(t, anymind.ParameterErr, anyErr.Type)
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+}
+
+func TestParseCSV(t *testing.T) {
+	rates, err := ParseCSV(strings.NewReader(`hour,base,quote,rate
+2022-01-01T10:00:00Z,BTC,USD,46000.25
+2022-01-01T11:00:00+07:00, ETH, USD, 3700
+`))
+	require.NoError(t, err)
+	require.Equal(t, []*anymind.Rate{
+		{Hour: time.Date(2022, 1, 1, 10, 0, 0, 0, time.UTC), Base: "BTC", Quote: "USD", Rate: mustApd("46000.25")},
+		{Hour: time.Date(2022, 1, 1, 11, 0, 0, 0, time.FixedZone("", 7*60*60)), Base: "ETH", Quote: "USD", Rate: mustApd("3700")},
+	}, rates)
+
+	_, err = ParseCSV(strings.NewReader("base,quote,rate,hour\n"))
+	require.ErrorContains(t, err, "rates header must be hour,base,quote,rate")
+
+	_, err = ParseCSV(strings.NewReader("hour,base,quote,rate\n2022-01-01T10:00:00Z,BTC,USD,abc\n"))
+	require.ErrorContains(t, err, "line 2: invalid rate")
+}
@@ -0,0 +1,21 @@
+package reqsign
+
+import (
+	"go.uber.org/zap"
+	"time"
+)
+
+type Option func(*Service)
+
+func WithLogger(logger *zap.Logger) Option {
+	return func(svc *Service) {
+		svc.logger = logger
+	}
+}
+
+// WithWindow set how far signature timestamp may be from server time, nonce is remembered for as long.
+func WithWindow(window time.Duration) Option {
+	return func(svc *Service) {
+		svc.window = window
+	}
+}
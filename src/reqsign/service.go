@@ -0,0 +1,127 @@
+package reqsign
+
+import (
+	"anymind"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var _ anymind.RequestVerifier = &Service{}
+
+// Service verify requests signed with per partner secret. Nonces are kept in store shared by every instance, so a
+// request is accepted once whichever instance it is sent to.
+type Service struct {
+	secrets map[string]string
+	nonces  anymind.NonceStore
+	window  time.Duration
+	logger  *zap.Logger
+	now     func() time.Time
+
+	mu        sync.Mutex
+	nextPrune time.Time
+}
+
+// NewService create verifier accepting signatures made with secrets, keyed by partner key id.
+func NewService(secrets map[string]string, nonces anymind.NonceStore, opts ...Option) *Service {
+	s := &Service{
+		secrets: secrets,
+		nonces:  nonces,
+		window:  5 * time.Minute,
+		now:     time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if s.logger == nil {
+		s.logger = zap.NewNop()
+	}
+
+	return s
+}
+
+// Sign return hex encoded hmac-sha256 of req with secret, req.Signature is ignored.
+// Signed message is method, path, timestamp, nonce and hex sha256 of body, separated by new line.
+func Sign(secret string, req *anymind.SignedRequest) string {
+	digest := sha256.Sum256(req.Body)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strings.Join([]string{
+		req.Method,
+		req.Path,
+		req.Timestamp,
+		req.Nonce,
+		hex.EncodeToString(digest[:]),
+	}, "\n")))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *Service) Verify(ctx context.Context, req *anymind.SignedRequest) error {
+	if req.KeyID == "" || req.Timestamp == "" || req.Nonce == "" || req.Signature == "" {
+		return anymind.UnauthorizedError(errors.New("request is not signed"))
+	}
+
+	secret, ok := s.secrets[req.KeyID]
+	if !ok {
+		return anymind.UnauthorizedError(fmt.Errorf("unknown signing key %q", req.KeyID))
+	}
+
+	ts, err := strconv.ParseInt(req.Timestamp, 10, 64)
+	if err != nil {
+		return anymind.UnauthorizedError(errors.New("invalid signature timestamp"))
+	}
+
+	now := s.now()
+	signedAt := time.Unix(ts, 0)
+	if signedAt.Before(now.Add(-s.window)) || signedAt.After(now.Add(s.window)) {
+		return anymind.UnauthorizedError(errors.New("signature timestamp is outside allowed window"))
+	}
+
+	expected := Sign(secret, req)
+	if !hmac.Equal([]byte(expected), []byte(strings.ToLower(req.Signature))) {
+		return anymind.UnauthorizedError(errors.New("invalid signature"))
+	}
+
+	// nonce is checked last, so request with invalid signature can not burn nonce of a valid one.
+	s.prune(ctx, now)
+	claimed, err := s.nonces.ClaimNonce(ctx, req.KeyID, req.Nonce, now, signedAt.Add(s.window))
+	if err != nil {
+		return anymind.InternalError(err)
+	}
+
+	if !claimed {
+		s.logger.Warn("replayed request rejected", zap.String("key", req.KeyID), zap.String("nonce", req.Nonce))
+
+		return anymind.UnauthorizedError(errors.New("nonce already used"))
+	}
+
+	return nil
+}
+
+// prune forget expired nonces once every window, failure is only logged as expired nonce can be claimed again.
+func (s *Service) prune(ctx context.Context, now time.Time) {
+	s.mu.Lock()
+	if !now.After(s.nextPrune) {
+		s.mu.Unlock()
+
+		return
+	}
+	s.nextPrune = now.Add(s.window)
+	s.mu.Unlock()
+
+	err := s.nonces.PruneNonces(ctx, now)
+	if err != nil {
+		s.logger.Error("failed to prune nonces", zap.Error(err))
+	}
+}
@@ -0,0 +1,170 @@
+package reqsign
+
+import (
+	"anymind"
+	"anymind/src/mock"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"strconv"
+	"testing"
+	"time"
+)
+
+var now = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func signed(secret string, update func(*anymind.SignedRequest)) *anymind.SignedRequest {
+	req := &anymind.SignedRequest{
+		KeyID:     "partner",
+		Method:    "POST",
+		Path:      "/deposit",
+		Timestamp: strconv.FormatInt(now.Unix(), 10),
+		Nonce:     "nonce",
+		Body:      []byte(`{"datetime":"2020-01-01T00:00:00Z","amount":"1"}`),
+	}
+	req.Signature = Sign(secret, req)
+
+	if update != nil {
+		update(req)
+	}
+
+	return req
+}
+
+// nonceStore keep nonces in memory like request_nonces table does.
+func nonceStore() (*mock.NonceStoreMock, map[string]time.Time) {
+	nonces := map[string]time.Time{}
+
+	return &mock.NonceStoreMock{
+		ClaimNonceFunc: func(_ context.Context, keyID, nonce string, now, expiresAt time.Time) (bool, error) {
+			if exp, ok := nonces[keyID+"\n"+nonce]; ok && !exp.Before(now) {
+				return false, nil
+			}
+
+			nonces[keyID+"\n"+nonce] = expiresAt
+
+			return true, nil
+		},
+		PruneNoncesFunc: func(_ context.Context, before time.Time) error {
+			for n, exp := range nonces {
+				if exp.Before(before) {
+					delete(nonces, n)
+				}
+			}
+
+			return nil
+		},
+	}, nonces
+}
+
+func requireUnauthorized(t *testing.T, err error) {
+	anyErr := anymind.ParameterError(nil)
+	require.ErrorAs(t, err, &anyErr)
+	require.Equal(t, anymind.UnauthorizedErr, anyErr.Type)
+}
+
+func TestVerify(t *testing.T) {
+	testCases := []struct {
+		name   string
+		req    *anymind.SignedRequest
+		accept bool
+	}{
+		{name: "valid", req: signed("secret", nil), accept: true},
+		{name: "unsigned", req: signed("secret", func(r *anymind.SignedRequest) { r.Signature = "" })},
+		{name: "unknown key", req: signed("secret", func(r *anymind.SignedRequest) { r.KeyID = "other" })},
+		{name: "wrong secret", req: signed("other", nil)},
+		{name: "tampered body", req: signed("secret", func(r *anymind.SignedRequest) { r.Body = []byte(`{"amount":"1000"}`) })},
+		{name: "tampered path", req: signed("secret", func(r *anymind.SignedRequest) { r.Path = "/historical" })},
+		{name: "tampered method", req: signed("secret", func(r *anymind.SignedRequest) { r.Method = "PUT" })},
+		{name: "invalid timestamp", req: signed("secret", func(r *anymind.SignedRequest) { r.Timestamp = "yesterday" })},
+		{
+			name: "expired",
+			req: signed("secret", func(r *anymind.SignedRequest) {
+				r.Timestamp = strconv.FormatInt(now.Add(-10*time.Minute).Unix(), 10)
+				r.Signature = Sign("secret", r)
+			}),
+		},
+		{
+			name: "future",
+			req: signed("secret", func(r *anymind.SignedRequest) {
+				r.Timestamp = strconv.FormatInt(now.Add(10*time.Minute).Unix(), 10)
+				r.Signature = Sign("secret", r)
+			}),
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			store, _ := nonceStore()
+			svc := NewService(map[string]string{"partner": "secret"}, store)
+			svc.now = func() time.Time { return now }
+
+			err := svc.Verify(context.Background(), tc.req)
+			if tc.accept {
+				require.NoError(t, err)
+			} else {
+				requireUnauthorized(t, err)
+			}
+		})
+	}
+}
+
+func TestReplay(t *testing.T) {
+	clock := now
+	store, nonces := nonceStore()
+	svc := NewService(map[string]string{"partner": "secret", "other": "secret"}, store, WithWindow(time.Minute))
+	svc.now = func() time.Time { return clock }
+	ctx := context.Background()
+
+	req := signed("secret", nil)
+	require.NoError(t, svc.Verify(ctx, req))
+	requireUnauthorized(t, svc.Verify(ctx, req))
+
+	// invalid signature must not burn the nonce.
+	fresh := signed("secret", func(r *anymind.SignedRequest) {
+		r.Nonce = "fresh"
+		r.Signature = Sign("secret", r)
+	})
+	requireUnauthorized(t, svc.Verify(ctx, signed("secret", func(r *anymind.SignedRequest) {
+		r.Nonce = "fresh"
+		r.Signature = Sign("wrong", r)
+	})))
+	require.NoError(t, svc.Verify(ctx, fresh))
+
+	// same nonce of other partner is not a replay.
+	require.NoError(t, svc.Verify(ctx, signed("secret", func(r *anymind.SignedRequest) {
+		r.KeyID = "other"
+		r.Signature = Sign("secret", r)
+	})))
+
+	// nonce is forgotten once its timestamp is out of window.
+	clock = clock.Add(2 * time.Minute)
+	require.NoError(t, svc.Verify(ctx, signed("secret", func(r *anymind.SignedRequest) {
+		r.Nonce = "next"
+		r.Timestamp = strconv.FormatInt(clock.Unix(), 10)
+		r.Signature = Sign("secret", r)
+	})))
+	require.Len(t, nonces, 1)
+	require.Len(t, store.PruneNoncesCalls(), 2)
+}
+
+func TestNonceStoreFailure(t *testing.T) {
+	store := &mock.NonceStoreMock{
+		ClaimNonceFunc: func(_ context.Context, _, _ string, _, _ time.Time) (bool, error) {
+			return false, errors.New("connection refused")
+		},
+		PruneNoncesFunc: func(_ context.Context, _ time.Time) error {
+			return nil
+		},
+	}
+	svc := NewService(map[string]string{"partner": "secret"}, store)
+	svc.now = func() time.Time { return now }
+
+	// request is not accepted when its nonce can not be recorded.
+	err := svc.Verify(context.Background(), signed("secret", nil))
+	anyErr := anymind.ParameterError(nil)
+	require.ErrorAs(t, err, &anyErr)
+	require.Equal(t, anymind.InternalErr, anyErr.Type)
+}
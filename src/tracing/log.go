@@ -0,0 +1,28 @@
+package tracing
+
+import (
+	"anymind"
+	"context"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// Logger return logger adding request id, trace and span id of ctx to every line.
+func Logger(ctx context.Context, logger *zap.Logger) *zap.Logger {
+	var fields []zap.Field
+	if id, ok := anymind.RequestIDFromContext(ctx); ok {
+		fields = append(fields, zap.String("request_id", id))
+	}
+
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		fields = append(fields,
+			zap.String("trace_id", sc.TraceID().String()),
+			zap.String("span_id", sc.SpanID().String()))
+	}
+
+	if len(fields) == 0 {
+		return logger
+	}
+
+	return logger.With(fields...)
+}
@@ -0,0 +1,21 @@
+package tracing
+
+type Option func(*config)
+
+type config struct {
+	serviceName string
+	sampleRatio float64
+}
+
+func WithServiceName(name string) Option {
+	return func(c *config) {
+		c.serviceName = name
+	}
+}
+
+// WithSampleRatio set fraction of new traces that are recorded, trace started by caller follow caller decision.
+func WithSampleRatio(ratio float64) Option {
+	return func(c *config) {
+		c.sampleRatio = ratio
+	}
+}
@@ -0,0 +1,88 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"os"
+	"strings"
+)
+
+// Supported exporters, see NewProvider.
+const (
+	ExporterNone     = "none"
+	ExporterStdout   = "stdout"
+	ExporterOTLP     = "otlp"
+	ExporterOTLPFile = "otlpfile"
+)
+
+// NewProvider create tracer provider exporting spans with given exporter:
+//   - "none" or empty: spans are not recorded
+//   - "stdout": spans are printed as json to stdout
+//   - "otlp": spans are sent over otlp/http, endpoint is configured by OTEL_EXPORTER_OTLP_* env
+//   - "otlpfile:<path>": spans are appended to file in otlp json lines format, for offline use
+//
+// Provider must be shut down to flush pending spans.
+func NewProvider(ctx context.Context, exporter string, opts ...Option) (*sdktrace.TracerProvider, error) {
+	cfg := &config{
+		serviceName: "anymind",
+		sampleRatio: 1,
+	}
+
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(cfg.serviceName),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	providerOpts := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.sampleRatio))),
+	}
+
+	kind, path, _ := strings.Cut(exporter, ":")
+	switch kind {
+	case "", ExporterNone:
+		providerOpts = append(providerOpts, sdktrace.WithSampler(sdktrace.NeverSample()))
+	case ExporterStdout:
+		exp, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+		if err != nil {
+			return nil, err
+		}
+
+		providerOpts = append(providerOpts, sdktrace.WithBatcher(exp))
+	case ExporterOTLP:
+		exp, err := otlptracehttp.New(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		providerOpts = append(providerOpts, sdktrace.WithBatcher(exp))
+	case ExporterOTLPFile:
+		if path == "" {
+			return nil, fmt.Errorf("missing file path of %s exporter", ExporterOTLPFile)
+		}
+
+		exp, err := otlptrace.New(ctx, &fileClient{path: path})
+		if err != nil {
+			return nil, err
+		}
+
+		providerOpts = append(providerOpts, sdktrace.WithBatcher(exp))
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q", exporter)
+	}
+
+	return sdktrace.NewTracerProvider(providerOpts...), nil
+}
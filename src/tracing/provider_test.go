@@ -0,0 +1,80 @@
+package tracing
+
+import (
+	"anymind"
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOTLPFileExporter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traces.jsonl")
+	ctx := context.Background()
+
+	provider, err := NewProvider(ctx, ExporterOTLPFile+":"+path, WithServiceName("test"))
+	require.NoError(t, err)
+
+	_, span := provider.Tracer("test").Start(ctx, "deposit")
+	span.End()
+	require.NoError(t, provider.Shutdown(ctx))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 1)
+
+	var req struct {
+		ResourceSpans []struct {
+			ScopeSpans []struct {
+				Spans []struct {
+					Name    string `json:"name"`
+					TraceID string `json:"traceId"`
+				} `json:"spans"`
+			} `json:"scopeSpans"`
+		} `json:"resourceSpans"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &req))
+	require.Equal(t, "deposit", req.ResourceSpans[0].ScopeSpans[0].Spans[0].Name)
+	require.NotEmpty(t, req.ResourceSpans[0].ScopeSpans[0].Spans[0].TraceID)
+}
+
+func TestNewProviderInvalid(t *testing.T) {
+	_, err := NewProvider(context.Background(), "jaeger")
+	require.Error(t, err)
+
+	_, err = NewProvider(context.Background(), ExporterOTLPFile)
+	require.Error(t, err)
+}
+
+func TestLogger(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	logger := zap.New(core)
+	ctx := context.Background()
+
+	Logger(ctx, logger).Info("no span")
+
+	provider, err := NewProvider(ctx, ExporterNone)
+	require.NoError(t, err)
+
+	ctx, span := provider.Tracer("test").Start(ctx, "deposit")
+	defer span.End()
+
+	Logger(ctx, logger).Info("in span")
+	Logger(anymind.WithRequestID(ctx, "req-1"), logger).Info("in request")
+
+	entries := logs.All()
+	require.Len(t, entries, 3)
+	require.NotContains(t, entries[0].ContextMap(), "trace_id")
+	require.Equal(t, span.SpanContext().TraceID().String(), entries[1].ContextMap()["trace_id"])
+	require.Equal(t, span.SpanContext().SpanID().String(), entries[1].ContextMap()["span_id"])
+	require.NotContains(t, entries[1].ContextMap(), "request_id")
+	require.Equal(t, "req-1", entries[2].ContextMap()["request_id"])
+	require.Equal(t, span.SpanContext().TraceID().String(), entries[2].ContextMap()["trace_id"])
+}
@@ -0,0 +1,45 @@
+package webhook
+
+import (
+	"go.uber.org/zap"
+	"net/http"
+	"time"
+)
+
+type Option func(*Service)
+
+func WithLogger(logger *zap.Logger) Option {
+	return func(svc *Service) {
+		svc.logger = logger
+	}
+}
+
+// WithHTTPClient set client used to deliver webhook.
+func WithHTTPClient(client *http.Client) Option {
+	return func(svc *Service) {
+		svc.client = client
+	}
+}
+
+// WithPollInterval set how often outbox is checked for due deliveries.
+func WithPollInterval(interval time.Duration) Option {
+	return func(svc *Service) {
+		svc.pollInterval = interval
+	}
+}
+
+// WithMaxAttempts set number of attempts before delivery is marked as dead.
+func WithMaxAttempts(attempts int) Option {
+	return func(svc *Service) {
+		svc.maxAttempts = attempts
+	}
+}
+
+// WithBackoff set retry delay of the first failed attempt and the maximum delay.
+// Delay is doubled on each subsequent attempt.
+func WithBackoff(base time.Duration, max time.Duration) Option {
+	return func(svc *Service) {
+		svc.backoffBase = base
+		svc.backoffMax = max
+	}
+}
@@ -0,0 +1,287 @@
+package webhook
+
+import (
+	"anymind"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/cockroachdb/apd"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+var _ anymind.WebhookService = &Service{}
+
+const (
+	batchSize         = 50
+	listDeliveryLimit = 100
+	maxErrorLength    = 512
+)
+
+// Service dispatch outbox deliveries and manage webhook subscriptions.
+type Service struct {
+	store        anymind.WebhookPersistenceService
+	client       *http.Client
+	logger       *zap.Logger
+	pollInterval time.Duration
+	maxAttempts  int
+	backoffBase  time.Duration
+	backoffMax   time.Duration
+	now          func() time.Time
+}
+
+type envelope struct {
+	ID        int64           `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"createdAt"`
+	Data      json.RawMessage `json:"data"`
+}
+
+func NewService(store anymind.WebhookPersistenceService, opts ...Option) *Service {
+	s := &Service{
+		store:        store,
+		client:       &http.Client{Timeout: 10 * time.Second},
+		pollInterval: time.Second,
+		maxAttempts:  10,
+		backoffBase:  10 * time.Second,
+		backoffMax:   time.Hour,
+		now:          time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if s.logger == nil {
+		s.logger = zap.NewNop()
+	}
+
+	return s
+}
+
+// Start dispatch due deliveries until ctx is done.
+func (s *Service) Start(ctx context.Context) error {
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			n, err := s.dispatch(ctx)
+			if err != nil {
+				s.logger.Error("failed to dispatch webhook", zap.Error(err))
+			}
+
+			// keep draining outbox while batch is full.
+			if err != nil || n < batchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// dispatch claim one batch of due deliveries and attempt them, it return number of claimed deliveries.
+func (s *Service) dispatch(ctx context.Context) (int, error) {
+	now := s.now()
+
+	// lease must outlive the http timeout so no other dispatcher picks up in-flight delivery.
+	deliveries, err := s.store.ClaimDeliveries(ctx, now, now.Add(s.client.Timeout+time.Minute), batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	// a failed update leave its delivery leased until lease expire, the rest of the batch is still attempted.
+	for _, d := range deliveries {
+		err = s.attempt(ctx, d)
+		if err != nil {
+			s.logger.Error("failed to record webhook delivery", zap.Int64("delivery", d.ID), zap.Error(err))
+		}
+	}
+
+	return len(deliveries), nil
+}
+
+// attempt send delivery once then record its outcome.
+func (s *Service) attempt(ctx context.Context, d *anymind.WebhookDelivery) error {
+	err := s.send(ctx, d)
+	now := s.now()
+
+	switch {
+	case err == nil:
+		d.Status = anymind.DeliveryDelivered
+		d.LastError = ""
+		d.DeliveredAt = &now
+	case d.Attempts >= s.maxAttempts:
+		d.Status = anymind.DeliveryDead
+		d.LastError = truncate(err.Error())
+	default:
+		d.Status = anymind.DeliveryPending
+		d.LastError = truncate(err.Error())
+		d.NextAttemptAt = now.Add(s.backoff(d.Attempts))
+	}
+
+	if err != nil {
+		s.logger.Warn("webhook delivery failed",
+			zap.Int64("delivery", d.ID),
+			zap.Int("attempts", d.Attempts),
+			zap.String("status", d.Status),
+			zap.Error(err))
+	}
+
+	return s.store.UpdateDelivery(ctx, d)
+}
+
+func (s *Service) send(ctx context.Context, d *anymind.WebhookDelivery) error {
+	body, err := json.Marshal(&envelope{
+		ID:        d.ID,
+		Type:      d.EventType,
+		CreatedAt: d.CreatedAt.UTC(),
+		Data:      d.Payload,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set(EventHeader, d.EventType)
+	req.Header.Set(DeliveryHeader, fmt.Sprint(d.ID))
+	req.Header.Set(SignatureHeader, Sign(d.Secret, s.now(), body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("receiver responded with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+func (s *Service) backoff(attempts int) time.Duration {
+	delay := s.backoffBase
+	for i := 1; i < attempts && delay < s.backoffMax; i++ {
+		delay *= 2
+	}
+
+	if delay > s.backoffMax {
+		delay = s.backoffMax
+	}
+
+	return delay
+}
+
+func (s *Service) CreateWebhook(ctx context.Context, sub *anymind.WebhookSubscription) error {
+	u, err := url.Parse(sub.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return anymind.ParameterError(errors.New("invalid webhook url"))
+	}
+
+	if len(sub.Events) == 0 {
+		return anymind.ParameterError(errors.New("empty webhook events"))
+	}
+
+	for _, ev := range sub.Events {
+		if ev != anymind.WebhookEventDeposit && ev != anymind.WebhookEventBalanceThreshold {
+			return anymind.ParameterError(fmt.Errorf("unknown webhook event %q", ev))
+		}
+	}
+
+	for i := range sub.Thresholds {
+		if sub.Thresholds[i].Form != apd.Finite || sub.Thresholds[i].Negative {
+			return anymind.ParameterError(errors.New("invalid webhook threshold"))
+		}
+	}
+
+	if sub.Secret == "" {
+		secret := make([]byte, 32)
+		_, err = rand.Read(secret)
+		if err != nil {
+			return anymind.InternalError(err)
+		}
+
+		sub.Secret = hex.EncodeToString(secret)
+	}
+
+	err = s.store.CreateWebhook(ctx, sub)
+	if errors.Is(err, anymind.ErrNotFound) {
+		return anymind.ParameterError(fmt.Errorf("unknown asset %s", sub.Asset))
+	}
+	if err != nil {
+		return anymind.InternalError(err)
+	}
+
+	return nil
+}
+
+func (s *Service) ListWebhooks(ctx context.Context) ([]*anymind.WebhookSubscription, error) {
+	res, err := s.store.ListWebhooks(ctx)
+	if err != nil {
+		return nil, anymind.InternalError(err)
+	}
+
+	return res, nil
+}
+
+func (s *Service) DeleteWebhook(ctx context.Context, id int64) error {
+	return storeError(s.store.DeleteWebhook(ctx, id))
+}
+
+func (s *Service) ListDeliveries(ctx context.Context, status string) ([]*anymind.WebhookDelivery, error) {
+	switch status {
+	case "", anymind.DeliveryPending, anymind.DeliveryDelivered, anymind.DeliveryDead:
+	default:
+		return nil, anymind.ParameterError(fmt.Errorf("unknown delivery status %q", status))
+	}
+
+	res, err := s.store.ListDeliveries(ctx, status, listDeliveryLimit)
+	if err != nil {
+		return nil, anymind.InternalError(err)
+	}
+
+	return res, nil
+}
+
+// Redeliver reset delivery, including dead one, so it is attempted again by dispatcher.
+func (s *Service) Redeliver(ctx context.Context, id int64) error {
+	return storeError(s.store.RedeliverWebhook(ctx, id, s.now()))
+}
+
+func storeError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, anymind.ErrNotFound):
+		return anymind.NotFoundError(err)
+	default:
+		return anymind.InternalError(err)
+	}
+}
+
+func truncate(msg string) string {
+	if len(msg) > maxErrorLength {
+		return msg[:maxErrorLength]
+	}
+
+	return msg
+}
@@ -0,0 +1,216 @@
+package webhook
+
+import (
+	"anymind"
+	"anymind/src/mock"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newDelivery(url string) *anymind.WebhookDelivery {
+	return &anymind.WebhookDelivery{
+		ID:             42,
+		SubscriptionID: 1,
+		URL:            url,
+		Secret:         "topsecret",
+		EventType:      anymind.WebhookEventDeposit,
+		Payload:        []byte(`{"id":1,"datetime":"2020-01-01T00:00:00Z","amount":"1.5"}`),
+		Status:         anymind.DeliveryPending,
+		Attempts:       1,
+		CreatedAt:      time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func storeWith(delivery *anymind.WebhookDelivery, updated *[]anymind.WebhookDelivery) *mock.WebhookPersistenceServiceMock {
+	claimed := false
+
+	return &mock.WebhookPersistenceServiceMock{
+		ClaimDeliveriesFunc: func(
+			_ context.Context,
+			_ time.Time,
+			_ time.Time,
+			_ int,
+		) ([]*anymind.WebhookDelivery, error) {
+			if claimed {
+				return nil, nil
+			}
+
+			claimed = true
+
+			return []*anymind.WebhookDelivery{delivery}, nil
+		},
+		UpdateDeliveryFunc: func(_ context.Context, d *anymind.WebhookDelivery) error {
+			*updated = append(*updated, *d)
+
+			return nil
+		},
+	}
+}
+
+func TestDeliverySigned(t *testing.T) {
+	received := make(chan *http.Request, 1)
+	var body []byte
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		received <- r
+	}))
+	defer receiver.Close()
+
+	var updated []anymind.WebhookDelivery
+	svc := NewService(storeWith(newDelivery(receiver.URL), &updated))
+
+	n, err := svc.dispatch(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+
+	r := <-received
+	require.Equal(t, anymind.WebhookEventDeposit, r.Header.Get(EventHeader))
+	require.Equal(t, "42", r.Header.Get(DeliveryHeader))
+	require.NoError(t, Verify("topsecret", r.Header.Get(SignatureHeader), body, time.Now(), time.Minute))
+	require.Error(t, Verify("othersecret", r.Header.Get(SignatureHeader), body, time.Now(), time.Minute))
+
+	var ev struct {
+		ID   int64           `json:"id"`
+		Type string          `json:"type"`
+		Data json.RawMessage `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(body, &ev))
+	require.Equal(t, int64(42), ev.ID)
+	require.Equal(t, anymind.WebhookEventDeposit, ev.Type)
+	require.JSONEq(t, `{"id":1,"datetime":"2020-01-01T00:00:00Z","amount":"1.5"}`, string(ev.Data))
+
+	require.Len(t, updated, 1)
+	require.Equal(t, anymind.DeliveryDelivered, updated[0].Status)
+	require.NotNil(t, updated[0].DeliveredAt)
+}
+
+func TestDeliveryRetryAndDeadLetter(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer receiver.Close()
+
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		attempts    int
+		status      string
+		nextAttempt time.Time
+	}{
+		{attempts: 1, status: anymind.DeliveryPending, nextAttempt: now.Add(time.Second)},
+		{attempts: 3, status: anymind.DeliveryPending, nextAttempt: now.Add(4 * time.Second)},
+		{attempts: 4, status: anymind.DeliveryPending, nextAttempt: now.Add(5 * time.Second)},
+		{attempts: 5, status: anymind.DeliveryDead},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.status, func(t *testing.T) {
+			delivery := newDelivery(receiver.URL)
+			delivery.Attempts = tc.attempts
+
+			var updated []anymind.WebhookDelivery
+			svc := NewService(
+				storeWith(delivery, &updated),
+				WithMaxAttempts(5),
+				WithBackoff(time.Second, 5*time.Second))
+			svc.now = func() time.Time { return now }
+
+			_, err := svc.dispatch(context.Background())
+			require.NoError(t, err)
+
+			require.Len(t, updated, 1)
+			require.Equal(t, tc.status, updated[0].Status)
+			require.Contains(t, updated[0].LastError, "503")
+			if tc.status == anymind.DeliveryPending {
+				require.Equal(t, tc.nextAttempt, updated[0].NextAttemptAt)
+			}
+		})
+	}
+}
+
+func TestRedeliverNotFound(t *testing.T) {
+	svc := NewService(&mock.WebhookPersistenceServiceMock{
+		RedeliverWebhookFunc: func(_ context.Context, _ int64, _ time.Time) error {
+			return anymind.ErrNotFound
+		},
+	})
+
+	err := svc.Redeliver(context.Background(), 1)
+
+	anyErr := anymind.NotFoundError(nil)
+	require.ErrorAs(t, err, &anyErr)
+	require.Equal(t, anymind.NotFoundErr, anyErr.Type)
+}
+
+func TestCreateWebhookInvalid(t *testing.T) {
+	svc := NewService(&mock.WebhookPersistenceServiceMock{})
+
+	testCases := []*anymind.WebhookSubscription{
+		{URL: "ftp://example.com", Events: []string{anymind.WebhookEventDeposit}},
+		{URL: "http://example.com"},
+		{URL: "http://example.com", Events: []string{"unknown"}},
+	}
+
+	for _, tc := range testCases {
+		err := svc.CreateWebhook(context.Background(), tc)
+
+		anyErr := anymind.ParameterError(nil)
+		require.ErrorAs(t, err, &anyErr)
+		require.Equal(t, anymind.ParameterErr, anyErr.Type)
+	}
+}
+
+func TestCreateWebhookGenerateSecret(t *testing.T) {
+	svc := NewService(&mock.WebhookPersistenceServiceMock{
+		CreateWebhookFunc: func(_ context.Context, sub *anymind.WebhookSubscription) error {
+			sub.ID = 1
+
+			return nil
+		},
+	})
+
+	sub := &anymind.WebhookSubscription{
+		URL:    "https://example.com/hook",
+		Events: []string{anymind.WebhookEventDeposit},
+	}
+	require.NoError(t, svc.CreateWebhook(context.Background(), sub))
+	require.Len(t, sub.Secret, 64)
+}
+
+func TestDispatchContinueAfterUpdateFailure(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer receiver.Close()
+
+	first, second := newDelivery(receiver.URL), newDelivery(receiver.URL)
+	second.ID = 43
+
+	var updated []int64
+	svc := NewService(&mock.WebhookPersistenceServiceMock{
+		ClaimDeliveriesFunc: func(_ context.Context, _ time.Time, _ time.Time, _ int) ([]*anymind.WebhookDelivery, error) {
+			return []*anymind.WebhookDelivery{first, second}, nil
+		},
+		UpdateDeliveryFunc: func(_ context.Context, d *anymind.WebhookDelivery) error {
+			updated = append(updated, d.ID)
+			if d.ID == first.ID {
+				return errors.New("connection reset")
+			}
+
+			return nil
+		},
+	})
+
+	n, err := svc.dispatch(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+	require.Equal(t, []int64{42, 43}, updated)
+}
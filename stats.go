@@ -0,0 +1,65 @@
+package anymind
+
+import (
+	"context"
+	"github.com/cockroachdb/apd"
+	"time"
+)
+
+// BucketStats summarize deposits of the hour ending at DateTime, bucket cover (DateTime - 1h, DateTime] like
+// HistoricalData of the same DateTime.
+type BucketStats struct {
+	DateTime time.Time
+	Count    int64
+	// Delta is sum of deposit amounts in the bucket.
+	Delta apd.Decimal
+	// Min, Max and Average are nil when bucket has no deposit, Average is rounded half away from zero to asset scale.
+	Min     *apd.Decimal
+	Max     *apd.Decimal
+	Average *apd.Decimal
+	// Open is balance at the start of the bucket, Close at its end.
+	Open  apd.Decimal
+	Close apd.Decimal
+}
+
+// BucketOf return datetime of the hourly bucket t belongs to, see BucketStats.
+func BucketOf(t time.Time) time.Time {
+	return CandleOf(t, time.Hour)
+}
+
+// FillStats expand sparse bucket stats into one entry per hour between req.Start and req.End, like FillHourly.
+// Open is balance before the first bucket, open and close balance of each bucket are accumulated from it.
+func FillStats(req *HistoricalDataReq, open apd.Decimal, buckets []*BucketStats) ([]*BucketStats, error) {
+	var res []*BucketStats
+
+	pos := 0
+	var balance apd.Decimal
+	balance.Set(&open)
+	for cur := BucketOf(req.Start); !cur.After(req.End); cur = cur.Add(time.Hour) {
+		entry := &BucketStats{DateTime: cur}
+		if pos < len(buckets) && buckets[pos].DateTime.Equal(cur) {
+			*entry = *buckets[pos]
+			pos++
+		}
+
+		entry.Open.Set(&balance)
+		// precision of base context is unlimited, sum is exact.
+		_, err := apd.BaseContext.Add(&entry.Close, &balance, &entry.Delta)
+		if err != nil {
+			return nil, err
+		}
+
+		balance.Set(&entry.Close)
+		res = append(res, entry)
+	}
+
+	return res, nil
+}
+
+// StatsService summarize deposits per hourly bucket.
+//
+//go:generate moq -out src/mock/mock_stats_service.go -pkg mock . StatsService
+type StatsService interface {
+	// Stats return stats of every bucket between req.Start and req.End, req.Quote is not supported.
+	Stats(ctx context.Context, req *HistoricalDataReq) ([]*BucketStats, error)
+}
@@ -0,0 +1,72 @@
+package anymind
+
+import (
+	"context"
+	"github.com/cockroachdb/apd"
+	"time"
+)
+
+// Webhook event types.
+const (
+	WebhookEventDeposit          = "deposit.created"
+	WebhookEventBalanceThreshold = "balance.threshold"
+)
+
+// Webhook delivery status.
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryDead      = "dead"
+)
+
+type WebhookSubscription struct {
+	ID     int64
+	URL    string
+	Events []string
+	Secret string
+	// Thresholds is list of balance which trigger WebhookEventBalanceThreshold when crossed.
+	Thresholds []apd.Decimal
+	// Asset is asset whose balance is compared with Thresholds, DefaultAsset when empty.
+	Asset     string
+	CreatedAt time.Time
+}
+
+type WebhookDelivery struct {
+	ID             int64
+	SubscriptionID int64
+	URL            string
+	Secret         string
+	EventType      string
+	Payload        []byte
+	Status         string
+	Attempts       int
+	NextAttemptAt  time.Time
+	LastError      string
+	CreatedAt      time.Time
+	DeliveredAt    *time.Time
+}
+
+// WebhookPersistenceService store webhook subscriptions and outbox deliveries.
+//
+//go:generate moq -out src/mock/mock_webhook_persistence_service.go -pkg mock . WebhookPersistenceService
+type WebhookPersistenceService interface {
+	CreateWebhook(ctx context.Context, sub *WebhookSubscription) error
+	ListWebhooks(ctx context.Context) ([]*WebhookSubscription, error)
+	DeleteWebhook(ctx context.Context, id int64) error
+	// ClaimDeliveries lease due pending deliveries until leaseUntil, so other dispatcher will not pick them.
+	ClaimDeliveries(ctx context.Context, now time.Time, leaseUntil time.Time, limit int) ([]*WebhookDelivery, error)
+	UpdateDelivery(ctx context.Context, delivery *WebhookDelivery) error
+	ListDeliveries(ctx context.Context, status string, limit int) ([]*WebhookDelivery, error)
+	RedeliverWebhook(ctx context.Context, id int64, at time.Time) error
+}
+
+// WebhookService manage webhook subscriptions and deliveries.
+//
+//go:generate moq -out src/mock/mock_webhook_service.go -pkg mock . WebhookService
+type WebhookService interface {
+	CreateWebhook(ctx context.Context, sub *WebhookSubscription) error
+	ListWebhooks(ctx context.Context) ([]*WebhookSubscription, error)
+	DeleteWebhook(ctx context.Context, id int64) error
+	ListDeliveries(ctx context.Context, status string) ([]*WebhookDelivery, error)
+	Redeliver(ctx context.Context, id int64) error
+}